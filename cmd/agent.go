@@ -13,6 +13,7 @@ import (
 	"github.com/crystaldolphin/crystaldolphin/internal/bus"
 	"github.com/crystaldolphin/crystaldolphin/internal/channels"
 	"github.com/crystaldolphin/crystaldolphin/internal/config"
+	agentcfg "github.com/crystaldolphin/crystaldolphin/internal/config/agent"
 	"github.com/crystaldolphin/crystaldolphin/internal/dependency"
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 	"github.com/crystaldolphin/crystaldolphin/internal/shared/cmdutils"
@@ -23,6 +24,7 @@ var (
 	key      string
 	markdown bool
 	logs     bool
+	dryRun   bool
 )
 
 var agentCmd = &cobra.Command{
@@ -36,6 +38,7 @@ func init() {
 	agentCmd.Flags().StringVarP(&key, "key", "s", "cli:direct", "Routing key")
 	agentCmd.Flags().BoolVar(&markdown, "markdown", true, "Render output as Markdown (no-op: plain output)")
 	agentCmd.Flags().BoolVar(&logs, "logs", false, "Show runtime logs")
+	agentCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the tool calls the model makes without executing them")
 }
 
 func runAgent(_ *cobra.Command, _ []string) error {
@@ -43,6 +46,9 @@ func runAgent(_ *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	if dryRun {
+		applyDryRun(cfg)
+	}
 
 	container, err := dependency.New(cfg)
 	if err != nil {
@@ -67,13 +73,27 @@ func runAgent(_ *cobra.Command, _ []string) error {
 	return runInteractive(loop, manager)
 }
 
+// applyDryRun switches cfg to dry-run mode: tool calls are answered with a
+// placeholder instead of running, and the session is kept in memory so the
+// preview leaves no trace in the saved conversation.
+func applyDryRun(cfg *config.Config) {
+	cfg.Agents.Defaults.DryRun = true
+	cfg.Agents.Defaults.SessionStore = agentcfg.SessionStoreMemory
+	fmt.Fprintln(os.Stderr, "Dry run: tool calls are shown but not executed; this conversation is not saved.")
+}
+
 // runSingleMessage sends one message to the agent and prints the response.
 func runSingleMessage(loop schema.AgentLooper, key string, channel bus.Channel, chatId string) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	msg := bus.NewAgentMessage(channel, "user", chatId, message, key)
+	// Nobody reads the chat while a single message runs, so tool approvals
+	// are denied rather than left waiting for a reply.
+	msg := bus.NewAgentMessageBuilder(channel, "user", chatId, message).
+		RoutingKey(key).
+		Unattended().
+		Build()
 
 	fmt.Fprintf(os.Stderr, "  ↳ thinking...\n")
 
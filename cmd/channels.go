@@ -87,6 +87,16 @@ var channelsStatusCmd = &cobra.Command{
 				yesNo(cfg.Channels.QQ.Enabled),
 				tokenHint(cfg.Channels.QQ.AppID),
 			},
+			{
+				"Webhook",
+				yesNo(cfg.Channels.Webhook.Enabled),
+				func() string {
+					if cfg.Channels.Webhook.URL != "" {
+						return cfg.Channels.Webhook.URL
+					}
+					return "(not configured)"
+				}(),
+			},
 		}
 
 		fmt.Printf("%-12s %-8s %s\n", "Channel", "Enabled", "Configuration")
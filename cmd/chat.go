@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/config"
+	"github.com/crystaldolphin/crystaldolphin/internal/dependency"
+	"github.com/crystaldolphin/crystaldolphin/internal/repl"
+)
+
+var chatKey string
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Interactive chat with line editing and history",
+	Long: "Chat with the agent in a REPL. Arrow keys edit the line and browse history, which is kept in\n" +
+		"~/.nanobot/chat_history. Ctrl+C cancels the running turn; exit or Ctrl+D quits.\n" +
+		"Slash commands (/new, /model, /stop, /status, /help) work as in other channels.",
+	RunE: runChat,
+}
+
+func init() {
+	chatCmd.Flags().StringVarP(&chatKey, "key", "s", "cli:direct", "Routing key")
+	chatCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the tool calls the model makes without executing them")
+}
+
+func runChat(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Load(config.ConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if dryRun {
+		applyDryRun(cfg)
+	}
+
+	container, err := dependency.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	history, err := repl.LoadHistory(repl.HistoryPath(), repl.DefaultHistorySize)
+	if err != nil {
+		slog.Warn("Failed to load chat history", "err", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer cancel()
+
+	// Run consumes the agent bus, so approval answers and subagent results
+	// reach the loop while turns run through ProcessDirect.
+	loop := container.AgentLoop()
+	go func() { _ = loop.Run(ctx) }()
+
+	fmt.Printf("%s Interactive chat (type /help for commands, exit or Ctrl+D to quit)\n\n", logo)
+
+	r := repl.New(
+		loop,
+		container.AgentBus(),
+		container.ChannelBus().Subscribe(),
+		repl.NewEditor(os.Stdin, os.Stdout, history),
+		history,
+		chatKey,
+		os.Stdout,
+	)
+	return r.Run(ctx)
+}
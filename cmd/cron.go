@@ -7,7 +7,6 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/crystaldolphin/crystaldolphin/internal/bus"
 	"github.com/crystaldolphin/crystaldolphin/internal/config"
 	"github.com/crystaldolphin/crystaldolphin/internal/cron"
 	"github.com/crystaldolphin/crystaldolphin/internal/dependency"
@@ -72,7 +71,7 @@ var (
 	cronAddTZ      string
 	cronAddAt      string
 	cronAddDeliver bool
-	cronAddTo      string
+	cronAddTo      []string
 	cronAddChannel string
 )
 
@@ -129,7 +128,7 @@ func init() {
 	cronAddCmd.Flags().StringVar(&cronAddTZ, "tz", "", "IANA timezone for --cron")
 	cronAddCmd.Flags().StringVar(&cronAddAt, "at", "", "Run once at ISO datetime")
 	cronAddCmd.Flags().BoolVarP(&cronAddDeliver, "deliver", "d", false, "Deliver response to channel")
-	cronAddCmd.Flags().StringVar(&cronAddTo, "to", "", "Recipient ID for delivery")
+	cronAddCmd.Flags().StringSliceVar(&cronAddTo, "to", nil, "Recipient ID(s) for delivery (comma-separated or repeated)")
 	cronAddCmd.Flags().StringVar(&cronAddChannel, "channel", "", "Channel for delivery")
 
 	_ = cronAddCmd.MarkFlagRequired("name")
@@ -196,17 +195,7 @@ var cronRunCmd = &cobra.Command{
 
 		svc := cron.NewService(cronStorePath())
 		svc.OnJobFunc(func(ctx context.Context, job cron.CronJob) (string, error) {
-			ch := bus.ChannelCLI
-			chatID := "direct"
-			if job.Payload.Channel != nil {
-				ch = bus.Channel(*job.Payload.Channel)
-			}
-			if job.Payload.To != nil {
-				chatID = *job.Payload.To
-			}
-
-			msg := bus.NewAgentMessage(ch, bus.SenderIdCLI, chatID, job.Payload.Message, "cron:"+job.ID)
-			resp := loop.ProcessDirect(ctx, msg)
+			resp := loop.ProcessDirect(ctx, cron.JobMessage(job))
 
 			cmdutils.PrintResponse(resp)
 
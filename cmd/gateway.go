@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -46,18 +47,21 @@ var gatewayStartCmd = &cobra.Command{
 	RunE:  runGatewayStart,
 }
 
-func runGatewayStart(_ *cobra.Command, _ []string) error {
+func runGatewayStart(cmd *cobra.Command, _ []string) error {
 	cfg, err := config.Load(config.ConfigPath())
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	if cmd.Flags().Changed("port") {
+		cfg.Gateway.Port = gatewayPort
+	}
 
 	svc, err := dependency.New(cfg)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("%s Starting crystaldolphin gateway on port %d...\n", logo, gatewayPort)
+	fmt.Printf("%s Starting crystaldolphin gateway on port %d...\n", logo, cfg.Gateway.Port)
 
 	if err := writePIDFile(); err != nil {
 		return err
@@ -70,32 +74,26 @@ func runGatewayStart(_ *cobra.Command, _ []string) error {
 	cronManager := svc.CronService()
 
 	cronManager.OnJobFunc(func(ctx context.Context, job cron.CronJob) (string, error) {
-		routingKey := "cron:" + job.ID
-		ch := bus.ChannelCLI
-		chatId := "direct"
-		if job.Payload.Channel != nil {
-			ch = bus.Channel(*job.Payload.Channel)
-		}
-		if job.Payload.To != nil {
-			chatId = *job.Payload.To
-		}
-
-		msg := bus.NewAgentMessage(ch, bus.SenderIdCLI, chatId, job.Payload.Message, routingKey)
-		resp := agentLoop.ProcessDirect(ctx, msg)
-		if job.Payload.Deliver && job.Payload.To != nil {
-			channelBus.Publish(bus.NewChannelMessage(ch, chatId, resp))
-		}
+		resp := agentLoop.ProcessQueued(ctx, cron.JobMessage(job))
+		cron.Deliver(channelBus, job, resp)
 		return resp, nil
 	})
 
 	heartbeat := heartbeat.NewService(cfg.WorkspacePath(),
 		func(ctx context.Context, content string) error {
-			agentLoop.ProcessDirect(ctx, bus.NewAgentMessage(bus.ChannelHeartbeat, bus.SenderIdCLI, "direct", content, "heartbeat:direct"))
+			agentLoop.ProcessQueued(ctx, bus.NewAgentMessage(bus.ChannelHeartbeat, bus.SenderIdCLI, "direct", content, "heartbeat:direct"))
 			return nil
 		},
 		0,
 	)
 
+	var mux *http.ServeMux
+	if cfg.Gateway.Metrics || cfg.Gateway.Admin {
+		if mux, err = newGatewayMux(cfg, cronManager); err != nil {
+			return err
+		}
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 
 	defer stop()
@@ -113,6 +111,10 @@ func runGatewayStart(_ *cobra.Command, _ []string) error {
 	g.Go(func() error { return heartbeat.Start(gctx) })
 	g.Go(func() error { return cronManager.Start(gctx) })
 	g.Go(func() error { return channelManager.StartAll(gctx) })
+	if mux != nil {
+		g.Go(func() error { return serveHTTP(gctx, gatewayAddr(cfg), mux) })
+		fmt.Printf("✓ HTTP endpoints on http://%s\n", gatewayAddr(cfg))
+	}
 
 	fmt.Printf("%s Gateway running. Press Ctrl+C to stop.\n", logo)
 
@@ -4,8 +4,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/config"
+	"github.com/crystaldolphin/crystaldolphin/internal/logging"
 )
 
 const version = "0.1.0"
@@ -16,6 +20,21 @@ var rootCmd = &cobra.Command{
 	Use:   "crystaldolphin",
 	Short: logo + " crystaldolphin — Personal AI Assistant",
 	Long:  logo + " crystaldolphin — a lightweight personal AI assistant framework",
+
+	PersistentPreRun: func(_ *cobra.Command, _ []string) { setupLogging() },
+}
+
+// setupLogging configures the global logger from the logging config section.
+// A bad setting is reported and the default logger is kept, so a typo in the
+// config never stops a command from running.
+func setupLogging() {
+	cfg, err := config.Load(config.ConfigPath())
+	if err != nil {
+		return
+	}
+	if _, err := logging.Setup(cfg.Logging, filepath.Join(config.DataDir(), "logs")); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 }
 
 // Execute runs the root command and exits on error.
@@ -31,9 +50,13 @@ func init() {
 
 	rootCmd.AddCommand(onboardCmd)
 	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(chatCmd)
 	rootCmd.AddCommand(gatewayCmd)
+	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(cronCmd)
+	rootCmd.AddCommand(sessionsCmd)
+	rootCmd.AddCommand(skillsCmd)
 	rootCmd.AddCommand(channelsCmd)
 	rootCmd.AddCommand(providerCmd)
 }
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/config"
+	"github.com/crystaldolphin/crystaldolphin/internal/cron"
+	"github.com/crystaldolphin/crystaldolphin/internal/dependency"
+	"github.com/crystaldolphin/crystaldolphin/internal/metrics"
+	"github.com/crystaldolphin/crystaldolphin/internal/server"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+)
+
+var serveOpenAI bool
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the agent over HTTP APIs",
+	Long: "Serve the agent over HTTP on gateway.host:gateway.port.\n" +
+		"Requests must carry gateway.authToken, which is required, as a bearer token.",
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().BoolVar(&serveOpenAI, "openai", false, "Expose an OpenAI-compatible /v1/chat/completions endpoint")
+}
+
+func runServe(_ *cobra.Command, _ []string) error {
+	if !serveOpenAI {
+		return fmt.Errorf("nothing to serve: pass --openai")
+	}
+
+	cfg, err := config.Load(config.ConfigPath())
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if cfg.Gateway.AuthToken == "" {
+		// The API runs agent turns with tool access on gateway.host, which
+		// defaults to all interfaces.
+		return fmt.Errorf("serve --openai requires gateway.authToken")
+	}
+
+	svc, err := dependency.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	mux, err := newGatewayMux(cfg, svc.CronService())
+	if err != nil {
+		return err
+	}
+	server.NewOpenAIServer(svc.AgentLoop(), cfg.Agents.Defaults.Model, cfg.Gateway.AuthToken).Register(mux)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("%s Serving OpenAI-compatible API on http://%s/v1\n", logo, gatewayAddr(cfg))
+	if err := serveHTTP(ctx, gatewayAddr(cfg), mux); err != nil {
+		return err
+	}
+	fmt.Println("\nShutdown complete.")
+	return nil
+}
+
+// newGatewayMux returns a mux with the routes every gateway HTTP server
+// carries: /metrics when gateway.metrics is enabled and the /api admin
+// routes, backed by jobs, when gateway.admin is enabled.
+func newGatewayMux(cfg *config.Config, jobs *cron.JobManager) (*http.ServeMux, error) {
+	mux := http.NewServeMux()
+	if cfg.Gateway.Metrics {
+		mux.Handle("/metrics", server.RequireToken(cfg.Gateway.AuthToken, metrics.Default.Handler()))
+	}
+	if cfg.Gateway.Admin {
+		if cfg.Gateway.AuthToken == "" {
+			return nil, fmt.Errorf("gateway.admin requires gateway.authToken")
+		}
+		sessions, err := session.NewManager(cfg.WorkspacePath())
+		if err != nil {
+			return nil, err
+		}
+		server.NewAdminServer(jobs, sessions, cfg.Gateway.AuthToken).Register(mux)
+	}
+	return mux, nil
+}
+
+func gatewayAddr(cfg *config.Config) string {
+	return net.JoinHostPort(cfg.Gateway.Host, strconv.Itoa(cfg.Gateway.Port))
+}
+
+// serveHTTP serves handler on addr until ctx is cancelled, then shuts the
+// server down gracefully.
+func serveHTTP(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
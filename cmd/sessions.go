@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/config"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage conversation sessions",
+}
+
+func init() {
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	sessionsCmd.AddCommand(sessionsPruneCmd)
+}
+
+// ---- export ----------------------------------------------------------------
+
+var sessionsExportFormat string
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <key>",
+	Short: "Print a session as a Markdown transcript or JSON",
+	Long: "Print a saved session to stdout. Keys look like \"telegram:12345678\" or \"cli:direct\".\n" +
+		"--format md writes a readable transcript; --format json writes the metadata and raw messages.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		mgr, err := sessionManager()
+		if err != nil {
+			return err
+		}
+		out, err := mgr.Export(args[0], sessionsExportFormat)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	},
+}
+
+func init() {
+	sessionsExportCmd.Flags().StringVarP(&sessionsExportFormat, "format", "f", session.ExportMarkdown, "Output format: md or json")
+}
+
+// ---- prune -----------------------------------------------------------------
+
+var sessionsPruneOlderThan string
+
+var sessionsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete sessions not updated within a given age",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		age, err := parseAge(sessionsPruneOlderThan)
+		if err != nil {
+			return err
+		}
+		mgr, err := sessionManager()
+		if err != nil {
+			return err
+		}
+		n, err := mgr.PruneOlderThan(age)
+		fmt.Printf("✓ Removed %d session(s) older than %s\n", n, sessionsPruneOlderThan)
+		return err
+	},
+}
+
+func init() {
+	sessionsPruneCmd.Flags().StringVar(&sessionsPruneOlderThan, "older-than", "30d", "Age cutoff, e.g. 30d, 12h")
+}
+
+// sessionManager opens the JSONL session store of the configured workspace.
+func sessionManager() (*session.Manager, error) {
+	cfg, err := config.Load(config.ConfigPath())
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return session.NewManager(cfg.WorkspacePath())
+}
+
+// parseAge accepts Go durations ("12h") plus whole days ("30d").
+func parseAge(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		var days int
+		days, err = strconv.Atoi(n)
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --older-than %q: use e.g. '30d' or '12h'", s)
+	}
+	return d, nil
+}
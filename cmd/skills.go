@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/agent"
+	"github.com/crystaldolphin/crystaldolphin/internal/config"
+)
+
+var skillsCmd = &cobra.Command{
+	Use:   "skills",
+	Short: "Manage workspace skills",
+}
+
+func init() {
+	skillsCmd.AddCommand(skillsInstallCmd)
+	skillsCmd.AddCommand(skillsEnableCmd)
+	skillsCmd.AddCommand(skillsDisableCmd)
+}
+
+// ---- install ---------------------------------------------------------------
+
+var skillsInstallForce bool
+
+var skillsInstallCmd = &cobra.Command{
+	Use:   "install <source>",
+	Short: "Install skills from a git repository or archive URL",
+	Long: "Install skills into workspace/skills. <source> is git+https://… (shallow clone)\n" +
+		"or a .zip/.tar.gz URL. It must contain a SKILL.md at its root or in each skill directory.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		loader, err := skillsLoader()
+		if err != nil {
+			return err
+		}
+		names, err := loader.Install(context.Background(), args[0], skillsInstallForce)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Installed %s\n", strings.Join(names, ", "))
+		return nil
+	},
+}
+
+func init() {
+	skillsInstallCmd.Flags().BoolVar(&skillsInstallForce, "force", false, "Replace skills that are already installed")
+}
+
+// ---- enable / disable ------------------------------------------------------
+
+var skillsEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Expose a disabled workspace skill to the agent again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return setSkillEnabled(args[0], true)
+	},
+}
+
+var skillsDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Hide a workspace skill from the agent without deleting it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return setSkillEnabled(args[0], false)
+	},
+}
+
+func setSkillEnabled(name string, enabled bool) error {
+	loader, err := skillsLoader()
+	if err != nil {
+		return err
+	}
+	if err := loader.SetEnabled(name, enabled); err != nil {
+		return err
+	}
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	fmt.Printf("✓ Skill %s %s\n", name, state)
+	return nil
+}
+
+// skillsLoader opens the skills of the configured workspace.
+func skillsLoader() (*agent.SkillsLoader, error) {
+	cfg, err := config.Load(config.ConfigPath())
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return agent.NewSkillsLoader(cfg.WorkspacePath(), ""), nil
+}
@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/llmutils"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+// Approver decides whether a tool call that needs human approval may run.
+type Approver interface {
+	Approve(ctx context.Context, toolName string, args map[string]any) bool
+}
+
+// ApprovalGate asks its Approver before running any tool listed in
+// tools.requireApproval. A nil gate allows everything.
+type ApprovalGate struct {
+	required map[string]bool
+	approver Approver
+}
+
+// NewApprovalGate returns a gate for the named tools, or nil when none are
+// listed.
+func NewApprovalGate(toolNames []string, approver Approver) *ApprovalGate {
+	if len(toolNames) == 0 {
+		return nil
+	}
+	required := make(map[string]bool, len(toolNames))
+	for _, name := range toolNames {
+		required[name] = true
+	}
+	return &ApprovalGate{required: required, approver: approver}
+}
+
+// allow reports whether the tool call may run.
+func (g *ApprovalGate) allow(ctx context.Context, name string, args map[string]any) bool {
+	if g == nil || !g.required[name] {
+		return true
+	}
+	ok := g.approver.Approve(ctx, name, args)
+	slog.Info("Tool approval", "tool", name, "approved", ok)
+	return ok
+}
+
+// Resolve hands msg to the approver when it answers a pending request,
+// reporting whether msg was consumed.
+func (g *ApprovalGate) Resolve(msg bus.AgentMessage) bool {
+	if g == nil {
+		return false
+	}
+	r, ok := g.approver.(interface{ Resolve(bus.AgentMessage) bool })
+	return ok && r.Resolve(msg)
+}
+
+// BusApprover asks the user in the chat of the current turn: it publishes an
+// approval request on the channel bus and waits for a yes/no reply, which
+// AgentLoop.Run passes to Resolve before queueing the message.
+type BusApprover struct {
+	bus     *bus.ChannelBus
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string][]chan bool // "channel:chat:sender" → waiting calls, oldest first
+}
+
+// NewBusApprover creates a BusApprover that denies requests unanswered
+// within timeout.
+func NewBusApprover(b *bus.ChannelBus, timeout time.Duration) *BusApprover {
+	return &BusApprover{bus: b, timeout: timeout, pending: map[string][]chan bool{}}
+}
+
+// Approve implements Approver. Turns with no user to ask (cron, heartbeat,
+// system, the OpenAI API and unattended runs) are denied straight away. Only
+// the user who started the turn can answer, so in a group chat one member
+// cannot approve a tool call requested by another.
+func (a *BusApprover) Approve(ctx context.Context, toolName string, args map[string]any) bool {
+	turn := tools.TurnCtx(ctx)
+	if turn.Unattended || !hasResponder(turn.Channel) {
+		slog.Warn("Tool needs approval but no user can answer; denying", "tool", toolName, "channel", turn.Channel)
+		return false
+	}
+
+	key := approvalKey(turn.Channel, turn.ChatID, turn.SenderID)
+	reply := make(chan bool, 1)
+	a.mu.Lock()
+	a.pending[key] = append(a.pending[key], reply)
+	a.mu.Unlock()
+	defer a.forget(key, reply)
+
+	argsJSON, _ := json.Marshal(args)
+	prompt := fmt.Sprintf(
+		"Approval needed: run %s with %s?\nReply \"yes\" to allow or \"no\" to deny (expires in %s).",
+		toolName, llmutils.Truncate(string(argsJSON), 500), a.timeout)
+	a.bus.Publish(bus.NewChannelMessageBuilder(turn.Channel, turn.ChatID, prompt).
+		Metadata(map[string]any{bus.MetadataButtons: [][]bus.Button{{{Text: "Yes", Data: "yes"}, {Text: "No", Data: "no"}}}}).
+		Build())
+
+	// The turn's slot is free for other conversations while the user decides.
+	defer releaseTurnSlot(ctx)()
+
+	timer := time.NewTimer(a.timeout)
+	defer timer.Stop()
+	select {
+	case ok := <-reply:
+		return ok
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Resolve answers the oldest pending request its sender made in msg's chat
+// when msg is a yes/no reply, reporting whether msg was consumed.
+func (a *BusApprover) Resolve(msg bus.AgentMessage) bool {
+	ok, isAnswer := parseApproval(msg.Content())
+	if !isAnswer {
+		return false
+	}
+	key := approvalKey(msg.Channel(), msg.ChatId(), msg.SenderId())
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	waiting := a.pending[key]
+	if len(waiting) == 0 {
+		return false
+	}
+	waiting[0] <- ok
+	a.pending[key] = waiting[1:]
+	return true
+}
+
+// hasResponder reports whether a user can reply in chats on channel. OpenAI
+// API requests get their reply only when the turn ends, so they cannot.
+func hasResponder(channel bus.Channel) bool {
+	switch channel {
+	case "", bus.ChannelCron, bus.ChannelHeartbeat, bus.ChannelSystem, bus.ChannelOpenAI:
+		return false
+	}
+	return true
+}
+
+// approvalKey identifies the pending requests a sender can answer in a chat.
+func approvalKey(channel bus.Channel, chatID, senderID string) string {
+	return string(channel) + ":" + chatID + ":" + senderID
+}
+
+// forget removes reply from the pending list once its call has returned.
+func (a *BusApprover) forget(key string, reply chan bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	waiting := a.pending[key]
+	for i, ch := range waiting {
+		if ch == reply {
+			waiting = append(waiting[:i], waiting[i+1:]...)
+			break
+		}
+	}
+	if len(waiting) == 0 {
+		delete(a.pending, key)
+	} else {
+		a.pending[key] = waiting
+	}
+}
+
+// parseApproval interprets a chat reply as approve (true) or deny (false).
+func parseApproval(text string) (approved, isAnswer bool) {
+	switch strings.Trim(strings.ToLower(strings.TrimSpace(text)), ".!") {
+	case "yes", "y", "approve", "allow", "ok":
+		return true, true
+	case "no", "n", "deny", "reject":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+// fakeApprover answers every request with allow and records what it was asked.
+type fakeApprover struct {
+	allow bool
+	asked []string
+}
+
+func (a *fakeApprover) Approve(_ context.Context, name string, _ map[string]any) bool {
+	a.asked = append(a.asked, name)
+	return a.allow
+}
+
+func runGatedTurn(t *testing.T, approver Approver, toolName string) (*probeTool, *scriptedProvider) {
+	t.Helper()
+	probe := &probeTool{onCall: func() {}}
+	p := &scriptedProvider{responses: []schema.LLMResponse{
+		{ToolCalls: []schema.ToolCallResponse{{Id: "a", Name: "probe"}}},
+		textResponse("done", "stop"),
+	}}
+	r := newLoopRunner(p, schema.AgentSettings{MaxIter: 5}, NewApprovalGate([]string{toolName}, approver), nil)
+	tls := tools.NewRegistryBuilder().Tool(probe).Build().GetAll()
+	r.run(context.Background(), schema.NewMessages(schema.NewUserMessage("go")), &tls, nil)
+	return probe, p
+}
+
+func TestApprovalGate_DeniedToolIsNotRun(t *testing.T) {
+	approver := &fakeApprover{allow: false}
+	probe, p := runGatedTurn(t, approver, "probe")
+	if probe.calls != 0 || len(approver.asked) != 1 {
+		t.Fatalf("expected one denied request and no run, got %d runs, asked %v", probe.calls, approver.asked)
+	}
+	msgs := p.sent[1].Messages
+	if last := msgs[len(msgs)-1]; last.Role != schema.RoleTool || !strings.Contains(last.Content.(string), "denied by user") {
+		t.Errorf("expected denial fed back to the model, got %+v", last)
+	}
+}
+
+func TestApprovalGate_ApprovedAndUnlistedToolsRun(t *testing.T) {
+	approver := &fakeApprover{allow: true}
+	if probe, _ := runGatedTurn(t, approver, "probe"); probe.calls != 1 {
+		t.Errorf("expected approved tool to run, got %d runs", probe.calls)
+	}
+	approver.asked = nil
+	if probe, _ := runGatedTurn(t, approver, "exec"); probe.calls != 1 || len(approver.asked) != 0 {
+		t.Errorf("expected unlisted tool to run without asking, got %d runs, asked %v", probe.calls, approver.asked)
+	}
+}
+
+func TestBusApprover_ReplyResolvesRequest(t *testing.T) {
+	out := bus.NewChannelBus(10)
+	a := NewBusApprover(out, time.Minute)
+	gate := NewApprovalGate([]string{"exec"}, a)
+	ctx := tools.WithTurn(context.Background(), tools.TurnContext{Channel: bus.ChannelTelegram, ChatID: "42", SenderID: "u1"})
+
+	result := make(chan bool)
+	go func() { result <- a.Approve(ctx, "exec", map[string]any{"command": "ls"}) }()
+
+	req := <-out.Subscribe()
+	if req.ChatId() != "42" || !strings.Contains(req.Content(), `run exec with {"command":"ls"}`) {
+		t.Fatalf("unexpected approval request: %q", req.Content())
+	}
+	if gate.Resolve(bus.NewAgentMessage(bus.ChannelTelegram, "u1", "7", "yes", "")) {
+		t.Error("a reply from another chat must not resolve the request")
+	}
+	if gate.Resolve(bus.NewAgentMessage(bus.ChannelTelegram, "u2", "42", "yes", "")) {
+		t.Error("another member of the chat must not resolve the request")
+	}
+	if gate.Resolve(bus.NewAgentMessage(bus.ChannelTelegram, "u1", "42", "what?", "")) {
+		t.Error("a non-answer must not be consumed")
+	}
+	if !gate.Resolve(bus.NewAgentMessage(bus.ChannelTelegram, "u1", "42", "Yes", "")) {
+		t.Fatal("expected the answer to be consumed")
+	}
+	if !<-result {
+		t.Error("expected approval")
+	}
+	if gate.Resolve(bus.NewAgentMessage(bus.ChannelTelegram, "u1", "42", "no", "")) {
+		t.Error("nothing should be pending after the answer")
+	}
+}
+
+func TestBusApprover_DeniesOnTimeoutOrWithoutUser(t *testing.T) {
+	out := bus.NewChannelBus(10)
+	a := NewBusApprover(out, 20*time.Millisecond)
+	ctx := tools.WithTurn(context.Background(), tools.TurnContext{Channel: bus.ChannelTelegram, ChatID: "42"})
+	if a.Approve(ctx, "exec", nil) {
+		t.Error("expected denial on timeout")
+	}
+	cron := tools.WithTurn(context.Background(), tools.TurnContext{Channel: bus.ChannelCron, ChatID: "job"})
+	openai := tools.WithTurn(context.Background(), tools.TurnContext{Channel: bus.ChannelOpenAI, ChatID: "alice"})
+	oneShot := tools.WithTurn(context.Background(), tools.TurnContext{Channel: bus.ChannelCLI, ChatID: "direct", Unattended: true})
+	for _, ctx := range []context.Context{cron, openai, oneShot, context.Background()} {
+		start := time.Now()
+		if a.Approve(ctx, "exec", nil) {
+			t.Errorf("expected denial when no user can answer: %+v", tools.TurnCtx(ctx))
+		}
+		if time.Since(start) >= 20*time.Millisecond {
+			t.Errorf("expected an immediate denial, waited %s", time.Since(start))
+		}
+	}
+	if len(out.Subscribe()) != 1 {
+		t.Errorf("expected only the timed-out request to be published, got %d", len(out.Subscribe()))
+	}
+}
+
+func TestBusApprover_WaitReleasesTurnSlot(t *testing.T) {
+	out := bus.NewChannelBus(10)
+	a := NewBusApprover(out, time.Minute)
+	slots := make(chan struct{}, 1)
+	slots <- struct{}{} // held by the turn asking for approval
+	ctx := withTurnSlot(context.Background(), slots)
+	ctx = tools.WithTurn(ctx, tools.TurnContext{Channel: bus.ChannelTelegram, ChatID: "42", SenderID: "u1"})
+
+	result := make(chan bool)
+	go func() { result <- a.Approve(ctx, "exec", nil) }()
+	<-out.Subscribe()
+
+	// While the user decides, another turn can take the slot.
+	select {
+	case slots <- struct{}{}:
+	case <-time.After(time.Second):
+		t.Fatal("the waiting turn kept its slot")
+	}
+	if !a.Resolve(bus.NewAgentMessage(bus.ChannelTelegram, "u1", "42", "yes", "")) {
+		t.Fatal("expected the answer to be consumed")
+	}
+	select {
+	case <-result:
+		t.Fatal("the approved turn went on without a free slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	<-slots // the other turn ends
+	if !<-result {
+		t.Error("expected approval")
+	}
+	if len(slots) != 1 {
+		t.Errorf("expected the approved turn to hold its slot again, got %d tokens", len(slots))
+	}
+}
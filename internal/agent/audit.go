@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/llmutils"
+)
+
+// auditQueueSize is how many records may wait for the writer before new
+// ones are dropped.
+const auditQueueSize = 1024
+
+// AuditRecord is one line of the tool audit log.
+type AuditRecord struct {
+	Time       time.Time `json:"ts"`
+	SessionKey string    `json:"session"`
+	Tool       string    `json:"tool"`
+	Args       string    `json:"args"`
+	Result     string    `json:"result"`
+	Outcome    string    `json:"outcome"` // ok, error, timeout, stopped, denied, not_found, dry_run
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// AuditLog appends an AuditRecord per tool call to a JSONL file. Records are
+// queued and written by a background goroutine so a slow disk never delays a
+// turn; when the queue is full a record is dropped and a warning logged.
+// A nil *AuditLog records nothing.
+type AuditLog struct {
+	out      io.WriteCloser
+	maxChars int
+	records  chan AuditRecord
+	done     chan struct{}
+	dropped  atomic.Int64
+
+	closeOnce sync.Once
+	mu        sync.RWMutex // guards records against send-after-close
+	closed    bool
+}
+
+// NewAuditLog opens (appending to) the audit log at path, creating its
+// directory. Args and results are cut to maxChars.
+func NewAuditLog(path string, maxChars int) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return newAuditLog(f, maxChars), nil
+}
+
+func newAuditLog(out io.WriteCloser, maxChars int) *AuditLog {
+	a := &AuditLog{
+		out:      out,
+		maxChars: maxChars,
+		records:  make(chan AuditRecord, auditQueueSize),
+		done:     make(chan struct{}),
+	}
+	go a.write()
+	return a
+}
+
+// Record queues rec without blocking, truncating its args and result.
+func (a *AuditLog) Record(rec AuditRecord) {
+	if a == nil {
+		return
+	}
+	if a.maxChars > 0 {
+		rec.Args = llmutils.Truncate(rec.Args, a.maxChars)
+		rec.Result = llmutils.Truncate(rec.Result, a.maxChars)
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+	select {
+	case a.records <- rec:
+	default:
+		if n := a.dropped.Add(1); n == 1 || n%100 == 0 {
+			slog.Warn("Audit log queue full; records dropped", "dropped", n)
+		}
+	}
+}
+
+// Close writes the queued records and closes the file.
+func (a *AuditLog) Close() error {
+	if a == nil {
+		return nil
+	}
+	var err error
+	a.closeOnce.Do(func() {
+		a.mu.Lock()
+		a.closed = true
+		close(a.records)
+		a.mu.Unlock()
+		<-a.done
+		err = a.out.Close()
+	})
+	return err
+}
+
+// write encodes queued records, flushing whenever the queue drains.
+func (a *AuditLog) write() {
+	defer close(a.done)
+	w := bufio.NewWriter(a.out)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for rec := range a.records {
+		if err := enc.Encode(rec); err != nil {
+			slog.Warn("Failed to write audit record", "err", err)
+		}
+		if len(a.records) == 0 {
+			if err := w.Flush(); err != nil {
+				slog.Warn("Failed to flush audit log", "err", err)
+			}
+		}
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+func readAuditRecords(t *testing.T, path string) []AuditRecord {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var recs []AuditRecord
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec AuditRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("bad audit line %q: %v", sc.Text(), err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestRun_AuditsEachToolCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "tools.jsonl")
+	audit, err := NewAuditLog(path, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetch := &slowTool{name: "web_fetch", release: make(chan struct{})}
+	close(fetch.release)
+	p := &scriptedProvider{responses: []schema.LLMResponse{
+		{ToolCalls: []schema.ToolCallResponse{
+			{Id: "a", Name: "probe", Arguments: map[string]any{"path": "x.txt", "apiToken": "s3cret"}},
+			{Id: "b", Name: "web_fetch"},
+		}},
+		textResponse("done", "stop"),
+	}}
+	r := newLoopRunner(p, schema.AgentSettings{MaxIter: 5}, nil, audit)
+	tls := tools.NewRegistryBuilder().Tool(&probeTool{onCall: func() {}}).Tool(fetch).Build().GetAll()
+	ctx := tools.WithTurn(context.Background(), tools.TurnContext{SessionKey: "cli:direct"})
+
+	r.run(ctx, schema.NewMessages(schema.NewUserMessage("go")), &tls, nil)
+	if err := audit.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recs := readAuditRecords(t, path)
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 audit records, got %d: %+v", len(recs), recs)
+	}
+	want := []struct{ tool, args, result string }{
+		{"probe", `{"apiToken":"[REDACTED]","path":"x.txt"}`, "ok"},
+		{"web_fetch", "null", "finished"},
+	}
+	for i, w := range want {
+		rec := recs[i]
+		if rec.Tool != w.tool || rec.Args != w.args || rec.Result != w.result {
+			t.Errorf("record %d: got %+v, want %+v", i, rec, w)
+		}
+		if rec.SessionKey != "cli:direct" || rec.Outcome != "ok" || rec.Time.IsZero() {
+			t.Errorf("record %d: missing session, outcome or time: %+v", i, rec)
+		}
+	}
+}
+
+func TestAuditLog_TruncatesFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.jsonl")
+	audit, err := NewAuditLog(path, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit.Record(AuditRecord{Tool: "read_file", Args: `{"path":"a"}`, Result: "0123456789"})
+	audit.Close()
+
+	recs := readAuditRecords(t, path)
+	if len(recs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(recs))
+	}
+	if recs[0].Args != `{"pat...` || recs[0].Result != "01234..." {
+		t.Errorf("expected truncated fields, got %+v", recs[0])
+	}
+}
+
+func TestAuditLog_NilIsNoop(t *testing.T) {
+	var audit *AuditLog
+	audit.Record(AuditRecord{Tool: "exec"})
+	if err := audit.Close(); err != nil {
+		t.Errorf("nil Close: %v", err)
+	}
+}
@@ -112,7 +112,7 @@ For normal conversation, just respond with text - do not call the message tool.
 Always be helpful, accurate, and concise. Before calling tools, briefly tell the user what you're about to do (one short sentence in the user's language).
 If you need to use tools, call them directly — never send a preliminary message like "Let me check" without actually calling a tool.
 When remembering something important, write to %s/memory/MEMORY.md
-To recall past events, grep %s/memory/HISTORY.md`,
+To recall past events, use the search_memory tool (it searches %s/memory/HISTORY.md)`,
 		now, tz,
 		runtimeStr,
 		wsExpanded,
@@ -22,7 +22,7 @@ type CoreAgent struct {
 // Execute implements schema.Agent.
 // conversation must be fully built by the caller (system prompt + history + user message).
 // It connects MCP servers on the first call (no-op on subsequent calls).
-func (a *CoreAgent) Execute(ctx context.Context, conversation schema.Messages, onProgress func(string)) (string, []string) {
+func (a *CoreAgent) Execute(ctx context.Context, conversation schema.Messages, onProgress func(string)) (string, []string, schema.Messages, map[string]int) {
 	a.mcpManager.ConnectOnce(ctx, a.tools)
 
 	return a.run(ctx, conversation, a.tools, onProgress)
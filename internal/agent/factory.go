@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"log/slog"
+
 	"github.com/crystaldolphin/crystaldolphin/internal/mcp"
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 	"github.com/crystaldolphin/crystaldolphin/internal/tools"
@@ -16,6 +18,8 @@ type AgentFactory struct {
 	coreTools   *tools.ToolList      // pointer to AgentLoop.tools — wired via SetCoreTools
 	subTools    tools.ToolList       // value copy of restricted registry — no MCP tools
 	mcpManager  *mcp.Manager
+	approvals   *ApprovalGate // tools that need a human yes before running; may be nil
+	audit       *AuditLog     // tool-call audit log; may be nil
 	workspace   string
 }
 
@@ -27,6 +31,8 @@ func NewFactory(
 	settings, subSettings schema.AgentSettings,
 	subRegistry *tools.Registry,
 	mcpManager *mcp.Manager,
+	approvals *ApprovalGate,
+	audit *AuditLog,
 	workspace string,
 ) *AgentFactory {
 	return &AgentFactory{
@@ -35,13 +41,19 @@ func NewFactory(
 		subSettings: subSettings,
 		subTools:    subRegistry.GetAll(),
 		mcpManager:  mcpManager,
+		approvals:   approvals,
+		audit:       audit,
 		workspace:   workspace,
 	}
 }
 
-// Close shuts down MCP server subprocesses. Called by AgentLoop.Run on exit.
+// Close shuts down MCP server subprocesses and flushes the audit log.
+// Called by AgentLoop.Run on exit.
 func (f *AgentFactory) Close() {
 	f.mcpManager.Close()
+	if err := f.audit.Close(); err != nil {
+		slog.Warn("Failed to close audit log", "err", err)
+	}
 }
 
 // SetCoreTools wires the factory to the AgentLoop's live ToolList.
@@ -52,9 +64,14 @@ func (f *AgentFactory) SetCoreTools(tls *tools.ToolList) {
 }
 
 // NewCoreAgent creates a CoreAgent ready to execute one user message.
-func (f *AgentFactory) NewCoreAgent() *CoreAgent {
+// A non-empty model overrides the configured one for this agent only.
+func (f *AgentFactory) NewCoreAgent(model string) *CoreAgent {
+	settings := f.settings
+	if model != "" {
+		settings.Model = model
+	}
 	return &CoreAgent{
-		LoopRunner: newLoopRunner(f.provider, f.settings),
+		LoopRunner: newLoopRunner(f.provider, settings, f.approvals, f.audit),
 		tools:      f.coreTools,
 		mcpManager: f.mcpManager,
 	}
@@ -63,7 +80,7 @@ func (f *AgentFactory) NewCoreAgent() *CoreAgent {
 // NewSubAgent creates a SubAgent ready to execute one background task.
 func (f *AgentFactory) NewSubAgent() *SubAgent {
 	return &SubAgent{
-		LoopRunner: newLoopRunner(f.provider, f.subSettings),
+		LoopRunner: newLoopRunner(f.provider, f.subSettings, f.approvals, f.audit),
 		tools:      f.subTools,
 		workspace:  f.workspace,
 	}
@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+)
+
+// inboundQueue buffers messages waiting for a turn slot, releasing them
+// highest-priority first and FIFO within a priority, so a burst of cron or
+// system work never delays interactive users.
+type inboundQueue struct {
+	mu     sync.Mutex
+	items  inboundHeap
+	seq    uint64
+	notify chan struct{} // one token per pending wakeup, capped at worker count
+}
+
+func newInboundQueue(workers int) *inboundQueue {
+	return &inboundQueue{notify: make(chan struct{}, workers)}
+}
+
+// queuedMsg is a message waiting in the queue. Messages queued by
+// ProcessQueued carry the caller's ctx and a reply channel for the result;
+// bus messages have neither and their result is published.
+type queuedMsg struct {
+	bus.AgentMessage
+	ctx   context.Context
+	reply chan *bus.ChannelMessage
+}
+
+// push enqueues a bus message and wakes an idle worker.
+func (q *inboundQueue) push(msg bus.AgentMessage) {
+	q.pushQueued(queuedMsg{AgentMessage: msg})
+}
+
+// pushQueued enqueues msg and wakes an idle worker.
+func (q *inboundQueue) pushQueued(msg queuedMsg) {
+	q.mu.Lock()
+	q.seq++
+	heap.Push(&q.items, inboundItem{msg: msg, seq: q.seq})
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// tryPop removes and returns the highest-priority message, if any.
+func (q *inboundQueue) tryPop() (queuedMsg, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.items.Len() == 0 {
+		return queuedMsg{}, false
+	}
+	return heap.Pop(&q.items).(inboundItem).msg, true
+}
+
+// pop blocks until a message is available or ctx is cancelled.
+func (q *inboundQueue) pop(ctx context.Context) (queuedMsg, bool) {
+	for {
+		if msg, ok := q.tryPop(); ok {
+			return msg, true
+		}
+		select {
+		case <-q.notify:
+		case <-ctx.Done():
+			return queuedMsg{}, false
+		}
+	}
+}
+
+type inboundItem struct {
+	msg queuedMsg
+	seq uint64
+}
+
+// inboundHeap implements heap.Interface ordered by priority, then arrival.
+type inboundHeap []inboundItem
+
+func (h inboundHeap) Len() int { return len(h) }
+func (h inboundHeap) Less(i, j int) bool {
+	if pi, pj := h[i].msg.Priority(), h[j].msg.Priority(); pi != pj {
+		return pi > pj
+	}
+	return h[i].seq < h[j].seq
+}
+func (h inboundHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *inboundHeap) Push(x any)   { *h = append(*h, x.(inboundItem)) }
+func (h *inboundHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
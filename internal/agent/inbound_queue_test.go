@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+)
+
+func TestInboundQueue_HighPriorityFirst(t *testing.T) {
+	q := newInboundQueue(1)
+	q.push(bus.NewAgentMessage(bus.ChannelCron, "cron", "job1", "cron 1", ""))
+	q.push(bus.NewAgentMessage(bus.ChannelSystem, "subagent", "cli:direct", "system", ""))
+	q.push(bus.NewAgentMessage(bus.ChannelCron, "cron", "job2", "cron 2", ""))
+	q.push(bus.NewAgentMessage(bus.ChannelTelegram, "42", "555", "user", ""))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var got []string
+	for range 4 {
+		msg, ok := q.pop(ctx)
+		if !ok {
+			t.Fatal("queue drained early")
+		}
+		got = append(got, msg.Content())
+	}
+
+	want := []string{"user", "system", "cron 1", "cron 2"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+}
+
+func TestInboundQueue_PopBlocksUntilPush(t *testing.T) {
+	q := newInboundQueue(2)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.push(bus.NewAgentMessageBuilder(bus.ChannelCron, "cron", "job", "late").Priority(bus.PriorityHigh).Build())
+	}()
+
+	msg, ok := q.pop(ctx)
+	if !ok || msg.Content() != "late" || msg.Priority() != bus.PriorityHigh {
+		t.Fatalf("unexpected pop: ok=%v msg=%q", ok, msg.Content())
+	}
+}
@@ -2,11 +2,14 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/providers"
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 	"github.com/crystaldolphin/crystaldolphin/internal/session"
 	"github.com/crystaldolphin/crystaldolphin/internal/shared/llmutils"
@@ -15,23 +18,42 @@ import (
 
 // AgentLoop is the core processing engine.
 //
-// It reads InboundMessages from the bus, routes each message to the
-// appropriate channel-kind handler, and publishes OutboundMessages.
-// Each inbound message is handled in its own goroutine.
+// It reads InboundMessages from the bus into a priority queue, which also
+// takes scheduled work from ProcessQueued. Up to settings.MaxConcurrentTurns
+// queued messages run at once; each is routed to the appropriate
+// channel-kind handler and its OutboundMessage published. /stop bypasses the
+// queue so it can cancel a turn that holds a slot, and a turn waiting for a
+// tool approval gives its slot up until the user answers.
 type AgentLoop struct {
 	agentBus   *bus.AgentBus
 	channelBus *bus.ChannelBus
 	settings   schema.AgentSettings
 	pctx       *PromptContext
-	sessions   *session.Manager
+	sessions   session.SessionStore
 	compactor  schema.MemoryCompactor
 	tools      tools.ToolList // MCP registration target; factory holds &loop.tools
 	subagents  *SubagentManager
 
 	runner  LoopRunner    // shared LLM iteration logic (used by handleSystemChannel)
 	factory *AgentFactory // creates per-request CoreAgent / SubAgent instances
+
+	queue *inboundQueue // messages waiting for a turn slot
+	slots chan struct{} // one token per running queued turn
+
+	turnsMu sync.Mutex
+	turns   map[string]*activeTurn // session key → in-flight turn, for /stop
 }
 
+// activeTurn is a running agent turn that /stop can cancel.
+type activeTurn struct {
+	cancel context.CancelCauseFunc
+}
+
+// errTurnStopped is the cancellation cause of a turn ended by /stop.
+var errTurnStopped = errors.New("turn stopped by user")
+
+const turnStoppedReply = "Stopped."
+
 // NewAgentLoop creates an AgentLoop with the supplied factory, tool registry, and
 // subagent manager.
 func NewAgentLoop(
@@ -39,7 +61,7 @@ func NewAgentLoop(
 	channelBus *bus.ChannelBus,
 	factory *AgentFactory,
 	settings schema.AgentSettings,
-	sessions *session.Manager,
+	sessions session.SessionStore,
 	compactor schema.MemoryCompactor,
 	registry *tools.Registry,
 	subagents *SubagentManager,
@@ -54,8 +76,11 @@ func NewAgentLoop(
 		compactor:  compactor,
 		tools:      registry.GetAll(),
 		subagents:  subagents,
-		runner:     newLoopRunner(factory.provider, settings),
+		runner:     newLoopRunner(factory.provider, settings, factory.approvals, factory.audit),
 		factory:    factory,
+		queue:      newInboundQueue(1),
+		slots:      make(chan struct{}, max(settings.MaxConcurrentTurns, 1)),
+		turns:      map[string]*activeTurn{},
 	}
 	// Wire the factory's coreTools pointer to this loop's live ToolList so that
 	// MCP tools added via ConnectOnce are visible to every CoreAgent created by
@@ -64,15 +89,26 @@ func NewAgentLoop(
 	return loop
 }
 
-// Run reads from the inbound bus and processes each message in a goroutine.
-// Blocks until ctx is cancelled.
+// Run reads from the inbound bus into a priority queue that is drained up to
+// settings.MaxConcurrentTurns turns at a time, so interactive messages are
+// picked up ahead of queued cron/system work. Blocks until ctx is cancelled.
 func (loop *AgentLoop) Run(ctx context.Context) error {
 	slog.Info("Agent loop started")
 
+	go loop.dispatch(ctx)
+
 	for {
 		select {
 		case msg := <-loop.agentBus.Subscribe():
-			go loop.consumeMessage(ctx, msg)
+			if loop.factory.approvals.Resolve(msg) {
+				continue // answered a pending tool approval
+			}
+			if isStopCommand(msg) {
+				// Must not wait behind the busy workers running the turn it stops.
+				go loop.consumeMessage(ctx, msg)
+				continue
+			}
+			loop.queue.push(msg)
 		case <-ctx.Done():
 			slog.Info("Agent loop stopping")
 			loop.factory.Close()
@@ -92,6 +128,58 @@ func (loop *AgentLoop) ProcessDirect(ctx context.Context, msg bus.AgentMessage)
 	return res.Content()
 }
 
+// ProcessQueued handles scheduled work (cron, heartbeat) like ProcessDirect,
+// but msg waits in the priority queue for a turn slot, so it counts against
+// settings.MaxConcurrentTurns and yields to interactive users. Run must be
+// running. Returns "" if ctx ends first.
+func (loop *AgentLoop) ProcessQueued(ctx context.Context, msg bus.AgentMessage) string {
+	reply := make(chan *bus.ChannelMessage, 1)
+	loop.queue.pushQueued(queuedMsg{AgentMessage: msg, ctx: ctx, reply: reply})
+	select {
+	case res := <-reply:
+		if res == nil {
+			return ""
+		}
+		return res.Content()
+	case <-ctx.Done():
+		return ""
+	}
+}
+
+// dispatch starts the highest-priority queued message whenever a turn slot
+// is free, until ctx is cancelled.
+func (loop *AgentLoop) dispatch(ctx context.Context) {
+	for {
+		select {
+		case loop.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		msg, ok := loop.queue.pop(ctx)
+		if !ok {
+			<-loop.slots
+			return
+		}
+		go func() {
+			defer func() { <-loop.slots }()
+			loop.runQueued(ctx, msg)
+		}()
+	}
+}
+
+// runQueued runs one dequeued message in the slot taken for it by dispatch.
+func (loop *AgentLoop) runQueued(ctx context.Context, msg queuedMsg) {
+	if msg.reply == nil {
+		loop.consumeMessage(withTurnSlot(ctx, loop.slots), msg.AgentMessage)
+		return
+	}
+	if msg.ctx.Err() != nil {
+		msg.reply <- nil // the caller gave up while it was queued
+		return
+	}
+	msg.reply <- loop.routeMessage(withTurnSlot(msg.ctx, loop.slots), msg.AgentMessage)
+}
+
 func (loop *AgentLoop) consumeMessage(ctx context.Context, msg bus.AgentMessage) {
 	resp := loop.routeMessage(ctx, msg)
 
@@ -143,21 +231,26 @@ func (loop *AgentLoop) handleSystemChannel(ctx context.Context, msg bus.AgentMes
 	key := channelStr + ":" + chatId
 	sess := loop.sessions.GetOrCreate(key)
 
-	ctx = tools.WithTurn(ctx, tools.TurnContext{Channel: channel, ChatID: chatId})
+	ctx = tools.WithTurn(ctx, tools.TurnContext{
+		Channel:    channel,
+		ChatID:     chatId,
+		SessionKey: key,
+		Workspace:  scopedWorkspace(loop.factory.workspace, loop.settings.WorkspaceScope, channel, key),
+	})
 
 	conversation := loop.pctx.BuildMessages(
-		sess.History(loop.settings.MemoryWindow),
+		sess.History(loop.settings.MemoryWindow, loop.settings.HistoryIncludeTools),
 		msg.Content(),
 		nil,
 		channel,
 		chatId,
 	)
 
-	final, _ := loop.runner.run(ctx, conversation, &loop.tools, nil)
+	final, _, _, usage := loop.runner.run(ctx, conversation, &loop.tools, nil)
 	final = llmutils.StringOrDefault(final, "Background task completed.")
 
 	sess.AddUser(fmt.Sprintf("[System: %s] %s", msg.SenderId(), msg.Content()))
-	sess.AddAssistant(final, nil)
+	sess.AddAssistant(final, nil, usage)
 	loop.sessions.Save(sess)
 
 	out := bus.NewChannelMessage(channel, chatId, final)
@@ -172,8 +265,9 @@ func (loop *AgentLoop) handleCLIChannel(ctx context.Context, msg bus.AgentMessag
 }
 
 // handleCronChannel handles messages arriving on the cron channel.
-// Cron always uses ProcessDirect (bypassing the bus); if a message
-// somehow arrives on the bus the pipeline runs but no outbound is published.
+// Cron runs through ProcessQueued or ProcessDirect (bypassing the bus); if a
+// message somehow arrives on the bus the pipeline runs but no outbound is
+// published.
 func (loop *AgentLoop) handleCronChannel(ctx context.Context, msg bus.AgentMessage) *bus.ChannelMessage {
 	loop.handleExternalChannel(ctx, msg)
 
@@ -181,7 +275,7 @@ func (loop *AgentLoop) handleCronChannel(ctx context.Context, msg bus.AgentMessa
 }
 
 // handleHeartbeatChannel handles messages arriving on the heartbeat channel.
-// Heartbeat always uses ProcessDirect (bypassing the bus); if a message
+// Heartbeat runs through ProcessQueued (bypassing the bus); if a message
 // somehow arrives on the bus the pipeline runs but no outbound is published.
 func (loop *AgentLoop) handleHeartbeatChannel(ctx context.Context, msg bus.AgentMessage) *bus.ChannelMessage {
 	loop.handleExternalChannel(ctx, msg)
@@ -202,33 +296,48 @@ func (loop *AgentLoop) handleExternalChannel(ctx context.Context, msg bus.AgentM
 	)
 
 	key := msg.RoutingKey()
-	ses := loop.sessions.GetOrCreate(key)
+	store := loop.sessions
+	if msg.Ephemeral() {
+		// Stateless request: the session lives only for this turn, so it is
+		// never written out, cached or consolidated.
+		store = session.NewInMemoryStore()
+	}
+	ses := store.GetOrCreate(key)
 
-	if resp := loop.handleSlashCommand(msg, ses, key); resp != nil {
+	if resp := loop.handleSlashCommand(msg, store, ses, key); resp != nil {
 		return resp
 	}
 
-	loop.compactor.Schedule(key, ses, false)
+	if !msg.Ephemeral() {
+		loop.compactor.Schedule(key, ses, false)
+	}
+
+	ctx, endTurn := loop.beginTurn(ctx, key)
+	defer endTurn()
 
 	ctx, msgSentChan := loop.withTurnContext(ctx, msg)
 
 	conversation := loop.pctx.BuildMessages(
-		ses.History(loop.settings.MemoryWindow),
+		ses.History(loop.settings.MemoryWindow, loop.settings.HistoryIncludeTools),
 		msg.Content(),
 		msg.Media(),
 		msg.Channel(),
 		msg.ChatId(),
 	)
 
-	core := loop.factory.NewCoreAgent()
-	final, toolsUsed := core.Execute(ctx, conversation, loop.progressCallback(msg))
+	core := loop.factory.NewCoreAgent(ses.Model())
+	final, toolsUsed, steps, usage := core.Execute(ctx, conversation, loop.progressCallback(msg))
+	if errors.Is(context.Cause(ctx), errTurnStopped) {
+		final = turnStoppedReply
+	}
 
 	// If the message tool sent something, suppress the automatic reply.
 	select {
 	case <-msgSentChan:
 		ses.AddUser(msg.Content())
-		ses.AddAssistant(final, toolsUsed)
-		loop.sessions.Save(ses)
+		ses.AddSteps(steps)
+		ses.AddAssistant(final, toolsUsed, usage)
+		store.Save(ses)
 		return nil
 	default:
 	}
@@ -240,8 +349,9 @@ func (loop *AgentLoop) handleExternalChannel(ctx context.Context, msg bus.AgentM
 	slog.Info("Response", "channel", msg.Channel(), "sender", msg.SenderId(), "length", len(final))
 
 	ses.AddUser(msg.Content())
-	ses.AddAssistant(final, toolsUsed)
-	loop.sessions.Save(ses)
+	ses.AddSteps(steps)
+	ses.AddAssistant(final, toolsUsed, usage)
+	store.Save(ses)
 
 	out := bus.NewChannelMessageBuilder(msg.Channel(), msg.ChatId(), final).
 		Metadata(msg.Metadata()).
@@ -254,40 +364,175 @@ func (loop *AgentLoop) handleExternalChannel(ctx context.Context, msg bus.AgentM
 // it. Returns non-nil if the command was handled (caller should return early).
 func (loop *AgentLoop) handleSlashCommand(
 	msg bus.AgentMessage,
+	store session.SessionStore,
 	ses *session.ChannelSessionImpl,
 	key string,
 ) *bus.ChannelMessage {
 	cmd := strings.TrimSpace(strings.ToLower(msg.Content()))
 	switch cmd {
 	case "/new":
-		return loop.handleCmdNew(msg, ses, key)
+		return loop.handleCmdNew(msg, store, ses, key)
+	case "/stop":
+		return loop.handleCmdStop(msg, key)
+	case "/status":
+		return loop.handleCmdStatus(msg, ses, key)
 	case "/help":
 		return loop.handleCmdHelp(msg)
 	}
+	if name, ok := strings.CutPrefix(strings.TrimSpace(msg.Content()), "/model"); ok && (name == "" || name[0] == ' ') {
+		return loop.handleCmdModel(msg, store, ses, strings.TrimSpace(name))
+	}
 	return nil
 }
 
 // handleCmdNew clears the current session and triggers background memory
 // consolidation, then replies with a confirmation.
-func (loop *AgentLoop) handleCmdNew(msg bus.AgentMessage, sess *session.ChannelSessionImpl, key string) *bus.ChannelMessage {
+func (loop *AgentLoop) handleCmdNew(msg bus.AgentMessage, store session.SessionStore, sess *session.ChannelSessionImpl, key string) *bus.ChannelMessage {
 	archived := sess.Messages()
 	sess.Clear()
-	loop.sessions.Save(sess)
-	loop.sessions.Invalidate(key)
+	sess.SetModel("")
+	store.Save(sess)
+	store.Invalidate(key)
 
 	tmp := session.NewArchivedSession(key, archived)
 	loop.compactor.Schedule(key+":archive", tmp, true)
 
-	out := bus.NewChannelMessageBuilder(msg.Channel(), msg.ChatId(), "New session started. Memory consolidation in progress.").
+	out := bus.NewChannelMessageBuilder(msg.Channel(), msg.ChatId(), "New session started. Memory consolidation in progress; use /status to check on it.").
+		Metadata(msg.Metadata()).
+		Build()
+
+	return &out
+}
+
+// handleCmdStop cancels the turn in progress for key, if any. The cancelled
+// turn replies with turnStoppedReply once its provider call or tool returns.
+func (loop *AgentLoop) handleCmdStop(msg bus.AgentMessage, key string) *bus.ChannelMessage {
+	text := "Nothing to stop."
+	loop.turnsMu.Lock()
+	if t, ok := loop.turns[key]; ok {
+		t.cancel(errTurnStopped)
+		text = "Stopping the current task…"
+	}
+	loop.turnsMu.Unlock()
+
+	out := bus.NewChannelMessageBuilder(msg.Channel(), msg.ChatId(), text).
 		Metadata(msg.Metadata()).
 		Build()
 
 	return &out
 }
 
+// beginTurn registers a cancellable turn for key and returns its context and
+// the function that unregisters it when the turn ends.
+func (loop *AgentLoop) beginTurn(ctx context.Context, key string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	t := &activeTurn{cancel: cancel}
+
+	loop.turnsMu.Lock()
+	loop.turns[key] = t
+	loop.turnsMu.Unlock()
+
+	return ctx, func() {
+		loop.turnsMu.Lock()
+		if loop.turns[key] == t {
+			delete(loop.turns, key)
+		}
+		loop.turnsMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// isStopCommand reports whether msg is a /stop command.
+func isStopCommand(msg bus.AgentMessage) bool {
+	return strings.TrimSpace(strings.ToLower(msg.Content())) == "/stop"
+}
+
+// handleCmdModel reports the session's model, or switches the session to the
+// model given as an argument once it resolves to a known provider. The choice
+// is kept in the session metadata until /new.
+func (loop *AgentLoop) handleCmdModel(msg bus.AgentMessage, store session.SessionStore, ses *session.ChannelSessionImpl, name string) *bus.ChannelMessage {
+	var text string
+	switch {
+	case name == "":
+		model := loop.sessionModel(ses)
+		text = "Current model: " + model
+		if model != loop.settings.Model {
+			text += fmt.Sprintf(" (default %s; /new resets it)", loop.settings.Model)
+		}
+		if err := providers.CheckModel(model); err != nil {
+			text += "\nWarning: " + err.Error()
+		}
+	default:
+		if err := providers.CheckModel(name); err != nil {
+			text = "Error: " + err.Error()
+			break
+		}
+		if name == loop.settings.Model {
+			ses.SetModel("")
+			text = "Switched this conversation back to the default model " + name + "."
+		} else {
+			ses.SetModel(name)
+			text = fmt.Sprintf("Switched this conversation to %s. Use /new to return to %s.", name, loop.settings.Model)
+		}
+		store.Save(ses)
+	}
+
+	out := bus.NewChannelMessageBuilder(msg.Channel(), msg.ChatId(), text).
+		Metadata(msg.Metadata()).
+		Build()
+
+	return &out
+}
+
+// sessionModel returns the model turns of ses run with.
+func (loop *AgentLoop) sessionModel(ses *session.ChannelSessionImpl) string {
+	if model := ses.Model(); model != "" {
+		return model
+	}
+	return loop.settings.Model
+}
+
+// handleCmdStatus reports the active model and the session's memory
+// consolidation state, covering both regular and /new archive runs.
+func (loop *AgentLoop) handleCmdStatus(msg bus.AgentMessage, ses *session.ChannelSessionImpl, key string) *bus.ChannelMessage {
+	st := latestCompactionStatus(loop.compactor.Status(key), loop.compactor.Status(key+":archive"))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Model: %s\nMemory consolidation: %s", loop.sessionModel(ses), st.State)
+	switch {
+	case st.State == schema.CompactionFailed:
+		fmt.Fprintf(&b, " at %s: %s", st.FinishedAt.Format("15:04"), st.LastError)
+	case st.State == schema.CompactionIdle && !st.FinishedAt.IsZero():
+		fmt.Fprintf(&b, " (last finished %s)", st.FinishedAt.Format("15:04"))
+	}
+
+	out := bus.NewChannelMessageBuilder(msg.Channel(), msg.ChatId(), b.String()).
+		Metadata(msg.Metadata()).
+		Build()
+
+	return &out
+}
+
+// latestCompactionStatus merges statuses for one session: any in-flight run
+// wins, otherwise the most recently finished run decides idle vs failed.
+func latestCompactionStatus(a, b schema.CompactionStatus) schema.CompactionStatus {
+	active := func(s schema.CompactionStatus) bool {
+		return s.State == schema.CompactionRunning || s.State == schema.CompactionQueued
+	}
+	switch {
+	case active(a):
+		return a
+	case active(b):
+		return b
+	case b.FinishedAt.After(a.FinishedAt):
+		return b
+	}
+	return a
+}
+
 // handleCmdHelp returns the help text listing available slash commands.
 func (loop *AgentLoop) handleCmdHelp(msg bus.AgentMessage) *bus.ChannelMessage {
-	out := bus.NewChannelMessageBuilder(msg.Channel(), msg.ChatId(), "crystaldolphin commands:\n/new — Start a new conversation\n/help — Show available commands").
+	out := bus.NewChannelMessageBuilder(msg.Channel(), msg.ChatId(), "crystaldolphin commands:\n/new — Start a new conversation\n/model [name] — Show or switch the model for this conversation\n/stop — Stop the task in progress\n/status — Show the model and memory consolidation state\n/help — Show available commands").
 		Metadata(msg.Metadata()).
 		Build()
 
@@ -306,7 +551,12 @@ func (loop *AgentLoop) withTurnContext(ctx context.Context, msg bus.AgentMessage
 		Channel:     msg.Channel(),
 		ChatID:      msg.ChatId(),
 		MsgID:       msgID,
+		SenderID:    msg.SenderId(),
+		Unattended:  msg.Unattended(),
+		SessionKey:  msg.RoutingKey(),
+		Workspace:   scopedWorkspace(loop.factory.workspace, loop.settings.WorkspaceScope, msg.Channel(), msg.RoutingKey()),
 		MessageSent: msgSent,
+		Progress:    loop.progressCallback(msg),
 	})
 	return ctx, msgSent
 }
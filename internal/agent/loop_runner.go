@@ -5,45 +5,83 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/crystaldolphin/crystaldolphin/internal/metrics"
+	"github.com/crystaldolphin/crystaldolphin/internal/providers"
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 	"github.com/crystaldolphin/crystaldolphin/internal/shared/llmutils"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/redact"
 	"github.com/crystaldolphin/crystaldolphin/internal/tools"
 )
 
+// continueNudge asks the model to resume a reply cut off by max_tokens.
+const continueNudge = "Your previous reply was cut off by the output length limit. Continue exactly where it stopped, without repeating anything."
+
 // LoopRunner executes the LLM ↔ tool iteration loop.
 // It is embedded by CoreAgent and SubAgent to share the loop body.
 type LoopRunner struct {
-	provider schema.LLMProvider
-	settings schema.AgentSettings
+	provider  schema.LLMProvider
+	settings  schema.AgentSettings
+	approvals *ApprovalGate // nil when no tool needs approval
+	audit     *AuditLog     // nil when tools.audit is off
 }
 
-func newLoopRunner(provider schema.LLMProvider, settings schema.AgentSettings) LoopRunner {
-	return LoopRunner{provider: provider, settings: settings}
+func newLoopRunner(provider schema.LLMProvider, settings schema.AgentSettings, approvals *ApprovalGate, audit *AuditLog) LoopRunner {
+	return LoopRunner{provider: provider, settings: settings, approvals: approvals, audit: audit}
 }
 
 // run is the canonical LLM ↔ tool loop body shared by CoreAgent and SubAgent.
 // tls is passed by pointer so CoreAgent can share AgentLoop.tools (MCP-extended live map).
-func (r *LoopRunner) run(ctx context.Context, conversation schema.Messages, tls *tools.ToolList, onProgress func(string)) (finalContent string, toolsUsed []string) {
+// steps holds the intermediate assistant tool-call and tool-result messages of
+// the turn, in order, so callers can persist the full transcript.
+// A text reply stopped by max_tokens is continued up to MaxContinuations
+// times and the pieces are joined into the final content.
+// usage is the token count of the final reply, summed over its continuations;
+// each tool-call step carries the usage of the response that produced it.
+func (r *LoopRunner) run(ctx context.Context, conversation schema.Messages, tls *tools.ToolList, onProgress func(string)) (finalContent string, toolsUsed []string, steps schema.Messages, usage map[string]int) {
+	var (
+		partial       strings.Builder // text of replies cut off by max_tokens
+		continuations int
+	)
 	for i := 0; i < r.settings.MaxIter; i++ {
+		if ctx.Err() != nil {
+			return turnStoppedReply, toolsUsed, steps, nil
+		}
+		start := time.Now()
 		resp, err := r.provider.Chat(ctx,
 			conversation,
 			tls.Definitions(),
 			schema.NewChatOptions(r.settings.Model, r.settings.MaxTokens, r.settings.Temperature),
 		)
+		r.recordLLMCall(start, err)
 
 		if err != nil {
+			if ctx.Err() != nil {
+				return turnStoppedReply, toolsUsed, steps, nil
+			}
 			slog.Error("LLM error", "err", err)
-			return "Sorry, I encountered an error calling the LLM.", nil
+			return "Sorry, I encountered an error calling the LLM.", nil, steps, nil
 		}
 
 		if len(resp.ToolCalls) == 0 {
 			// Terminal response.
+			usage = addUsage(usage, resp.Usage)
 			content := ""
 			if resp.Content != nil {
 				content = *resp.Content
 			}
-			return llmutils.StripThink(content), toolsUsed
+			if resp.FinishReason == "length" && continuations < r.settings.MaxContinuations {
+				continuations++
+				slog.Info("Reply hit max_tokens; continuing", "continuation", continuations)
+				partial.WriteString(content)
+				conversation.AddAssistant(resp.Content, nil, resp.ReasoningContent)
+				conversation.AddUser(continueNudge)
+				continue
+			}
+			return llmutils.StripThink(partial.String() + content), toolsUsed, steps, usage
 		}
 
 		// Progress: emit partial text + tool hint.
@@ -63,24 +101,169 @@ func (r *LoopRunner) run(ctx context.Context, conversation schema.Messages, tls
 		}
 
 		conversation.AddAssistant(resp.Content, toolCalls, resp.ReasoningContent)
+		steps.AddAssistant(resp.Content, toolCalls, nil)
+		steps.Messages[len(steps.Messages)-1].Usage = addUsage(nil, resp.Usage)
 
 		// Execute each tool.
 		for _, tc := range resp.ToolCalls {
-			toolsUsed = append(toolsUsed, tc.Name)
-			argsJSON, _ := json.Marshal(tc.Arguments)
-
-			slog.Info("Tool call", "name", tc.Name, "args", llmutils.Truncate(string(argsJSON), 200))
-
 			var result string
-			if t := tls.Get(tc.Name); t != nil {
-				result, _ = t.Execute(ctx, tc.Arguments)
+			if ctx.Err() != nil {
+				// Stopped: keep the transcript well-formed with a result per call.
+				result = "Error: stopped before running"
 			} else {
-				result = fmt.Sprintf("Error: Tool '%s' not found", tc.Name)
+				toolsUsed = append(toolsUsed, tc.Name)
+				// Logged and audited only; the tool itself gets the real values.
+				argsJSON, _ := json.Marshal(redact.Args(tc.Arguments))
+
+				slog.Info("Tool call", "name", tc.Name, "args", llmutils.Truncate(string(argsJSON), 200))
+
+				start := time.Now()
+				var outcome string
+				if t := tls.Get(tc.Name); t == nil {
+					outcome = "not_found"
+					metrics.ToolExecutions.Inc(tc.Name, outcome)
+					result = fmt.Sprintf("Error: Tool '%s' not found", tc.Name)
+				} else if r.settings.DryRun {
+					outcome = "dry_run"
+					result = dryRunResult(tc.Name, tc.Arguments)
+					slog.Info("Dry run: tool not executed", "name", tc.Name, "args", llmutils.Truncate(string(argsJSON), 200))
+				} else if !r.approvals.allow(ctx, tc.Name, tc.Arguments) {
+					outcome = "denied"
+					metrics.ToolExecutions.Inc(tc.Name, outcome)
+					result = fmt.Sprintf("Error: running %s was denied by user. Do not retry it; ask the user how to proceed.", tc.Name)
+				} else {
+					result, outcome = r.execute(ctx, t, tc.Name, tc.Arguments)
+				}
+				r.audit.Record(AuditRecord{
+					Time:       start.UTC(),
+					SessionKey: tools.TurnCtx(ctx).SessionKey,
+					Tool:       tc.Name,
+					Args:       string(argsJSON),
+					Result:     result,
+					Outcome:    outcome,
+					DurationMS: time.Since(start).Milliseconds(),
+				})
 			}
 
+			result = r.truncateResult(tc.Name, result)
 			conversation.AddToolResult(tc.Id, tc.Name, result)
+
+			step := schema.NewToolResultMessage(tc.Id, tc.Name, result)
+			step.ToolArgs = tc.Arguments
+			steps.Add(step)
 		}
 	}
 
-	return "I've reached the maximum number of tool iterations without a final answer.", toolsUsed
+	if partial.Len() > 0 {
+		return llmutils.StripThink(partial.String()), toolsUsed, steps, usage
+	}
+	return "I've reached the maximum number of tool iterations without a final answer.", toolsUsed, steps, nil
+}
+
+// dryRunResult is the placeholder fed back to the model for a tool call that
+// was not executed.
+func dryRunResult(name string, args map[string]any) string {
+	if args == nil {
+		args = map[string]any{}
+	}
+	argsJSON, _ := json.Marshal(args)
+	return fmt.Sprintf("[dry-run: would call %s with %s]", name, argsJSON)
+}
+
+// addUsage adds the prompt and completion token counts of src to dst and
+// returns it, allocating dst on first use. Other keys are ignored.
+func addUsage(dst, src map[string]int) map[string]int {
+	for _, k := range []string{"prompt_tokens", "completion_tokens"} {
+		if n := src[k]; n > 0 {
+			if dst == nil {
+				dst = make(map[string]int, 2)
+			}
+			dst[k] += n
+		}
+	}
+	return dst
+}
+
+// truncateResult cuts result to the byte limit configured for the tool,
+// marking how much was dropped so the model knows the output is incomplete.
+func (r *LoopRunner) truncateResult(name, result string) string {
+	limit, ok := r.settings.ToolResultLimits[name]
+	if !ok {
+		limit = r.settings.MaxToolResultBytes
+	}
+	if limit <= 0 || len(result) <= limit {
+		return result
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(result[cut]) {
+		cut-- // don't split a multi-byte character
+	}
+	return fmt.Sprintf("%s\n...truncated (%d bytes omitted)", result[:cut], len(result)-cut)
+}
+
+// recordLLMCall counts one provider call and its latency.
+func (r *LoopRunner) recordLLMCall(start time.Time, err error) {
+	provider := "unknown"
+	if spec := providers.FindByModel(r.settings.Model); spec != nil {
+		provider = spec.Name
+	}
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	metrics.LLMRequests.Inc(provider, r.settings.Model, status)
+	metrics.LLMDuration.Observe(metrics.Since(start), provider, r.settings.Model)
+}
+
+// execute runs t bounded by settings.ToolTimeout and records its outcome in
+// the metrics; the result is returned with that outcome label.
+// exec is exempt from the bound because it enforces tools.exec.timeout
+// itself. A tool that ignores its context is left running in the background
+// so the turn is not blocked.
+func (r *LoopRunner) execute(ctx context.Context, t schema.Tool, name string, args map[string]any) (string, string) {
+	start := time.Now()
+	result, outcome := r.executeBounded(ctx, t, name, args)
+	metrics.ToolExecutions.Inc(name, outcome)
+	metrics.ToolDuration.Observe(metrics.Since(start), name)
+	return result, outcome
+}
+
+// executeBounded runs t and returns its result with an outcome label:
+// "ok", "error", "timeout" or "stopped".
+func (r *LoopRunner) executeBounded(ctx context.Context, t schema.Tool, name string, args map[string]any) (string, string) {
+	timeout := r.settings.ToolTimeout
+	if timeout <= 0 || name == string(tools.ToolExec) {
+		result, err := t.Execute(ctx, args)
+		return result, toolOutcome(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type toolResult struct {
+		result string
+		err    error
+	}
+	done := make(chan toolResult, 1)
+	go func() {
+		result, err := t.Execute(ctx, args)
+		done <- toolResult{result, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.result, toolOutcome(res.err)
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			slog.Warn("Tool timed out", "name", name, "timeout", timeout)
+			return fmt.Sprintf("Error: tool %s timed out after %s", name, timeout), "timeout"
+		}
+		return "Error: stopped before finishing", "stopped"
+	}
+}
+
+func toolOutcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
 }
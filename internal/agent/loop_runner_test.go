@@ -0,0 +1,222 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/metrics"
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+// scriptedProvider replies with its responses in order and records the
+// conversations it was sent.
+type scriptedProvider struct {
+	responses []schema.LLMResponse
+	sent      []schema.Messages
+	opts      []schema.ChatOptions
+}
+
+func (p *scriptedProvider) Chat(_ context.Context, msgs schema.Messages, _ []map[string]any, opts schema.ChatOptions) (schema.LLMResponse, error) {
+	p.sent = append(p.sent, schema.NewMessages(msgs.Messages...))
+	p.opts = append(p.opts, opts)
+	resp := p.responses[0]
+	p.responses = p.responses[1:]
+	return resp, nil
+}
+func (p *scriptedProvider) DefaultModel() string { return "test" }
+
+func textResponse(text, finish string) schema.LLMResponse {
+	return schema.LLMResponse{Content: &text, FinishReason: finish}
+}
+
+func TestRun_ContinuesLengthLimitedReply(t *testing.T) {
+	p := &scriptedProvider{responses: []schema.LLMResponse{
+		textResponse("The quick brown ", "length"),
+		textResponse("fox jumps.", "stop"),
+	}}
+	r := newLoopRunner(p, schema.AgentSettings{MaxIter: 5, MaxContinuations: 2}, nil, nil)
+	tls := tools.NewToolList()
+
+	final, _, _, _ := r.run(context.Background(), schema.NewMessages(schema.NewUserMessage("go")), tls, nil)
+	if final != "The quick brown fox jumps." {
+		t.Errorf("expected joined reply, got %q", final)
+	}
+	if len(p.sent) != 2 {
+		t.Fatalf("expected one continuation request, got %d calls", len(p.sent))
+	}
+	msgs := p.sent[1].Messages
+	if n := len(msgs); n != 3 || msgs[1].Role != schema.RoleAssistant || msgs[2].Content != continueNudge {
+		t.Errorf("expected partial reply and nudge appended, got %+v", msgs)
+	}
+}
+
+func TestRun_StopsContinuingAtLimit(t *testing.T) {
+	p := &scriptedProvider{responses: []schema.LLMResponse{
+		textResponse("one ", "length"),
+		textResponse("two ", "length"),
+		textResponse("three", "length"),
+	}}
+	r := newLoopRunner(p, schema.AgentSettings{MaxIter: 5, MaxContinuations: 1}, nil, nil)
+	tls := tools.NewToolList()
+
+	final, _, _, _ := r.run(context.Background(), schema.NewMessages(schema.NewUserMessage("go")), tls, nil)
+	if final != "one two " || len(p.sent) != 2 {
+		t.Errorf("expected two pieces from two calls, got %q from %d calls", final, len(p.sent))
+	}
+}
+
+func TestTruncateResult(t *testing.T) {
+	r := newLoopRunner(nil, schema.AgentSettings{
+		MaxToolResultBytes: 10,
+		ToolResultLimits:   map[string]int{"read_file": 2, "exec": 0},
+	}, nil, nil)
+
+	if got := r.truncateResult("web_fetch", "short"); got != "short" {
+		t.Errorf("small result changed: %q", got)
+	}
+	if got := r.truncateResult("web_fetch", "0123456789abcdef"); got != "0123456789\n...truncated (6 bytes omitted)" {
+		t.Errorf("unexpected default truncation: %q", got)
+	}
+	if got := r.truncateResult("read_file", "héllo"); got != "h\n...truncated (5 bytes omitted)" {
+		t.Errorf("expected per-tool limit cut on a rune boundary, got %q", got)
+	}
+	long := strings.Repeat("x", 50)
+	if got := r.truncateResult("exec", long); got != long {
+		t.Errorf("expected a zero per-tool limit to disable the cap, got %q", got)
+	}
+}
+
+// slowTool blocks until release is closed, ignoring its context.
+type slowTool struct {
+	name    string
+	release chan struct{}
+}
+
+func (t *slowTool) Name() string                { return t.name }
+func (t *slowTool) Description() string         { return "slow" }
+func (t *slowTool) Parameters() json.RawMessage { return json.RawMessage(`{"type":"object"}`) }
+func (t *slowTool) Execute(context.Context, map[string]any) (string, error) {
+	<-t.release
+	return "finished", nil
+}
+
+func TestExecute_ToolTimeout(t *testing.T) {
+	r := newLoopRunner(nil, schema.AgentSettings{ToolTimeout: 20 * time.Millisecond}, nil, nil)
+	slow := &slowTool{name: "web_fetch", release: make(chan struct{})}
+	defer close(slow.release)
+
+	start := time.Now()
+	got, _ := r.execute(context.Background(), slow, slow.name, nil)
+	if got != "Error: tool web_fetch timed out after 20ms" {
+		t.Errorf("expected timeout result, got %q", got)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("a tool ignoring its context must not block the turn")
+	}
+}
+
+func TestExecute_ExecIsExemptFromToolTimeout(t *testing.T) {
+	r := newLoopRunner(nil, schema.AgentSettings{ToolTimeout: time.Millisecond}, nil, nil)
+	slow := &slowTool{name: "exec", release: make(chan struct{})}
+	time.AfterFunc(30*time.Millisecond, func() { close(slow.release) })
+
+	if got, _ := r.execute(context.Background(), slow, slow.name, nil); got != "finished" {
+		t.Errorf("expected exec to run to completion, got %q", got)
+	}
+}
+
+func TestRun_RecordsMetrics(t *testing.T) {
+	p := &scriptedProvider{responses: []schema.LLMResponse{
+		{ToolCalls: []schema.ToolCallResponse{{Id: "a", Name: "probe"}, {Id: "b", Name: "missing"}}},
+		textResponse("done", "stop"),
+	}}
+	r := newLoopRunner(p, schema.AgentSettings{MaxIter: 5, Model: "anthropic/claude-test"}, nil, nil)
+	tls := tools.NewRegistryBuilder().Tool(&probeTool{onCall: func() {}}).Build().GetAll()
+
+	llmBefore := metrics.LLMRequests.Value("anthropic", "anthropic/claude-test", "ok")
+	okBefore := metrics.ToolExecutions.Value("probe", "ok")
+	missingBefore := metrics.ToolExecutions.Value("missing", "not_found")
+
+	r.run(context.Background(), schema.NewMessages(schema.NewUserMessage("go")), &tls, nil)
+
+	if got := metrics.LLMRequests.Value("anthropic", "anthropic/claude-test", "ok") - llmBefore; got != 2 {
+		t.Errorf("expected 2 LLM calls recorded, got %v", got)
+	}
+	if metrics.ToolExecutions.Value("probe", "ok")-okBefore != 1 || metrics.ToolExecutions.Value("missing", "not_found")-missingBefore != 1 {
+		t.Error("expected one ok and one not_found tool execution recorded")
+	}
+
+	var out strings.Builder
+	metrics.Default.WriteText(&out)
+	for _, name := range []string{
+		"crystaldolphin_llm_requests_total",
+		"crystaldolphin_llm_request_duration_seconds_bucket",
+		"crystaldolphin_tool_executions_total",
+		"crystaldolphin_tool_duration_seconds_count",
+		"crystaldolphin_cron_runs_total",
+		"crystaldolphin_channel_messages_total",
+	} {
+		if !strings.Contains(out.String(), name) {
+			t.Errorf("expected %s in the exposition", name)
+		}
+	}
+}
+
+func TestRun_ReportsUsage(t *testing.T) {
+	call := textResponse("", "tool_calls")
+	call.Content = nil
+	call.ToolCalls = []schema.ToolCallResponse{{Id: "call_1", Name: "missing"}}
+	call.Usage = map[string]int{"prompt_tokens": 50, "completion_tokens": 4, "total_tokens": 54}
+	first := textResponse("part ", "length")
+	first.Usage = map[string]int{"prompt_tokens": 60, "completion_tokens": 10}
+	second := textResponse("done", "stop")
+	second.Usage = map[string]int{"prompt_tokens": 75, "completion_tokens": 3}
+
+	p := &scriptedProvider{responses: []schema.LLMResponse{call, first, second}}
+	r := newLoopRunner(p, schema.AgentSettings{MaxIter: 5, MaxContinuations: 1}, nil, nil)
+
+	final, _, steps, usage := r.run(context.Background(), schema.NewMessages(schema.NewUserMessage("go")), tools.NewToolList(), nil)
+	if final != "part done" {
+		t.Fatalf("unexpected reply %q", final)
+	}
+	if usage["prompt_tokens"] != 135 || usage["completion_tokens"] != 13 || len(usage) != 2 {
+		t.Errorf("expected usage summed over continuations, got %v", usage)
+	}
+	if u := steps.Messages[0].Usage; u["prompt_tokens"] != 50 || u["completion_tokens"] != 4 {
+		t.Errorf("expected tool-call step usage, got %v", u)
+	}
+}
+
+func TestRun_DryRunSkipsExecution(t *testing.T) {
+	probe := &probeTool{onCall: func() {}}
+	p := &scriptedProvider{responses: []schema.LLMResponse{
+		{ToolCalls: []schema.ToolCallResponse{{Id: "a", Name: "probe", Arguments: map[string]any{"path": "x.txt"}}}},
+		{ToolCalls: []schema.ToolCallResponse{{Id: "b", Name: "probe"}, {Id: "c", Name: "missing"}}},
+		textResponse("done", "stop"),
+	}}
+	approver := &fakeApprover{allow: true}
+	r := newLoopRunner(p, schema.AgentSettings{MaxIter: 5, DryRun: true}, NewApprovalGate([]string{"probe"}, approver), nil)
+	tls := tools.NewRegistryBuilder().Tool(probe).Build().GetAll()
+
+	final, toolsUsed, steps, _ := r.run(context.Background(), schema.NewMessages(schema.NewUserMessage("go")), &tls, nil)
+	if final != "done" || len(p.sent) != 3 {
+		t.Fatalf("expected the loop to finish normally, got %q after %d calls", final, len(p.sent))
+	}
+	if probe.calls != 0 || len(approver.asked) != 0 {
+		t.Errorf("expected no tool run and no approval asked, got %d runs, asked %v", probe.calls, approver.asked)
+	}
+	if len(toolsUsed) != 3 {
+		t.Errorf("expected the intended calls reported, got %v", toolsUsed)
+	}
+	results := []string{steps.Messages[1].Content.(string), steps.Messages[3].Content.(string), steps.Messages[4].Content.(string)}
+	want := []string{`[dry-run: would call probe with {"path":"x.txt"}]`, "[dry-run: would call probe with {}]", "Error: Tool 'missing' not found"}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("result %d = %q, want %q", i, results[i], want[i])
+		}
+	}
+}
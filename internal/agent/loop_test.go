@@ -0,0 +1,273 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/cron"
+	"github.com/crystaldolphin/crystaldolphin/internal/mcp"
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+// modelRecorder answers every Chat call and records the model it was sent.
+type modelRecorder struct {
+	mu     sync.Mutex
+	models []string
+}
+
+func (p *modelRecorder) Chat(_ context.Context, _ schema.Messages, _ []map[string]any, opts schema.ChatOptions) (schema.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.models = append(p.models, opts.Model)
+	reply := "ok"
+	return schema.LLMResponse{Content: &reply}, nil
+}
+func (p *modelRecorder) DefaultModel() string { return "test" }
+
+func (p *modelRecorder) last() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.models[len(p.models)-1]
+}
+
+func newTestLoop(t *testing.T, p schema.LLMProvider, sessions session.SessionStore, extra ...schema.Tool) *AgentLoop {
+	t.Helper()
+	ws := t.TempDir()
+	mem, err := NewMemoryStore(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := schema.AgentSettings{Model: "anthropic/claude-sonnet-4", MaxIter: 3, MemoryWindow: 50}
+	b := tools.NewRegistryBuilder()
+	for _, tool := range extra {
+		b.Tool(tool)
+	}
+	registry := b.Build()
+	factory := NewFactory(p, settings, settings, registry, mcp.NewManager(nil, ws), nil, nil, ws)
+	agentBus := bus.NewAgentBus(10)
+	return NewAgentLoop(
+		agentBus, bus.NewChannelBus(10), factory, settings, sessions,
+		NewCompactor(mem, sessions, p, settings.Model, settings.MemoryWindow, 0, nil),
+		registry, NewSubagentManager(factory, agentBus),
+		NewContextBuilder(ws, mem, NewSkillsLoader(ws, "")),
+	)
+}
+
+func TestModelCommand_OverridePersistsUntilNew(t *testing.T) {
+	p := &modelRecorder{}
+	sessions := session.NewInMemoryStore()
+	loop := newTestLoop(t, p, sessions)
+	ctx := context.Background()
+	send := func(text string) string {
+		return loop.ProcessDirect(ctx, bus.NewAgentMessage(bus.ChannelTelegram, "u1", "42", text, ""))
+	}
+
+	if out := send("/model"); !strings.Contains(out, "Current model: anthropic/claude-sonnet-4") {
+		t.Errorf("expected current model, got %q", out)
+	}
+	if out := send("/model claud-opus"); !strings.HasPrefix(out, "Error:") {
+		t.Errorf("expected unknown model rejected, got %q", out)
+	}
+
+	if out := send("/model anthropic/claude-opus-4"); !strings.Contains(out, "Switched") {
+		t.Fatalf("expected switch confirmation, got %q", out)
+	}
+	for range 2 {
+		send("hard question")
+		if got := p.last(); got != "anthropic/claude-opus-4" {
+			t.Fatalf("expected override model, got %q", got)
+		}
+	}
+	if out := send("/model"); !strings.Contains(out, "Current model: anthropic/claude-opus-4") {
+		t.Errorf("expected override reported, got %q", out)
+	}
+
+	send("/new")
+	send("easy question")
+	if got := p.last(); got != "anthropic/claude-sonnet-4" {
+		t.Errorf("expected /new to restore the default model, got %q", got)
+	}
+}
+
+func TestEphemeralMessages_LeaveNoSession(t *testing.T) {
+	dir := t.TempDir()
+	sessions, err := session.NewManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop := newTestLoop(t, &modelRecorder{}, sessions)
+
+	for i := range 5 {
+		chatID := fmt.Sprintf("req-%d", i)
+		msg := bus.NewAgentMessageBuilder(bus.ChannelOpenAI, chatID, chatID, "hello").Ephemeral().Build()
+		if out := loop.ProcessDirect(context.Background(), msg); out != "ok" {
+			t.Fatalf("unexpected reply %q", out)
+		}
+	}
+	if infos := sessions.List(); len(infos) != 0 {
+		t.Errorf("expected no stored sessions, got %v", infos)
+	}
+	if files, _ := os.ReadDir(filepath.Join(dir, "sessions")); len(files) != 0 {
+		t.Errorf("expected an empty sessions dir, found %d entries", len(files))
+	}
+	if n := sessions.GetOrCreate("openai:req-0").Len(); n != 0 {
+		t.Errorf("expected no cached session, got %d messages", n)
+	}
+}
+
+// toolLooper asks for two probe calls on every turn, never finishing.
+type toolLooper struct{ calls int }
+
+func (p *toolLooper) Chat(_ context.Context, _ schema.Messages, _ []map[string]any, _ schema.ChatOptions) (schema.LLMResponse, error) {
+	p.calls++
+	return schema.LLMResponse{ToolCalls: []schema.ToolCallResponse{
+		{Id: "a", Name: "probe"}, {Id: "b", Name: "probe"},
+	}}, nil
+}
+func (p *toolLooper) DefaultModel() string { return "test" }
+
+// probeTool runs onCall each time it executes.
+type probeTool struct {
+	calls  int
+	onCall func()
+}
+
+func (t *probeTool) Name() string                { return "probe" }
+func (t *probeTool) Description() string         { return "test probe" }
+func (t *probeTool) Parameters() json.RawMessage { return json.RawMessage(`{"type":"object"}`) }
+func (t *probeTool) Execute(context.Context, map[string]any) (string, error) {
+	t.calls++
+	t.onCall()
+	return "ok", nil
+}
+
+func TestStopCommand_CancelsTurn(t *testing.T) {
+	p := &toolLooper{}
+	probe := &probeTool{}
+	loop := newTestLoop(t, p, session.NewInMemoryStore(), probe)
+	ctx := context.Background()
+	msg := func(text string) bus.AgentMessage {
+		return bus.NewAgentMessage(bus.ChannelTelegram, "u1", "42", text, "")
+	}
+
+	var stopReply string
+	probe.onCall = func() { stopReply = loop.ProcessDirect(ctx, msg("/stop")) }
+
+	if out := loop.ProcessDirect(ctx, msg("loop forever")); out != turnStoppedReply {
+		t.Errorf("expected stopped reply, got %q", out)
+	}
+	if !strings.HasPrefix(stopReply, "Stopping") {
+		t.Errorf("expected /stop to find the active turn, got %q", stopReply)
+	}
+	if probe.calls != 1 || p.calls != 1 {
+		t.Errorf("expected no work after /stop, got %d tool and %d LLM calls", probe.calls, p.calls)
+	}
+	if out := loop.ProcessDirect(ctx, msg("/stop")); out != "Nothing to stop." {
+		t.Errorf("expected finished turn to be unregistered, got %q", out)
+	}
+}
+
+// turnGate is a provider that reports the last message of each turn on started and holds
+// turns mentioning "block" until release is closed.
+type turnGate struct {
+	started chan string
+	release chan struct{}
+}
+
+func (p *turnGate) Chat(_ context.Context, msgs schema.Messages, _ []map[string]any, _ schema.ChatOptions) (schema.LLMResponse, error) {
+	text := fmt.Sprint(msgs.Messages[len(msgs.Messages)-1].Content)
+	p.started <- text
+	if strings.Contains(text, "block") {
+		<-p.release
+	}
+	reply := "ok"
+	return schema.LLMResponse{Content: &reply}, nil
+}
+func (p *turnGate) DefaultModel() string { return "test" }
+
+func (p *turnGate) waitStarted(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case got := <-p.started:
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected turn %q to start next, got %q", want, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("turn %q never started", want)
+	}
+}
+
+// waitQueued waits until n messages are waiting for a turn slot.
+func waitQueued(t *testing.T, loop *AgentLoop, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		loop.queue.mu.Lock()
+		got := loop.queue.items.Len()
+		loop.queue.mu.Unlock()
+		if got == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d queued messages, got %d", n, got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestProcessQueued_CronFireWaitsBehindInteractiveTurns(t *testing.T) {
+	p := &turnGate{started: make(chan string, 10), release: make(chan struct{})}
+	loop := newTestLoop(t, p, session.NewInMemoryStore()) // one turn slot
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loop.Run(ctx)
+
+	loop.agentBus.Publish(bus.NewAgentMessage(bus.ChannelTelegram, "u1", "1", "block me", ""))
+	p.waitStarted(t, "block me")
+
+	jobs := cron.NewService(filepath.Join(t.TempDir(), "jobs.json"))
+	fired := make(chan string, 1)
+	jobs.OnJobFunc(func(ctx context.Context, job cron.CronJob) (string, error) {
+		resp := loop.ProcessQueued(ctx, cron.JobMessage(job))
+		fired <- resp
+		return resp, nil
+	})
+	atMs := time.Now().Add(20 * time.Millisecond).UnixMilli()
+	if _, err := jobs.AddJob("report", "cron report", "at", 0, "", "", atMs, false, "", "", false); err != nil {
+		t.Fatal(err)
+	}
+	jobsDone := make(chan struct{})
+	go func() {
+		_ = jobs.Start(ctx)
+		close(jobsDone)
+	}()
+	defer func() { cancel(); <-jobsDone }()
+
+	// The fired job waits for the busy slot; a user message arriving after
+	// it still goes first.
+	waitQueued(t, loop, 1)
+	loop.agentBus.Publish(bus.NewAgentMessage(bus.ChannelTelegram, "u2", "2", "second", ""))
+	waitQueued(t, loop, 2)
+	close(p.release)
+
+	p.waitStarted(t, "second")
+	p.waitStarted(t, "cron report")
+	select {
+	case resp := <-fired:
+		if resp != "ok" {
+			t.Errorf("expected the cron turn's reply, got %q", resp)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cron job never got its reply")
+	}
+}
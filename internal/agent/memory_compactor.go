@@ -29,14 +29,26 @@ type MemoryCompactor struct {
 	model        string
 	memoryWindow int
 
+	// maxMemoryChars caps MEMORY.md; 0 means no cap.
+	maxMemoryChars int
+
 	// Per-session consolidation state (idle=absent, running=1, queued=2).
 	compacting map[string]uint8
-	mu         sync.Mutex
+	// Result of the last finished run per session, for Status.
+	last map[string]compactionResult
+	mu   sync.Mutex
+}
+
+// compactionResult records how a finished consolidation run ended.
+type compactionResult struct {
+	err        error
+	finishedAt time.Time
 }
 
 // NewCompactor returns a MemoryCompactor. The save_memory tool is resolved
 // from reg; if absent it falls back to constructing one directly from store.
-func NewCompactor(store schema.MemoryStore, saver schema.SessionSaver, provider schema.LLMProvider, model string, memoryWindow int, reg *tools.Registry) *MemoryCompactor {
+// maxMemoryChars caps MEMORY.md (0 disables the cap).
+func NewCompactor(store schema.MemoryStore, saver schema.SessionSaver, provider schema.LLMProvider, model string, memoryWindow, maxMemoryChars int, reg *tools.Registry) *MemoryCompactor {
 	registry := tools.NewRegistryBuilder().
 		Tool(tools.NewSaveMemoryTool(store)).
 		Build()
@@ -49,13 +61,18 @@ func NewCompactor(store schema.MemoryStore, saver schema.SessionSaver, provider
 		reg:          registry,
 		memoryWindow: memoryWindow,
 		compacting:   make(map[string]uint8),
+		last:         make(map[string]compactionResult),
+
+		maxMemoryChars: maxMemoryChars,
 	}
 }
 
 // Schedule is the single entry point for all consolidation work.
 // It enforces at most one active goroutine per key with one pending slot.
+// Only user/assistant text counts toward memoryWindow, as in the history the
+// model sees; persisted tool steps do not.
 func (c *MemoryCompactor) Schedule(key string, sess schema.ChannelSession, archiveAll bool) {
-	if sess.Messages().Len() <= c.memoryWindow && !archiveAll {
+	if conversationLen(sess.Messages()) <= c.memoryWindow && !archiveAll {
 		return
 	}
 
@@ -74,6 +91,17 @@ func (c *MemoryCompactor) Schedule(key string, sess schema.ChannelSession, archi
 	go c.performOneAtAtime(key, sess, archiveAll)
 }
 
+// conversationLen counts the user and assistant text messages in msgs.
+func conversationLen(msgs schema.Messages) int {
+	n := 0
+	for _, m := range msgs.Messages {
+		if !m.IsToolStep() {
+			n++
+		}
+	}
+	return n
+}
+
 func (c *MemoryCompactor) performOneAtAtime(key string, sess schema.ChannelSession, archiveAll bool) {
 	for {
 		err := c.Compact(context.Background(), sess, archiveAll)
@@ -83,6 +111,7 @@ func (c *MemoryCompactor) performOneAtAtime(key string, sess schema.ChannelSessi
 		}
 
 		c.mu.Lock()
+		c.last[key] = compactionResult{err: err, finishedAt: time.Now()}
 		if c.compacting[key] == queued {
 			c.compacting[key] = running
 			c.mu.Unlock()
@@ -94,6 +123,30 @@ func (c *MemoryCompactor) performOneAtAtime(key string, sess schema.ChannelSessi
 	}
 }
 
+// Status reports whether consolidation for key is running, queued, idle, or
+// failed, along with the outcome of the last finished run.
+func (c *MemoryCompactor) Status(key string) schema.CompactionStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res := c.last[key]
+	st := schema.CompactionStatus{State: schema.CompactionIdle, FinishedAt: res.finishedAt}
+	if res.err != nil {
+		st.LastError = res.err.Error()
+	}
+	switch c.compacting[key] {
+	case running:
+		st.State = schema.CompactionRunning
+	case queued:
+		st.State = schema.CompactionQueued
+	default:
+		if res.err != nil {
+			st.State = schema.CompactionFailed
+		}
+	}
+	return st
+}
+
 // Compact summarises old session messages into MEMORY.md and HISTORY.md
 // via a single LLM tool call. It is safe to call concurrently for different
 // sessions; the caller must guard against concurrent calls for the same session
@@ -139,6 +192,9 @@ func (c *MemoryCompactor) compactAndSave(ctx context.Context, old schema.Message
 		current,
 		formatMessagesForPrompt(old.Messages),
 	)
+	if c.maxMemoryChars > 0 {
+		prompt += fmt.Sprintf("\n\nKeep memory_update under %d characters; condense older facts if needed.", c.maxMemoryChars)
+	}
 
 	messages := schema.NewMessages(
 		schema.NewSystemMessage("You are a memory consolidation agent. Call the save_memory tool with your consolidation of the conversation."),
@@ -150,6 +206,7 @@ func (c *MemoryCompactor) compactAndSave(ctx context.Context, old schema.Message
 		return fmt.Errorf("consolidation LLM call: %w", err)
 	}
 
+	c.enforceMemoryLimit(ctx)
 	return nil
 }
 
@@ -168,7 +225,7 @@ func formatMessagesForPrompt(msgs []schema.Message) string {
 				content = *v
 			}
 		}
-		if content == "" {
+		if content == "" || msg.Role == schema.RoleTool {
 			continue
 		}
 		toolsStr := ""
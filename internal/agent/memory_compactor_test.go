@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+)
+
+// gatedProvider blocks each Chat call until release is closed, then returns err.
+type gatedProvider struct {
+	started chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func (p *gatedProvider) Chat(ctx context.Context, _ schema.Messages, _ []map[string]any, _ schema.ChatOptions) (schema.LLMResponse, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return schema.LLMResponse{}, p.err
+}
+func (p *gatedProvider) DefaultModel() string { return "test" }
+
+func newTestCompactor(t *testing.T, p schema.LLMProvider) *MemoryCompactor {
+	t.Helper()
+	store, err := NewMemoryStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCompactor(store, session.NewInMemoryStore(), p, "test", 10, 0, nil)
+}
+
+func archivedSession() schema.ChannelSession {
+	msgs := schema.NewMessages()
+	msgs.AddUser("remember that I like tea")
+	return session.NewArchivedSession("cli:direct", msgs)
+}
+
+// waitForState polls Status until it reports want.
+func waitForState(t *testing.T, c *MemoryCompactor, key, want string) schema.CompactionStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st := c.Status(key)
+		if st.State == want {
+			return st
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected state %q, still %q", want, st.State)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCompactorStatus_RunningThenIdle(t *testing.T) {
+	p := &gatedProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	c := newTestCompactor(t, p)
+	key := "cli:direct:archive"
+
+	if st := c.Status(key); st.State != schema.CompactionIdle || !st.FinishedAt.IsZero() {
+		t.Fatalf("expected idle before any run, got %+v", st)
+	}
+
+	c.Schedule(key, archivedSession(), true)
+	<-p.started
+	if st := c.Status(key); st.State != schema.CompactionRunning {
+		t.Fatalf("expected running, got %+v", st)
+	}
+	c.Schedule(key, archivedSession(), true)
+	if st := c.Status(key); st.State != schema.CompactionQueued {
+		t.Fatalf("expected queued, got %+v", st)
+	}
+
+	close(p.release)
+	st := waitForState(t, c, key, schema.CompactionIdle)
+	if st.LastError != "" || st.FinishedAt.IsZero() {
+		t.Errorf("expected successful finished run, got %+v", st)
+	}
+}
+
+func TestCompactorStatus_Failed(t *testing.T) {
+	p := &gatedProvider{started: make(chan struct{}, 1), release: make(chan struct{}), err: errors.New("provider down")}
+	close(p.release)
+	c := newTestCompactor(t, p)
+	key := "cli:direct:archive"
+
+	c.Schedule(key, archivedSession(), true)
+	st := waitForState(t, c, key, schema.CompactionFailed)
+	if st.LastError != "consolidation LLM call: provider down" {
+		t.Errorf("unexpected last error %q", st.LastError)
+	}
+}
+
+func TestSchedule_IgnoresToolStepsInWindow(t *testing.T) {
+	p := &gatedProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	close(p.release)
+	c := newTestCompactor(t, p) // memoryWindow 10
+
+	msgs := schema.NewMessages()
+	for i := 0; i < 4; i++ {
+		msgs.AddUser("run it")
+		for j := 0; j < 5; j++ {
+			msgs.AddAssistant(nil, []schema.ToolCall{schema.NewToolCall("c", "exec", nil)}, nil)
+			msgs.AddToolResult("c", "exec", "ok")
+		}
+		reply := "done"
+		msgs.AddAssistant(&reply, nil, nil)
+	}
+	key := "cli:direct:tools"
+	c.Schedule(key, session.NewArchivedSession(key, msgs), false)
+	if st := c.Status(key); st.State != schema.CompactionIdle {
+		t.Fatalf("8 text messages are within the window of 10, got state %q", st.State)
+	}
+
+	for i := 0; i < 2; i++ {
+		msgs.AddUser("more")
+		reply := "ok"
+		msgs.AddAssistant(&reply, nil, nil)
+	}
+	c.Schedule(key, session.NewArchivedSession(key, msgs), false)
+	select {
+	case <-p.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected consolidation once text messages exceed the window")
+	}
+}
+
+func TestLatestCompactionStatus(t *testing.T) {
+	now := time.Now()
+	idle := schema.CompactionStatus{State: schema.CompactionIdle, FinishedAt: now}
+	failed := schema.CompactionStatus{State: schema.CompactionFailed, FinishedAt: now.Add(-time.Minute), LastError: "x"}
+	running := schema.CompactionStatus{State: schema.CompactionRunning}
+
+	if got := latestCompactionStatus(idle, running); got.State != schema.CompactionRunning {
+		t.Errorf("in-flight run should win, got %q", got.State)
+	}
+	if got := latestCompactionStatus(failed, idle); got.State != schema.CompactionIdle {
+		t.Errorf("most recent run should win, got %q", got.State)
+	}
+}
+
+func TestCompact_UsesConfiguredModel(t *testing.T) {
+	p := &scriptedProvider{responses: []schema.LLMResponse{saveMemoryResponse("Likes tea.")}}
+	store, _ := NewMemoryStore(t.TempDir())
+	c := NewCompactor(store, session.NewInMemoryStore(), p, "openai/gpt-4o-mini", 10, 0, nil)
+
+	if err := c.Compact(context.Background(), archivedSession(), true); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.opts) != 1 || p.opts[0].Model != "openai/gpt-4o-mini" {
+		t.Errorf("expected the consolidation model in ChatOptions, got %+v", p.opts)
+	}
+}
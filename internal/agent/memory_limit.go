@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/llmutils"
+)
+
+// memoryTruncatedNote heads a MEMORY.md whose oldest sections were dropped.
+const memoryTruncatedNote = "> Note: older sections of this memory were dropped to stay within the %d-character limit."
+
+// enforceMemoryLimit keeps MEMORY.md within maxMemoryChars. An oversized
+// memory is first sent back to the model to be compressed; if that fails or
+// still does not fit, the oldest sections are dropped.
+func (c *MemoryCompactor) enforceMemoryLimit(ctx context.Context) {
+	limit := c.maxMemoryChars
+	current := c.memoryStore.ReadLongTerm()
+	if limit <= 0 || utf8.RuneCountInString(current) <= limit {
+		return
+	}
+	slog.Info("Long-term memory over limit; compressing", "chars", utf8.RuneCountInString(current), "limit", limit)
+
+	memory := current
+	if compressed, err := c.compressMemory(ctx, current, limit); err != nil {
+		slog.Warn("Memory compression failed", "err", err)
+	} else if compressed != "" && utf8.RuneCountInString(compressed) < utf8.RuneCountInString(current) {
+		memory = compressed
+	}
+	if utf8.RuneCountInString(memory) > limit {
+		slog.Warn("Long-term memory still over limit; dropping oldest sections", "limit", limit)
+		memory = truncateMemory(memory, limit)
+	}
+	if err := c.memoryStore.WriteLongTerm(memory); err != nil {
+		slog.Warn("failed to write long-term memory", "err", err)
+	}
+}
+
+// compressMemory asks the model to rewrite memory in at most limit characters.
+func (c *MemoryCompactor) compressMemory(ctx context.Context, memory string, limit int) (string, error) {
+	messages := schema.NewMessages(
+		schema.NewSystemMessage(fmt.Sprintf(
+			"You compress an agent's long-term memory. Rewrite the markdown below in at most %d characters. "+
+				"Keep durable facts, preferences and decisions; merge duplicates; drop stale or trivial details. "+
+				"Reply with the rewritten markdown only.", limit)),
+		schema.NewUserMessage(memory),
+	)
+	resp, err := c.provider.Chat(ctx, messages, nil, schema.NewChatOptions(c.model, 4096, 0.3))
+	if err != nil {
+		return "", err
+	}
+	if resp.Content == nil {
+		return "", nil
+	}
+	return strings.TrimSpace(llmutils.StripThink(*resp.Content)), nil
+}
+
+// truncateMemory drops whole sections (split at markdown headings) from the
+// top of memory, which consolidation appends to, until the rest fits in
+// limit characters along with a note saying so. A single section that is
+// still too long keeps only its tail.
+func truncateMemory(memory string, limit int) string {
+	note := fmt.Sprintf(memoryTruncatedNote, limit)
+	budget := limit - utf8.RuneCountInString(note) - 2 // blank line after the note
+	if budget <= 0 {
+		return string([]rune(note)[:min(limit, utf8.RuneCountInString(note))])
+	}
+
+	sections := splitSections(memory)
+	for len(sections) > 1 && utf8.RuneCountInString(strings.Join(sections, "")) > budget {
+		sections = sections[1:]
+	}
+	kept := strings.TrimSpace(strings.Join(sections, ""))
+	if runes := []rune(kept); len(runes) > budget {
+		kept = strings.TrimSpace(string(runes[len(runes)-budget:]))
+	}
+	return note + "\n\n" + kept
+}
+
+// splitSections splits markdown before each heading line; text before the
+// first heading is its own section.
+func splitSections(md string) []string {
+	var sections []string
+	var cur strings.Builder
+	for _, line := range strings.SplitAfter(md, "\n") {
+		if strings.HasPrefix(line, "#") && cur.Len() > 0 {
+			sections = append(sections, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(line)
+	}
+	if cur.Len() > 0 {
+		sections = append(sections, cur.String())
+	}
+	return sections
+}
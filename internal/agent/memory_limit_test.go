@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+func saveMemoryResponse(memory string) schema.LLMResponse {
+	return schema.LLMResponse{ToolCalls: []schema.ToolCallResponse{{
+		Id:   "call_1",
+		Name: string(tools.ToolSaveMemory),
+		Arguments: map[string]any{
+			"history_entry": "[2026-01-05 18:40] Talked about tea.",
+			"memory_update": memory,
+		},
+	}}}
+}
+
+func TestCompact_OversizedMemoryIsCompressed(t *testing.T) {
+	p := &scriptedProvider{responses: []schema.LLMResponse{
+		saveMemoryResponse("# Facts\n\n" + strings.Repeat("The user likes green tea. ", 10)),
+		textResponse("# Facts\n\nLikes green tea.", "stop"),
+	}}
+	store, _ := NewMemoryStore(t.TempDir())
+	c := NewCompactor(store, session.NewInMemoryStore(), p, "test", 10, 100, nil)
+
+	if err := c.Compact(context.Background(), archivedSession(), true); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.ReadLongTerm(); got != "# Facts\n\nLikes green tea." {
+		t.Errorf("expected compressed memory, got %q", got)
+	}
+	if len(p.sent) != 2 || !strings.Contains(p.sent[1].Messages[0].Content.(string), "at most 100 characters") {
+		t.Errorf("expected a compression request naming the limit, got %d calls", len(p.sent))
+	}
+}
+
+func TestCompact_OversizedMemoryIsTruncated(t *testing.T) {
+	memory := "# Old\n\n" + strings.Repeat("stale fact ", 20) + "\n\n## Recent\n\nLikes green tea.\n"
+	p := &scriptedProvider{responses: []schema.LLMResponse{
+		saveMemoryResponse(memory),
+		textResponse(memory, "stop"), // compression that does not shrink it
+	}}
+	store, _ := NewMemoryStore(t.TempDir())
+	limit := 150
+	c := NewCompactor(store, session.NewInMemoryStore(), p, "test", 10, limit, nil)
+
+	if err := c.Compact(context.Background(), archivedSession(), true); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(memoryTruncatedNote, limit) + "\n\n## Recent\n\nLikes green tea."
+	if got := store.ReadLongTerm(); got != want {
+		t.Errorf("expected oldest section dropped:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestTruncateMemory_KeepsTailOfSingleSection(t *testing.T) {
+	got := truncateMemory(strings.Repeat("x", 500)+"END", 120)
+	if n := utf8.RuneCountInString(got); n > 120 || !strings.HasSuffix(got, "END") {
+		t.Errorf("expected at most 120 chars ending with the newest text, got %d: %q", n, got)
+	}
+}
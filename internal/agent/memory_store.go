@@ -3,8 +3,13 @@ package agent
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 )
@@ -13,11 +18,22 @@ type FileMemoryStore struct {
 	memoryDir       string
 	memoryFilePath  string
 	historyFilePath string
+
+	embedder    schema.Embedder // nil disables semantic recall
+	vectorsPath string
+	vectorsMu   sync.Mutex // guards vectorsPath
 }
 
 // NewMemoryStore creates a FileMemoryStore rooted at workspace.
 // The memory/ subdirectory is created if it does not exist.
 func NewMemoryStore(workspace string) (schema.MemoryStore, error) {
+	return NewMemoryStoreWithEmbedder(workspace, nil)
+}
+
+// NewMemoryStoreWithEmbedder is NewMemoryStore with semantic recall: history
+// entries are embedded as they are appended and RecallMemory ranks them by
+// similarity. A nil embedder gives a plain NewMemoryStore.
+func NewMemoryStoreWithEmbedder(workspace string, embedder schema.Embedder) (schema.MemoryStore, error) {
 	dir := filepath.Join(workspace, "memory")
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, fmt.Errorf("create memory dir: %w", err)
@@ -27,6 +43,8 @@ func NewMemoryStore(workspace string) (schema.MemoryStore, error) {
 		memoryDir:       dir,
 		memoryFilePath:  filepath.Join(dir, "MEMORY.md"),
 		historyFilePath: filepath.Join(dir, "HISTORY.md"),
+		embedder:        embedder,
+		vectorsPath:     filepath.Join(dir, "HISTORY.vectors.jsonl"),
 	}, nil
 }
 
@@ -57,8 +75,16 @@ func (m *FileMemoryStore) AppendHistory(entry string) error {
 	for len(line) > 0 && (line[len(line)-1] == '\n' || line[len(line)-1] == '\r' || line[len(line)-1] == ' ') {
 		line = line[:len(line)-1]
 	}
-	_, err = fmt.Fprintf(f, "%s\n\n", line)
-	return err
+	if _, err = fmt.Fprintf(f, "%s\n\n", line); err != nil {
+		return err
+	}
+
+	// The entry is saved; a failed embedding only costs recall quality and
+	// is retried by the next RecallMemory backfill.
+	if err := m.indexEntry(line); err != nil {
+		slog.Warn("Failed to embed history entry", "err", err)
+	}
+	return nil
 }
 
 // GetMemoryContext returns the long-term memory formatted for injection into
@@ -71,3 +97,64 @@ func (m *FileMemoryStore) GetMemoryContext() string {
 
 	return "## Long-term Memory\n" + longTerm
 }
+
+// reHistoryStamp matches the "[YYYY-MM-DD HH:MM]" prefix of a history entry.
+var reHistoryStamp = regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2} \d{2}:\d{2})\]\s*`)
+
+// Search implements schema.MemoryStore by scanning the blank-line separated
+// paragraphs of HISTORY.md (newest first) and optionally MEMORY.md.
+func (m *FileMemoryStore) Search(query string, limit int, includeLongTerm bool) []schema.MemoryMatch {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+	var matches []schema.MemoryMatch
+	add := func(source string, paragraphs []string) {
+		for _, p := range paragraphs {
+			if limit > 0 && len(matches) >= limit {
+				return
+			}
+			if !containsAll(strings.ToLower(p), terms) {
+				continue
+			}
+			match := schema.MemoryMatch{Source: source, Text: p}
+			if sm := reHistoryStamp.FindStringSubmatch(p); sm != nil {
+				match.Timestamp = sm[1]
+				match.Text = p[len(sm[0]):]
+			}
+			matches = append(matches, match)
+		}
+	}
+
+	history := paragraphs(m.historyFilePath)
+	slices.Reverse(history)
+	add("HISTORY.md", history)
+	if includeLongTerm {
+		add("MEMORY.md", paragraphs(m.memoryFilePath))
+	}
+	return matches
+}
+
+// paragraphs returns the non-empty, blank-line separated blocks of a file.
+func paragraphs(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, p := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func containsAll(text string, terms []string) bool {
+	for _, t := range terms {
+		if !strings.Contains(text, t) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+const historyFixture = `[2026-01-03 09:15] Set up the Postgres backup cron job; runs nightly at 02:00.
+
+[2026-01-05 18:40] User asked to move the backup to S3. Decided on the eu-west-1 bucket.
+
+[2026-01-09 11:02] Discussed holiday plans in Lisbon.
+
+`
+
+func TestFileMemoryStore_Search(t *testing.T) {
+	ws := t.TempDir()
+	store, err := NewMemoryStore(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ws, "memory", "HISTORY.md"), []byte(historyFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.WriteLongTerm("# Facts\n\nBackups live in the eu-west-1 bucket."); err != nil {
+		t.Fatal(err)
+	}
+
+	got := store.Search("BACKUP", 0, false)
+	want := []schema.MemoryMatch{
+		{Source: "HISTORY.md", Timestamp: "2026-01-05 18:40", Text: "User asked to move the backup to S3. Decided on the eu-west-1 bucket."},
+		{Source: "HISTORY.md", Timestamp: "2026-01-03 09:15", Text: "Set up the Postgres backup cron job; runs nightly at 02:00."},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("unexpected matches:\n got %+v\nwant %+v", got, want)
+	}
+
+	if got := store.Search("backup s3", 0, false); len(got) != 1 || got[0].Timestamp != "2026-01-05 18:40" {
+		t.Errorf("expected every word to be required, got %+v", got)
+	}
+	if got := store.Search("backup", 1, false); len(got) != 1 {
+		t.Errorf("expected limit to cap matches, got %d", len(got))
+	}
+	got = store.Search("eu-west-1", 0, true)
+	if len(got) != 2 || got[1].Source != "MEMORY.md" || got[1].Timestamp != "" {
+		t.Errorf("expected history then long-term match, got %+v", got)
+	}
+	if got := store.Search("lisbon", 0, false); len(got) != 1 {
+		t.Errorf("expected one holiday match, got %+v", got)
+	}
+}
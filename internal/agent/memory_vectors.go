@@ -0,0 +1,234 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+const (
+	// embedTimeout bounds the embedding request made by AppendHistory.
+	embedTimeout = 30 * time.Second
+	// embedBatchSize is how many paragraphs are sent per embedding request.
+	embedBatchSize = 64
+	// maxBackfillPerRecall caps how many unindexed paragraphs one
+	// RecallMemory call embeds, so a large legacy HISTORY.md is indexed
+	// gradually instead of stalling a single turn.
+	maxBackfillPerRecall = 256
+)
+
+// vectorRecord is one line of memory/HISTORY.vectors.jsonl.
+type vectorRecord struct {
+	Model  string    `json:"model"`
+	Text   string    `json:"text"`
+	Vector []float32 `json:"vector"`
+}
+
+// indexEntry embeds the paragraphs of a newly appended history entry and
+// appends their vectors to the sidecar file. A no-op without an embedder.
+func (m *FileMemoryStore) indexEntry(entry string) error {
+	if m.embedder == nil {
+		return nil
+	}
+	var texts []string
+	for _, p := range strings.Split(strings.ReplaceAll(entry, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			texts = append(texts, p)
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), embedTimeout)
+	defer cancel()
+	return m.embedAndStore(ctx, texts)
+}
+
+// RecallMemory implements schema.MemoryStore. Paragraphs of HISTORY.md not
+// yet in the sidecar (written before embeddings were configured, or whose
+// embedding failed) are embedded first.
+func (m *FileMemoryStore) RecallMemory(ctx context.Context, query string, k int) []schema.MemoryMatch {
+	if m.embedder == nil {
+		return m.Search(query, k, false)
+	}
+	matches, err := m.recall(ctx, query, k)
+	if err != nil {
+		slog.Warn("Semantic memory recall failed; falling back to keyword search", "err", err)
+		return m.Search(query, k, false)
+	}
+	return matches
+}
+
+func (m *FileMemoryStore) recall(ctx context.Context, query string, k int) ([]schema.MemoryMatch, error) {
+	history := paragraphs(m.historyFilePath)
+	if len(history) == 0 {
+		return nil, nil
+	}
+
+	index, err := m.loadVectors()
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, p := range history {
+		if _, ok := index[p]; !ok && !slices.Contains(missing, p) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > maxBackfillPerRecall {
+		missing = missing[len(missing)-maxBackfillPerRecall:] // keep the newest
+	}
+	if len(missing) > 0 {
+		if err := m.embedAndStore(ctx, missing); err != nil {
+			return nil, fmt.Errorf("backfill history vectors: %w", err)
+		}
+		if index, err = m.loadVectors(); err != nil {
+			return nil, err
+		}
+	}
+
+	qv, err := m.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(qv) != 1 {
+		return nil, errors.New("embed query: no vector returned")
+	}
+
+	type scored struct {
+		text  string
+		score float64
+	}
+	var ranked []scored
+	seen := map[string]bool{}
+	for _, p := range history {
+		v, ok := index[p]
+		if !ok || seen[p] {
+			continue
+		}
+		seen[p] = true
+		ranked = append(ranked, scored{text: p, score: cosine(qv[0], v)})
+	}
+	// Stable so equally similar entries keep file order.
+	slices.SortStableFunc(ranked, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+	if k > 0 && len(ranked) > k {
+		ranked = ranked[:k]
+	}
+
+	matches := make([]schema.MemoryMatch, 0, len(ranked))
+	for _, r := range ranked {
+		match := schema.MemoryMatch{Source: "HISTORY.md", Text: r.text}
+		if sm := reHistoryStamp.FindStringSubmatch(r.text); sm != nil {
+			match.Timestamp = sm[1]
+			match.Text = r.text[len(sm[0]):]
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// embedAndStore embeds texts in batches and appends them to the sidecar.
+func (m *FileMemoryStore) embedAndStore(ctx context.Context, texts []string) error {
+	for start := 0; start < len(texts); start += embedBatchSize {
+		batch := texts[start:min(start+embedBatchSize, len(texts))]
+		vectors, err := m.embedder.Embed(ctx, batch)
+		if err != nil {
+			return err
+		}
+		if len(vectors) != len(batch) {
+			return fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(batch))
+		}
+		records := make([]vectorRecord, len(batch))
+		for i, text := range batch {
+			records[i] = vectorRecord{Model: m.embedder.Model(), Text: text, Vector: vectors[i]}
+		}
+		if err := m.appendVectors(records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *FileMemoryStore) appendVectors(records []vectorRecord) error {
+	m.vectorsMu.Lock()
+	defer m.vectorsMu.Unlock()
+	f, err := os.OpenFile(m.vectorsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open vectors file: %w", err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("write vectors file: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadVectors reads the sidecar into a text → vector map, keeping only
+// vectors from the current embedding model.
+func (m *FileMemoryStore) loadVectors() (map[string][]float32, error) {
+	m.vectorsMu.Lock()
+	defer m.vectorsMu.Unlock()
+	index := map[string][]float32{}
+	f, err := os.Open(m.vectorsPath)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open vectors file: %w", err)
+	}
+	defer f.Close()
+
+	model := m.embedder.Model()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1<<20), 16<<20)
+	for scanner.Scan() {
+		var r vectorRecord
+		if json.Unmarshal(scanner.Bytes(), &r) != nil || r.Model != model || len(r.Vector) == 0 {
+			continue // skip corrupt lines and other models' vectors
+		}
+		index[r.Text] = r.Vector
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read vectors file: %w", err)
+	}
+	return index, nil
+}
+
+// cosine returns the cosine similarity of a and b, or 0 when their lengths
+// differ or either is zero.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubEmbedder maps each text to a fixed vector of topic features, so
+// "storage" and "backup" land close together without sharing a word.
+type stubEmbedder struct {
+	calls int
+	err   error
+}
+
+var stubTopics = [][]string{
+	{"backup", "storage", "s3", "bucket", "postgres"},
+	{"holiday", "vacation", "lisbon", "trip"},
+	{"cron", "schedule", "nightly"},
+}
+
+func (e *stubEmbedder) Model() string { return "stub" }
+
+func (e *stubEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, len(stubTopics)+1)
+		v[len(stubTopics)] = 0.1 // keep unrelated texts off the zero vector
+		lower := strings.ToLower(text)
+		for j, words := range stubTopics {
+			for _, w := range words {
+				if strings.Contains(lower, w) {
+					v[j]++
+				}
+			}
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func writeHistory(t *testing.T, ws string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(ws, "memory", "HISTORY.md"), []byte(historyFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecallMemory_RanksBySimilarity(t *testing.T) {
+	ws := t.TempDir()
+	emb := &stubEmbedder{}
+	store, err := NewMemoryStoreWithEmbedder(ws, emb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeHistory(t, ws) // written before embeddings existed: backfilled on recall
+
+	got := store.RecallMemory(context.Background(), "vacation", 1)
+	if len(got) != 1 || got[0].Timestamp != "2026-01-09 11:02" {
+		t.Fatalf("expected the Lisbon entry without a shared word, got %+v", got)
+	}
+	if got := store.Search("vacation", 0, false); len(got) != 0 {
+		t.Fatalf("word search should not find it, got %+v", got)
+	}
+
+	got = store.RecallMemory(context.Background(), "cloud storage", 2)
+	if len(got) != 2 || got[0].Timestamp != "2026-01-05 18:40" || got[1].Timestamp != "2026-01-03 09:15" {
+		t.Errorf("expected the two backup entries, most similar first, got %+v", got)
+	}
+
+	// New entries are indexed on append, so recall only embeds the query.
+	if err := store.AppendHistory("[2026-01-10 08:00] Booked a trip to Porto."); err != nil {
+		t.Fatal(err)
+	}
+	emb.calls = 0
+	got = store.RecallMemory(context.Background(), "vacation", 5)
+	if emb.calls != 1 {
+		t.Errorf("expected only the query to be embedded, got %d calls", emb.calls)
+	}
+	top := map[string]bool{}
+	for _, m := range got[:min(2, len(got))] {
+		top[m.Timestamp] = true
+	}
+	if len(got) != 4 || !top["2026-01-09 11:02"] || !top["2026-01-10 08:00"] {
+		t.Errorf("expected both trip entries first, got %+v", got)
+	}
+}
+
+func TestRecallMemory_FallsBackToSearch(t *testing.T) {
+	ws := t.TempDir()
+	plain, err := NewMemoryStore(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeHistory(t, ws)
+	if got := plain.RecallMemory(context.Background(), "backup", 0); len(got) != 2 || got[0].Timestamp != "2026-01-05 18:40" {
+		t.Errorf("expected word search without an embedder, got %+v", got)
+	}
+
+	failing, err := NewMemoryStoreWithEmbedder(ws, &stubEmbedder{err: errors.New("unavailable")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := failing.AppendHistory("[2026-01-11 10:00] Rotated the backup keys."); err != nil {
+		t.Fatalf("a failed embedding must not fail AppendHistory: %v", err)
+	}
+	if got := failing.RecallMemory(context.Background(), "backup", 0); len(got) != 3 || got[0].Timestamp != "2026-01-11 10:00" {
+		t.Errorf("expected word search when embedding fails, got %+v", got)
+	}
+}
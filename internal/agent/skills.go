@@ -17,6 +17,7 @@ import (
 type skillMeta struct {
 	Description string `yaml:"description"`
 	Always      bool   `yaml:"always"`
+	Disabled    bool   `yaml:"disabled"`
 	// Nested JSON string under "metadata" key
 	Metadata string `yaml:"metadata"`
 }
@@ -47,7 +48,10 @@ func NewSkillsLoader(workspace, builtinSkillsDir string) *SkillsLoader {
 	}
 }
 
-// ListSkills returns all available skills.
+// disabledMarker is the file in a skill directory that disables the skill.
+const disabledMarker = ".disabled"
+
+// ListSkills returns all enabled skills.
 // If filterUnavailable is true, skills with unmet requirements are excluded.
 func (sl *SkillsLoader) ListSkills(filterUnavailable bool) []schema.SkillInfo {
 	seen := map[string]bool{}
@@ -61,8 +65,11 @@ func (sl *SkillsLoader) ListSkills(filterUnavailable bool) []schema.SkillInfo {
 			}
 			p := filepath.Join(sl.workspaceSkills, e.Name(), "SKILL.md")
 			if _, err := os.Stat(p); err == nil {
-				skills = append(skills, schema.SkillInfo{Name: e.Name(), Path: p, Source: "workspace"})
+				// A disabled workspace skill also hides the builtin of the same name.
 				seen[e.Name()] = true
+				if !skillDisabled(p) {
+					skills = append(skills, schema.SkillInfo{Name: e.Name(), Path: p, Source: "workspace"})
+				}
 			}
 		}
 	}
@@ -75,7 +82,7 @@ func (sl *SkillsLoader) ListSkills(filterUnavailable bool) []schema.SkillInfo {
 					continue
 				}
 				p := filepath.Join(sl.builtinSkills, e.Name(), "SKILL.md")
-				if _, err := os.Stat(p); err == nil {
+				if _, err := os.Stat(p); err == nil && !skillDisabled(p) {
 					skills = append(skills, schema.SkillInfo{Name: e.Name(), Path: p, Source: "builtin"})
 				}
 			}
@@ -114,9 +121,14 @@ func (sl *SkillsLoader) LoadSkill(name string) string {
 // LoadSkillsForContext loads a set of named skills and returns them formatted
 // for inclusion in the system prompt (frontmatter stripped).
 func (sl *SkillsLoader) LoadSkillsForContext(names []string) string {
+	return skillsForContext(names, sl.LoadSkill)
+}
+
+// skillsForContext formats the named skills, read with load, for the prompt.
+func skillsForContext(names []string, load func(string) string) string {
 	var parts []string
 	for _, name := range names {
-		content := sl.LoadSkill(name)
+		content := load(name)
 		if content == "" {
 			continue
 		}
@@ -156,6 +168,25 @@ func (sl *SkillsLoader) BuildSkillsSummary() string {
 	return sb.String()
 }
 
+// SetEnabled enables or disables the workspace skill name by removing or
+// creating its .disabled marker. A disabled skill stays on disk but is left
+// out of ListSkills, the skills summary and the always-loaded set.
+// A "disabled: true" frontmatter field is not changed.
+func (sl *SkillsLoader) SetEnabled(name string, enabled bool) error {
+	dir := filepath.Join(sl.workspaceSkills, name)
+	if !reSkillName.MatchString(name) || !fileExists(filepath.Join(dir, "SKILL.md")) {
+		return fmt.Errorf("skill %q not found in %s", name, sl.workspaceSkills)
+	}
+	marker := filepath.Join(dir, disabledMarker)
+	if enabled {
+		if err := os.Remove(marker); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(marker, nil, 0o644)
+}
+
 // GetAlwaysSkills returns names of skills marked always=true with met requirements.
 func (sl *SkillsLoader) GetAlwaysSkills() []string {
 	var result []string
@@ -175,7 +206,11 @@ func (sl *SkillsLoader) GetAlwaysSkills() []string {
 // ---------------------------------------------------------------------------
 
 func (sl *SkillsLoader) getSkillFrontmatter(name string) skillMeta {
-	content := sl.LoadSkill(name)
+	return parseFrontmatter(sl.LoadSkill(name))
+}
+
+// parseFrontmatter decodes the YAML frontmatter of SKILL.md content.
+func parseFrontmatter(content string) skillMeta {
 	if content == "" || !strings.HasPrefix(content, "---") {
 		return skillMeta{}
 	}
@@ -253,6 +288,16 @@ func (sl *SkillsLoader) getMissingRequirements(m crystalDolphinMeta) string {
 	return strings.Join(missing, ", ")
 }
 
+// skillDisabled reports whether the skill whose SKILL.md is at path has a
+// .disabled marker or "disabled: true" in its frontmatter.
+func skillDisabled(path string) bool {
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), disabledMarker)); err == nil {
+		return true
+	}
+	data, err := os.ReadFile(path)
+	return err == nil && parseFrontmatter(string(data)).Disabled
+}
+
 // stripFrontmatter removes the leading --- ... --- YAML block from markdown.
 func stripFrontmatter(content string) string {
 	if !strings.HasPrefix(content, "---") {
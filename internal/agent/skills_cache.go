@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// CachedSkillsLoader memoises a SkillsLoader so the per-turn system prompt
+// does not re-read every SKILL.md. An fsnotify watcher on the skills
+// directories drops the cache on any change, so skills added, edited or
+// disabled at runtime show up on the next turn. If the watcher cannot be
+// started every call goes straight to the filesystem.
+type CachedSkillsLoader struct {
+	loader  *SkillsLoader
+	watcher *fsnotify.Watcher // nil when watching failed: caching is off
+
+	mu      sync.RWMutex
+	gen     uint64 // bumped on every invalidation
+	lists   map[bool][]schema.SkillInfo
+	skills  map[string]string
+	summary *string
+	always  []string
+	hasAll  bool // always has been computed
+}
+
+// NewCachedSkillsLoader wraps loader and starts watching its directories.
+// Call Close to stop the watcher.
+func NewCachedSkillsLoader(loader *SkillsLoader) *CachedSkillsLoader {
+	c := &CachedSkillsLoader{loader: loader}
+	c.reset()
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Warn("Skills watcher unavailable; skills are re-read every turn", "err", err)
+		return c
+	}
+	c.watcher = w
+	// The workspace root is watched so a skills/ directory created later is
+	// picked up too.
+	for _, dir := range []string{loader.workspace, loader.workspaceSkills, loader.builtinSkills} {
+		c.watchTree(dir)
+	}
+	go c.watch()
+	return c
+}
+
+// Close stops the watcher.
+func (c *CachedSkillsLoader) Close() error {
+	if c.watcher == nil {
+		return nil
+	}
+	return c.watcher.Close()
+}
+
+// watchTree watches dir and its immediate subdirectories (one per skill).
+func (c *CachedSkillsLoader) watchTree(dir string) {
+	if dir == "" {
+		return
+	}
+	if err := c.watcher.Add(dir); err != nil {
+		return // not there yet; its parent's Create event will add it
+	}
+	if dir == c.loader.workspace {
+		return
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir() {
+			_ = c.watcher.Add(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+func (c *CachedSkillsLoader) watch() {
+	for {
+		select {
+		case ev, ok := <-c.watcher.Events:
+			if !ok {
+				return
+			}
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					c.watchTree(ev.Name)
+				}
+			}
+			if c.relevant(ev.Name) {
+				c.invalidate()
+			}
+		case err, ok := <-c.watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been lost; don't trust the cache.
+			slog.Warn("Skills watcher error", "err", err)
+			c.invalidate()
+		}
+	}
+}
+
+// relevant reports whether a change at path can affect the skills, which
+// filters out unrelated activity in the workspace root.
+func (c *CachedSkillsLoader) relevant(path string) bool {
+	for _, dir := range []string{c.loader.workspaceSkills, c.loader.builtinSkills} {
+		if dir != "" && (path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CachedSkillsLoader) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
+}
+
+// reset clears the cache; c.mu must be held (or c unshared).
+func (c *CachedSkillsLoader) reset() {
+	c.gen++
+	c.lists = map[bool][]schema.SkillInfo{}
+	c.skills = map[string]string{}
+	c.summary = nil
+	c.always, c.hasAll = nil, false
+}
+
+// cached returns a cached value via get, or computes it with load and
+// stores it via put unless the cache was invalidated meanwhile.
+func cached[T any](c *CachedSkillsLoader, get func() (T, bool), load func() T, put func(T)) T {
+	if c.watcher == nil {
+		return load()
+	}
+	c.mu.RLock()
+	v, ok := get()
+	gen := c.gen
+	c.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	v = load()
+	c.mu.Lock()
+	if c.gen == gen {
+		put(v)
+	}
+	c.mu.Unlock()
+	return v
+}
+
+// ListSkills implements schema.SkillLoader.
+func (c *CachedSkillsLoader) ListSkills(filterUnavailable bool) []schema.SkillInfo {
+	return slices.Clone(cached(c,
+		func() ([]schema.SkillInfo, bool) { v, ok := c.lists[filterUnavailable]; return v, ok },
+		func() []schema.SkillInfo { return c.loader.ListSkills(filterUnavailable) },
+		func(v []schema.SkillInfo) { c.lists[filterUnavailable] = v },
+	))
+}
+
+// LoadSkill implements schema.SkillLoader.
+func (c *CachedSkillsLoader) LoadSkill(name string) string {
+	return cached(c,
+		func() (string, bool) { v, ok := c.skills[name]; return v, ok },
+		func() string { return c.loader.LoadSkill(name) },
+		func(v string) { c.skills[name] = v },
+	)
+}
+
+// LoadSkillsForContext implements schema.SkillLoader.
+func (c *CachedSkillsLoader) LoadSkillsForContext(names []string) string {
+	return skillsForContext(names, c.LoadSkill)
+}
+
+// BuildSkillsSummary implements schema.SkillLoader.
+func (c *CachedSkillsLoader) BuildSkillsSummary() string {
+	return cached(c,
+		func() (string, bool) {
+			if c.summary == nil {
+				return "", false
+			}
+			return *c.summary, true
+		},
+		c.loader.BuildSkillsSummary,
+		func(v string) { c.summary = &v },
+	)
+}
+
+// GetAlwaysSkills implements schema.SkillLoader.
+func (c *CachedSkillsLoader) GetAlwaysSkills() []string {
+	return slices.Clone(cached(c,
+		func() ([]string, bool) { return c.always, c.hasAll },
+		c.loader.GetAlwaysSkills,
+		func(v []string) { c.always, c.hasAll = v, true },
+	))
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCachedSkillsLoader_PicksUpNewSkill(t *testing.T) {
+	ws := t.TempDir() // no skills/ directory yet
+	c := NewCachedSkillsLoader(NewSkillsLoader(ws, ""))
+	t.Cleanup(func() { c.Close() })
+
+	if summary := c.BuildSkillsSummary(); summary != "" {
+		t.Fatalf("expected no skills, got %s", summary)
+	}
+
+	writeSkill(t, ws, "notes", "---\ndescription: Note taking\nalways: true\n---\nTake notes.")
+
+	// Concurrent turns keep reading while the watcher invalidates the cache.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				c.BuildSkillsSummary()
+				c.GetAlwaysSkills()
+			}
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(c.BuildSkillsSummary(), "<name>notes</name>") {
+		if time.Now().After(deadline) {
+			t.Fatal("new skill never appeared in the summary")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := c.GetAlwaysSkills(); len(got) != 1 || got[0] != "notes" {
+		t.Errorf("expected notes always loaded, got %v", got)
+	}
+	if !strings.Contains(c.LoadSkillsForContext([]string{"notes"}), "Take notes.") {
+		t.Error("expected skill content for context")
+	}
+
+	writeSkill(t, ws, "notes", "---\ndescription: Better notes\n---\nTake notes.")
+	deadline = time.Now().Add(2 * time.Second)
+	for !strings.Contains(c.BuildSkillsSummary(), "Better notes") {
+		if time.Now().After(deadline) {
+			t.Fatal("edited skill never refreshed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
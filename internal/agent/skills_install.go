@@ -0,0 +1,312 @@
+package agent
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSkillArchiveBytes bounds a downloaded archive and its extracted size.
+	maxSkillArchiveBytes = 100 << 20
+	// skillInstallTimeout bounds a clone or download.
+	skillInstallTimeout = 5 * time.Minute
+)
+
+// reSkillName is what an installed skill directory may be called.
+var reSkillName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Install fetches skills from source into workspace/skills and returns their
+// names. source is either "git+<url>" (shallow clone) or a .zip, .tar.gz or
+// .tgz URL (http, https or file). The fetched tree must be a skill itself
+// (SKILL.md at its root) or hold skills as subdirectories, each with a
+// SKILL.md. Existing skills are only replaced when force is set.
+func (sl *SkillsLoader) Install(ctx context.Context, source string, force bool) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, skillInstallTimeout)
+	defer cancel()
+
+	if err := os.MkdirAll(sl.workspaceSkills, 0o755); err != nil {
+		return nil, fmt.Errorf("create skills dir: %w", err)
+	}
+	// Staged inside the skills dir so the final move is a same-filesystem rename.
+	staging, err := os.MkdirTemp(sl.workspaceSkills, ".install-")
+	if err != nil {
+		return nil, fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	fetched := filepath.Join(staging, "src")
+	var name string
+	switch {
+	case strings.HasPrefix(source, "git+"):
+		repo := strings.TrimPrefix(source, "git+")
+		name = strings.TrimSuffix(path.Base(strings.TrimRight(repo, "/")), ".git")
+		if err := gitClone(ctx, repo, fetched); err != nil {
+			return nil, err
+		}
+		_ = os.RemoveAll(filepath.Join(fetched, ".git"))
+	case archiveKind(source) != "":
+		name = strings.TrimSuffix(path.Base(source), archiveKind(source))
+		if err := fetchArchive(ctx, source, staging, fetched); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported skill source %q: use git+https://… or a .zip/.tar.gz URL", source)
+	}
+
+	skills, err := findSkillDirs(fetched, name)
+	if err != nil {
+		return nil, err
+	}
+	for skill := range skills {
+		if _, err := os.Stat(filepath.Join(sl.workspaceSkills, skill)); err == nil && !force {
+			return nil, fmt.Errorf("skill %q is already installed; use --force to replace it", skill)
+		}
+	}
+
+	var installed []string
+	for _, skill := range slices.Sorted(maps.Keys(skills)) {
+		dir := skills[skill]
+		dest := filepath.Join(sl.workspaceSkills, skill)
+		if err := os.RemoveAll(dest); err != nil {
+			return installed, fmt.Errorf("remove old skill %q: %w", skill, err)
+		}
+		if err := os.Rename(dir, dest); err != nil {
+			return installed, fmt.Errorf("install skill %q: %w", skill, err)
+		}
+		installed = append(installed, skill)
+	}
+	return installed, nil
+}
+
+// findSkillDirs maps skill names to their directories in a fetched tree. A
+// lone top-level directory (as in most archives) is looked through.
+func findSkillDirs(root, name string) (map[string]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 1 && entries[0].IsDir() && !fileExists(filepath.Join(root, "SKILL.md")) {
+		name = entries[0].Name()
+		root = filepath.Join(root, name)
+		if entries, err = os.ReadDir(root); err != nil {
+			return nil, err
+		}
+	}
+
+	skills := map[string]string{}
+	if fileExists(filepath.Join(root, "SKILL.md")) {
+		skills[name] = root
+	} else {
+		for _, e := range entries {
+			if e.IsDir() && fileExists(filepath.Join(root, e.Name(), "SKILL.md")) {
+				skills[e.Name()] = filepath.Join(root, e.Name())
+			}
+		}
+	}
+	if len(skills) == 0 {
+		return nil, errors.New("no SKILL.md found: the source must be a skill or contain skill directories")
+	}
+	for skill := range skills {
+		if !reSkillName.MatchString(skill) {
+			return nil, fmt.Errorf("invalid skill name %q", skill)
+		}
+	}
+	return skills, nil
+}
+
+func gitClone(ctx context.Context, repo, dest string) error {
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--quiet", "--", repo, dest)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git clone %s: %w: %s", repo, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// archiveKind returns the archive suffix of source, or "".
+func archiveKind(source string) string {
+	p := source
+	if u, err := url.Parse(source); err == nil && u.Path != "" {
+		p = u.Path
+	}
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(strings.ToLower(p), ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// fetchArchive downloads source into tmpDir and extracts it to dest.
+func fetchArchive(ctx context.Context, source, tmpDir, dest string) error {
+	u, err := url.Parse(source)
+	if err != nil {
+		return fmt.Errorf("invalid skill source: %w", err)
+	}
+	var body io.ReadCloser
+	switch u.Scheme {
+	case "file":
+		if body, err = os.Open(u.Path); err != nil {
+			return err
+		}
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("download %s: %w", source, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("download %s: %s", source, resp.Status)
+		}
+		body = resp.Body
+	default:
+		return fmt.Errorf("unsupported archive URL scheme %q", u.Scheme)
+	}
+	defer body.Close()
+
+	// zip needs random access, so the archive is saved to disk first.
+	archive := filepath.Join(tmpDir, "archive")
+	f, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(f, io.LimitReader(body, maxSkillArchiveBytes+1))
+	if err != nil {
+		return fmt.Errorf("download %s: %w", source, err)
+	}
+	if n > maxSkillArchiveBytes {
+		return fmt.Errorf("archive is larger than %d MB", maxSkillArchiveBytes>>20)
+	}
+
+	if archiveKind(source) == ".zip" {
+		return extractZip(f, n, dest)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return extractTarGz(f, dest)
+}
+
+func extractTarGz(r io.Reader, dest string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if _, err := extractPath(dest, hdr.Name, true); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if total += hdr.Size; total > maxSkillArchiveBytes {
+				return fmt.Errorf("archive expands to more than %d MB", maxSkillArchiveBytes>>20)
+			}
+			if err := extractFile(dest, hdr.Name, tr); err != nil {
+				return err
+			}
+		} // links and special files are skipped
+	}
+}
+
+func extractZip(r io.ReaderAt, size int64, dest string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+	var total uint64
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			if _, err := extractPath(dest, zf.Name, true); err != nil {
+				return err
+			}
+			continue
+		}
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+		if total += zf.UncompressedSize64; total > maxSkillArchiveBytes {
+			return fmt.Errorf("archive expands to more than %d MB", maxSkillArchiveBytes>>20)
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		err = extractFile(dest, zf.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractPath resolves an archive entry name under dest, rejecting entries
+// that would escape it, and creates the directory (or the file's parent).
+func extractPath(dest, name string, isDir bool) (string, error) {
+	target := filepath.Join(dest, filepath.FromSlash(name))
+	if !withinDir(target, dest) {
+		return "", fmt.Errorf("archive entry %q escapes the skill directory", name)
+	}
+	dir := target
+	if !isDir {
+		dir = filepath.Dir(target)
+	}
+	return target, os.MkdirAll(dir, 0o755)
+}
+
+func extractFile(dest, name string, r io.Reader) error {
+	target, err := extractPath(dest, name, false)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, io.LimitReader(r, maxSkillArchiveBytes)); err != nil {
+		return fmt.Errorf("extract %s: %w", name, err)
+	}
+	return nil
+}
+
+func withinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
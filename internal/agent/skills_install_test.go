@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTarball writes files (name → content) as a .tar.gz and returns its
+// file:// URL.
+func writeTarball(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []interface{ Close() error }{tw, gz, f} {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return "file://" + p
+}
+
+func TestInstallSkill_Tarball(t *testing.T) {
+	ws := t.TempDir()
+	sl := NewSkillsLoader(ws, "")
+	src := writeTarball(t, "weather-1.0.tar.gz", map[string]string{
+		"weather/SKILL.md":         "---\ndescription: Weather lookups\n---\nUse wttr.in.",
+		"weather/scripts/fetch.sh": "curl wttr.in",
+	})
+
+	names, err := sl.Install(context.Background(), src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "weather" {
+		t.Fatalf("expected weather installed, got %v", names)
+	}
+	if !strings.Contains(sl.LoadSkill("weather"), "wttr.in") {
+		t.Error("expected the installed SKILL.md to load")
+	}
+	if _, err := os.Stat(filepath.Join(ws, "skills", "weather", "scripts", "fetch.sh")); err != nil {
+		t.Errorf("expected skill files installed: %v", err)
+	}
+
+	update := writeTarball(t, "weather.tar.gz", map[string]string{"weather/SKILL.md": "v2"})
+	if _, err := sl.Install(context.Background(), update, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected refusal to overwrite, got %v", err)
+	}
+	if _, err := sl.Install(context.Background(), update, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := sl.LoadSkill("weather"); got != "v2" {
+		t.Errorf("expected forced reinstall to replace the skill, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(ws, "skills", "weather", "scripts")); !os.IsNotExist(err) {
+		t.Error("expected old skill files removed on forced reinstall")
+	}
+}
+
+func TestInstallSkill_RejectsInvalidArchives(t *testing.T) {
+	sl := NewSkillsLoader(t.TempDir(), "")
+	cases := map[string]map[string]string{
+		"no SKILL.md": {"notes/README.md": "hi"},
+		"escapes":     {"../evil/SKILL.md": "x"},
+	}
+	for name, files := range cases {
+		src := writeTarball(t, "bad.tar.gz", files)
+		if _, err := sl.Install(context.Background(), src, false); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if len(sl.ListSkills(false)) != 0 {
+		t.Error("expected nothing installed")
+	}
+	if _, err := sl.Install(context.Background(), "ftp://example.com/skill", false); err == nil {
+		t.Error("expected unsupported source error")
+	}
+}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeSkill(t *testing.T, ws, name, content string) {
+	t.Helper()
+	dir := filepath.Join(ws, "skills", name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSkills_DisabledAreHidden(t *testing.T) {
+	ws := t.TempDir()
+	writeSkill(t, ws, "notes", "---\ndescription: Note taking\nalways: true\n---\nTake notes.")
+	writeSkill(t, ws, "weather", "---\ndescription: Weather\n---\nUse wttr.in.")
+	writeSkill(t, ws, "draft", "---\ndescription: Draft\ndisabled: true\n---\nWIP.")
+	sl := NewSkillsLoader(ws, "")
+
+	if got := sl.GetAlwaysSkills(); !slices.Equal(got, []string{"notes"}) {
+		t.Fatalf("expected notes always loaded, got %v", got)
+	}
+	if summary := sl.BuildSkillsSummary(); strings.Contains(summary, "draft") || !strings.Contains(summary, "<name>notes</name>") {
+		t.Fatalf("expected frontmatter-disabled skill hidden, got %s", summary)
+	}
+
+	if err := sl.SetEnabled("notes", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := sl.GetAlwaysSkills(); len(got) != 0 {
+		t.Errorf("expected disabled skill out of the always set, got %v", got)
+	}
+	if summary := sl.BuildSkillsSummary(); strings.Contains(summary, "notes") || !strings.Contains(summary, "weather") {
+		t.Errorf("expected disabled skill out of the summary, got %s", summary)
+	}
+
+	if err := sl.SetEnabled("notes", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := sl.GetAlwaysSkills(); !slices.Equal(got, []string{"notes"}) {
+		t.Errorf("expected re-enabled skill back, got %v", got)
+	}
+	if err := sl.SetEnabled("missing", false); err == nil {
+		t.Error("expected an error for an unknown skill")
+	}
+}
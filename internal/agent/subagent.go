@@ -43,6 +43,17 @@ func (sm *SubagentManager) Spawn(ctx context.Context, task, label string, origin
 	label = llmutils.Truncate(label, 30)
 
 	subctx, cancel := context.WithCancel(context.Background()) // detached from caller
+	// Tool approvals are asked of the user who spawned the task, in their
+	// chat, and the task works in that conversation's workspace.
+	parent := tools.TurnCtx(ctx)
+	subctx = tools.WithTurn(subctx, tools.TurnContext{
+		Channel:    originChannel,
+		ChatID:     originChatID,
+		SenderID:   parent.SenderID,
+		Unattended: parent.Unattended,
+		SessionKey: "subagent:" + taskID,
+		Workspace:  parent.Workspace,
+	})
 
 	sm.mu.Lock()
 	sm.running[taskID] = cancel
@@ -92,7 +103,7 @@ func (sm *SubagentManager) executeTask(ctx context.Context, task, _ string) (str
 		schema.NewUserMessage(task),
 	)
 
-	content, _ := subAgent.Execute(ctx, conversation, nil)
+	content, _, _, _ := subAgent.Execute(ctx, conversation, nil)
 	content = llmutils.StringOrDefault(content, "Task completed but no final response was generated.")
 
 	return content, nil
@@ -134,7 +145,7 @@ type SubAgent struct {
 }
 
 // Execute implements schema.Agent.
-func (a *SubAgent) Execute(ctx context.Context, conversation schema.Messages, onProgress func(string)) (string, []string) {
+func (a *SubAgent) Execute(ctx context.Context, conversation schema.Messages, onProgress func(string)) (string, []string, schema.Messages, map[string]int) {
 	return a.run(ctx, conversation, &a.tools, onProgress)
 }
 
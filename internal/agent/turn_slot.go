@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"context"
+	"sync"
+)
+
+// turnSlot is the place among settings.MaxConcurrentTurns that a queued turn
+// holds. A turn blocked on a user gives its place up meanwhile, so a few
+// pending tool approvals cannot stall every other conversation.
+type turnSlot struct {
+	slots chan struct{} // AgentLoop.slots: one token per running turn
+
+	mu      sync.Mutex
+	waiting int // calls of this turn currently waiting on a user
+}
+
+type turnSlotKey struct{}
+
+// withTurnSlot records in ctx that the turn holds a token of slots.
+func withTurnSlot(ctx context.Context, slots chan struct{}) context.Context {
+	return context.WithValue(ctx, turnSlotKey{}, &turnSlot{slots: slots})
+}
+
+// releaseTurnSlot frees the calling turn's slot while it waits on a user and
+// returns the function that takes a slot back before the turn goes on. Turns
+// run outside the queue (ProcessDirect) hold no slot, so it is a no-op there.
+func releaseTurnSlot(ctx context.Context) (reacquire func()) {
+	s, _ := ctx.Value(turnSlotKey{}).(*turnSlot)
+	if s == nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	if s.waiting++; s.waiting == 1 {
+		<-s.slots
+	}
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		if s.waiting--; s.waiting == 0 {
+			s.slots <- struct{}{}
+		}
+		s.mu.Unlock()
+	}
+}
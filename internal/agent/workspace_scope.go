@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	agentcfg "github.com/crystaldolphin/crystaldolphin/internal/config/agent"
+)
+
+// scopesDir holds the isolated workspaces under the workspace root.
+const scopesDir = "scopes"
+
+// scopedWorkspace returns the conversation's own workspace under
+// agents.defaults.workspaceScope, creating it, or "" when the workspace is
+// shared. key is the session key.
+func scopedWorkspace(root, scope string, channel bus.Channel, key string) string {
+	var name string
+	switch scope {
+	case agentcfg.WorkspaceScopeChannel:
+		name = string(channel)
+	case agentcfg.WorkspaceScopeSession:
+		name = key
+	default:
+		return ""
+	}
+	dir := filepath.Join(root, scopesDir, scopeDirName(name))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		// Still confine the tools to dir: falling back to the shared root
+		// would expose other conversations' files.
+		slog.Warn("Failed to create scoped workspace", "dir", dir, "err", err)
+	}
+	return dir
+}
+
+// scopeDirName turns a channel or session key into a single safe path
+// component, e.g. "telegram:12345" → "telegram_12345-de97b035". The hash
+// suffix keeps keys that sanitise alike ("a:b", "a_b") apart.
+func scopeDirName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == '@':
+			return r
+		}
+		return '_'
+	}, key)
+	sum := sha256.Sum256([]byte(key))
+	return name + "-" + hex.EncodeToString(sum[:4])
+}
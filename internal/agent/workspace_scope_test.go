@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	agentcfg "github.com/crystaldolphin/crystaldolphin/internal/config/agent"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+func TestScopedWorkspace_IsolatesSessions(t *testing.T) {
+	root := t.TempDir()
+	write := tools.NewWriteFileTool(root, "")
+	read := tools.NewReadFileTool(root, "", 0)
+
+	turn := func(key string) (context.Context, string) {
+		ws := scopedWorkspace(root, agentcfg.WorkspaceScopeSession, bus.Channel("telegram"), key)
+		return tools.WithTurn(context.Background(), tools.TurnContext{Workspace: ws}), ws
+	}
+	aliceCtx, aliceWS := turn("telegram:111")
+	bobCtx, bobWS := turn("telegram:222")
+
+	if aliceWS == bobWS {
+		t.Fatalf("expected separate workspaces, both got %s", aliceWS)
+	}
+	for _, ws := range []string{aliceWS, bobWS} {
+		if info, err := os.Stat(ws); err != nil || !info.IsDir() {
+			t.Fatalf("expected workspace %s to be created: %v", ws, err)
+		}
+	}
+
+	if got, _ := write.Execute(aliceCtx, map[string]any{"path": "notes.txt", "content": "secret"}); strings.HasPrefix(got, "Error") {
+		t.Fatalf("write failed: %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(aliceWS, "notes.txt")); err != nil {
+		t.Fatalf("expected file in alice's workspace: %v", err)
+	}
+
+	if got, _ := read.Execute(aliceCtx, map[string]any{"path": "notes.txt"}); !strings.Contains(got, "secret") {
+		t.Errorf("alice should read her own file, got %q", got)
+	}
+	for _, path := range []string{"notes.txt", filepath.Join(aliceWS, "notes.txt"), "../telegram_111-4468309f/notes.txt"} {
+		if got, _ := read.Execute(bobCtx, map[string]any{"path": path}); strings.Contains(got, "secret") {
+			t.Errorf("bob read alice's file via %q: %q", path, got)
+		}
+	}
+}
+
+func TestScopedWorkspace_Scopes(t *testing.T) {
+	root := t.TempDir()
+	if ws := scopedWorkspace(root, agentcfg.WorkspaceScopeShared, "telegram", "telegram:1"); ws != "" {
+		t.Errorf("shared scope should use the root, got %q", ws)
+	}
+	a := scopedWorkspace(root, agentcfg.WorkspaceScopeChannel, "telegram", "telegram:1")
+	b := scopedWorkspace(root, agentcfg.WorkspaceScopeChannel, "telegram", "telegram:2")
+	if a != b || a != filepath.Join(root, scopesDir, "telegram-3f404629") {
+		t.Errorf("channel scope should share one dir per channel, got %q and %q", a, b)
+	}
+	for key, want := range map[string]string{
+		"telegram:12345": "telegram_12345-de97b035",
+		"webhook:../x":   "webhook_.._x-",
+		"..":             "..-",
+	} {
+		if got := scopeDirName(key); !strings.HasPrefix(got, want) || strings.ContainsRune(got, filepath.Separator) {
+			t.Errorf("scopeDirName(%q) = %q, want prefix %q", key, got, want)
+		}
+	}
+	if scopeDirName("a:b") == scopeDirName("a_b") {
+		t.Error("keys that sanitise alike must map to different dirs")
+	}
+}
@@ -18,6 +18,9 @@ type AgentMessage struct {
 	timestamp  time.Time      // when the message was received
 	media      []string       // local file paths of downloaded attachments
 	metadata   map[string]any // channel-specific extra data (message_id, username, …)
+	priority   Priority       // scheduling priority; defaults from channel
+	ephemeral  bool           // run in a throwaway session that is never saved
+	unattended bool           // nobody can answer prompts (tool approvals) mid-turn
 }
 
 // NewAgentMessage creates an InboundMessage with Timestamp set to now.
@@ -36,6 +39,7 @@ func NewAgentMessage(channel Channel, senderId, chatId, content, routingKey stri
 		content:    content,
 		routingKey: key,
 		timestamp:  time.Now(),
+		priority:   DefaultPriority(channel),
 	}
 }
 
@@ -46,6 +50,9 @@ func (m AgentMessage) Channel() Channel         { return m.channel }
 func (m AgentMessage) Timestamp() time.Time     { return m.timestamp }
 func (m AgentMessage) Media() []string          { return m.media }
 func (m AgentMessage) Metadata() map[string]any { return m.metadata }
+func (m AgentMessage) Priority() Priority       { return m.priority }
+func (m AgentMessage) Ephemeral() bool          { return m.ephemeral }
+func (m AgentMessage) Unattended() bool         { return m.unattended }
 
 // RoutingKey returns the unique key used to look up the conversation session.
 // If an explicit key was set via SetRoutingKey, it is returned;
@@ -62,6 +69,9 @@ type AgentMessageBuilder struct {
 	routingKey string
 	media      []string
 	metadata   map[string]any
+	priority   *Priority
+	ephemeral  bool
+	unattended bool
 }
 
 func NewAgentMessageBuilder(channel Channel, senderId, chatId, content string) *AgentMessageBuilder {
@@ -73,6 +83,12 @@ func NewAgentMessageBuilder(channel Channel, senderId, chatId, content string) *
 	}
 }
 
+// RoutingKey overrides the default "channel:chatId" session key.
+func (b *AgentMessageBuilder) RoutingKey(key string) *AgentMessageBuilder {
+	b.routingKey = key
+	return b
+}
+
 func (b *AgentMessageBuilder) Media(media []string) *AgentMessageBuilder {
 	b.media = media
 	return b
@@ -83,11 +99,35 @@ func (b *AgentMessageBuilder) Metadata(md map[string]any) *AgentMessageBuilder {
 	return b
 }
 
+// Priority overrides the channel's default scheduling priority.
+func (b *AgentMessageBuilder) Priority(p Priority) *AgentMessageBuilder {
+	b.priority = &p
+	return b
+}
+
+// Ephemeral runs the message in a fresh session that is discarded after the
+// turn instead of being saved, for stateless requests.
+func (b *AgentMessageBuilder) Ephemeral() *AgentMessageBuilder {
+	b.ephemeral = true
+	return b
+}
+
+// Unattended marks a turn nobody can answer prompts for mid-turn, such as a
+// one-shot CLI run, so tool approvals are denied instead of waiting.
+func (b *AgentMessageBuilder) Unattended() *AgentMessageBuilder {
+	b.unattended = true
+	return b
+}
+
 func (b *AgentMessageBuilder) Build() AgentMessage {
 	key := b.routingKey
 	if key == "" {
 		key = RoutingKey(b.channel, b.chatId)
 	}
+	priority := DefaultPriority(b.channel)
+	if b.priority != nil {
+		priority = *b.priority
+	}
 
 	return AgentMessage{
 		channel:    b.channel,
@@ -98,5 +138,8 @@ func (b *AgentMessageBuilder) Build() AgentMessage {
 		timestamp:  time.Now(),
 		media:      b.media,
 		metadata:   b.metadata,
+		priority:   priority,
+		ephemeral:  b.ephemeral,
+		unattended: b.unattended,
 	}
 }
@@ -11,6 +11,8 @@ const (
 	ChannelDingTalk  Channel = "dingtalk"
 	ChannelEmail     Channel = "email"
 	ChannelMochat    Channel = "mochat"
+	ChannelWebhook   Channel = "webhook"
+	ChannelOpenAI    Channel = "openai"
 	ChannelCLI       Channel = "cli"
 	ChannelCron      Channel = "cron"
 	ChannelHeartbeat Channel = "heartbeat"
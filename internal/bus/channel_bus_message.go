@@ -17,6 +17,25 @@ func (m ChannelMessage) ReplyTo() string          { return m.replyTo }
 func (m ChannelMessage) Media() []string          { return m.media }
 func (m ChannelMessage) Metadata() map[string]any { return m.metadata }
 
+// MetadataButtons is the ChannelMessage metadata key holding inline buttons
+// as [][]Button, one slice per row.
+const MetadataButtons = "buttons"
+
+// Button is an inline button under a sent message. Pressing it sends Data
+// back as an inbound message; a URL button opens the link instead. Channels
+// without button support ignore them.
+type Button struct {
+	Text string
+	Data string
+	URL  string
+}
+
+// Buttons returns the inline button rows attached to the message, if any.
+func (m ChannelMessage) Buttons() [][]Button {
+	rows, _ := m.metadata[MetadataButtons].([][]Button)
+	return rows
+}
+
 func NewChannelMessage(channel Channel, chatId, content string) ChannelMessage {
 	return ChannelMessage{
 		channel: channel,
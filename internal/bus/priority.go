@@ -0,0 +1,23 @@
+package bus
+
+// Priority orders inbound messages waiting for an agent worker.
+// Higher values are processed first.
+type Priority int
+
+const (
+	PriorityLow    Priority = iota // batch work: cron, heartbeat
+	PriorityNormal                 // system messages (subagent results)
+	PriorityHigh                   // interactive users on chat channels and the CLI
+)
+
+// DefaultPriority returns the priority for messages arriving on ch.
+func DefaultPriority(ch Channel) Priority {
+	switch ch {
+	case ChannelCron, ChannelHeartbeat:
+		return PriorityLow
+	case ChannelSystem:
+		return PriorityNormal
+	default:
+		return PriorityHigh
+	}
+}
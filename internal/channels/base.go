@@ -2,33 +2,109 @@
 package channels
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/metrics"
 )
 
 // Base holds common state and helper methods shared by all channels.
 type Base struct {
-	channelName bus.Channel
-	agentBus    *bus.AgentBus
-	allowFrom   []string // empty = allow all
+	channelName     bus.Channel
+	agentBus        *bus.AgentBus
+	allowFrom       []allowEntry // empty = allow all
+	maxInboundChars int          // 0 = unlimited
+	sendLimiter     *sendLimiter // nil = unlimited
 }
 
-// NewBase creates a Base with the given channel name, bus, and allowlist.
-func NewBase(name bus.Channel, b *bus.AgentBus, allowFrom []string) Base {
-	return Base{channelName: name, agentBus: b, allowFrom: allowFrom}
+// NewBase creates a Base with the given channel name, bus, allowlist, and
+// inbound length cap (0 disables truncation). allowFrom patterns are
+// compiled here, once per channel.
+func NewBase(name bus.Channel, b *bus.AgentBus, allowFrom []string, maxInboundChars int) Base {
+	return Base{
+		channelName:     name,
+		agentBus:        b,
+		allowFrom:       compileAllowFrom(name, allowFrom),
+		maxInboundChars: maxInboundChars,
+	}
+}
+
+// WithSendRate returns b with outbound posts limited to rate per second
+// (0 disables the limit). With perChat set the limit applies to each chat
+// separately, for platforms that rate-limit per conversation.
+func (b Base) WithSendRate(rate float64, perChat bool) Base {
+	if rate > 0 {
+		b.sendLimiter = newSendLimiter(rate, perChat)
+	}
+	return b
 }
 
-// IsAllowed checks whether senderID is on the allowlist.
+// WaitSend blocks until the send rate limit allows another post to chatID,
+// or ctx is done. Channels call it before each platform API post.
+func (b *Base) WaitSend(ctx context.Context, chatID string) error {
+	if b.sendLimiter == nil {
+		return nil
+	}
+	return b.sendLimiter.wait(ctx, chatID)
+}
+
+// allowEntry is one compiled allowFrom entry: an exact string, or a pattern
+// when re is set. The zero value matches nothing.
+type allowEntry struct {
+	exact string
+	re    *regexp.Regexp
+}
+
+func (e allowEntry) match(s string) bool {
+	if e.re != nil {
+		return e.re.MatchString(s)
+	}
+	return e.exact != "" && e.exact == s
+}
+
+// compileAllowFrom compiles allowFrom entries. "re:<regex>" entries are
+// regular expressions that must match the whole sender ID, entries
+// containing "*" are globs matching any run of characters, and anything else
+// matches exactly. Invalid regexes are logged and kept as entries that never
+// match, so a bad pattern cannot turn the list into allow-all.
+func compileAllowFrom(name bus.Channel, allowFrom []string) []allowEntry {
+	entries := make([]allowEntry, 0, len(allowFrom))
+	for _, a := range allowFrom {
+		switch {
+		case strings.HasPrefix(a, "re:"):
+			re, err := regexp.Compile("^(?:" + strings.TrimPrefix(a, "re:") + ")$")
+			if err != nil {
+				slog.Warn("invalid allowFrom regex", "channel", name, "entry", a, "err", err)
+				entries = append(entries, allowEntry{})
+				continue
+			}
+			entries = append(entries, allowEntry{re: re})
+		case strings.Contains(a, "*"):
+			parts := strings.Split(a, "*")
+			for i, p := range parts {
+				parts[i] = regexp.QuoteMeta(p)
+			}
+			entries = append(entries, allowEntry{re: regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")})
+		default:
+			entries = append(entries, allowEntry{exact: a})
+		}
+	}
+	return entries
+}
+
+// IsAllowed checks whether senderID matches an allowlist entry.
 // senderID may be "id|username" (Telegram) or a plain string.
 func (b *Base) IsAllowed(senderID string) bool {
 	if len(b.allowFrom) == 0 {
 		return true
 	}
-	s := senderID
 	for _, allowed := range b.allowFrom {
-		if allowed == s {
+		if allowed.match(senderID) {
 			return true
 		}
 	}
@@ -39,7 +115,7 @@ func (b *Base) IsAllowed(senderID string) bool {
 				continue
 			}
 			for _, allowed := range b.allowFrom {
-				if allowed == part {
+				if allowed.match(part) {
 					return true
 				}
 			}
@@ -48,6 +124,10 @@ func (b *Base) IsAllowed(senderID string) bool {
 	return false
 }
 
+// editedMessagePrefix marks the content of an edited inbound message so the
+// agent treats it as a correction of the user's earlier message.
+const editedMessagePrefix = "[Edited message] "
+
 // HandleMessage verifies the sender is allowed, then pushes an InboundMessage to the bus.
 func (b *Base) HandleMessage(
 	senderId, chatId, content string,
@@ -59,6 +139,13 @@ func (b *Base) HandleMessage(
 		return
 	}
 
+	if truncated, dropped := truncateInbound(content, b.maxInboundChars); dropped > 0 {
+		slog.Info("inbound message truncated", "channel", b.channelName, "sender", senderId, "dropped_chars", dropped)
+		content = truncated
+	}
+
+	metrics.ChannelMessages.Inc(string(b.channelName), "inbound")
+
 	message := bus.
 		NewAgentMessageBuilder(b.channelName, senderId, chatId, content).
 		Media(media).
@@ -68,29 +155,102 @@ func (b *Base) HandleMessage(
 	b.agentBus.Publish(message)
 }
 
+// truncateInbound cuts content to at most maxChars characters, appending a
+// note with the number of characters dropped. It returns the content
+// unchanged and dropped=0 when no cut is needed or maxChars <= 0.
+func truncateInbound(content string, maxChars int) (string, int) {
+	if maxChars <= 0 || len(content) <= maxChars {
+		return content, 0
+	}
+	runes := []rune(content)
+	if len(runes) <= maxChars {
+		return content, 0
+	}
+	dropped := len(runes) - maxChars
+	return string(runes[:maxChars]) + fmt.Sprintf("\n\n[truncated: %d more characters]", dropped), dropped
+}
+
 // splitMessage splits content into chunks that fit within maxLen,
-// preferring newline breaks, then space breaks, then hard cut.
-// Mirrors Python's _split_message in telegram.py / discord.py.
+// preferring paragraph breaks, then newlines, then spaces, then a hard cut.
+// A chunk that ends inside a ``` code block gets a closing fence, and the
+// next chunk reopens it with the same info string, so each chunk renders
+// on its own.
 func splitMessage(content string, maxLen int) []string {
 	if len(content) <= maxLen {
 		return []string{content}
 	}
 	var chunks []string
+	fence := "" // opening fence line to carry into the next chunk
 	for len(content) > 0 {
+		if fence != "" {
+			content = fence + "\n" + content
+		}
 		if len(content) <= maxLen {
 			chunks = append(chunks, content)
 			break
 		}
-		cut := content[:maxLen]
-		pos := strings.LastIndex(cut, "\n")
-		if pos <= 0 {
-			pos = strings.LastIndex(cut, " ")
+		// Leave room for a closing fence in case the cut is inside a block.
+		limit := maxLen - len(closingFence)
+		pos := splitPoint(content, limit)
+		if pos <= len(fence) {
+			// A reopened fence line alone fills the chunk; cut anywhere.
+			pos = hardCut(content, limit)
 		}
-		if pos <= 0 {
-			pos = maxLen
+		chunk := content[:pos]
+		fence = openFence(chunk)
+		if fence != "" {
+			chunk = strings.TrimRight(chunk, "\n") + closingFence
+			content = strings.TrimLeft(content[pos:], "\n")
+		} else {
+			content = strings.TrimLeft(content[pos:], " \t\n")
 		}
-		chunks = append(chunks, content[:pos])
-		content = strings.TrimLeft(content[pos:], " \t")
+		chunks = append(chunks, chunk)
 	}
 	return chunks
 }
+
+const closingFence = "\n```"
+
+// splitPoint returns where to cut content so the first part is at most
+// limit bytes: the last paragraph break in the second half of the window,
+// else the last newline, else the last space, else a hard cut.
+func splitPoint(content string, limit int) int {
+	window := content[:limit]
+	if pos := strings.LastIndex(window, "\n\n"); pos > limit/2 {
+		return pos
+	}
+	if pos := strings.LastIndex(window, "\n"); pos > 0 {
+		return pos
+	}
+	if pos := strings.LastIndex(window, " "); pos > 0 {
+		return pos
+	}
+	return hardCut(content, limit)
+}
+
+// hardCut returns limit moved back to the start of a UTF-8 rune.
+func hardCut(content string, limit int) int {
+	pos := limit
+	for pos > 1 && !utf8.RuneStart(content[pos]) {
+		pos--
+	}
+	return pos
+}
+
+// openFence returns the opening ``` line of a code block left unclosed at
+// the end of chunk, or "" if chunk ends outside a code block.
+func openFence(chunk string) string {
+	open := ""
+	for _, line := range strings.Split(chunk, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+		if open == "" {
+			open = trimmed
+		} else {
+			open = ""
+		}
+	}
+	return open
+}
@@ -0,0 +1,124 @@
+package channels
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+)
+
+func TestHandleMessage_TruncatesOversizedInbound(t *testing.T) {
+	agentBus := bus.NewAgentBus(2)
+	b := NewBase("telegram", agentBus, nil, 10)
+
+	b.HandleMessage("u1", "c1", strings.Repeat("é", 25), nil, nil)
+	msg := <-agentBus.Subscribe()
+	want := strings.Repeat("é", 10) + "\n\n[truncated: 15 more characters]"
+	if msg.Content() != want {
+		t.Errorf("expected %q, got %q", want, msg.Content())
+	}
+
+	b.HandleMessage("u1", "c1", "short", nil, nil)
+	if msg := <-agentBus.Subscribe(); msg.Content() != "short" {
+		t.Errorf("expected short message untouched, got %q", msg.Content())
+	}
+}
+
+func TestIsAllowed_Patterns(t *testing.T) {
+	b := NewBase("email", bus.NewAgentBus(1), []string{
+		"alice@corp.com",
+		"*@example.com",
+		"12345*",
+		`re:^user-\d{3}$`,
+		`re:ops-\d+`,
+		"re:(unclosed",
+	}, 0)
+
+	cases := map[string]bool{
+		"alice@corp.com":       true,
+		"bob@corp.com":         false,
+		"bob@example.com":      true,
+		"bob@example.com.evil": false,
+		"1234567":              true,
+		"9912345":              false,
+		"user-042":             true,
+		"user-42":              false,
+		"ops-7":                true,
+		"devops-7":             false, // re: entries are anchored
+		"ops-7x":               false,
+		"99|12345678":          true, // Telegram "id|username"
+		"(unclosed":            false,
+	}
+	for sender, want := range cases {
+		if got := b.IsAllowed(sender); got != want {
+			t.Errorf("IsAllowed(%q) = %v, want %v", sender, got, want)
+		}
+	}
+
+	open := NewBase("email", bus.NewAgentBus(1), nil, 0)
+	if !open.IsAllowed("anyone") {
+		t.Error("expected an empty allowlist to allow everyone")
+	}
+	broken := NewBase("email", bus.NewAgentBus(1), []string{"re:["}, 0)
+	if broken.IsAllowed("anyone") {
+		t.Error("expected a list of only invalid patterns to allow no one")
+	}
+}
+
+func TestSplitMessage_PrefersParagraphs(t *testing.T) {
+	para := strings.Repeat("word ", 10) // 50 bytes
+	content := para + "\n\n" + para + "\nline\n\n" + para
+	chunks := splitMessage(content, 120)
+	if len(chunks) != 2 || chunks[0] != para+"\n\n"+para+"\nline" || chunks[1] != para {
+		t.Errorf("expected a split at the last paragraph break, got %q", chunks)
+	}
+}
+
+func TestSplitMessage_CodeBlockSpanningBoundary(t *testing.T) {
+	var code strings.Builder
+	for i := 0; i < 40; i++ {
+		code.WriteString("fmt.Println(\"line\")\n")
+	}
+	content := "Here is the program:\n\n```go\n" + code.String() + "```\n\nThat's all."
+	const maxLen = 300
+
+	chunks := splitMessage(content, maxLen)
+	if len(chunks) < 3 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	var rebuilt strings.Builder
+	for i, c := range chunks {
+		if len(c) > maxLen {
+			t.Errorf("chunk %d is %d bytes, over %d", i, len(c), maxLen)
+		}
+		if openFence(c) != "" {
+			t.Errorf("chunk %d leaves a code block open: %q", i, c)
+		}
+		if i > 0 && i < len(chunks)-1 && !strings.HasPrefix(c, "```go\n") {
+			t.Errorf("chunk %d does not reopen the code block: %q", i, c)
+		}
+		body := strings.TrimPrefix(c, "```go\n")
+		body = strings.TrimSuffix(body, closingFence)
+		rebuilt.WriteString(body)
+	}
+	if got := strings.Count(rebuilt.String(), "fmt.Println"); got != 40 {
+		t.Errorf("expected all 40 code lines kept, got %d", got)
+	}
+	if !strings.HasSuffix(chunks[len(chunks)-1], "That's all.") {
+		t.Errorf("expected trailing text in the last chunk, got %q", chunks[len(chunks)-1])
+	}
+}
+
+func TestSplitMessage_HardCutKeepsRunes(t *testing.T) {
+	content := strings.Repeat("é", 100)
+	chunks := splitMessage(content, 51)
+	if strings.Join(chunks, "") != content {
+		t.Fatal("expected a lossless split")
+	}
+	for _, c := range chunks {
+		if len(c) > 51 || !utf8.ValidString(c) {
+			t.Errorf("bad chunk %q", c)
+		}
+	}
+}
@@ -30,7 +30,7 @@ type CLIChannel struct {
 // NewCLIChannel creates a CLIChannel.
 func NewCLIChannel(inbound *bus.AgentBus, console *bus.ConsoleBus) *CLIChannel {
 	return &CLIChannel{
-		Base:    NewBase(bus.ChannelCLI, inbound, nil),
+		Base:    NewBase(bus.ChannelCLI, inbound, nil, 0),
 		console: console,
 	}
 }
@@ -30,7 +30,7 @@ type DingTalkChannel struct {
 
 func NewDingTalkChannel(cfg *channel.DingTalkConfig, b *bus.AgentBus) *DingTalkChannel {
 	return &DingTalkChannel{
-		Base:       NewBase("dingtalk", b, cfg.AllowFrom),
+		Base:       NewBase("dingtalk", b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, false),
 		cfg:        cfg,
 		httpClient: &http.Client{Timeout: 15 * time.Second},
 	}
@@ -217,6 +217,9 @@ func (d *DingTalkChannel) Send(ctx context.Context, msg bus.ChannelMessage) erro
 		"https://api.dingtalk.com/v1.0/robot/oToMessages/batchSend", bytes.NewReader(data))
 	req.Header.Set("x-acs-dingtalk-access-token", token)
 	req.Header.Set("Content-Type", "application/json")
+	if err := d.WaitSend(ctx, msg.ChatId()); err != nil {
+		return err
+	}
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
 		return err
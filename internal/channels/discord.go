@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,6 +25,12 @@ const (
 	discordAPI       = "https://discord.com/api/v10"
 	discordMaxMsgLen = 2000
 	discordMaxFileB  = 20 * 1024 * 1024 // 20 MB
+
+	// Embed limits: description length, embeds per message, and total
+	// text across a message's embeds.
+	discordMaxEmbedDesc   = 4096
+	discordMaxEmbeds      = 10
+	discordMaxEmbedsTotal = 6000
 )
 
 // DiscordChannel connects to the Discord Gateway WebSocket.
@@ -32,11 +40,18 @@ type DiscordChannel struct {
 	httpClient *http.Client
 	conn       *websocket.Conn
 	seq        *int
+
+	// Set from the READY dispatch; cleared when the session can't be resumed.
+	sessionID string
+	resumeURL string
 }
 
+// errInvalidSession is returned by gatewayLoop on a non-resumable op 9.
+var errInvalidSession = errors.New("discord: gateway invalidated the session")
+
 func NewDiscordChannel(cfg *channel.DiscordConfig, b *bus.AgentBus) *DiscordChannel {
 	return &DiscordChannel{
-		Base:       NewBase("discord", b, cfg.AllowFrom),
+		Base:       NewBase("discord", b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, true),
 		cfg:        cfg,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}
@@ -49,9 +64,13 @@ func (d *DiscordChannel) Start(ctx context.Context) error {
 		return fmt.Errorf("discord: token not configured")
 	}
 	for {
-		if err := d.connect(ctx); err != nil && ctx.Err() != nil {
+		err := d.connect(ctx)
+		if err != nil && ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if !discordResumable(err) {
+			d.sessionID, d.resumeURL, d.seq = "", "", nil
+		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -60,8 +79,39 @@ func (d *DiscordChannel) Start(ctx context.Context) error {
 	}
 }
 
+// discordResumable reports whether a session dropped with err can be
+// resumed with op 6 RESUME rather than a fresh IDENTIFY. Discord closes with
+// 4007 (invalid seq) and 4009 (session timed out) when the session is gone;
+// the other 4xxx codes listed are fatal and a new session is the only retry.
+func discordResumable(err error) bool {
+	if errors.Is(err, errInvalidSession) {
+		return false
+	}
+	var ce *websocket.CloseError
+	if errors.As(err, &ce) {
+		switch ce.Code {
+		case 4004, 4007, 4009, 4010, 4011, 4012, 4013, 4014:
+			return false
+		}
+	}
+	return true
+}
+
+// gatewayURL returns the URL to dial: the READY resume URL, with the
+// configured query string, while there is a session to resume.
+func (d *DiscordChannel) gatewayURL() string {
+	if d.sessionID == "" || d.resumeURL == "" {
+		return d.cfg.GatewayURL
+	}
+	u := strings.TrimSuffix(d.resumeURL, "/") + "/"
+	if cfg, err := url.Parse(d.cfg.GatewayURL); err == nil && cfg.RawQuery != "" {
+		u += "?" + cfg.RawQuery
+	}
+	return u
+}
+
 func (d *DiscordChannel) connect(ctx context.Context) error {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, d.cfg.GatewayURL, nil)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, d.gatewayURL(), nil)
 	if err != nil {
 		return err
 	}
@@ -107,17 +157,44 @@ func (d *DiscordChannel) gatewayLoop(ctx context.Context, conn *websocket.Conn)
 			_ = json.Unmarshal(payload.D, &hello)
 			interval := time.Duration(hello.HeartbeatInterval) * time.Millisecond
 			go d.heartbeatLoop(ctx, conn, interval, heartbeatStop)
-			if err := d.identify(conn); err != nil {
+			if d.sessionID != "" {
+				err = d.resume(conn)
+			} else {
+				err = d.identify(conn)
+			}
+			if err != nil {
 				return err
 			}
 		case 0: // DISPATCH
-			if payload.T == "MESSAGE_CREATE" {
+			switch {
+			case payload.T == "READY":
+				var ready struct {
+					SessionID        string `json:"session_id"`
+					ResumeGatewayURL string `json:"resume_gateway_url"`
+				}
+				_ = json.Unmarshal(payload.D, &ready)
+				d.sessionID, d.resumeURL = ready.SessionID, ready.ResumeGatewayURL
+			case payload.T == "RESUMED":
+				slog.Info("discord: gateway session resumed")
+			case payload.T == "MESSAGE_CREATE":
 				var msg map[string]any
 				if err := json.Unmarshal(payload.D, &msg); err == nil {
-					go d.handleMessageCreate(ctx, msg)
+					go d.handleMessageCreate(ctx, msg, false)
+				}
+			case payload.T == "MESSAGE_UPDATE" && d.cfg.HandleEdits:
+				var msg map[string]any
+				if err := json.Unmarshal(payload.D, &msg); err == nil && isContentEdit(msg) {
+					go d.handleMessageCreate(ctx, msg, true)
 				}
 			}
-		case 7, 9: // RECONNECT / INVALID_SESSION
+		case 7: // RECONNECT
+			return fmt.Errorf("discord: gateway requested reconnect (op=%d)", payload.Op)
+		case 9: // INVALID_SESSION; d tells whether it may be resumed
+			var resumable bool
+			_ = json.Unmarshal(payload.D, &resumable)
+			if !resumable {
+				return errInvalidSession
+			}
 			return fmt.Errorf("discord: gateway requested reconnect (op=%d)", payload.Op)
 		}
 	}
@@ -155,7 +232,33 @@ func (d *DiscordChannel) identify(conn *websocket.Conn) error {
 	return conn.WriteMessage(websocket.TextMessage, data)
 }
 
-func (d *DiscordChannel) handleMessageCreate(ctx context.Context, payload map[string]any) {
+// resume asks the gateway to replay events missed since d.seq on the
+// stored session.
+func (d *DiscordChannel) resume(conn *websocket.Conn) error {
+	payload := map[string]any{
+		"op": 6,
+		"d": map[string]any{
+			"token":      d.cfg.Token,
+			"session_id": d.sessionID,
+			"seq":        d.seq,
+		},
+	}
+	data, _ := json.Marshal(payload)
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// isContentEdit reports whether a MESSAGE_UPDATE payload is a user edit of the
+// message text. Discord also sends partial updates (e.g. embed unfurls) that
+// carry no author, content, or edited_timestamp; those are ignored.
+func isContentEdit(payload map[string]any) bool {
+	_, hasContent := payload["content"].(string)
+	_, hasAuthor := payload["author"].(map[string]any)
+	return hasContent && hasAuthor && payload["edited_timestamp"] != nil
+}
+
+// handleMessageCreate dispatches a MESSAGE_CREATE payload, or a MESSAGE_UPDATE
+// payload when edited is true.
+func (d *DiscordChannel) handleMessageCreate(ctx context.Context, payload map[string]any, edited bool) {
 	author, _ := payload["author"].(map[string]any)
 	if bot, _ := author["bot"].(bool); bot {
 		return
@@ -203,6 +306,9 @@ func (d *DiscordChannel) handleMessageCreate(ctx context.Context, payload map[st
 	if text == "" {
 		text = "[empty message]"
 	}
+	if edited {
+		text = editedMessagePrefix + text
+	}
 
 	// Typing indicator.
 	typingCtx, cancelTyping := context.WithCancel(ctx)
@@ -216,11 +322,80 @@ func (d *DiscordChannel) handleMessageCreate(ctx context.Context, payload map[st
 		}
 	}
 
-	d.HandleMessage(senderID, channelID, text, mediaPaths, map[string]any{
+	metadata := map[string]any{
 		"message_id": payload["id"],
 		"guild_id":   payload["guild_id"],
 		"reply_to":   replyTo,
-	})
+	}
+	if threadID := discordThreadID(payload); threadID != "" {
+		metadata["thread_id"] = threadID
+	}
+	if edited {
+		metadata["edited"] = true
+	}
+
+	d.HandleMessage(senderID, channelID, text, mediaPaths, metadata)
+}
+
+// discordThreadID returns the thread a MESSAGE_CREATE payload belongs to, or
+// "". A message inside a thread carries a position; one that started a
+// thread carries the thread object.
+func discordThreadID(payload map[string]any) string {
+	if thread, ok := payload["thread"].(map[string]any); ok {
+		if id, _ := thread["id"].(string); id != "" {
+			return id
+		}
+	}
+	if _, inThread := payload["position"]; inThread {
+		id, _ := payload["channel_id"].(string)
+		return id
+	}
+	return ""
+}
+
+// replyChannel returns the channel a reply should be posted to: the
+// originating thread when there is one, else the chat.
+func replyChannel(msg bus.ChannelMessage) string {
+	if id, _ := msg.Metadata()["thread_id"].(string); id != "" {
+		return id
+	}
+	return msg.ChatId()
+}
+
+// wantsThread reports whether a reply should open a new thread on the
+// message it answers: it is long, is not already in a thread, and was
+// sent in a guild (DMs have no threads).
+func (d *DiscordChannel) wantsThread(msg bus.ChannelMessage) bool {
+	meta := msg.Metadata()
+	if d.cfg.AutoThreadChars <= 0 || len(msg.Content()) <= d.cfg.AutoThreadChars {
+		return false
+	}
+	threadID, _ := meta["thread_id"].(string)
+	guildID, _ := meta["guild_id"].(string)
+	messageID, _ := meta["message_id"].(string)
+	return threadID == "" && guildID != "" && messageID != ""
+}
+
+// startThread creates a thread on the message being answered and returns
+// its id. The thread is named after the first line of the reply.
+func (d *DiscordChannel) startThread(ctx context.Context, msg bus.ChannelMessage) (string, error) {
+	messageID, _ := msg.Metadata()["message_id"].(string)
+	name, _, _ := strings.Cut(strings.TrimSpace(msg.Content()), "\n")
+	name = strings.Trim(name, "#*_` ")
+	if r := []rune(name); len(r) > 100 {
+		name = string(r[:100])
+	}
+	if name == "" {
+		name = "Reply"
+	}
+	url := discordAPI + "/channels/" + msg.ChatId() + "/messages/" + messageID + "/threads"
+	var thread struct {
+		ID string `json:"id"`
+	}
+	if err := d.postJSONInto(ctx, url, map[string]any{"name": name}, &thread); err != nil {
+		return "", err
+	}
+	return thread.ID, nil
 }
 
 func (d *DiscordChannel) sendTypingLoop(ctx context.Context, channelID string) {
@@ -241,17 +416,24 @@ func (d *DiscordChannel) sendTypingLoop(ctx context.Context, channelID string) {
 }
 
 func (d *DiscordChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
-	url := discordAPI + "/channels/" + msg.ChatId() + "/messages"
-	chunks := splitMessage(msg.Content(), discordMaxMsgLen)
-	if len(chunks) == 0 {
-		return nil
+	target := replyChannel(msg)
+	threaded := false
+	if d.wantsThread(msg) {
+		if id, err := d.startThread(ctx, msg); err != nil {
+			slog.Warn("discord: could not start thread; replying in channel", "err", err)
+		} else {
+			target, threaded = id, true
+		}
 	}
-	for i, chunk := range chunks {
-		payload := map[string]any{"content": chunk}
-		if i == 0 && msg.ReplyTo() != "" {
+	url := discordAPI + "/channels/" + target + "/messages"
+	for i, payload := range d.messagePayloads(msg.Content()) {
+		if i == 0 && msg.ReplyTo() != "" && !threaded {
 			payload["message_reference"] = map[string]any{"message_id": msg.ReplyTo()}
 			payload["allowed_mentions"] = map[string]any{"replied_user": false}
 		}
+		if err := d.WaitSend(ctx, target); err != nil {
+			return err
+		}
 		if err := d.postJSON(ctx, url, payload); err != nil {
 			slog.Error("discord: send failed", "err", err)
 		}
@@ -259,7 +441,52 @@ func (d *DiscordChannel) Send(ctx context.Context, msg bus.ChannelMessage) error
 	return nil
 }
 
+// messagePayloads returns the message bodies for content: plain chunks, or
+// embeds when useEmbeds is on and content is too long for one message.
+func (d *DiscordChannel) messagePayloads(content string) []map[string]any {
+	var payloads []map[string]any
+	if d.cfg.UseEmbeds && len(content) > discordMaxMsgLen {
+		for _, group := range embedGroups(content) {
+			embeds := make([]map[string]any, len(group))
+			for i, desc := range group {
+				embeds[i] = map[string]any{"description": desc}
+			}
+			payloads = append(payloads, map[string]any{"embeds": embeds})
+		}
+		return payloads
+	}
+	for _, chunk := range splitMessage(content, discordMaxMsgLen) {
+		payloads = append(payloads, map[string]any{"content": chunk})
+	}
+	return payloads
+}
+
+// embedGroups splits content into embed descriptions and groups them into
+// messages within Discord's per-message embed count and total-length limits.
+func embedGroups(content string) [][]string {
+	var groups [][]string
+	var group []string
+	total := 0
+	for _, desc := range splitMessage(content, discordMaxEmbedDesc) {
+		if len(group) == discordMaxEmbeds || total+len(desc) > discordMaxEmbedsTotal {
+			groups = append(groups, group)
+			group, total = nil, 0
+		}
+		group = append(group, desc)
+		total += len(desc)
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
 func (d *DiscordChannel) postJSON(ctx context.Context, url string, payload any) error {
+	return d.postJSONInto(ctx, url, payload, nil)
+}
+
+// postJSONInto is postJSON that decodes the response body into out, if set.
+func (d *DiscordChannel) postJSONInto(ctx context.Context, url string, payload, out any) error {
 	data, _ := json.Marshal(payload)
 	for attempt := 0; attempt < 3; attempt++ {
 		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
@@ -290,6 +517,9 @@ func (d *DiscordChannel) postJSON(ctx context.Context, url string, payload any)
 		if resp.StatusCode >= 400 {
 			return fmt.Errorf("discord: HTTP %d: %s", resp.StatusCode, string(body))
 		}
+		if out != nil {
+			return json.Unmarshal(body, out)
+		}
 		return nil
 	}
 	return fmt.Errorf("discord: max retries exceeded")
@@ -0,0 +1,158 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+)
+
+func TestDiscordResumable(t *testing.T) {
+	closed := func(code int) error {
+		return fmt.Errorf("read: %w", &websocket.CloseError{Code: code})
+	}
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"network drop", io.ErrUnexpectedEOF, true},
+		{"abnormal closure", closed(websocket.CloseAbnormalClosure), true},
+		{"unknown error", closed(4000), true},
+		{"op 7 reconnect", errors.New("discord: gateway requested reconnect (op=7)"), true},
+		{"op 9 invalid session", errInvalidSession, false},
+		{"invalid seq", closed(4007), false},
+		{"session timed out", closed(4009), false},
+		{"authentication failed", closed(4004), false},
+		{"disallowed intents", closed(4014), false},
+	}
+	for _, c := range cases {
+		if got := discordResumable(c.err); got != c.want {
+			t.Errorf("%s: discordResumable = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDiscordGatewayURL(t *testing.T) {
+	cfg := channel.DefaultDiscordConfig()
+	d := NewDiscordChannel(&cfg, bus.NewAgentBus(1))
+	if got := d.gatewayURL(); got != cfg.GatewayURL {
+		t.Errorf("expected the configured URL without a session, got %q", got)
+	}
+	d.sessionID, d.resumeURL = "abc", "wss://gateway-us-east1-b.discord.gg"
+	if got := d.gatewayURL(); got != "wss://gateway-us-east1-b.discord.gg/?v=10&encoding=json" {
+		t.Errorf("expected the resume URL with the configured query, got %q", got)
+	}
+}
+
+func TestDiscordHandleMessageCreate_ThreadReply(t *testing.T) {
+	cfg := channel.DefaultDiscordConfig()
+	agentBus := bus.NewAgentBus(1)
+	d := NewDiscordChannel(&cfg, agentBus)
+
+	d.handleMessageCreate(context.Background(), map[string]any{
+		"id":         "m1",
+		"channel_id": "thread-9",
+		"guild_id":   "g1",
+		"position":   3.0,
+		"author":     map[string]any{"id": "u1"},
+		"content":    "what's next?",
+	}, false)
+	in := <-agentBus.Subscribe()
+	if in.Metadata()["thread_id"] != "thread-9" {
+		t.Fatalf("expected thread_id in metadata, got %v", in.Metadata())
+	}
+
+	reply := bus.NewChannelMessageBuilder("discord", "parent-1", "next step").
+		Metadata(in.Metadata()).Build()
+	if got := replyChannel(reply); got != "thread-9" {
+		t.Errorf("expected the reply routed to the thread, got %q", got)
+	}
+	plain := bus.NewChannelMessageBuilder("discord", "parent-1", "hi").Build()
+	if got := replyChannel(plain); got != "parent-1" {
+		t.Errorf("expected a non-thread reply to use the chat id, got %q", got)
+	}
+}
+
+func TestDiscordWantsThread(t *testing.T) {
+	cfg := channel.DefaultDiscordConfig()
+	d := NewDiscordChannel(&cfg, bus.NewAgentBus(1))
+	reply := func(content string, meta map[string]any) bus.ChannelMessage {
+		return bus.NewChannelMessageBuilder("discord", "c1", content).Metadata(meta).Build()
+	}
+	guild := map[string]any{"guild_id": "g1", "message_id": "m1"}
+	long := strings.Repeat("x", 50)
+
+	if d.wantsThread(reply(long, guild)) {
+		t.Error("expected no thread when autoThreadChars is 0")
+	}
+	cfg.AutoThreadChars = 20
+	if !d.wantsThread(reply(long, guild)) {
+		t.Error("expected a long guild reply to open a thread")
+	}
+	if d.wantsThread(reply("short", guild)) {
+		t.Error("expected no thread for a short reply")
+	}
+	if d.wantsThread(reply(long, map[string]any{"message_id": "m1"})) {
+		t.Error("expected no thread in a DM")
+	}
+	if d.wantsThread(reply(long, map[string]any{"guild_id": "g1", "message_id": "m1", "thread_id": "t1"})) {
+		t.Error("expected no new thread inside a thread")
+	}
+}
+
+func TestEmbedGroups(t *testing.T) {
+	para := strings.Repeat("a", 3000)
+	content := strings.Join([]string{para, para, para, para, para}, "\n\n") // 15008 bytes
+
+	groups := embedGroups(content)
+	// Each 3000-char paragraph is its own description; two fit in a
+	// message's 6000-char embed total, so five need three messages.
+	if len(groups) != 3 || len(groups[0]) != 2 || len(groups[1]) != 2 || len(groups[2]) != 1 {
+		t.Fatalf("unexpected grouping: %d groups", len(groups))
+	}
+	for i, g := range groups {
+		total := 0
+		for _, desc := range g {
+			if len(desc) > discordMaxEmbedDesc {
+				t.Errorf("group %d: description of %d chars", i, len(desc))
+			}
+			total += len(desc)
+		}
+		if total > discordMaxEmbedsTotal || len(g) > discordMaxEmbeds {
+			t.Errorf("group %d over limits: %d embeds, %d chars", i, len(g), total)
+		}
+	}
+
+	many := strings.Repeat(strings.Repeat("b", 400)+"\n\n", 30)
+	for _, g := range embedGroups(many) {
+		if len(g) > discordMaxEmbeds {
+			t.Errorf("expected at most %d embeds per message, got %d", discordMaxEmbeds, len(g))
+		}
+	}
+}
+
+func TestDiscordMessagePayloads(t *testing.T) {
+	cfg := channel.DefaultDiscordConfig()
+	d := NewDiscordChannel(&cfg, bus.NewAgentBus(1))
+	long := strings.Repeat("word ", 1000)
+
+	if p := d.messagePayloads(long); len(p) != 3 || p[0]["content"] == nil {
+		t.Fatalf("expected plain chunks by default, got %d payloads", len(p))
+	}
+	cfg.UseEmbeds = true
+	if p := d.messagePayloads("short"); len(p) != 1 || p[0]["content"] != "short" {
+		t.Errorf("expected a short reply kept as plain text, got %v", p)
+	}
+	p := d.messagePayloads(long)
+	if len(p) != 1 || len(p[0]["embeds"].([]map[string]any)) != 2 {
+		t.Errorf("expected one message with two embeds, got %v", p)
+	}
+}
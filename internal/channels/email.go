@@ -1,12 +1,23 @@
 package channels
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
+	"html"
+	"io"
 	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
 	"net"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -20,15 +31,18 @@ import (
 // to avoid bringing in a heavy dependency.
 type EmailChannel struct {
 	Base
-	cfg     *channel.EmailConfig
-	seenUID map[uint32]bool
+	cfg      *channel.EmailConfig
+	seenUID  map[uint32]bool
+	mediaDir string // where inbound attachments are saved
 }
 
 func NewEmailChannel(cfg *channel.EmailConfig, b *bus.AgentBus) *EmailChannel {
+	home, _ := os.UserHomeDir()
 	return &EmailChannel{
-		Base:    NewBase("email", b, cfg.AllowFrom),
-		cfg:     cfg,
-		seenUID: make(map[uint32]bool),
+		Base:     NewBase("email", b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, false),
+		cfg:      cfg,
+		seenUID:  make(map[uint32]bool),
+		mediaDir: filepath.Join(home, ".nanobot", "media"),
 	}
 }
 
@@ -92,8 +106,13 @@ func (e *EmailChannel) poll(ctx context.Context) error {
 		return err
 	}
 
-	// LOGIN
-	if err := imap.cmd("A1", fmt.Sprintf("LOGIN %q %q", e.cfg.IMAPUsername, e.cfg.IMAPPassword)); err != nil {
+	// LOGIN, or AUTHENTICATE XOAUTH2 with an access token.
+	if e.useOAuth2() {
+		token := base64.StdEncoding.EncodeToString([]byte(xoauth2Token(e.cfg.IMAPUsername, e.oauth2Token())))
+		if err := imap.authenticate("A1", "XOAUTH2", token); err != nil {
+			return fmt.Errorf("imap authenticate: %w", err)
+		}
+	} else if err := imap.cmd("A1", fmt.Sprintf("LOGIN %q %q", e.cfg.IMAPUsername, e.cfg.IMAPPassword)); err != nil {
 		return fmt.Errorf("imap login: %w", err)
 	}
 
@@ -133,12 +152,16 @@ func (e *EmailChannel) poll(ctx context.Context) error {
 			slog.Warn("email: fetch error", "seq", seq, "err", err)
 			continue
 		}
-		from, subject, body := parseEmail(rawMsg)
-		if from == "" {
+		maxBytes := e.cfg.MaxAttachmentBytes
+		if maxBytes <= 0 {
+			maxBytes = 10 << 20
+		}
+		in := parseEmail(rawMsg, e.cfg.MaxAttachments, maxBytes)
+		if in.From == "" {
 			continue
 		}
 
-		senderID := extractEmail(from)
+		senderID := extractEmail(in.From)
 		if !e.IsAllowed(senderID) {
 			continue
 		}
@@ -147,16 +170,23 @@ func (e *EmailChannel) poll(ctx context.Context) error {
 		if maxChars <= 0 {
 			maxChars = 12000
 		}
+		body := in.Body
 		if len(body) > maxChars {
 			body = body[:maxChars]
 		}
 
-		content := fmt.Sprintf("Subject: %s\nFrom: %s\n\n%s", subject, from, body)
+		content := fmt.Sprintf("Subject: %s\nFrom: %s\n\n%s", in.Subject, in.From, body)
+		mediaPaths, notes := e.saveAttachments(in.Attachments)
+		if len(notes) > 0 {
+			content += "\n\n" + strings.Join(notes, "\n")
+		}
 
-		e.HandleMessage(senderID, senderID, content, nil, map[string]any{
-			"from":    from,
-			"subject": subject,
-			"seq":     seq,
+		e.HandleMessage(senderID, senderID, content, mediaPaths, map[string]any{
+			"from":       in.From,
+			"subject":    in.Subject,
+			"seq":        seq,
+			"message_id": in.MessageID,
+			"references": in.References,
 		})
 
 		if e.cfg.MarkSeen {
@@ -175,11 +205,16 @@ func (e *EmailChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
 		subject = e.cfg.SubjectPrefix + s
 	}
 
-	body := fmt.Sprintf("To: %s\r\nFrom: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s",
-		to, e.cfg.FromAddress, subject, msg.Content())
+	body := composeEmail(e.cfg.FromAddress, to, subject, msg.Metadata(), msg.Content())
 
+	if err := e.WaitSend(ctx, to); err != nil {
+		return err
+	}
 	addr := net.JoinHostPort(e.cfg.SMTPHost, fmt.Sprintf("%d", e.cfg.SMTPPort))
 	auth := smtp.PlainAuth("", e.cfg.SMTPUsername, e.cfg.SMTPPassword, e.cfg.SMTPHost)
+	if e.useOAuth2() {
+		auth = &xoauth2Auth{user: e.cfg.SMTPUsername, token: e.oauth2Token(), host: e.cfg.SMTPHost}
+	}
 
 	var err error
 	if e.cfg.SMTPUseSSL {
@@ -208,6 +243,66 @@ func (e *EmailChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
 	return err
 }
 
+func (e *EmailChannel) useOAuth2() bool {
+	return strings.EqualFold(e.cfg.AuthMethod, "xoauth2")
+}
+
+// oauth2Token returns the configured access token with ${VAR} references
+// expanded, so the token can be kept out of the config file.
+func (e *EmailChannel) oauth2Token() string {
+	return os.ExpandEnv(e.cfg.OAuth2Token)
+}
+
+// xoauth2Token builds the SASL XOAUTH2 initial response (before base64).
+func xoauth2Token(user, accessToken string) string {
+	return "user=" + user + "\x01auth=Bearer " + accessToken + "\x01\x01"
+}
+
+// xoauth2Auth implements smtp.Auth for the XOAUTH2 mechanism.
+type xoauth2Auth struct {
+	user, token, host string
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// Like smtp.PlainAuth, never send the token over an unencrypted
+	// connection to a remote host.
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, fmt.Errorf("smtp: unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, fmt.Errorf("smtp: wrong host name")
+	}
+	return "XOAUTH2", []byte(xoauth2Token(a.user, a.token)), nil
+}
+
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		// A challenge carries the error details; an empty reply makes the
+		// server finish with its failure status.
+		return []byte{}, nil
+	}
+	return nil, nil
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
+
+// composeEmail renders an outbound plain-text message. When meta carries
+// the inbound message_id, In-Reply-To and References are set so the reply
+// threads under the original in mail clients.
+func composeEmail(from, to, subject string, meta map[string]any, content string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "To: %s\r\nFrom: %s\r\nSubject: %s\r\n", to, from, subject)
+	if id, _ := meta["message_id"].(string); id != "" {
+		refs, _ := meta["references"].(string)
+		fmt.Fprintf(&sb, "In-Reply-To: %s\r\nReferences: %s\r\n", id, strings.TrimSpace(refs+" "+id))
+	}
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	sb.WriteString(content)
+	return sb.String()
+}
+
 // ---------------------------------------------------------------------------
 // Minimal IMAP client (avoids importing emersion/go-imap just for polling)
 // ---------------------------------------------------------------------------
@@ -254,6 +349,31 @@ func (c *imapConn) cmd(tag, command string) error {
 	}
 }
 
+// authenticate runs AUTHENTICATE with a SASL initial response. A "+"
+// continuation carries an error challenge, answered with an empty line so
+// the server finishes with its tagged NO.
+func (c *imapConn) authenticate(tag, mechanism, initial string) error {
+	if _, err := fmt.Fprintf(c.conn, "%s AUTHENTICATE %s %s\r\n", tag, mechanism, initial); err != nil {
+		return err
+	}
+	for {
+		line, err := c.readline()
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.HasPrefix(line, "+"):
+			if _, err := fmt.Fprint(c.conn, "\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, tag+" OK"):
+			return nil
+		case strings.HasPrefix(line, tag+" NO"), strings.HasPrefix(line, tag+" BAD"):
+			return fmt.Errorf("imap: %s", line)
+		}
+	}
+}
+
 func (c *imapConn) search(tag, command string) ([]string, error) {
 	_, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, command)
 	if err != nil {
@@ -311,7 +431,139 @@ var reSubj = regexp.MustCompile(`(?i)^Subject:\s*(.+)$`)
 var reTags = regexp.MustCompile(`<[^>]+>`)
 var reMultiNL = regexp.MustCompile(`\n{3,}`)
 
-func parseEmail(raw string) (from, subject, body string) {
+// emailAttachment is a file attached to an inbound email.
+type emailAttachment struct {
+	Filename string
+	Data     []byte
+	Skipped  string // why the file was not kept, e.g. "too large"; "" if kept
+}
+
+// inboundEmail is a parsed inbound message.
+type inboundEmail struct {
+	From, Subject, Body string
+	MessageID           string // with angle brackets, as in the header
+	References          string // the message's own References chain
+	Attachments         []emailAttachment
+}
+
+// parseEmail extracts the headers, text body and attachments of a raw
+// RFC 822 message. Multipart bodies are walked: text/plain parts form
+// the body (text/html, tags stripped, only if there is no plain text) and
+// parts with a filename or an attachment disposition become attachments.
+// Beyond maxAttachments, or over maxBytes, attachments are kept as Skipped.
+func parseEmail(raw string, maxAttachments, maxBytes int) inboundEmail {
+	var in inboundEmail
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		in.From, in.Subject, in.Body = parseEmailLines(raw)
+		return in
+	}
+	dec := new(mime.WordDecoder)
+	decode := func(s string) string {
+		if d, err := dec.DecodeHeader(s); err == nil {
+			return strings.TrimSpace(d)
+		}
+		return strings.TrimSpace(s)
+	}
+	in.From, in.Subject = decode(msg.Header.Get("From")), decode(msg.Header.Get("Subject"))
+	in.MessageID = strings.TrimSpace(msg.Header.Get("Message-Id"))
+	in.References = strings.Join(strings.Fields(msg.Header.Get("References")), " ")
+
+	p := &emailParts{maxAttachments: maxAttachments, maxBytes: maxBytes}
+	p.walk(textproto.MIMEHeader(msg.Header), msg.Body, 0)
+	text := p.plain.String()
+	if strings.TrimSpace(text) == "" {
+		text = htmlToText(p.html.String())
+	}
+	in.Body = strings.TrimSpace(reMultiNL.ReplaceAllString(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n"))
+	in.Attachments = p.attachments
+	return in
+}
+
+var reHTMLBreak = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|tr|h[1-6])>`)
+var reHTMLHidden = regexp.MustCompile(`(?is)<(style|script)[^>]*>.*?</(style|script)>`)
+
+// htmlToText converts an HTML body to plain text: block ends and <br>
+// become newlines, style/script content and tags are dropped, and
+// entities are unescaped.
+func htmlToText(s string) string {
+	s = reHTMLHidden.ReplaceAllString(s, "")
+	s = reHTMLBreak.ReplaceAllString(s, "\n")
+	s = reTags.ReplaceAllString(s, "")
+	return html.UnescapeString(s)
+}
+
+// emailParts accumulates the parts of a MIME tree.
+type emailParts struct {
+	maxAttachments, maxBytes int
+	plain, html              strings.Builder
+	attachments              []emailAttachment
+}
+
+func (p *emailParts) walk(h textproto.MIMEHeader, r io.Reader, depth int) {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= 10 || params["boundary"] == "" {
+			return
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err != nil {
+				return
+			}
+			p.walk(part.Header, part, depth+1)
+		}
+	}
+
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	}
+
+	disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	filename := dparams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if disposition != "attachment" && filename == "" {
+		data, _ := io.ReadAll(r)
+		switch mediaType {
+		case "text/plain":
+			p.plain.Write(data)
+			p.plain.WriteString("\n")
+		case "text/html":
+			p.html.Write(data)
+			p.html.WriteString("\n")
+		}
+		return
+	}
+
+	if filename == "" {
+		filename = "attachment"
+	}
+	att := emailAttachment{Filename: filename}
+	if len(p.attachments) >= p.maxAttachments {
+		att.Skipped = "too many attachments"
+	} else {
+		data, _ := io.ReadAll(io.LimitReader(r, int64(p.maxBytes)+1))
+		if len(data) > p.maxBytes {
+			att.Skipped = "too large"
+		} else {
+			att.Data = data
+		}
+	}
+	p.attachments = append(p.attachments, att)
+}
+
+// parseEmailLines is the fallback for messages net/mail cannot parse: it
+// scans the headers line by line and returns the rest as the body.
+func parseEmailLines(raw string) (from, subject, body string) {
 	lines := strings.Split(raw, "\n")
 	var bodyLines []string
 	inBody := false
@@ -339,6 +591,36 @@ func parseEmail(raw string) (from, subject, body string) {
 	return
 }
 
+// saveAttachments writes attachments to the media directory and returns
+// their paths plus one "[attachment: ...]" note per attachment for the
+// message content.
+func (e *EmailChannel) saveAttachments(attachments []emailAttachment) (paths, notes []string) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+	_ = os.MkdirAll(e.mediaDir, 0o755)
+	for _, a := range attachments {
+		if a.Skipped != "" {
+			notes = append(notes, "[attachment: "+a.Filename+" - skipped, "+a.Skipped+"]")
+			continue
+		}
+		f, err := os.CreateTemp(e.mediaDir, "email-*-"+safeFilename(filepath.Base(a.Filename)))
+		if err == nil {
+			_, err = io.Copy(f, bytes.NewReader(a.Data))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			notes = append(notes, "[attachment: "+a.Filename+" - save failed]")
+			continue
+		}
+		paths = append(paths, f.Name())
+		notes = append(notes, "[attachment: "+f.Name()+"]")
+	}
+	return paths, notes
+}
+
 func extractEmail(from string) string {
 	// "Name <email@host>" → "email@host"
 	start := strings.LastIndex(from, "<")
@@ -0,0 +1,181 @@
+package channels
+
+import (
+	"encoding/base64"
+	"net/smtp"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+)
+
+const multipartFixture = "From: Alice <alice@example.com>\n" +
+	"Subject: =?UTF-8?Q?Q3_r=C3=A9port?=\n" +
+	"MIME-Version: 1.0\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\n" +
+	"\n" +
+	"--outer\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\n" +
+	"\n" +
+	"--inner\n" +
+	"Content-Type: text/plain; charset=utf-8\n" +
+	"Content-Transfer-Encoding: quoted-printable\n" +
+	"\n" +
+	"Numbers attached, caf=C3=A9 budget included.\n" +
+	"--inner\n" +
+	"Content-Type: text/html; charset=utf-8\n" +
+	"\n" +
+	"<p>Numbers attached</p>\n" +
+	"--inner--\n" +
+	"--outer\n" +
+	"Content-Type: text/csv; name=\"q3.csv\"\n" +
+	"Content-Disposition: attachment; filename=\"q3.csv\"\n" +
+	"Content-Transfer-Encoding: base64\n" +
+	"\n" +
+	"cXVhcnRlcixyZXZlbnVl\n" +
+	"CnEzLDQyCg==\n" +
+	"--outer\n" +
+	"Content-Type: application/octet-stream\n" +
+	"Content-Disposition: attachment; filename=\"big.bin\"\n" +
+	"\n" +
+	"0123456789012345678901234567890123456789\n" +
+	"--outer--\n"
+
+func TestParseEmail_Multipart(t *testing.T) {
+	in := parseEmail(multipartFixture, 5, 32)
+	if in.From != "Alice <alice@example.com>" || in.Subject != "Q3 réport" {
+		t.Errorf("unexpected headers: from=%q subject=%q", in.From, in.Subject)
+	}
+	if in.Body != "Numbers attached, café budget included." {
+		t.Errorf("expected the plain text part as body, got %q", in.Body)
+	}
+	atts := in.Attachments
+	if len(atts) != 2 {
+		t.Fatalf("expected 2 attachments, got %d", len(atts))
+	}
+	if atts[0].Filename != "q3.csv" || string(atts[0].Data) != "quarter,revenue\nq3,42\n" || atts[0].Skipped != "" {
+		t.Errorf("unexpected first attachment: %+v", atts[0])
+	}
+	if atts[1].Skipped != "too large" || atts[1].Data != nil {
+		t.Errorf("expected big.bin skipped as too large, got %+v", atts[1])
+	}
+
+	if atts := parseEmail(multipartFixture, 0, 32).Attachments; len(atts) != 2 || atts[0].Skipped != "too many attachments" {
+		t.Errorf("expected attachments skipped when maxAttachments is 0, got %+v", atts)
+	}
+}
+
+func TestEmailSaveAttachments(t *testing.T) {
+	cfg := channel.DefaultEmailConfig()
+	e := NewEmailChannel(&cfg, bus.NewAgentBus(1))
+	e.mediaDir = t.TempDir()
+
+	paths, notes := e.saveAttachments([]emailAttachment{
+		{Filename: "../q3.csv", Data: []byte("q3,42")},
+		{Filename: "big.bin", Skipped: "too large"},
+	})
+	if len(paths) != 1 || !strings.HasPrefix(paths[0], e.mediaDir) || !strings.HasSuffix(paths[0], "q3.csv") {
+		t.Fatalf("expected one file saved in the media dir, got %v", paths)
+	}
+	if data, _ := os.ReadFile(paths[0]); string(data) != "q3,42" {
+		t.Errorf("unexpected saved content %q", data)
+	}
+	if len(notes) != 2 || notes[0] != "[attachment: "+paths[0]+"]" || notes[1] != "[attachment: big.bin - skipped, too large]" {
+		t.Errorf("unexpected notes: %v", notes)
+	}
+}
+
+func TestParseEmail_TransferEncodings(t *testing.T) {
+	cases := map[string]struct{ raw, want string }{
+		"quoted-printable": {
+			raw: "From: bob@example.com\nSubject: hi\nContent-Type: text/plain; charset=utf-8\n" +
+				"Content-Transfer-Encoding: quoted-printable\n\n" +
+				"A long line that is soft-=\nwrapped, na=C3=AFve =3D ok.\n",
+			want: "A long line that is soft-wrapped, naïve = ok.",
+		},
+		"base64": {
+			raw: "From: bob@example.com\nSubject: hi\nContent-Type: text/plain; charset=utf-8\n" +
+				"Content-Transfer-Encoding: base64\n\n" +
+				"SGVsbG8gZnJvbSBi\nYXNlNjQh\n",
+			want: "Hello from base64!",
+		},
+		"html only alternative": {
+			raw: "From: bob@example.com\nSubject: hi\nContent-Type: multipart/alternative; boundary=b\n\n" +
+				"--b\nContent-Type: text/html\n\n" +
+				"<html><style>p{color:red}</style><p>First &amp; foremost</p><p>Line<br>two</p></html>\n" +
+				"--b--\n",
+			want: "First & foremost\nLine\ntwo",
+		},
+		"alternative prefers plain": {
+			raw: "From: bob@example.com\nSubject: hi\nContent-Type: multipart/alternative; boundary=b\n\n" +
+				"--b\nContent-Type: text/plain\n\nplain version\n" +
+				"--b\nContent-Type: text/html\n\n<p>html version</p>\n" +
+				"--b--\n",
+			want: "plain version",
+		},
+	}
+	for name, c := range cases {
+		if body := parseEmail(c.raw, 5, 1024).Body; body != c.want {
+			t.Errorf("%s: body = %q, want %q", name, body, c.want)
+		}
+	}
+}
+
+func TestComposeEmail_ThreadsReply(t *testing.T) {
+	raw := "From: alice@example.com\nSubject: Plans\nMessage-ID: <m2@example.com>\n" +
+		"References: <m0@example.com>\n <m1@example.com>\n\nsee you?\n"
+	in := parseEmail(raw, 5, 1024)
+	if in.MessageID != "<m2@example.com>" || in.References != "<m0@example.com> <m1@example.com>" {
+		t.Fatalf("unexpected threading headers: %+v", in)
+	}
+
+	meta := map[string]any{"message_id": in.MessageID, "references": in.References}
+	out := composeEmail("bot@example.com", "alice@example.com", "Re: Plans", meta, "yes")
+	for _, want := range []string{
+		"In-Reply-To: <m2@example.com>\r\n",
+		"References: <m0@example.com> <m1@example.com> <m2@example.com>\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	if out := composeEmail("bot@example.com", "alice@example.com", "Re: Message", nil, "hi"); strings.Contains(out, "In-Reply-To") {
+		t.Errorf("expected no threading headers without an inbound message, got:\n%s", out)
+	}
+}
+
+func TestXOAuth2(t *testing.T) {
+	raw := xoauth2Token("bot@example.com", "ya29.token")
+	if raw != "user=bot@example.com\x01auth=Bearer ya29.token\x01\x01" {
+		t.Errorf("unexpected SASL string %q", raw)
+	}
+	// The reference encoding from Google's XOAUTH2 documentation.
+	got := base64.StdEncoding.EncodeToString([]byte(xoauth2Token("someuser@example.com", "ya29.vF9dft4qmTc2Nvb3RlckBhdHRhdmlzdGEuY29tCg")))
+	want := "dXNlcj1zb21ldXNlckBleGFtcGxlLmNvbQFhdXRoPUJlYXJlciB5YTI5LnZGOWRmdDRxbVRjMk52YjNSbGNrQmhkSFJoZG1semRHRXVZMjl0Q2cBAQ=="
+	if got != want {
+		t.Errorf("encoded token = %s, want %s", got, want)
+	}
+
+	auth := &xoauth2Auth{user: "bot@example.com", token: "ya29.token", host: "smtp.example.com"}
+	mech, resp, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true})
+	if err != nil || mech != "XOAUTH2" || string(resp) != raw {
+		t.Errorf("unexpected Start: %q %q %v", mech, resp, err)
+	}
+	if _, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com"}); err == nil {
+		t.Error("expected the token refused over an unencrypted connection")
+	}
+	if next, err := auth.Next([]byte(`{"status":"401"}`), true); err != nil || len(next) != 0 {
+		t.Errorf("expected an empty reply to an error challenge, got %q %v", next, err)
+	}
+
+	t.Setenv("EMAIL_TEST_TOKEN", "from-env")
+	cfg := channel.DefaultEmailConfig()
+	cfg.AuthMethod, cfg.OAuth2Token = "XOAUTH2", "${EMAIL_TEST_TOKEN}"
+	e := NewEmailChannel(&cfg, bus.NewAgentBus(1))
+	if !e.useOAuth2() || e.oauth2Token() != "from-env" {
+		t.Errorf("expected xoauth2 with the token from the environment, got %v %q", e.useOAuth2(), e.oauth2Token())
+	}
+}
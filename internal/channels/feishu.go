@@ -7,7 +7,12 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -27,13 +32,16 @@ type FeishuChannel struct {
 	token      string
 	tokenMu    sync.Mutex
 	tokenExp   time.Time
+	mediaDir   string // where inbound images and files are saved
 }
 
 func NewFeishuChannel(cfg *channel.FeishuConfig, b *bus.AgentBus) *FeishuChannel {
+	home, _ := os.UserHomeDir()
 	return &FeishuChannel{
-		Base:       NewBase("feishu", b, cfg.AllowFrom),
+		Base:       NewBase("feishu", b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, true),
 		cfg:        cfg,
 		httpClient: &http.Client{Timeout: 15 * time.Second},
+		mediaDir:   filepath.Join(home, ".nanobot", "media"),
 	}
 }
 
@@ -205,13 +213,26 @@ func (f *FeishuChannel) handleEvent(data json.RawMessage) {
 	msgType := event.Event.Message.MessageType
 	rawContent := event.Event.Message.Content
 
-	// Extract text from JSON content.
+	// Extract text from JSON content; images and files are downloaded.
+	var mediaPaths []string
 	text := extractFeishuText(msgType, rawContent)
+	if res, ok := feishuResourceOf(msgType, rawContent); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		path, err := f.downloadResource(ctx, event.Event.Message.MessageID, res)
+		cancel()
+		if err != nil {
+			slog.Warn("feishu: media download failed", "type", msgType, "err", err)
+			text = "[" + msgType + ": " + res.Name + " - download failed]"
+		} else {
+			mediaPaths = append(mediaPaths, path)
+			text = "[" + msgType + ": " + path + "]"
+		}
+	}
 	if text == "" {
 		return
 	}
 
-	f.HandleMessage(senderID, chatID, text, nil, map[string]any{
+	f.HandleMessage(senderID, chatID, text, mediaPaths, map[string]any{
 		"message_id": event.Event.Message.MessageID,
 		"chat_type":  event.Event.Message.ChatType,
 		"msg_type":   msgType,
@@ -237,6 +258,96 @@ func extractFeishuText(msgType, rawContent string) string {
 	return rawContent
 }
 
+// feishuResource identifies a message attachment for the message-resource API.
+type feishuResource struct {
+	Key  string // image_key or file_key
+	Type string // "image" or "file", the API's type parameter
+	Name string // file name, or the key when there is none
+}
+
+// feishuResourceOf returns the downloadable resource of an image, file,
+// audio or media message.
+func feishuResourceOf(msgType, rawContent string) (feishuResource, bool) {
+	var content struct {
+		ImageKey string `json:"image_key"`
+		FileKey  string `json:"file_key"`
+		FileName string `json:"file_name"`
+	}
+	if err := json.Unmarshal([]byte(rawContent), &content); err != nil {
+		return feishuResource{}, false
+	}
+	switch msgType {
+	case "image":
+		if content.ImageKey != "" {
+			return feishuResource{Key: content.ImageKey, Type: "image", Name: content.ImageKey}, true
+		}
+	case "file", "audio", "media":
+		if content.FileKey != "" {
+			name := content.FileName
+			if name == "" {
+				name = content.FileKey
+			}
+			return feishuResource{Key: content.FileKey, Type: "file", Name: name}, true
+		}
+	}
+	return feishuResource{}, false
+}
+
+// resourceRequest builds the GET request for a message resource.
+func (f *FeishuChannel) resourceRequest(ctx context.Context, token, messageID string, res feishuResource) (*http.Request, error) {
+	u := "https://open.feishu.cn/open-apis/im/v1/messages/" + url.PathEscape(messageID) +
+		"/resources/" + url.PathEscape(res.Key) + "?type=" + res.Type
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// downloadResource saves a message resource to the media directory and
+// returns its path.
+func (f *FeishuChannel) downloadResource(ctx context.Context, messageID string, res feishuResource) (string, error) {
+	token, err := f.getAccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	req, err := f.resourceRequest(ctx, token, messageID, res)
+	if err != nil {
+		return "", err
+	}
+	client := *f.httpClient
+	client.Timeout = 0 // bounded by ctx; files can take longer than API calls
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("feishu: HTTP %d", resp.StatusCode)
+	}
+
+	name := safeFilename(filepath.Base(res.Name))
+	if res.Type == "image" && filepath.Ext(name) == "" {
+		if exts, _ := mime.ExtensionsByType(resp.Header.Get("Content-Type")); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	if err := os.MkdirAll(f.mediaDir, 0o755); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(f.mediaDir, messageID+"_"+name)
+	out, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		return "", err
+	}
+	return dest, out.Close()
+}
+
 func extractPostText(v any, parts *[]string) {
 	switch val := v.(type) {
 	case map[string]any:
@@ -272,6 +383,13 @@ func (f *FeishuChannel) Send(ctx context.Context, msg bus.ChannelMessage) error
 		"msg_type":   "text",
 		"content":    `{"text":"` + escapeFeishuText(msg.Content()) + `"}`,
 	}
+	if f.cfg.UseCards {
+		if card, err := markdownToFeishuCard(msg.Content()); err != nil {
+			slog.Warn("feishu: card rendering failed; sending text", "err", err)
+		} else {
+			body["msg_type"], body["content"] = "interactive", card
+		}
+	}
 	data, _ := json.Marshal(body)
 
 	url := "https://open.feishu.cn/open-apis/im/v1/messages?receive_id_type=" + idType
@@ -279,6 +397,9 @@ func (f *FeishuChannel) Send(ctx context.Context, msg bus.ChannelMessage) error
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
+	if err := f.WaitSend(ctx, msg.ChatId()); err != nil {
+		return err
+	}
 	resp, err := f.httpClient.Do(req)
 	if err != nil {
 		return err
@@ -293,3 +414,89 @@ func escapeFeishuText(s string) string {
 	s = strings.ReplaceAll(s, "\n", `\n`)
 	return s
 }
+
+// feishuMaxCardBytes is Feishu's size limit for a card's JSON.
+const feishuMaxCardBytes = 30 * 1024
+
+var (
+	reFeishuHeading = regexp.MustCompile(`^#{1,6}\s+(.+)$`)
+	reFeishuBullet  = regexp.MustCompile(`^(\s*)[-*+]\s+(.+)$`)
+	reFeishuRule    = regexp.MustCompile(`^(-{3,}|\*{3,}|_{3,})$`)
+)
+
+// markdownToFeishuCard renders markdown-ish text as the JSON content of an
+// interactive card. A leading heading becomes the card header; later
+// headings become bold lines, bullets "•" items and --- a divider.
+// Paragraphs are lark_md divs (bold, italics and links render natively)
+// and fenced code goes into markdown elements.
+func markdownToFeishuCard(content string) (string, error) {
+	card := map[string]any{"config": map[string]any{"wide_screen_mode": true}}
+	var elements []map[string]any
+	var para []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(para, "\n")); text != "" {
+			elements = append(elements, map[string]any{
+				"tag":  "div",
+				"text": map[string]any{"tag": "lark_md", "content": text},
+			})
+		}
+		para = nil
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			block := []string{trimmed}
+			for i++; i < len(lines); i++ {
+				block = append(block, lines[i])
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+					break
+				}
+			}
+			if !strings.HasPrefix(strings.TrimSpace(block[len(block)-1]), "```") || len(block) == 1 {
+				block = append(block, "```")
+			}
+			elements = append(elements, map[string]any{"tag": "markdown", "content": strings.Join(block, "\n")})
+		case trimmed == "":
+			flush()
+		case reFeishuRule.MatchString(trimmed):
+			flush()
+			elements = append(elements, map[string]any{"tag": "hr"})
+		case reFeishuHeading.MatchString(trimmed):
+			flush()
+			title := reFeishuHeading.FindStringSubmatch(trimmed)[1]
+			if len(elements) == 0 && card["header"] == nil {
+				card["header"] = map[string]any{
+					"title":    map[string]any{"tag": "plain_text", "content": title},
+					"template": "blue",
+				}
+			} else {
+				para = append(para, "**"+title+"**")
+				flush()
+			}
+		case reFeishuBullet.MatchString(line):
+			m := reFeishuBullet.FindStringSubmatch(line)
+			para = append(para, m[1]+"• "+m[2])
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+
+	if len(elements) == 0 {
+		return "", fmt.Errorf("feishu: no card content")
+	}
+	card["elements"] = elements
+	data, err := json.Marshal(card)
+	if err != nil {
+		return "", err
+	}
+	if len(data) > feishuMaxCardBytes {
+		return "", fmt.Errorf("feishu: card is %d bytes, over the %d limit", len(data), feishuMaxCardBytes)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,118 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+)
+
+func TestMarkdownToFeishuCard(t *testing.T) {
+	content := "# Weekly report\n\n" +
+		"Revenue is **up** this week.\n" +
+		"See [dashboard](https://example.com).\n\n" +
+		"## Highlights\n" +
+		"- shipped cards\n" +
+		"  * nested item\n\n" +
+		"---\n" +
+		"```go\nfmt.Println(\"hi\")\n```"
+
+	raw, err := markdownToFeishuCard(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var card struct {
+		Header struct {
+			Title struct{ Content string } `json:"title"`
+		} `json:"header"`
+		Elements []struct {
+			Tag     string `json:"tag"`
+			Content string `json:"content"`
+			Text    struct {
+				Tag     string `json:"tag"`
+				Content string `json:"content"`
+			} `json:"text"`
+		} `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(raw), &card); err != nil {
+		t.Fatalf("card is not valid JSON: %v", err)
+	}
+	if card.Header.Title.Content != "Weekly report" {
+		t.Errorf("expected the leading heading as the header, got %q", card.Header.Title.Content)
+	}
+
+	want := []struct{ tag, content string }{
+		{"div", "Revenue is **up** this week.\nSee [dashboard](https://example.com)."},
+		{"div", "**Highlights**"},
+		{"div", "• shipped cards\n  • nested item"},
+		{"hr", ""},
+		{"markdown", "```go\nfmt.Println(\"hi\")\n```"},
+	}
+	if len(card.Elements) != len(want) {
+		t.Fatalf("expected %d elements, got %d: %s", len(want), len(card.Elements), raw)
+	}
+	for i, w := range want {
+		el := card.Elements[i]
+		got := el.Content
+		if el.Tag == "div" {
+			got = el.Text.Content
+			if el.Text.Tag != "lark_md" {
+				t.Errorf("element %d: expected lark_md text, got %q", i, el.Text.Tag)
+			}
+		}
+		if el.Tag != w.tag || got != w.content {
+			t.Errorf("element %d = %s %q, want %s %q", i, el.Tag, got, w.tag, w.content)
+		}
+	}
+}
+
+func TestMarkdownToFeishuCard_Errors(t *testing.T) {
+	if _, err := markdownToFeishuCard("  \n\n"); err == nil {
+		t.Error("expected an error for empty content")
+	}
+	if _, err := markdownToFeishuCard(strings.Repeat("long line\n", 5000)); err == nil {
+		t.Error("expected an error for a card over the size limit")
+	}
+	if raw, err := markdownToFeishuCard("```\nunclosed"); err != nil || !strings.Contains(raw, "unclosed\\n```") {
+		t.Errorf("expected an unclosed fence to be closed, got %s %v", raw, err)
+	}
+}
+
+func TestFeishuResourceOf(t *testing.T) {
+	res, ok := feishuResourceOf("image", `{"image_key":"img_v2_abc"}`)
+	if !ok || res != (feishuResource{Key: "img_v2_abc", Type: "image", Name: "img_v2_abc"}) {
+		t.Errorf("unexpected image resource: %+v %v", res, ok)
+	}
+	res, ok = feishuResourceOf("file", `{"file_key":"file_v2_x","file_name":"report.pdf"}`)
+	if !ok || res != (feishuResource{Key: "file_v2_x", Type: "file", Name: "report.pdf"}) {
+		t.Errorf("unexpected file resource: %+v %v", res, ok)
+	}
+	if _, ok := feishuResourceOf("text", `{"text":"hi"}`); ok {
+		t.Error("expected no resource for a text message")
+	}
+	if _, ok := feishuResourceOf("image", `{}`); ok {
+		t.Error("expected no resource without an image key")
+	}
+}
+
+func TestFeishuResourceRequest(t *testing.T) {
+	cfg := channel.DefaultFeishuConfig()
+	f := NewFeishuChannel(&cfg, bus.NewAgentBus(1))
+	res := feishuResource{Key: "img_v2_abc", Type: "image", Name: "img_v2_abc"}
+
+	req, err := f.resourceRequest(context.Background(), "t-123", "om_456", res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "https://open.feishu.cn/open-apis/im/v1/messages/om_456/resources/img_v2_abc?type=image"
+	if req.Method != http.MethodGet || req.URL.String() != want {
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer t-123" {
+		t.Errorf("expected the tenant token, got %q", got)
+	}
+}
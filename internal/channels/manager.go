@@ -7,6 +7,7 @@ import (
 
 	"github.com/crystaldolphin/crystaldolphin/internal/bus"
 	"github.com/crystaldolphin/crystaldolphin/internal/config"
+	"github.com/crystaldolphin/crystaldolphin/internal/metrics"
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 )
 
@@ -74,6 +75,11 @@ func NewManager(cfg *config.Config, inbound *bus.AgentBus, outbound *bus.Channel
 		m.channels["qq"] = ch
 		slog.Info("channel enabled", "name", "qq")
 	}
+	if cfg.Channels.Webhook.Enabled {
+		ch := NewWebhookChannel(&cfg.Channels.Webhook, inbound)
+		m.channels["webhook"] = ch
+		slog.Info("channel enabled", "name", "webhook")
+	}
 
 	return m
 }
@@ -141,6 +147,7 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				slog.Debug("unknown channel for outbound message", "channel", msg.Channel())
 				continue
 			}
+			metrics.ChannelMessages.Inc(string(msg.Channel()), "outbound")
 			if err := ch.Send(ctx, msg); err != nil {
 				slog.Error("send error", "channel", msg.Channel(), "err", err)
 			}
@@ -31,7 +31,7 @@ type MochatChannel struct {
 
 func NewMochatChannel(cfg *channel.MochatConfig, b *bus.AgentBus) *MochatChannel {
 	return &MochatChannel{
-		Base:       NewBase("mochat", b, cfg.AllowFrom),
+		Base:       NewBase("mochat", b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, false),
 		cfg:        cfg,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		cursors:    make(map[string]string),
@@ -179,6 +179,9 @@ func (m *MochatChannel) Send(ctx context.Context, msg bus.ChannelMessage) error
 	}
 	req.Header.Set("Authorization", "Bearer "+m.cfg.ClawToken)
 	req.Header.Set("Content-Type", "application/json")
+	if err := m.WaitSend(ctx, msg.ChatId()); err != nil {
+		return err
+	}
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
 		return err
@@ -3,11 +3,18 @@ package channels
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,7 +25,8 @@ import (
 )
 
 // QQChannel connects to the QQ bot gateway WebSocket.
-// Implements C2C (private) message handling, mirroring Python qq.py.
+// Implements C2C (private) messages, mirroring Python qq.py, and group
+// messages that @-mention the bot, routed with a "group:<id>" chat id.
 type QQChannel struct {
 	Base
 	cfg        *channel.QQConfig
@@ -34,7 +42,7 @@ type QQChannel struct {
 
 func NewQQChannel(cfg *channel.QQConfig, b *bus.AgentBus) *QQChannel {
 	return &QQChannel{
-		Base:       NewBase("qq", b, cfg.AllowFrom),
+		Base:       NewBase("qq", b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, true),
 		cfg:        cfg,
 		httpClient: &http.Client{Timeout: 15 * time.Second},
 		seen:       make(map[string]bool),
@@ -173,10 +181,15 @@ func (q *QQChannel) gatewayLoop(ctx context.Context, conn *websocket.Conn, token
 				return err
 			}
 		case 0:
-			if payload.T == "C2C_MESSAGE_CREATE" {
+			switch payload.T {
+			case "C2C_MESSAGE_CREATE":
 				var msg map[string]any
 				_ = json.Unmarshal(payload.D, &msg)
 				go q.handleC2CMessage(msg)
+			case "GROUP_AT_MESSAGE_CREATE":
+				var msg map[string]any
+				_ = json.Unmarshal(payload.D, &msg)
+				go q.handleGroupMessage(msg)
 			}
 		}
 	}
@@ -203,7 +216,7 @@ func (q *QQChannel) identify(conn *websocket.Conn, token string) error {
 		"op": 2,
 		"d": map[string]any{
 			"token":   "QQBot " + token,
-			"intents": 1 << 25, // C2C_MESSAGE_CREATE
+			"intents": 1 << 25, // GROUP_AND_C2C_EVENT: C2C_MESSAGE_CREATE + GROUP_AT_MESSAGE_CREATE
 			"shard":   []int{0, 1},
 		},
 	}
@@ -211,13 +224,12 @@ func (q *QQChannel) identify(conn *websocket.Conn, token string) error {
 	return conn.WriteMessage(websocket.TextMessage, data)
 }
 
-func (q *QQChannel) handleC2CMessage(payload map[string]any) {
-	msgID, _ := payload["id"].(string)
-
+// firstSeen records msgID and reports whether it had not been seen before.
+func (q *QQChannel) firstSeen(msgID string) bool {
 	q.seenMu.Lock()
+	defer q.seenMu.Unlock()
 	if q.seen[msgID] {
-		q.seenMu.Unlock()
-		return
+		return false
 	}
 	q.seen[msgID] = true
 	q.seenQueue = append(q.seenQueue, msgID)
@@ -226,7 +238,14 @@ func (q *QQChannel) handleC2CMessage(payload map[string]any) {
 		q.seenQueue = q.seenQueue[1:]
 		delete(q.seen, del)
 	}
-	q.seenMu.Unlock()
+	return true
+}
+
+func (q *QQChannel) handleC2CMessage(payload map[string]any) {
+	msgID, _ := payload["id"].(string)
+	if !q.firstSeen(msgID) {
+		return
+	}
 
 	author, _ := payload["author"].(map[string]any)
 	senderID, _ := author["user_openid"].(string)
@@ -243,21 +262,173 @@ func (q *QQChannel) handleC2CMessage(payload map[string]any) {
 	})
 }
 
+// qqGroupPrefix marks group chat ids, which are "group:<group_openid>".
+const qqGroupPrefix = "group:"
+
+var reQQMention = regexp.MustCompile(`<@!?\w+>`)
+
+// handleGroupMessage dispatches a GROUP_AT_MESSAGE_CREATE payload. QQ only
+// delivers group messages that @-mention the bot; groupPolicy then decides
+// which groups are answered.
+func (q *QQChannel) handleGroupMessage(payload map[string]any) {
+	msgID, _ := payload["id"].(string)
+	groupID, _ := payload["group_openid"].(string)
+	if !q.allowedGroup(groupID) || !q.firstSeen(msgID) {
+		return
+	}
+
+	author, _ := payload["author"].(map[string]any)
+	senderID, _ := author["member_openid"].(string)
+	content, _ := payload["content"].(string)
+	content = strings.TrimSpace(reQQMention.ReplaceAllString(content, ""))
+	if content == "" || senderID == "" {
+		return
+	}
+
+	q.HandleMessage(senderID, qqGroupPrefix+groupID, content, nil, map[string]any{
+		"message_id": msgID,
+		"group_id":   groupID,
+	})
+}
+
+// allowedGroup applies groupPolicy: "mention" answers every group,
+// "allowlist" only groups in groupAllowFrom, "disabled" none.
+func (q *QQChannel) allowedGroup(groupID string) bool {
+	if groupID == "" {
+		return false
+	}
+	switch q.cfg.GroupPolicy {
+	case "disabled":
+		return false
+	case "allowlist":
+		return slices.Contains(q.cfg.GroupAllowFrom, groupID)
+	}
+	return true
+}
+
+// qqMessageURL returns the send endpoint for a chat id: the group messages
+// endpoint for "group:<id>", else the user's C2C endpoint.
+func qqMessageURL(chatID string) string {
+	if groupID, ok := strings.CutPrefix(chatID, qqGroupPrefix); ok {
+		return "https://api.sgroup.qq.com/v2/groups/" + url.PathEscape(groupID) + "/messages"
+	}
+	return "https://api.sgroup.qq.com/v2/users/" + url.PathEscape(chatID) + "/messages"
+}
+
 func (q *QQChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
 	token, err := q.getAccessToken(ctx)
 	if err != nil {
 		return err
 	}
+	msgID, _ := msg.Metadata()["message_id"].(string)
+	// Replies to one message need distinct msg_seq values.
+	seq := 0
+	content := msg.Content()
+
+	for _, path := range msg.Media() {
+		fileInfo, err := q.uploadMedia(ctx, token, msg.ChatId(), path)
+		if err == nil {
+			err = q.WaitSend(ctx, msg.ChatId())
+		}
+		if err == nil {
+			seq++
+			err = q.postJSON(ctx, token, qqMessageURL(msg.ChatId()), qqMediaPayload(fileInfo, msgID, seq), nil)
+		}
+		if err != nil {
+			slog.Warn("qq: media send failed; sending text only", "path", path, "err", err)
+			content = strings.TrimSpace(content + "\n[attachment: " + filepath.Base(path) + "]")
+		}
+	}
+	if content == "" {
+		return nil
+	}
+
+	seq++
 	body := map[string]any{
-		"content":  msg.Content(),
+		"content":  content,
 		"msg_type": 0,
+		"msg_seq":  seq,
 	}
-	if mid, ok := msg.Metadata()["message_id"].(string); ok {
-		body["msg_id"] = mid
+	if msgID != "" {
+		body["msg_id"] = msgID
 	}
+	if err := q.WaitSend(ctx, msg.ChatId()); err != nil {
+		return err
+	}
+	return q.postJSON(ctx, token, qqMessageURL(msg.ChatId()), body, nil)
+}
+
+// qqFileTypes maps media extensions to the file API's file_type:
+// 1 image, 2 video, 3 voice.
+var qqFileTypes = map[string]int{
+	".png": 1, ".jpg": 1, ".jpeg": 1, ".gif": 1, ".webp": 1, ".bmp": 1,
+	".mp4": 2, ".silk": 3,
+}
+
+// qqUploadBody returns the file API request body for path: a URL for
+// http(s) media, else the file's bytes as base64 file_data.
+func qqUploadBody(path string) (map[string]any, error) {
+	remote := strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+	name := path
+	if u, err := url.Parse(path); err == nil && remote {
+		name = u.Path
+	}
+	fileType, ok := qqFileTypes[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		return nil, fmt.Errorf("qq: unsupported media type %q", filepath.Ext(name))
+	}
+	body := map[string]any{"file_type": fileType, "srv_send_msg": false}
+	if remote {
+		body["url"] = path
+		return body, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	body["file_data"] = base64.StdEncoding.EncodeToString(data)
+	return body, nil
+}
+
+// qqMediaPayload assembles a msg_type 7 (rich media) message for an
+// uploaded file.
+func qqMediaPayload(fileInfo, msgID string, seq int) map[string]any {
+	body := map[string]any{
+		"content":  " ",
+		"msg_type": 7,
+		"media":    map[string]any{"file_info": fileInfo},
+		"msg_seq":  seq,
+	}
+	if msgID != "" {
+		body["msg_id"] = msgID
+	}
+	return body
+}
+
+// uploadMedia uploads path through the chat's file API and returns the
+// file_info to reference in a media message.
+func (q *QQChannel) uploadMedia(ctx context.Context, token, chatID, path string) (string, error) {
+	body, err := qqUploadBody(path)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		FileInfo string `json:"file_info"`
+	}
+	filesURL := strings.TrimSuffix(qqMessageURL(chatID), "/messages") + "/files"
+	if err := q.postJSON(ctx, token, filesURL, body, &result); err != nil {
+		return "", err
+	}
+	if result.FileInfo == "" {
+		return "", fmt.Errorf("qq: upload returned no file_info")
+	}
+	return result.FileInfo, nil
+}
+
+// postJSON posts body to endpoint and decodes the response into out, if set.
+func (q *QQChannel) postJSON(ctx context.Context, token, endpoint string, body, out any) error {
 	data, _ := json.Marshal(body)
-	url := fmt.Sprintf("https://api.sgroup.qq.com/v2/users/%s/messages", msg.ChatId())
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
 	if err != nil {
 		return err
 	}
@@ -267,6 +438,13 @@ func (q *QQChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("qq: HTTP %d: %s", resp.StatusCode, string(b))
+	}
+	if out != nil {
+		return json.Unmarshal(b, out)
+	}
 	return nil
 }
@@ -0,0 +1,97 @@
+package channels
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+)
+
+func TestQQHandleGroupMessage(t *testing.T) {
+	cfg := channel.DefaultQQConfig()
+	agentBus := bus.NewAgentBus(1)
+	q := NewQQChannel(&cfg, agentBus)
+	at := map[string]any{
+		"id":           "m1",
+		"group_openid": "G1",
+		"content":      " <@!12345> what's the weather?",
+		"author":       map[string]any{"member_openid": "U1"},
+	}
+
+	q.handleGroupMessage(at)
+	select {
+	case msg := <-agentBus.Subscribe():
+		if msg.SenderId() != "U1" || msg.ChatId() != "group:G1" || msg.Content() != "what's the weather?" {
+			t.Errorf("unexpected dispatch: sender=%s chat=%s content=%q", msg.SenderId(), msg.ChatId(), msg.Content())
+		}
+		if msg.Metadata()["message_id"] != "m1" || msg.Metadata()["group_id"] != "G1" {
+			t.Errorf("unexpected metadata: %v", msg.Metadata())
+		}
+	default:
+		t.Fatal("expected the group message to be dispatched")
+	}
+
+	q.handleGroupMessage(at)
+	cfg.GroupPolicy = "allowlist"
+	at["id"] = "m2"
+	q.handleGroupMessage(at)
+	select {
+	case msg := <-agentBus.Subscribe():
+		t.Errorf("expected duplicates and non-allowlisted groups ignored, got %q", msg.Content())
+	default:
+	}
+
+	cfg.GroupAllowFrom = []string{"G1"}
+	q.handleGroupMessage(at)
+	select {
+	case <-agentBus.Subscribe():
+	default:
+		t.Error("expected an allowlisted group to be answered")
+	}
+}
+
+func TestQQMessageURL(t *testing.T) {
+	if got := qqMessageURL("group:G1"); got != "https://api.sgroup.qq.com/v2/groups/G1/messages" {
+		t.Errorf("unexpected group URL %q", got)
+	}
+	if got := qqMessageURL("U1"); got != "https://api.sgroup.qq.com/v2/users/U1/messages" {
+		t.Errorf("unexpected C2C URL %q", got)
+	}
+}
+
+func TestQQUploadBody(t *testing.T) {
+	img := filepath.Join(t.TempDir(), "chart.PNG")
+	if err := os.WriteFile(img, []byte("png-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	body, err := qqUploadBody(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body["file_type"] != 1 || body["srv_send_msg"] != false || body["file_data"] != base64.StdEncoding.EncodeToString([]byte("png-bytes")) {
+		t.Errorf("unexpected local upload body: %v", body)
+	}
+
+	body, err = qqUploadBody("https://example.com/clip.mp4?sig=1")
+	if err != nil || body["file_type"] != 2 || body["url"] != "https://example.com/clip.mp4?sig=1" || body["file_data"] != nil {
+		t.Errorf("unexpected remote upload body: %v %v", body, err)
+	}
+
+	if _, err := qqUploadBody("/tmp/report.pdf"); err == nil {
+		t.Error("expected unsupported media to be rejected")
+	}
+}
+
+func TestQQMediaPayload(t *testing.T) {
+	p := qqMediaPayload("FILEINFO", "m1", 2)
+	media, _ := p["media"].(map[string]any)
+	if p["msg_type"] != 7 || media["file_info"] != "FILEINFO" || p["msg_id"] != "m1" || p["msg_seq"] != 2 {
+		t.Errorf("unexpected media payload: %v", p)
+	}
+	if _, ok := qqMediaPayload("FILEINFO", "", 1)["msg_id"]; ok {
+		t.Error("expected no msg_id for a proactive message")
+	}
+}
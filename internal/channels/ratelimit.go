@@ -0,0 +1,97 @@
+package channels
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sendLimiterMaxIdle bounds how many per-chat buckets are kept before idle
+// (fully refilled) ones are dropped.
+const sendLimiterMaxIdle = 1024
+
+// sendLimiter paces outbound posts with token buckets holding one token, so
+// bursts are spread out at rate posts per second. With perChat set each chat
+// ID gets its own bucket; otherwise all chats share one.
+type sendLimiter struct {
+	rate    float64
+	perChat bool
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newSendLimiter(rate float64, perChat bool) *sendLimiter {
+	return &sendLimiter{rate: rate, perChat: perChat, buckets: make(map[string]*tokenBucket)}
+}
+
+// wait blocks until a post to chatID is allowed or ctx is done.
+func (l *sendLimiter) wait(ctx context.Context, chatID string) error {
+	key := ""
+	if l.perChat {
+		key = chatID
+	}
+
+	l.mu.Lock()
+	tb, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= sendLimiterMaxIdle {
+			l.pruneLocked()
+		}
+		tb = &tokenBucket{tokens: 1, last: time.Now()}
+		l.buckets[key] = tb
+	}
+	delay := tb.reserve(l.rate, time.Now())
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		tb.tokens++ // give the reservation back
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// pruneLocked drops buckets that have fully refilled. l.mu must be held.
+func (l *sendLimiter) pruneLocked() {
+	now := time.Now()
+	for k, tb := range l.buckets {
+		if tb.refill(l.rate, now) >= 1 {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// tokenBucket is a single bucket of capacity one. tokens goes negative while
+// posts are queued behind it.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (tb *tokenBucket) refill(rate float64, now time.Time) float64 {
+	tb.tokens += now.Sub(tb.last).Seconds() * rate
+	if tb.tokens > 1 {
+		tb.tokens = 1
+	}
+	tb.last = now
+	return tb.tokens
+}
+
+// reserve takes a token and returns how long the caller must wait for it.
+func (tb *tokenBucket) reserve(rate float64, now time.Time) time.Duration {
+	tb.refill(rate, now)
+	tb.tokens--
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / rate * float64(time.Second))
+}
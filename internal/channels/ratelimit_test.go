@@ -0,0 +1,56 @@
+package channels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+)
+
+func TestWaitSend_PacesToRate(t *testing.T) {
+	b := NewBase("discord", bus.NewAgentBus(1), nil, 0).WithSendRate(20, false)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := b.WaitSend(context.Background(), "c1"); err != nil {
+			t.Fatalf("WaitSend: %v", err)
+		}
+	}
+	// The first post is immediate; the other four are spaced 50ms apart.
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond || elapsed > 400*time.Millisecond {
+		t.Errorf("expected 5 sends at 20/s to take ~200ms, took %v", elapsed)
+	}
+}
+
+func TestWaitSend_PerChatBuckets(t *testing.T) {
+	b := NewBase("telegram", bus.NewAgentBus(1), nil, 0).WithSendRate(1, true)
+
+	start := time.Now()
+	for _, chat := range []string{"a", "b", "c"} {
+		if err := b.WaitSend(context.Background(), chat); err != nil {
+			t.Fatalf("WaitSend: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected first sends to distinct chats to be immediate, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := b.WaitSend(ctx, "a"); err == nil {
+		t.Error("expected a second send to the same chat within 1s to wait past the deadline")
+	}
+}
+
+func TestWaitSend_UnlimitedByDefault(t *testing.T) {
+	b := NewBase("email", bus.NewAgentBus(1), nil, 0).WithSendRate(0, false)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		_ = b.WaitSend(context.Background(), "c1")
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected no pacing with rate 0, took %v", elapsed)
+	}
+}
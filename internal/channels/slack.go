@@ -2,9 +2,14 @@ package channels
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
+	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	slackgo "github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -14,7 +19,23 @@ import (
 	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
 )
 
-// SlackChannel implements Slack via Socket Mode.
+// slackMaxEventBody caps the size of an Events API request body.
+const slackMaxEventBody = 1 << 20
+
+// slackInbound is the subset of a message or app_mention event the channel
+// acts on, normalised from the typed slackevents structs.
+type slackInbound struct {
+	evType      string
+	user        string
+	channel     string
+	channelType string
+	text        string
+	subtype     string
+	ts          string
+	threadTS    string
+}
+
+// SlackChannel implements Slack via Socket Mode or the HTTP Events API.
 type SlackChannel struct {
 	Base
 	cfg       *channel.SlackConfig
@@ -25,7 +46,7 @@ type SlackChannel struct {
 
 func NewSlackChannel(cfg *channel.SlackConfig, b *bus.AgentBus) *SlackChannel {
 	return &SlackChannel{
-		Base: NewBase("slack", b, nil), // Slack uses its own allow logic
+		Base: NewBase("slack", b, nil, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, true), // Slack uses its own allow logic
 		cfg:  cfg,
 	}
 }
@@ -33,8 +54,8 @@ func NewSlackChannel(cfg *channel.SlackConfig, b *bus.AgentBus) *SlackChannel {
 func (s *SlackChannel) Name() string { return "slack" }
 
 func (s *SlackChannel) Start(ctx context.Context) error {
-	if s.cfg.BotToken == "" || s.cfg.AppToken == "" {
-		slog.Warn("slack: bot/app token not configured")
+	if s.cfg.BotToken == "" {
+		slog.Warn("slack: bot token not configured")
 		<-ctx.Done()
 		return ctx.Err()
 	}
@@ -48,6 +69,19 @@ func (s *SlackChannel) Start(ctx context.Context) error {
 		slog.Info("slack: connected", "bot_user_id", s.botUserID)
 	}
 
+	if s.cfg.Mode == "events" {
+		return s.startEvents(ctx)
+	}
+	return s.startSocket(ctx)
+}
+
+func (s *SlackChannel) startSocket(ctx context.Context) error {
+	if s.cfg.AppToken == "" {
+		slog.Warn("slack: app token not configured for socket mode")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
 	s.smClient = socketmode.New(s.webClient)
 
 	go s.smClient.RunContext(ctx) //nolint:errcheck
@@ -65,6 +99,78 @@ func (s *SlackChannel) Start(ctx context.Context) error {
 	}
 }
 
+// startEvents serves the Events API request URL at cfg.WebhookPath.
+func (s *SlackChannel) startEvents(ctx context.Context) error {
+	if s.cfg.SigningSecret == "" || s.cfg.ListenAddr == "" {
+		slog.Warn("slack: signing secret/listen address not configured for events mode")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.cfg.WebhookPath, s.handleEventsRequest)
+	srv := &http.Server{Addr: s.cfg.ListenAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("slack: listening for events", "addr", s.cfg.ListenAddr, "path", s.cfg.WebhookPath)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return ctx.Err()
+}
+
+// handleEventsRequest verifies the request signature, answers URL
+// verification challenges and dispatches callback events.
+func (s *SlackChannel) handleEventsRequest(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxEventBody))
+	if err != nil {
+		http.Error(rw, "read error", http.StatusBadRequest)
+		return
+	}
+
+	sv, err := slackgo.NewSecretsVerifier(r.Header, s.cfg.SigningSecret)
+	if err != nil {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if _, err := sv.Write(body); err != nil || sv.Ensure() != nil {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	evt, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		http.Error(rw, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	switch evt.Type {
+	case slackevents.URLVerification:
+		var challenge slackevents.ChallengeResponse
+		if err := json.Unmarshal(body, &challenge); err != nil {
+			http.Error(rw, "invalid challenge", http.StatusBadRequest)
+			return
+		}
+		rw.Header().Set("Content-Type", "text/plain")
+		_, _ = rw.Write([]byte(challenge.Challenge))
+	case slackevents.CallbackEvent:
+		rw.WriteHeader(http.StatusOK)
+		s.handleInnerEvent(evt.InnerEvent)
+	default:
+		rw.WriteHeader(http.StatusOK)
+	}
+}
+
 func (s *SlackChannel) handleEvent(_ context.Context, evt socketmode.Event) {
 	switch evt.Type {
 	case socketmode.EventTypeEventsAPI:
@@ -73,63 +179,75 @@ func (s *SlackChannel) handleEvent(_ context.Context, evt socketmode.Event) {
 		if !ok {
 			return
 		}
-		if cb.InnerEvent.Type != "message" && cb.InnerEvent.Type != "app_mention" {
-			return
-		}
-		// Inner event data is map[string]interface{} — parse manually.
 		s.handleInnerEvent(cb.InnerEvent)
 	}
 }
 
+// handleInnerEvent normalises message and app_mention events; other event
+// types are ignored.
 func (s *SlackChannel) handleInnerEvent(ev slackevents.EventsAPIInnerEvent) {
-	data, ok := ev.Data.(map[string]interface{})
-	if !ok {
-		return
+	switch e := ev.Data.(type) {
+	case *slackevents.MessageEvent:
+		s.handleInbound(slackInbound{
+			evType:      "message",
+			user:        e.User,
+			channel:     e.Channel,
+			channelType: e.ChannelType,
+			text:        e.Text,
+			subtype:     e.SubType,
+			ts:          e.TimeStamp,
+			threadTS:    e.ThreadTimeStamp,
+		})
+	case *slackevents.AppMentionEvent:
+		s.handleInbound(slackInbound{
+			evType:   "app_mention",
+			user:     e.User,
+			channel:  e.Channel,
+			text:     e.Text,
+			ts:       e.TimeStamp,
+			threadTS: e.ThreadTimeStamp,
+		})
 	}
-	userID, _ := data["user"].(string)
-	channel, _ := data["channel"].(string)
-	text, _ := data["text"].(string)
-	subtype, _ := data["subtype"].(string)
-	channelType, _ := data["channel_type"].(string)
-	ts, _ := data["ts"].(string)
-	threadTS, _ := data["thread_ts"].(string)
+}
 
-	if subtype != "" || userID == "" || channel == "" {
+func (s *SlackChannel) handleInbound(in slackInbound) {
+	if in.subtype != "" || in.user == "" || in.channel == "" {
 		return
 	}
-	if userID == s.botUserID {
+	if in.user == s.botUserID {
 		return
 	}
 	// Avoid double-processing mention + message events.
-	if ev.Type == "message" && s.botUserID != "" && strings.Contains(text, "<@"+s.botUserID+">") {
+	if in.evType == "message" && s.botUserID != "" && strings.Contains(in.text, "<@"+s.botUserID+">") {
 		return
 	}
 
-	if !s.isAllowedSlack(userID, channel, channelType) {
+	if !s.isAllowedSlack(in.user, in.channel, in.channelType) {
 		return
 	}
-	if channelType != "im" && !s.shouldRespond(ev.Type, text, channel) {
+	if in.channelType != "im" && !s.shouldRespond(in.evType, in.text, in.channel) {
 		return
 	}
 
-	text = s.stripMention(text)
+	text := s.stripMention(in.text)
 
+	threadTS := in.threadTS
 	if s.cfg.ReplyInThread && threadTS == "" {
-		threadTS = ts
+		threadTS = in.ts
 	}
 
 	// Best-effort reaction.
-	if s.webClient != nil && ts != "" {
+	if s.webClient != nil && in.ts != "" && s.cfg.ReactEmoji != "" {
 		_ = s.webClient.AddReaction(s.cfg.ReactEmoji, slackgo.ItemRef{
-			Channel:   channel,
-			Timestamp: ts,
+			Channel:   in.channel,
+			Timestamp: in.ts,
 		})
 	}
 
-	s.HandleMessage(userID, channel, text, nil, map[string]any{
+	s.HandleMessage(in.user, in.channel, text, nil, map[string]any{
 		"slack": map[string]any{
 			"thread_ts":    threadTS,
-			"channel_type": channelType,
+			"channel_type": in.channelType,
 		},
 	})
 }
@@ -205,6 +323,9 @@ func (s *SlackChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
 		options = append(options, slackgo.MsgOptionTS(threadTS))
 	}
 
+	if err := s.WaitSend(ctx, msg.ChatId()); err != nil {
+		return err
+	}
 	_, _, err := s.webClient.PostMessageContext(ctx, msg.ChatId(), options...)
 	return err
 }
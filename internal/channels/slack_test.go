@@ -0,0 +1,148 @@
+package channels
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+)
+
+func signedSlackRequest(t *testing.T, secret, body string) *http.Request {
+	t.Helper()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+	req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestSlackEvents_URLVerificationAndSignature(t *testing.T) {
+	cfg := channel.DefaultSlackConfig()
+	cfg.SigningSecret = "s3cret"
+	s := NewSlackChannel(&cfg, bus.NewAgentBus(1))
+
+	body := `{"type":"url_verification","token":"x","challenge":"abc123"}`
+	rec := httptest.NewRecorder()
+	s.handleEventsRequest(rec, signedSlackRequest(t, "s3cret", body))
+	if rec.Code != http.StatusOK || rec.Body.String() != "abc123" {
+		t.Fatalf("expected challenge echo, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleEventsRequest(rec, signedSlackRequest(t, "wrong", body))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for bad signature, got %d", rec.Code)
+	}
+}
+
+func TestSlackEvents_RoutesAppMention(t *testing.T) {
+	agentBus := bus.NewAgentBus(1)
+	cfg := channel.DefaultSlackConfig()
+	cfg.SigningSecret = "s3cret"
+	s := NewSlackChannel(&cfg, agentBus)
+	s.botUserID = "UBOT"
+
+	body := `{"type":"event_callback","event":{"type":"app_mention","user":"U1","channel":"C1","text":"<@UBOT> hello","ts":"1.000"}}`
+	rec := httptest.NewRecorder()
+	s.handleEventsRequest(rec, signedSlackRequest(t, "s3cret", body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	select {
+	case msg := <-agentBus.Subscribe():
+		if msg.SenderId() != "U1" || msg.ChatId() != "C1" || msg.Content() != "hello" {
+			t.Errorf("unexpected dispatch: sender=%s chat=%s content=%q", msg.SenderId(), msg.ChatId(), msg.Content())
+		}
+		meta, _ := msg.Metadata()["slack"].(map[string]any)
+		if meta["thread_ts"] != "1.000" {
+			t.Errorf("expected reply thread to default to message ts, got %v", meta)
+		}
+	default:
+		t.Fatal("expected the mention to be dispatched")
+	}
+}
+
+func TestSlackHandleInnerEvent_GroupMentionPolicy(t *testing.T) {
+	agentBus := bus.NewAgentBus(1)
+	cfg := channel.DefaultSlackConfig()
+	s := NewSlackChannel(&cfg, agentBus)
+	s.botUserID = "UBOT"
+
+	// Plain channel messages are ignored under the default "mention" policy,
+	// and message events carrying a mention are left to app_mention.
+	for _, text := range []string{"just chatting", "<@UBOT> hi"} {
+		s.handleInnerEvent(slackevents.EventsAPIInnerEvent{
+			Type: "message",
+			Data: &slackevents.MessageEvent{User: "U1", Channel: "C1", ChannelType: "channel", Text: text, TimeStamp: "1.0"},
+		})
+	}
+	select {
+	case msg := <-agentBus.Subscribe():
+		t.Fatalf("expected no dispatch, got %q", msg.Content())
+	default:
+	}
+
+	cfg.GroupPolicy = "open"
+	s.handleInnerEvent(slackevents.EventsAPIInnerEvent{
+		Type: "message",
+		Data: &slackevents.MessageEvent{User: "U1", Channel: "C1", ChannelType: "channel", Text: "just chatting", TimeStamp: "1.0"},
+	})
+	select {
+	case <-agentBus.Subscribe():
+	default:
+		t.Fatal("expected dispatch under the open group policy")
+	}
+}
+
+func TestSlackHandleInnerEvent_DMPolicy(t *testing.T) {
+	agentBus := bus.NewAgentBus(1)
+	cfg := channel.DefaultSlackConfig()
+	cfg.DM.Policy = "allowlist"
+	cfg.DM.AllowFrom = []string{"U1"}
+	s := NewSlackChannel(&cfg, agentBus)
+
+	dm := func(user string) {
+		s.handleInnerEvent(slackevents.EventsAPIInnerEvent{
+			Type: "message",
+			Data: &slackevents.MessageEvent{User: user, Channel: "D1", ChannelType: "im", Text: "hi", TimeStamp: "1.0"},
+		})
+	}
+
+	dm("U2")
+	select {
+	case msg := <-agentBus.Subscribe():
+		t.Fatalf("expected DM from non-allowlisted user to be dropped, got sender %s", msg.SenderId())
+	default:
+	}
+
+	dm("U1")
+	select {
+	case msg := <-agentBus.Subscribe():
+		if msg.SenderId() != "U1" || msg.ChatId() != "D1" {
+			t.Errorf("unexpected dispatch: sender=%s chat=%s", msg.SenderId(), msg.ChatId())
+		}
+	default:
+		t.Fatal("expected DM from allowlisted user to be dispatched")
+	}
+
+	cfg.DM.Enabled = false
+	dm("U1")
+	select {
+	case <-agentBus.Subscribe():
+		t.Fatal("expected DMs to be dropped when disabled")
+	default:
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
@@ -23,13 +25,38 @@ type TelegramChannel struct {
 	Base
 	cfg *channel.TelegramConfig
 	bot *tgbotapi.BotAPI
+
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+
+	// progress maps a turn (chat + inbound message ID) to the message showing
+	// its progress, when cfg.EditProgress is set.
+	progressMu sync.Mutex
+	progress   map[string]int
+}
+
+// telegramUpdateSource is the subset of *tgbotapi.BotAPI used for polling.
+type telegramUpdateSource interface {
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	StopReceivingUpdates()
 }
 
 // NewTelegramChannel creates a TelegramChannel.
 func NewTelegramChannel(cfg *channel.TelegramConfig, b *bus.AgentBus) *TelegramChannel {
+	delay := time.Duration(cfg.ReconnectDelay) * time.Second
+	if delay <= 0 {
+		delay = 5 * time.Second
+	}
+	maxDelay := time.Duration(cfg.MaxReconnectDelay) * time.Second
+	if maxDelay < delay {
+		maxDelay = max(delay, 60*time.Second)
+	}
 	return &TelegramChannel{
-		Base: NewBase("telegram", b, cfg.AllowFrom),
-		cfg:  cfg,
+		Base:              NewBase("telegram", b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, true),
+		cfg:               cfg,
+		reconnectDelay:    delay,
+		maxReconnectDelay: maxDelay,
+		progress:          map[string]int{},
 	}
 }
 
@@ -46,34 +73,66 @@ func (t *TelegramChannel) Start(ctx context.Context) error {
 	t.bot = bot
 	slog.Info("telegram: connected", "username", bot.Self.UserName)
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 30
-	updates := bot.GetUpdatesChan(u)
+	return t.poll(ctx, bot)
+}
 
+// poll consumes updates from src until ctx is cancelled. When the updates
+// channel closes it is re-opened with exponential backoff, resuming from the
+// last seen update offset so nothing is processed twice.
+func (t *TelegramChannel) poll(ctx context.Context, src telegramUpdateSource) error {
+	offset := 0
+	delay := t.reconnectDelay
 	for {
-		select {
-		case update, ok := <-updates:
-			if !ok {
-				return nil
+		u := tgbotapi.NewUpdate(offset)
+		u.Timeout = 30
+		updates := src.GetUpdatesChan(u)
+
+		received := false
+	consume:
+		for {
+			select {
+			case update, ok := <-updates:
+				if !ok {
+					break consume
+				}
+				received = true
+				offset = update.UpdateID + 1
+				go t.handleUpdate(ctx, update)
+			case <-ctx.Done():
+				src.StopReceivingUpdates()
+				return ctx.Err()
 			}
-			go t.handleUpdate(ctx, update)
+		}
+
+		if received {
+			delay = t.reconnectDelay
+		}
+		slog.Warn("telegram: updates stream closed, reconnecting", "in", delay, "offset", offset)
+		select {
 		case <-ctx.Done():
-			bot.StopReceivingUpdates()
 			return ctx.Err()
+		case <-time.After(delay):
 		}
+		delay = min(delay*2, t.maxReconnectDelay)
 	}
 }
 
 func (t *TelegramChannel) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		t.handleCallback(update.CallbackQuery)
+		return
+	}
 	msg := update.Message
+	edited := false
+	if msg == nil && update.EditedMessage != nil && t.cfg.HandleEdits {
+		msg = update.EditedMessage
+		edited = true
+	}
 	if msg == nil || msg.From == nil {
 		return
 	}
 
-	senderID := fmt.Sprintf("%d", msg.From.ID)
-	if msg.From.UserName != "" {
-		senderID = senderID + "|" + msg.From.UserName
-	}
+	senderID := telegramSenderID(msg.From)
 	chatID := fmt.Sprintf("%d", msg.Chat.ID)
 
 	content := msg.Text
@@ -99,6 +158,9 @@ func (t *TelegramChannel) handleUpdate(ctx context.Context, update tgbotapi.Upda
 	if content == "" {
 		content = "[empty message]"
 	}
+	if edited {
+		content = editedMessagePrefix + content
+	}
 
 	// Start typing indicator.
 	typingCtx, cancelTyping := context.WithCancel(ctx)
@@ -112,10 +174,45 @@ func (t *TelegramChannel) handleUpdate(ctx context.Context, update tgbotapi.Upda
 		"first_name": msg.From.FirstName,
 		"is_group":   msg.Chat.Type != "private",
 	}
+	if edited {
+		metadata["edited"] = true
+	}
 
 	t.HandleMessage(senderID, chatID, content, mediaPaths, metadata)
 }
 
+// handleCallback turns an inline button press into an inbound message whose
+// content is the button's callback data.
+func (t *TelegramChannel) handleCallback(cq *tgbotapi.CallbackQuery) {
+	if t.bot != nil {
+		// Stop the client's loading spinner on the button.
+		if _, err := t.bot.Request(tgbotapi.NewCallback(cq.ID, "")); err != nil {
+			slog.Debug("telegram: answer callback failed", "err", err)
+		}
+	}
+	if cq.From == nil || cq.Message == nil || cq.Message.Chat == nil || cq.Data == "" {
+		return
+	}
+	metadata := map[string]any{
+		"message_id":        cq.Message.MessageID,
+		"user_id":           cq.From.ID,
+		"username":          cq.From.UserName,
+		"first_name":        cq.From.FirstName,
+		"is_group":          cq.Message.Chat.Type != "private",
+		"callback_query_id": cq.ID,
+	}
+	t.HandleMessage(telegramSenderID(cq.From), fmt.Sprintf("%d", cq.Message.Chat.ID), cq.Data, nil, metadata)
+}
+
+// telegramSenderID formats a user as "id|username" (or just "id").
+func telegramSenderID(u *tgbotapi.User) string {
+	id := fmt.Sprintf("%d", u.ID)
+	if u.UserName != "" {
+		id += "|" + u.UserName
+	}
+	return id
+}
+
 func (t *TelegramChannel) downloadFile(fileID, ext string) (string, error) {
 	if t.bot == nil {
 		return "", fmt.Errorf("bot not running")
@@ -165,7 +262,7 @@ func (t *TelegramChannel) sendTypingLoop(ctx context.Context, chatID int64) {
 	}
 }
 
-func (t *TelegramChannel) Send(_ context.Context, msg bus.ChannelMessage) error {
+func (t *TelegramChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
 	if t.bot == nil {
 		return fmt.Errorf("telegram: bot not running")
 	}
@@ -190,6 +287,9 @@ func (t *TelegramChannel) Send(_ context.Context, msg bus.ChannelMessage) error
 			sendCfg = tgbotapi.NewDocument(chatID, tgbotapi.FileReader{Name: filepath.Base(mediaPath), Reader: f})
 		}
 		_ = f.Close()
+		if err := t.WaitSend(ctx, msg.ChatId()); err != nil {
+			return err
+		}
 		_, _ = t.bot.Send(sendCfg)
 	}
 
@@ -197,6 +297,19 @@ func (t *TelegramChannel) Send(_ context.Context, msg bus.ChannelMessage) error
 		return nil
 	}
 
+	action, turn, progressID := t.planProgress(msg)
+	switch action {
+	case progressEdit:
+		if t.editProgress(chatID, progressID, msg.Content()) == nil {
+			return nil
+		}
+		action = progressSend // e.g. the message was deleted: post a new one
+	case progressClear:
+		if _, err := t.bot.Request(tgbotapi.NewDeleteMessage(chatID, progressID)); err != nil {
+			slog.Debug("telegram: delete progress message failed", "err", err)
+		}
+	}
+
 	// Get optional reply-to message ID.
 	var replyMsgID int
 	if t.cfg.ReplyToMessage {
@@ -210,25 +323,138 @@ func (t *TelegramChannel) Send(_ context.Context, msg bus.ChannelMessage) error
 		}
 	}
 
-	for _, chunk := range splitMessage(msg.Content(), 4000) {
+	keyboard, hasKeyboard := telegramKeyboard(msg.Buttons())
+	chunks := splitMessage(msg.Content(), 4000)
+	for i, chunk := range chunks {
 		html := markdownToTelegramHTML(chunk)
 		m := tgbotapi.NewMessage(chatID, html)
 		m.ParseMode = "HTML"
 		if replyMsgID != 0 {
 			m.ReplyToMessageID = replyMsgID
 		}
-		if _, err := t.bot.Send(m); err != nil {
+		// Buttons go under the last chunk.
+		if hasKeyboard && i == len(chunks)-1 {
+			m.ReplyMarkup = keyboard
+		}
+		if err := t.WaitSend(ctx, msg.ChatId()); err != nil {
+			return err
+		}
+		sent, err := t.bot.Send(m)
+		if err != nil {
 			// Fallback to plain text.
 			m2 := tgbotapi.NewMessage(chatID, chunk)
 			if replyMsgID != 0 {
 				m2.ReplyToMessageID = replyMsgID
 			}
-			_, _ = t.bot.Send(m2)
+			m2.ReplyMarkup = m.ReplyMarkup
+			sent, _ = t.bot.Send(m2)
+		}
+		if action == progressSend && sent.MessageID != 0 {
+			t.setProgress(turn, sent.MessageID)
 		}
 	}
 	return nil
 }
 
+// progressAction is how Send treats a message with respect to the turn's
+// progress message.
+type progressAction int
+
+const (
+	progressNone  progressAction = iota // send normally
+	progressSend                        // send, and remember it as the progress message
+	progressEdit                        // edit the existing progress message instead
+	progressClear                       // final reply: delete the progress message, then send
+)
+
+// planProgress decides how to deliver msg when channels.telegram.editProgress
+// is on. A turn is identified by the chat and the message_id of the inbound
+// message, which both progress updates and the final reply carry in their
+// metadata.
+func (t *TelegramChannel) planProgress(msg bus.ChannelMessage) (action progressAction, turn string, messageID int) {
+	mid, ok := msg.Metadata()["message_id"]
+	if !t.cfg.EditProgress || !ok {
+		return progressNone, "", 0
+	}
+	turn = fmt.Sprintf("%s:%v", msg.ChatId(), mid)
+
+	t.progressMu.Lock()
+	defer t.progressMu.Unlock()
+	messageID, exists := t.progress[turn]
+	if isProgress, _ := msg.Metadata()["_progress"].(bool); isProgress {
+		if exists {
+			return progressEdit, turn, messageID
+		}
+		return progressSend, turn, 0
+	}
+	if exists {
+		delete(t.progress, turn)
+		return progressClear, turn, messageID
+	}
+	return progressNone, turn, 0
+}
+
+func (t *TelegramChannel) setProgress(turn string, messageID int) {
+	t.progressMu.Lock()
+	defer t.progressMu.Unlock()
+	t.progress[turn] = messageID
+}
+
+// editProgress replaces the text of the progress message.
+func (t *TelegramChannel) editProgress(chatID int64, messageID int, content string) error {
+	content = splitMessage(content, 4000)[0]
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, markdownToTelegramHTML(content))
+	edit.ParseMode = "HTML"
+	if _, err := t.bot.Send(edit); err != nil {
+		if strings.Contains(err.Error(), "message is not modified") {
+			return nil
+		}
+		plain := tgbotapi.NewEditMessageText(chatID, messageID, content)
+		if _, err := t.bot.Send(plain); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+			return err
+		}
+	}
+	return nil
+}
+
+// telegramCallbackDataMax is Telegram's limit on callback_data, in bytes.
+const telegramCallbackDataMax = 64
+
+// telegramKeyboard builds an inline keyboard from button rows, reporting
+// false when there are none. Callback data over Telegram's 64-byte limit is
+// cut on a character boundary.
+func telegramKeyboard(rows [][]bus.Button) (tgbotapi.InlineKeyboardMarkup, bool) {
+	var kbRows [][]tgbotapi.InlineKeyboardButton
+	for _, row := range rows {
+		var kbRow []tgbotapi.InlineKeyboardButton
+		for _, b := range row {
+			if b.URL != "" {
+				kbRow = append(kbRow, tgbotapi.NewInlineKeyboardButtonURL(b.Text, b.URL))
+				continue
+			}
+			data := b.Data
+			if data == "" {
+				data = b.Text
+			}
+			if len(data) > telegramCallbackDataMax {
+				cut := telegramCallbackDataMax
+				for cut > 0 && !utf8.RuneStart(data[cut]) {
+					cut--
+				}
+				data = data[:cut]
+			}
+			kbRow = append(kbRow, tgbotapi.NewInlineKeyboardButtonData(b.Text, data))
+		}
+		if len(kbRow) > 0 {
+			kbRows = append(kbRows, tgbotapi.NewInlineKeyboardRow(kbRow...))
+		}
+	}
+	if len(kbRows) == 0 {
+		return tgbotapi.InlineKeyboardMarkup{}, false
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(kbRows...), true
+}
+
 func parseChatID(s string) (int64, error) {
 	var id int64
 	if _, err := fmt.Sscanf(s, "%d", &id); err != nil {
@@ -249,7 +475,7 @@ var (
 	reTGLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
 	reTGBold1      = regexp.MustCompile(`\*\*(.+?)\*\*`)
 	reTGBold2      = regexp.MustCompile(`__(.+?)__`)
-	reTGItalic     = regexp.MustCompile(`(?:^|[^a-zA-Z0-9])_([^_]+)_(?:[^a-zA-Z0-9]|$)`)
+	reTGItalic     = regexp.MustCompile(`(^|[^a-zA-Z0-9])_([^_]+)_([^a-zA-Z0-9]|$)`)
 	reTGStrike     = regexp.MustCompile(`~~(.+?)~~`)
 	reTGBullet     = regexp.MustCompile(`(?m)^[-*]\s+`)
 )
@@ -290,8 +516,15 @@ func markdownToTelegramHTML(text string) string {
 	// 7. Bold.
 	text = reTGBold1.ReplaceAllString(text, "<b>$1</b>")
 	text = reTGBold2.ReplaceAllString(text, "<b>$1</b>")
-	// 8. Italic.
-	text = reTGItalic.ReplaceAllString(text, "<i>$1</i>")
+	// 8. Italic. The boundary characters are re-emitted; repeating picks up
+	// spans like "_a_ _b_" whose shared boundary the previous pass consumed.
+	for {
+		next := reTGItalic.ReplaceAllString(text, "${1}<i>${2}</i>${3}")
+		if next == text {
+			break
+		}
+		text = next
+	}
 	// 9. Strikethrough.
 	text = reTGStrike.ReplaceAllString(text, "<s>$1</s>")
 	// 10. Bullet lists.
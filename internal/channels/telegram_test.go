@@ -0,0 +1,218 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+)
+
+// fakeUpdateSource hands out one pre-filled channel per GetUpdatesChan call
+// and records the offsets it was asked for.
+type fakeUpdateSource struct {
+	mu      sync.Mutex
+	streams []chan tgbotapi.Update
+	offsets []int
+	calls   chan struct{}
+}
+
+func (f *fakeUpdateSource) GetUpdatesChan(cfg tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.offsets = append(f.offsets, cfg.Offset)
+	i := len(f.offsets) - 1
+	f.calls <- struct{}{}
+	if i < len(f.streams) {
+		return f.streams[i]
+	}
+	return make(chan tgbotapi.Update) // block until cancelled
+}
+
+func (f *fakeUpdateSource) StopReceivingUpdates() {}
+
+func TestTelegramPoll_ReconnectsOnClosedUpdates(t *testing.T) {
+	first := make(chan tgbotapi.Update, 2)
+	first <- tgbotapi.Update{UpdateID: 41}
+	first <- tgbotapi.Update{UpdateID: 42}
+	close(first)
+
+	src := &fakeUpdateSource{streams: []chan tgbotapi.Update{first}, calls: make(chan struct{}, 4)}
+
+	cfg := channel.DefaultTelegramConfig()
+	tg := NewTelegramChannel(&cfg, bus.NewAgentBus(1))
+	tg.reconnectDelay = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tg.poll(ctx, src) }()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-src.calls:
+		case err := <-done:
+			t.Fatalf("poll stopped instead of reconnecting: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for reconnect")
+		}
+	}
+	cancel()
+	<-done
+
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if src.offsets[0] != 0 || src.offsets[1] != 43 {
+		t.Errorf("expected offsets [0 43], got %v", src.offsets)
+	}
+}
+
+func TestTelegramHandleUpdate_EditedMessage(t *testing.T) {
+	edit := tgbotapi.Update{
+		UpdateID: 7,
+		EditedMessage: &tgbotapi.Message{
+			MessageID: 99,
+			From:      &tgbotapi.User{ID: 1, UserName: "alice"},
+			Chat:      &tgbotapi.Chat{ID: 555, Type: "private"},
+			Text:      "meet at 3pm, not 2pm",
+		},
+	}
+
+	cfg := channel.DefaultTelegramConfig()
+	agentBus := bus.NewAgentBus(1)
+	tg := NewTelegramChannel(&cfg, agentBus)
+	tg.handleUpdate(context.Background(), edit)
+	select {
+	case msg := <-agentBus.Subscribe():
+		t.Errorf("expected edit ignored by default, got %q", msg.Content())
+	default:
+	}
+
+	cfg.HandleEdits = true
+	tg.handleUpdate(context.Background(), edit)
+	select {
+	case msg := <-agentBus.Subscribe():
+		if msg.ChatId() != "555" || msg.Content() != editedMessagePrefix+"meet at 3pm, not 2pm" {
+			t.Errorf("unexpected dispatch: chat=%s content=%q", msg.ChatId(), msg.Content())
+		}
+		if msg.Metadata()["edited"] != true || msg.Metadata()["message_id"] != 99 {
+			t.Errorf("unexpected metadata: %v", msg.Metadata())
+		}
+	default:
+		t.Fatal("expected edited message to be dispatched when handleEdits is on")
+	}
+}
+
+func TestTelegramKeyboard(t *testing.T) {
+	if _, ok := telegramKeyboard(nil); ok {
+		t.Error("expected no keyboard without buttons")
+	}
+
+	long := strings.Repeat("é", 40) // 80 bytes
+	kb, ok := telegramKeyboard([][]bus.Button{
+		{{Text: "Yes", Data: "yes"}, {Text: "No"}},
+		{{Text: "Docs", URL: "https://example.com"}, {Text: "Long", Data: long}},
+	})
+	if !ok || len(kb.InlineKeyboard) != 2 || len(kb.InlineKeyboard[0]) != 2 {
+		t.Fatalf("unexpected keyboard: %+v", kb)
+	}
+	row0, row1 := kb.InlineKeyboard[0], kb.InlineKeyboard[1]
+	if *row0[0].CallbackData != "yes" || *row0[1].CallbackData != "No" {
+		t.Errorf("expected data buttons defaulting to the label, got %q %q", *row0[0].CallbackData, *row0[1].CallbackData)
+	}
+	if row1[0].URL == nil || *row1[0].URL != "https://example.com" || row1[0].CallbackData != nil {
+		t.Errorf("expected a URL button, got %+v", row1[0])
+	}
+	if data := *row1[1].CallbackData; len(data) != 64 || !utf8.ValidString(data) {
+		t.Errorf("expected callback data cut to 64 bytes on a rune boundary, got %d bytes", len(data))
+	}
+}
+
+func TestTelegramHandleUpdate_CallbackQuery(t *testing.T) {
+	press := tgbotapi.Update{
+		UpdateID: 8,
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      "cb1",
+			From:    &tgbotapi.User{ID: 1, UserName: "alice"},
+			Message: &tgbotapi.Message{MessageID: 100, Chat: &tgbotapi.Chat{ID: 555, Type: "private"}},
+			Data:    "yes",
+		},
+	}
+
+	cfg := channel.DefaultTelegramConfig()
+	agentBus := bus.NewAgentBus(1)
+	tg := NewTelegramChannel(&cfg, agentBus)
+	tg.handleUpdate(context.Background(), press)
+
+	select {
+	case msg := <-agentBus.Subscribe():
+		if msg.SenderId() != "1|alice" || msg.ChatId() != "555" || msg.Content() != "yes" {
+			t.Errorf("unexpected dispatch: sender=%s chat=%s content=%q", msg.SenderId(), msg.ChatId(), msg.Content())
+		}
+		if msg.Metadata()["callback_query_id"] != "cb1" || msg.Metadata()["message_id"] != 100 {
+			t.Errorf("unexpected metadata: %v", msg.Metadata())
+		}
+	default:
+		t.Fatal("expected button press to be dispatched")
+	}
+}
+
+func TestTelegramPlanProgress(t *testing.T) {
+	cfg := channel.DefaultTelegramConfig()
+	tg := NewTelegramChannel(&cfg, bus.NewAgentBus(1))
+	progress := func(text string) bus.ChannelMessage {
+		return bus.NewChannelMessageBuilder("telegram", "555", text).
+			Metadata(map[string]any{"_progress": true, "message_id": 100}).Build()
+	}
+	final := bus.NewChannelMessageBuilder("telegram", "555", "done").
+		Metadata(map[string]any{"message_id": 100}).Build()
+
+	if action, _, _ := tg.planProgress(progress("thinking")); action != progressNone {
+		t.Fatalf("expected plain sends when editProgress is off, got %v", action)
+	}
+
+	cfg.EditProgress = true
+	action, turn, _ := tg.planProgress(progress("thinking"))
+	if action != progressSend || turn != "555:100" {
+		t.Fatalf("expected first progress to be sent, got %v %q", action, turn)
+	}
+	tg.setProgress(turn, 7)
+	if action, _, id := tg.planProgress(progress("web_search(\"x\")")); action != progressEdit || id != 7 {
+		t.Errorf("expected later progress to edit message 7, got %v %d", action, id)
+	}
+	if action, _, id := tg.planProgress(final); action != progressClear || id != 7 {
+		t.Errorf("expected the reply to clear message 7, got %v %d", action, id)
+	}
+	if action, _, _ := tg.planProgress(final); action != progressNone {
+		t.Errorf("expected the turn forgotten after its reply, got %v", action)
+	}
+
+	// A message tool reply carries the inbound message_id as a string.
+	tg.setProgress("555:100", 8)
+	viaTool := bus.NewChannelMessageBuilder("telegram", "555", "hi").
+		Metadata(map[string]any{"message_id": "100"}).Build()
+	if action, _, id := tg.planProgress(viaTool); action != progressClear || id != 8 {
+		t.Errorf("expected a message tool reply to clear the progress message, got %v %d", action, id)
+	}
+}
+
+func TestMarkdownToTelegramHTML_Italic(t *testing.T) {
+	cases := map[string]string{
+		"_start_ of line":      "<i>start</i> of line",
+		"a _word_ b":           "a <i>word</i> b",
+		"end with _this_":      "end with <i>this</i>",
+		"(_aside_), then":      "(<i>aside</i>), then",
+		"quick _a_ _b_ pair":   "quick <i>a</i> <i>b</i> pair",
+		"snake_case_name here": "snake_case_name here",
+	}
+	for in, want := range cases {
+		if got := markdownToTelegramHTML(in); got != want {
+			t.Errorf("markdownToTelegramHTML(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+)
+
+// webhookMaxInboundBody caps the size of an inbound request body.
+const webhookMaxInboundBody = 1 << 20
+
+// webhookOutbound is the JSON body POSTed to the configured URL for each reply.
+type webhookOutbound struct {
+	Content  string         `json:"content"`
+	ChatID   string         `json:"chatId"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// webhookInbound is the JSON body accepted on the inbound endpoint. Sender
+// and chat may be given as "sender"/"chat_id" or "senderId"/"chatId"; the
+// camelCase names win when both are present.
+type webhookInbound struct {
+	SenderID  string         `json:"senderId"`
+	ChatID    string         `json:"chatId"`
+	Sender    string         `json:"sender"`
+	ChatIDAlt string         `json:"chat_id"`
+	Content   string         `json:"content"`
+	Metadata  map[string]any `json:"metadata"`
+}
+
+// WebhookChannel POSTs replies to a configured URL and optionally accepts
+// inbound messages on a local HTTP endpoint.
+type WebhookChannel struct {
+	Base
+	cfg        *channel.WebhookConfig
+	httpClient *http.Client
+}
+
+func NewWebhookChannel(cfg *channel.WebhookConfig, b *bus.AgentBus) *WebhookChannel {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	return &WebhookChannel{
+		Base:       NewBase(bus.ChannelWebhook, b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, false),
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *WebhookChannel) Name() string { return "webhook" }
+
+// Start serves the inbound endpoint when listenAddr is configured; otherwise
+// it just blocks until ctx is cancelled. The endpoint is never served without
+// inboundToken: senderId is whatever the caller claims, so allowFrom alone
+// cannot keep strangers out.
+func (w *WebhookChannel) Start(ctx context.Context) error {
+	if w.cfg.ListenAddr == "" {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if w.cfg.InboundToken == "" {
+		return fmt.Errorf("webhook: listenAddr is set but inboundToken is empty; refusing to accept unauthenticated messages")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(w.path(), w.handleInbound)
+	srv := &http.Server{Addr: w.cfg.ListenAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("webhook: listening", "addr", w.cfg.ListenAddr, "path", w.path())
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return ctx.Err()
+}
+
+func (w *WebhookChannel) path() string {
+	if w.cfg.Path == "" {
+		return "/webhook"
+	}
+	return w.cfg.Path
+}
+
+// handleInbound accepts a POSTed webhookInbound and dispatches it to the agent.
+func (w *WebhookChannel) handleInbound(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	want := "Bearer " + w.cfg.InboundToken
+	if w.cfg.InboundToken == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var in webhookInbound
+	if err := json.NewDecoder(io.LimitReader(r.Body, webhookMaxInboundBody)).Decode(&in); err != nil {
+		http.Error(rw, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if in.SenderID == "" {
+		in.SenderID = in.Sender
+	}
+	if in.ChatID == "" {
+		in.ChatID = in.ChatIDAlt
+	}
+	if in.Content == "" || in.ChatID == "" {
+		http.Error(rw, "chat_id and content are required", http.StatusBadRequest)
+		return
+	}
+	if in.SenderID == "" {
+		in.SenderID = in.ChatID
+	}
+
+	w.HandleMessage(in.SenderID, in.ChatID, in.Content, nil, in.Metadata)
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+// Send POSTs the reply as JSON to the configured URL.
+func (w *WebhookChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
+	if w.cfg.URL == "" {
+		return fmt.Errorf("webhook: url not configured")
+	}
+	data, err := json.Marshal(webhookOutbound{
+		Content:  msg.Content(),
+		ChatID:   msg.ChatId(),
+		Metadata: msg.Metadata(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.cfg.AuthToken != "" {
+		header := w.cfg.AuthHeader
+		if header == "" {
+			header = "Authorization"
+		}
+		req.Header.Set(header, w.cfg.AuthToken)
+	}
+
+	if err := w.WaitSend(ctx, msg.ChatId()); err != nil {
+		return err
+	}
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook: POST returned HTTP %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+)
+
+func TestWebhookSend_PostsJSONWithAuthHeader(t *testing.T) {
+	var got webhookOutbound
+	var auth, ctype string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("X-Api-Key")
+		ctype = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := channel.DefaultWebhookConfig()
+	cfg.URL = srv.URL
+	cfg.AuthHeader = "X-Api-Key"
+	cfg.AuthToken = "secret"
+	ch := NewWebhookChannel(&cfg, bus.NewAgentBus(1))
+
+	msg := bus.NewChannelMessageBuilder(bus.ChannelWebhook, "chat-1", "hello").
+		Metadata(map[string]any{"thread": "t1"}).
+		Build()
+	if err := ch.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if auth != "secret" {
+		t.Errorf("expected auth header %q, got %q", "secret", auth)
+	}
+	if ctype != "application/json" {
+		t.Errorf("expected JSON content type, got %q", ctype)
+	}
+	if got.Content != "hello" || got.ChatID != "chat-1" || got.Metadata["thread"] != "t1" {
+		t.Errorf("unexpected body: %+v", got)
+	}
+}
+
+func TestWebhookSend_ErrorOnNon2xx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	cfg := channel.DefaultWebhookConfig()
+	cfg.URL = srv.URL
+	ch := NewWebhookChannel(&cfg, bus.NewAgentBus(1))
+
+	err := ch.Send(context.Background(), bus.NewChannelMessage(bus.ChannelWebhook, "c", "x"))
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected HTTP 502 error, got %v", err)
+	}
+}
+
+func TestWebhookInbound_DispatchesToBus(t *testing.T) {
+	agentBus := bus.NewAgentBus(1)
+	cfg := channel.DefaultWebhookConfig()
+	cfg.InboundToken = "tok"
+	ch := NewWebhookChannel(&cfg, agentBus)
+
+	body := `{"senderId":"u1","chatId":"c1","content":"hi there","metadata":{"k":"v"}}`
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	ch.handleInbound(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer tok")
+	rec = httptest.NewRecorder()
+	ch.handleInbound(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+
+	msg := <-agentBus.Subscribe()
+	if msg.Channel() != bus.ChannelWebhook || msg.SenderId() != "u1" || msg.ChatId() != "c1" || msg.Content() != "hi there" {
+		t.Errorf("unexpected message: channel=%s sender=%s chat=%s content=%q",
+			msg.Channel(), msg.SenderId(), msg.ChatId(), msg.Content())
+	}
+	if msg.Metadata()["k"] != "v" {
+		t.Errorf("expected metadata to be forwarded, got %v", msg.Metadata())
+	}
+}
+
+func TestWebhookInbound_RequiresToken(t *testing.T) {
+	cfg := channel.DefaultWebhookConfig()
+	cfg.ListenAddr = "127.0.0.1:0"
+	ch := NewWebhookChannel(&cfg, bus.NewAgentBus(1))
+
+	if err := ch.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "inboundToken") {
+		t.Fatalf("expected Start to refuse a listener without inboundToken, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"chatId":"c1","content":"hi"}`))
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	ch.handleInbound(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with no token configured, got %d", rec.Code)
+	}
+}
+
+func TestWebhookRoundTrip_AllowFromAndCallback(t *testing.T) {
+	var callback webhookOutbound
+	called := make(chan struct{}, 1)
+	cbSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&callback)
+		called <- struct{}{}
+	}))
+	defer cbSrv.Close()
+
+	agentBus := bus.NewAgentBus(1)
+	cfg := channel.DefaultWebhookConfig()
+	cfg.URL = cbSrv.URL
+	cfg.AllowFrom = []string{"u1"}
+	cfg.InboundToken = "tok"
+	ch := NewWebhookChannel(&cfg, agentBus)
+
+	inSrv := httptest.NewServer(http.HandlerFunc(ch.handleInbound))
+	defer inSrv.Close()
+
+	post := func(sender string) {
+		body := `{"sender":"` + sender + `","chat_id":"c1","content":"ping"}`
+		req, _ := http.NewRequest(http.MethodPost, inSrv.URL, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer tok")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	post("intruder")
+	post("u1")
+
+	msg := <-agentBus.Subscribe()
+	if msg.SenderId() != "u1" {
+		t.Fatalf("expected only the allowlisted sender to reach the bus, got %s", msg.SenderId())
+	}
+
+	reply := bus.NewChannelMessage(bus.ChannelWebhook, msg.ChatId(), "pong")
+	if err := ch.Send(context.Background(), reply); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-called
+	if callback.ChatID != "c1" || callback.Content != "pong" {
+		t.Errorf("unexpected callback body: %+v", callback)
+	}
+}
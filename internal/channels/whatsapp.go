@@ -23,7 +23,7 @@ type WhatsAppChannel struct {
 
 func NewWhatsAppChannel(cfg *channel.WhatsAppConfig, b *bus.AgentBus) *WhatsAppChannel {
 	return &WhatsAppChannel{
-		Base: NewBase("whatsapp", b, cfg.AllowFrom),
+		Base: NewBase("whatsapp", b, cfg.AllowFrom, cfg.MaxInboundChars).WithSendRate(cfg.SendRate, true),
 		cfg:  cfg,
 	}
 }
@@ -124,10 +124,13 @@ func (w *WhatsAppChannel) handleBridgeMessage(raw []byte) {
 	}
 }
 
-func (w *WhatsAppChannel) Send(_ context.Context, msg bus.ChannelMessage) error {
+func (w *WhatsAppChannel) Send(ctx context.Context, msg bus.ChannelMessage) error {
 	if w.conn == nil || !w.connected {
 		return fmt.Errorf("whatsapp: bridge not connected")
 	}
+	if err := w.WaitSend(ctx, msg.ChatId()); err != nil {
+		return err
+	}
 	payload, _ := json.Marshal(map[string]string{
 		"type": "send",
 		"to":   msg.ChatId(),
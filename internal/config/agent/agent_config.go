@@ -1,5 +1,18 @@
 package agent
 
+// Session store backends for AgentDefaults.SessionStore.
+const (
+	SessionStoreJSONL  = "jsonl"  // one JSONL file per session under workspace/sessions (default)
+	SessionStoreMemory = "memory" // process memory only; sessions are lost on restart
+)
+
+// Workspace scopes for AgentDefaults.WorkspaceScope.
+const (
+	WorkspaceScopeShared  = "shared"  // every conversation uses the workspace root (default)
+	WorkspaceScopeChannel = "channel" // one subdirectory per channel, e.g. scopes/telegram
+	WorkspaceScopeSession = "session" // one subdirectory per session key, e.g. scopes/telegram_12345
+)
+
 type AgentDefaults struct {
 	Workspace    string  `json:"workspace"`
 	Model        string  `json:"model"`
@@ -7,6 +20,42 @@ type AgentDefaults struct {
 	Temperature  float64 `json:"temperature"`
 	MaxToolIter  int     `json:"maxToolIterations"`
 	MemoryWindow int     `json:"memoryWindow"`
+
+	// ConsolidationModel is used for memory consolidation instead of
+	// Model, e.g. a cheaper one; empty means Model.
+	ConsolidationModel string `json:"consolidationModel"`
+
+	// MaxMemoryChars caps MEMORY.md; consolidation compresses, then drops
+	// the oldest sections of, a memory that outgrows it. 0 disables the cap.
+	MaxMemoryChars int `json:"maxMemoryChars"`
+
+	// MaxContinuations is how many times a reply cut off by maxTokens is
+	// automatically continued; 0 disables it.
+	MaxContinuations int `json:"maxContinuations"`
+
+	// HistoryIncludeTools replays persisted tool calls/results to the model.
+	HistoryIncludeTools bool `json:"historyIncludeTools"`
+
+	// MaxConcurrentTurns bounds parallel message processing; excess waits
+	// in a priority queue (interactive > system > cron).
+	MaxConcurrentTurns int `json:"maxConcurrentTurns"`
+
+	// EmbeddingModel enables semantic memory recall over HISTORY.md, e.g.
+	// "openai/text-embedding-3-small"; its provider is matched like model.
+	// Empty disables it and search_memory uses word search only.
+	EmbeddingModel string `json:"embeddingModel"`
+
+	// SessionStore selects the conversation history backend.
+	SessionStore string `json:"sessionStore"`
+
+	// WorkspaceScope gives each channel or session its own subdirectory of
+	// the workspace for the file and exec tools, so users don't share
+	// files. Memory and skills stay shared.
+	WorkspaceScope string `json:"workspaceScope"`
+
+	// DryRun makes the agent report tool calls instead of running them.
+	// Set by `agent --dry-run` / `chat --dry-run`; never read from the file.
+	DryRun bool `json:"-"`
 }
 
 type AgentsConfig struct {
@@ -15,15 +64,52 @@ type AgentsConfig struct {
 
 func defaultAgentDefaults() AgentDefaults {
 	return AgentDefaults{
-		Workspace:    "~/.nanobot/workspace",
-		Model:        "gemini/gemini-2.5-pro",
-		MaxTokens:    8192,
-		Temperature:  0.7,
-		MaxToolIter:  20,
-		MemoryWindow: 50,
+		Workspace:          "~/.nanobot/workspace",
+		Model:              "gemini/gemini-2.5-pro",
+		MaxTokens:          8192,
+		Temperature:        0.7,
+		MaxToolIter:        20,
+		MemoryWindow:       50,
+		MaxMemoryChars:     12000,
+		MaxContinuations:   2,
+		MaxConcurrentTurns: 4,
+		SessionStore:       SessionStoreJSONL,
+		WorkspaceScope:     WorkspaceScopeShared,
 	}
 }
 
 func DefaultAgentsConfig() AgentsConfig {
 	return AgentsConfig{Defaults: defaultAgentDefaults()}
 }
+
+// Normalize restores defaults for fields whose loaded value is unusable
+// (e.g. "maxTokens": 0). Values where zero is meaningful, such as
+// temperature 0, are kept.
+func (c *AgentsConfig) Normalize() {
+	def := defaultAgentDefaults()
+	d := &c.Defaults
+	if d.MaxTokens <= 0 {
+		d.MaxTokens = def.MaxTokens
+	}
+	if d.Temperature < 0 {
+		d.Temperature = def.Temperature
+	}
+	if d.MaxToolIter <= 0 {
+		d.MaxToolIter = def.MaxToolIter
+	}
+	if d.MaxMemoryChars < 0 {
+		d.MaxMemoryChars = def.MaxMemoryChars
+	}
+	if d.MaxContinuations < 0 {
+		d.MaxContinuations = def.MaxContinuations
+	}
+	if d.MaxConcurrentTurns <= 0 {
+		d.MaxConcurrentTurns = def.MaxConcurrentTurns
+	}
+	if d.SessionStore == "" {
+		d.SessionStore = def.SessionStore
+	}
+	if d.WorkspaceScope == "" {
+		d.WorkspaceScope = def.WorkspaceScope
+	}
+}
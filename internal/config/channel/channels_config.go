@@ -1,5 +1,10 @@
 package channel
 
+// DefaultMaxInboundChars is the default per-channel cap on inbound message
+// length; longer messages are truncated before reaching the agent. A
+// channel's maxInboundChars of 0 disables the cap.
+const DefaultMaxInboundChars = 20000
+
 type ChannelsConfig struct {
 	WhatsApp WhatsAppConfig `json:"whatsapp"`
 	Telegram TelegramConfig `json:"telegram"`
@@ -10,6 +15,7 @@ type ChannelsConfig struct {
 	Email    EmailConfig    `json:"email"`
 	Slack    SlackConfig    `json:"slack"`
 	QQ       QQConfig       `json:"qq"`
+	Webhook  WebhookConfig  `json:"webhook"`
 }
 
 func DefaultChannelsConfig() ChannelsConfig {
@@ -23,5 +29,6 @@ func DefaultChannelsConfig() ChannelsConfig {
 		Email:    DefaultEmailConfig(),
 		Slack:    DefaultSlackConfig(),
 		QQ:       DefaultQQConfig(),
+		Webhook:  DefaultWebhookConfig(),
 	}
 }
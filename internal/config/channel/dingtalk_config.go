@@ -1,12 +1,14 @@
 package channel
 
 type DingTalkConfig struct {
-	Enabled      bool     `json:"enabled"`
-	ClientID     string   `json:"clientId"`
-	ClientSecret string   `json:"clientSecret"`
-	AllowFrom    []string `json:"allowFrom"`
+	Enabled         bool     `json:"enabled"`
+	ClientID        string   `json:"clientId"`
+	ClientSecret    string   `json:"clientSecret"`
+	AllowFrom       []string `json:"allowFrom"`
+	MaxInboundChars int      `json:"maxInboundChars"`
+	SendRate        float64  `json:"sendRate"` // outbound posts per second; 0 = unlimited
 }
 
 func DefaultDingTalkConfig() DingTalkConfig {
-	return DingTalkConfig{AllowFrom: []string{}}
+	return DingTalkConfig{AllowFrom: []string{}, MaxInboundChars: DefaultMaxInboundChars}
 }
@@ -2,17 +2,24 @@ package channel
 
 // DiscordConfig configures the Discord channel.
 type DiscordConfig struct {
-	Enabled    bool     `json:"enabled"`
-	Token      string   `json:"token"`
-	AllowFrom  []string `json:"allowFrom"`
-	GatewayURL string   `json:"gatewayUrl"`
-	Intents    int      `json:"intents"`
+	Enabled         bool     `json:"enabled"`
+	Token           string   `json:"token"`
+	AllowFrom       []string `json:"allowFrom"`
+	GatewayURL      string   `json:"gatewayUrl"`
+	Intents         int      `json:"intents"`
+	HandleEdits     bool     `json:"handleEdits"` // opt-in: dispatch MESSAGE_UPDATE edits as new turns
+	MaxInboundChars int      `json:"maxInboundChars"`
+	SendRate        float64  `json:"sendRate"`        // outbound posts per second; 0 = unlimited
+	AutoThreadChars int      `json:"autoThreadChars"` // replies longer than this open a thread; 0 = never
+	UseEmbeds       bool     `json:"useEmbeds"`       // send replies over 2000 chars as embeds instead of chunks
 }
 
 func DefaultDiscordConfig() DiscordConfig {
 	return DiscordConfig{
-		GatewayURL: "wss://gateway.discord.gg/?v=10&encoding=json",
-		Intents:    37377, // GUILDS + GUILD_MESSAGES + DIRECT_MESSAGES + MESSAGE_CONTENT
-		AllowFrom:  []string{},
+		GatewayURL:      "wss://gateway.discord.gg/?v=10&encoding=json",
+		Intents:         37377, // GUILDS + GUILD_MESSAGES + DIRECT_MESSAGES + MESSAGE_CONTENT
+		AllowFrom:       []string{},
+		MaxInboundChars: DefaultMaxInboundChars,
+		SendRate:        5,
 	}
 }
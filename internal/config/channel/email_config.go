@@ -22,6 +22,11 @@ type EmailConfig struct {
 	SMTPUseSSL   bool   `json:"smtpUseSsl"`
 	FromAddress  string `json:"fromAddress"`
 
+	// Auth: "password" (LOGIN / PLAIN) or "xoauth2" with an OAuth2 access
+	// token for both IMAP and SMTP. ${VAR} references in the token are expanded.
+	AuthMethod  string `json:"authMethod"`
+	OAuth2Token string `json:"oauth2Token"`
+
 	// Behaviour
 	AutoReplyEnabled    bool     `json:"autoReplyEnabled"`
 	PollIntervalSeconds int      `json:"pollIntervalSeconds"`
@@ -29,6 +34,10 @@ type EmailConfig struct {
 	MaxBodyChars        int      `json:"maxBodyChars"`
 	SubjectPrefix       string   `json:"subjectPrefix"`
 	AllowFrom           []string `json:"allowFrom"`
+	MaxInboundChars     int      `json:"maxInboundChars"`
+	SendRate            float64  `json:"sendRate"`           // outbound posts per second; 0 = unlimited
+	MaxAttachments      int      `json:"maxAttachments"`     // saved per message; 0 = save none
+	MaxAttachmentBytes  int      `json:"maxAttachmentBytes"` // larger files are skipped
 }
 
 func DefaultEmailConfig() EmailConfig {
@@ -38,11 +47,15 @@ func DefaultEmailConfig() EmailConfig {
 		IMAPUseSSL:          true,
 		SMTPPort:            587,
 		SMTPUseTLS:          true,
+		AuthMethod:          "password",
 		AutoReplyEnabled:    true,
 		PollIntervalSeconds: 30,
 		MarkSeen:            true,
 		MaxBodyChars:        12000,
 		SubjectPrefix:       "Re: ",
 		AllowFrom:           []string{},
+		MaxInboundChars:     DefaultMaxInboundChars,
+		MaxAttachments:      5,
+		MaxAttachmentBytes:  10 << 20, // 10 MB
 	}
 }
@@ -8,8 +8,11 @@ type FeishuConfig struct {
 	EncryptKey        string   `json:"encryptKey"`
 	VerificationToken string   `json:"verificationToken"`
 	AllowFrom         []string `json:"allowFrom"`
+	MaxInboundChars   int      `json:"maxInboundChars"`
+	SendRate          float64  `json:"sendRate"` // outbound posts per second; 0 = unlimited
+	UseCards          bool     `json:"useCards"` // send replies as interactive cards instead of plain text
 }
 
 func DefaultFeishuConfig() FeishuConfig {
-	return FeishuConfig{AllowFrom: []string{}}
+	return FeishuConfig{AllowFrom: []string{}, MaxInboundChars: DefaultMaxInboundChars, SendRate: 5}
 }
@@ -34,6 +34,8 @@ type MochatConfig struct {
 	Groups                    map[string]MochatGroupRule `json:"groups"`
 	ReplyDelayMode            string                     `json:"replyDelayMode"`
 	ReplyDelayMs              int                        `json:"replyDelayMs"`
+	MaxInboundChars           int                        `json:"maxInboundChars"`
+	SendRate                  float64                    `json:"sendRate"` // outbound posts per second; 0 = unlimited
 }
 
 func DefaultMochatConfig() MochatConfig {
@@ -53,5 +55,6 @@ func DefaultMochatConfig() MochatConfig {
 		Groups:                    map[string]MochatGroupRule{},
 		ReplyDelayMode:            "non-mention",
 		ReplyDelayMs:              120000,
+		MaxInboundChars:           DefaultMaxInboundChars,
 	}
 }
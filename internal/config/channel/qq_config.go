@@ -2,12 +2,21 @@ package channel
 
 // QQConfig configures the QQ channel.
 type QQConfig struct {
-	Enabled   bool     `json:"enabled"`
-	AppID     string   `json:"appId"`
-	Secret    string   `json:"secret"`
-	AllowFrom []string `json:"allowFrom"`
+	Enabled         bool     `json:"enabled"`
+	AppID           string   `json:"appId"`
+	Secret          string   `json:"secret"`
+	AllowFrom       []string `json:"allowFrom"`
+	MaxInboundChars int      `json:"maxInboundChars"`
+	SendRate        float64  `json:"sendRate"`    // outbound posts per second; 0 = unlimited
+	GroupPolicy     string   `json:"groupPolicy"` // "mention" | "allowlist" | "disabled"
+	GroupAllowFrom  []string `json:"groupAllowFrom"`
 }
 
 func DefaultQQConfig() QQConfig {
-	return QQConfig{AllowFrom: []string{}}
+	return QQConfig{
+		AllowFrom:       []string{},
+		MaxInboundChars: DefaultMaxInboundChars,
+		GroupPolicy:     "mention",
+		GroupAllowFrom:  []string{},
+	}
 }
@@ -14,8 +14,10 @@ func DefaultSlackDMConfig() SlackDMConfig {
 // SlackConfig configures the Slack channel.
 type SlackConfig struct {
 	Enabled           bool          `json:"enabled"`
-	Mode              string        `json:"mode"`
-	WebhookPath       string        `json:"webhookPath"`
+	Mode              string        `json:"mode"`          // "socket" or "events"
+	WebhookPath       string        `json:"webhookPath"`   // events mode endpoint path
+	ListenAddr        string        `json:"listenAddr"`    // events mode listen address, e.g. ":18801"
+	SigningSecret     string        `json:"signingSecret"` // events mode request signing secret
 	BotToken          string        `json:"botToken"`
 	AppToken          string        `json:"appToken"`
 	UserTokenReadOnly bool          `json:"userTokenReadOnly"`
@@ -24,17 +26,22 @@ type SlackConfig struct {
 	GroupPolicy       string        `json:"groupPolicy"`
 	GroupAllowFrom    []string      `json:"groupAllowFrom"`
 	DM                SlackDMConfig `json:"dm"`
+	MaxInboundChars   int           `json:"maxInboundChars"`
+	SendRate          float64       `json:"sendRate"` // outbound posts per second; 0 = unlimited
 }
 
 func DefaultSlackConfig() SlackConfig {
 	return SlackConfig{
 		Mode:              "socket",
 		WebhookPath:       "/slack/events",
+		ListenAddr:        ":18801",
 		UserTokenReadOnly: true,
 		ReplyInThread:     true,
 		ReactEmoji:        "eyes",
 		GroupPolicy:       "mention",
 		GroupAllowFrom:    []string{},
 		DM:                DefaultSlackDMConfig(),
+		MaxInboundChars:   DefaultMaxInboundChars,
+		SendRate:          1,
 	}
 }
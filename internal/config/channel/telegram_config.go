@@ -7,8 +7,22 @@ type TelegramConfig struct {
 	AllowFrom      []string `json:"allowFrom"`
 	Proxy          string   `json:"proxy,omitempty"`
 	ReplyToMessage bool     `json:"replyToMessage"`
+	HandleEdits    bool     `json:"handleEdits"` // opt-in: dispatch edited messages as new turns
+
+	// EditProgress shows a turn's progress updates in one message, edited in
+	// place and deleted when the reply arrives, instead of one message each.
+	EditProgress bool `json:"editProgress"`
+
+	// Backoff (seconds) before re-opening the updates stream after it closes.
+	ReconnectDelay    int `json:"reconnectDelay"`
+	MaxReconnectDelay int `json:"maxReconnectDelay"`
+
+	// MaxInboundChars truncates longer inbound messages before they reach the
+	// agent.
+	MaxInboundChars int     `json:"maxInboundChars"`
+	SendRate        float64 `json:"sendRate"` // outbound posts per second; 0 = unlimited
 }
 
 func DefaultTelegramConfig() TelegramConfig {
-	return TelegramConfig{AllowFrom: []string{}}
+	return TelegramConfig{AllowFrom: []string{}, ReconnectDelay: 5, MaxReconnectDelay: 60, MaxInboundChars: DefaultMaxInboundChars, SendRate: 1}
 }
@@ -0,0 +1,28 @@
+package channel
+
+// WebhookConfig configures the generic webhook channel. Replies are POSTed
+// as JSON to URL; when ListenAddr is set, messages are also accepted on an
+// HTTP endpoint at Path.
+type WebhookConfig struct {
+	Enabled         bool     `json:"enabled"`
+	URL             string   `json:"url"`
+	AuthHeader      string   `json:"authHeader"`   // header name sent with outbound POSTs
+	AuthToken       string   `json:"authToken"`    // header value; empty = no auth header
+	ListenAddr      string   `json:"listenAddr"`   // e.g. "127.0.0.1:18800"; empty = outbound only
+	Path            string   `json:"path"`         // inbound endpoint path
+	InboundToken    string   `json:"inboundToken"` // bearer token for inbound; required with listenAddr
+	Timeout         int      `json:"timeout"`      // outbound POST timeout in seconds
+	AllowFrom       []string `json:"allowFrom"`
+	MaxInboundChars int      `json:"maxInboundChars"`
+	SendRate        float64  `json:"sendRate"` // outbound posts per second; 0 = unlimited
+}
+
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		AuthHeader:      "Authorization",
+		Path:            "/webhook",
+		Timeout:         15,
+		AllowFrom:       []string{},
+		MaxInboundChars: DefaultMaxInboundChars,
+	}
+}
@@ -2,12 +2,14 @@ package channel
 
 // WhatsAppConfig configures the WhatsApp channel.
 type WhatsAppConfig struct {
-	Enabled     bool     `json:"enabled"`
-	BridgeURL   string   `json:"bridgeUrl"`
-	BridgeToken string   `json:"bridgeToken"`
-	AllowFrom   []string `json:"allowFrom"`
+	Enabled         bool     `json:"enabled"`
+	BridgeURL       string   `json:"bridgeUrl"`
+	BridgeToken     string   `json:"bridgeToken"`
+	AllowFrom       []string `json:"allowFrom"`
+	MaxInboundChars int      `json:"maxInboundChars"`
+	SendRate        float64  `json:"sendRate"` // outbound posts per second; 0 = unlimited
 }
 
 func DefaultWhatsAppConfig() WhatsAppConfig {
-	return WhatsAppConfig{BridgeURL: "ws://localhost:3001", AllowFrom: []string{}}
+	return WhatsAppConfig{BridgeURL: "ws://localhost:3001", AllowFrom: []string{}, MaxInboundChars: DefaultMaxInboundChars}
 }
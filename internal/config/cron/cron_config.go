@@ -0,0 +1,17 @@
+package cron
+
+// Overflow policies applied when every execution slot is busy.
+const (
+	OverflowQueue = "queue" // wait for a free slot
+	OverflowSkip  = "skip"  // drop the run and record lastStatus="skipped"
+)
+
+// CronConfig holds scheduler settings.
+type CronConfig struct {
+	MaxConcurrent  int    `json:"maxConcurrent"`
+	OverflowPolicy string `json:"overflowPolicy"` // "queue" | "skip"
+}
+
+func DefaultCronConfig() CronConfig {
+	return CronConfig{MaxConcurrent: 2, OverflowPolicy: OverflowQueue}
+}
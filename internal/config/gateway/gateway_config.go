@@ -2,8 +2,11 @@ package gateway
 
 // GatewayConfig holds gateway server settings.
 type GatewayConfig struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Host      string `json:"host"`
+	Port      int    `json:"port"`
+	AuthToken string `json:"authToken"` // bearer token for HTTP APIs; empty = no auth
+	Metrics   bool   `json:"metrics"`   // serve Prometheus metrics on /metrics
+	Admin     bool   `json:"admin"`     // serve the cron/session admin API under /api; requires AuthToken
 }
 
 func DefaultGatewayConfig() GatewayConfig {
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	channelcfg "github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
 )
 
 // ConfigPath returns the default configuration file path: ~/.nanobot/config.json.
@@ -27,6 +30,8 @@ func DataDir() string {
 
 // Load reads and parses the config file at path.
 // If path is empty, ConfigPath() is used.
+// The file is decoded over DefaultConfig(), so omitted fields at any depth keep
+// their defaults; see normalize for explicitly-set values that are replaced.
 // On parse failure it prints a warning and returns DefaultConfig().
 func Load(path string) (*Config, error) {
 	if path == "" {
@@ -49,10 +54,24 @@ func Load(path string) (*Config, error) {
 		cfg2 := DefaultConfig()
 		return &cfg2, nil
 	}
+	cfg.normalize()
 
 	return &cfg, nil
 }
 
+// normalize repairs values a partial or hand-edited file can leave unusable:
+// an explicit null empties maps that callers write to, and zero values that
+// are invalid fall back to their defaults.
+func (c *Config) normalize() {
+	c.Agents.Normalize()
+	if c.Tools.MCPServers == nil {
+		c.Tools.MCPServers = map[string]toolcfg.MCPServerConfig{}
+	}
+	if c.Channels.Mochat.Groups == nil {
+		c.Channels.Mochat.Groups = map[string]channelcfg.MochatGroupRule{}
+	}
+}
+
 // Save writes cfg to path as indented JSON.
 // If path is empty, ConfigPath() is used.
 func Save(cfg *Config, path string) error {
@@ -160,3 +160,49 @@ func TestLoad_PartialConfig_UsesDefaults(t *testing.T) {
 		t.Errorf("expected default memoryWindow %d, got %d", def.Agents.Defaults.MemoryWindow, cfg.Agents.Defaults.MemoryWindow)
 	}
 }
+
+func TestLoad_PartialConfigKeepsNestedDefaults(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, map[string]any{
+		"agents": map[string]any{"defaults": map[string]any{"model": "openai/gpt-4o"}},
+		"tools":  map[string]any{"exec": map[string]any{}},
+	})
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	def := DefaultConfig()
+	if cfg.Agents.Defaults.MaxTokens != def.Agents.Defaults.MaxTokens {
+		t.Errorf("expected omitted maxTokens to keep default %d, got %d",
+			def.Agents.Defaults.MaxTokens, cfg.Agents.Defaults.MaxTokens)
+	}
+	if cfg.Tools.Exec.Timeout != def.Tools.Exec.Timeout {
+		t.Errorf("expected empty exec section to keep default timeout, got %d", cfg.Tools.Exec.Timeout)
+	}
+}
+
+func TestLoad_ExplicitZeroValues(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, map[string]any{
+		"agents": map[string]any{"defaults": map[string]any{
+			"maxTokens":   0,
+			"temperature": 0,
+		}},
+		"tools": map[string]any{"mcpServers": nil},
+	})
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Agents.Defaults.Temperature != 0 {
+		t.Errorf("expected explicit temperature 0 to be respected, got %v", cfg.Agents.Defaults.Temperature)
+	}
+	if want := DefaultConfig().Agents.Defaults.MaxTokens; cfg.Agents.Defaults.MaxTokens != want {
+		t.Errorf("expected invalid maxTokens 0 to fall back to %d, got %d", want, cfg.Agents.Defaults.MaxTokens)
+	}
+	if cfg.Tools.MCPServers == nil {
+		t.Error("expected null mcpServers to be restored to an empty map")
+	}
+}
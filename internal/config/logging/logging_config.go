@@ -0,0 +1,20 @@
+package logging
+
+// Log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// LoggingConfig controls the global slog handler installed at startup.
+type LoggingConfig struct {
+	Level      string `json:"level"`      // "debug" | "info" | "warn" | "error"
+	Format     string `json:"format"`     // "text" | "json"
+	File       bool   `json:"file"`       // also write to ~/.nanobot/logs/crystaldolphin.log
+	MaxSizeMB  int    `json:"maxSizeMb"`  // rotate the log file once it reaches this size
+	MaxBackups int    `json:"maxBackups"` // rotated log files kept (.1 … .N)
+}
+
+func DefaultLoggingConfig() LoggingConfig {
+	return LoggingConfig{Level: "info", Format: FormatText, MaxSizeMB: 10, MaxBackups: 3}
+}
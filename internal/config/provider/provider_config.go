@@ -25,6 +25,14 @@ type ProviderConfig struct {
 	APIKey       string            `json:"apiKey"`
 	APIBase      string            `json:"apiBase,omitempty"`
 	ExtraHeaders map[string]string `json:"extraHeaders,omitempty"`
+
+	// CacheTTL is the prompt-cache lifetime for providers that support
+	// cache_control (Anthropic, OpenRouter): "5m" (default) or "1h".
+	CacheTTL string `json:"cacheTtl,omitempty"`
+
+	// ToolResultsAsUser sends tool results as user messages, for
+	// OpenAI-compatible endpoints that reject the "tool" role.
+	ToolResultsAsUser bool `json:"toolResultsAsUser,omitempty"`
 }
 
 // ProvidersConfig holds credentials for all supported LLM providers.
@@ -46,10 +54,33 @@ type ProvidersConfig struct {
 	VolcEngine    ProviderConfig `json:"volcengine"`
 	OpenAICodex   ProviderConfig `json:"openaiCodex"`
 	GithubCopilot ProviderConfig `json:"githubCopilot"`
+
+	// DebugHTTP logs raw provider requests and responses, with credentials
+	// redacted, to logs/http-debug.log under the data dir. The
+	// CRYSTALDOLPHIN_DEBUG_HTTP environment variable enables it too.
+	DebugHTTP bool `json:"debugHttp"`
+
+	CircuitBreaker CircuitBreakerConfig `json:"circuitBreaker"`
+}
+
+// CircuitBreakerConfig controls the breaker around the active provider: after
+// FailureThreshold consecutive retryable failures (transport errors, 429, 5xx)
+// within WindowSeconds, calls fail fast for CooldownSeconds before one probe
+// call tests recovery. A FailureThreshold of 0 disables the breaker.
+type CircuitBreakerConfig struct {
+	FailureThreshold int `json:"failureThreshold"`
+	WindowSeconds    int `json:"windowSeconds"`
+	CooldownSeconds  int `json:"cooldownSeconds"`
 }
 
 func DefaultProvidersConfig() ProvidersConfig {
-	return ProvidersConfig{}
+	return ProvidersConfig{
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureThreshold: 5,
+			WindowSeconds:    60,
+			CooldownSeconds:  30,
+		},
+	}
 }
 
 // ByName returns a pointer to the ProviderConfig field matching the given
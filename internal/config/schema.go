@@ -6,7 +6,9 @@ import (
 
 	agentcfg "github.com/crystaldolphin/crystaldolphin/internal/config/agent"
 	channelcfg "github.com/crystaldolphin/crystaldolphin/internal/config/channel"
+	croncfg "github.com/crystaldolphin/crystaldolphin/internal/config/cron"
 	gatewaycfg "github.com/crystaldolphin/crystaldolphin/internal/config/gateway"
+	logcfg "github.com/crystaldolphin/crystaldolphin/internal/config/logging"
 	providercfg "github.com/crystaldolphin/crystaldolphin/internal/config/provider"
 	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
 )
@@ -18,6 +20,8 @@ type Config struct {
 	Gateway   gatewaycfg.GatewayConfig    `json:"gateway"`
 	Tools     toolcfg.ToolsConfig         `json:"tools"`
 	Providers providercfg.ProvidersConfig `json:"providers"`
+	Cron      croncfg.CronConfig          `json:"cron"`
+	Logging   logcfg.LoggingConfig        `json:"logging"`
 }
 
 // DefaultConfig returns a Config populated with all default values.
@@ -28,6 +32,8 @@ func DefaultConfig() Config {
 		Gateway:   gatewaycfg.DefaultGatewayConfig(),
 		Channels:  channelcfg.DefaultChannelsConfig(),
 		Providers: providercfg.DefaultProvidersConfig(),
+		Cron:      croncfg.DefaultCronConfig(),
+		Logging:   logcfg.DefaultLoggingConfig(),
 	}
 }
 
@@ -46,6 +52,22 @@ func (c *Config) WorkspacePath() string {
 	return ws
 }
 
+// AuditPath returns the expanded path of the tool audit log, defaulting to
+// audit/tools.jsonl under the data dir.
+func (c *Config) AuditPath() string {
+	p := c.Tools.Audit.Path
+	if p == "" {
+		return filepath.Join(DataDir(), "audit", "tools.jsonl")
+	}
+	if len(p) >= 2 && p[:2] == "~/" {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			p = filepath.Join(home, p[2:])
+		}
+	}
+	return p
+}
+
 // ProviderByName returns a pointer to the ProviderConfig field matching the
 // given registry name. Returns nil if unknown.
 func (c *Config) ProviderByName(name string) *providercfg.ProviderConfig {
@@ -0,0 +1,14 @@
+package tool
+
+// AuditConfig configures the tool-usage audit log: one JSON line per tool
+// call with the session, tool name, truncated arguments and result, outcome
+// and duration.
+type AuditConfig struct {
+	Enabled       bool   `json:"enabled"`
+	Path          string `json:"path"`          // default ~/.nanobot/audit/tools.jsonl
+	MaxFieldChars int    `json:"maxFieldChars"` // args and result are cut to this length
+}
+
+func DefaultAuditConfig() AuditConfig {
+	return AuditConfig{MaxFieldChars: 1000}
+}
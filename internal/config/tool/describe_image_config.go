@@ -0,0 +1,13 @@
+package tool
+
+// DescribeImageToolConfig configures the describe_image tool. Model must
+// accept image input and be served by the agent's provider; empty means
+// agents.defaults.model.
+type DescribeImageToolConfig struct {
+	Enabled bool   `json:"enabled"`
+	Model   string `json:"model"`
+}
+
+func DefaultDescribeImageToolConfig() DescribeImageToolConfig {
+	return DescribeImageToolConfig{Enabled: true}
+}
@@ -0,0 +1,15 @@
+package tool
+
+// DownloadToolConfig configures the download_file tool. Like web_fetch it
+// refuses URLs resolving to private, loopback, or link-local addresses unless
+// AllowPrivateNetworks is set or the host is in AllowedHosts.
+type DownloadToolConfig struct {
+	MaxBytes             int64    `json:"maxBytes"` // larger downloads are aborted and removed
+	Timeout              int      `json:"timeout"`  // seconds
+	AllowPrivateNetworks bool     `json:"allowPrivateNetworks"`
+	AllowedHosts         []string `json:"allowedHosts"`
+}
+
+func DefaultDownloadToolConfig() DownloadToolConfig {
+	return DownloadToolConfig{MaxBytes: 50 << 20, Timeout: 120}
+}
@@ -1,10 +1,30 @@
 package tool
 
+// Sandbox modes for ExecToolConfig.Sandbox.
+const (
+	ExecSandboxNone   = ""       // run commands directly on the host (default)
+	ExecSandboxDocker = "docker" // run each command in a throwaway container
+)
+
 // ExecToolConfig configures the shell-exec tool.
 type ExecToolConfig struct {
 	Timeout int `json:"timeout"` // seconds
+	// MaxOutputBytes caps captured stdout+stderr; the rest is discarded.
+	MaxOutputBytes int `json:"maxOutputBytes"`
+
+	// Sandbox selects where commands run; see the ExecSandbox* constants.
+	Sandbox string `json:"sandbox"`
+	// Image is the container image used by the docker sandbox.
+	Image string `json:"image"`
+	// Network enables container networking; it is off by default.
+	Network bool `json:"network"`
+
+	// Allow, when non-empty, limits commands to those matching one of these
+	// regular expressions. Deny rejects commands matching any of them.
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
 }
 
 func DefaultExecToolConfig() ExecToolConfig {
-	return ExecToolConfig{Timeout: 60}
+	return ExecToolConfig{Timeout: 60, MaxOutputBytes: 10000, Image: "alpine:3", Allow: []string{}, Deny: []string{}}
 }
@@ -0,0 +1,10 @@
+package tool
+
+// GrepToolConfig configures the grep (search-in-files) tool.
+type GrepToolConfig struct {
+	MaxMatches int `json:"maxMatches"` // matching lines returned per call
+}
+
+func DefaultGrepToolConfig() GrepToolConfig {
+	return GrepToolConfig{MaxMatches: 200}
+}
@@ -0,0 +1,15 @@
+package tool
+
+// HTTPToolConfig configures the http_request tool. It is disabled by default
+// because it lets the model send arbitrary authenticated requests.
+type HTTPToolConfig struct {
+	Enabled              bool     `json:"enabled"`
+	Timeout              int      `json:"timeout"` // seconds
+	MaxResponseChars     int      `json:"maxResponseChars"`
+	AllowPrivateNetworks bool     `json:"allowPrivateNetworks"`
+	AllowedHosts         []string `json:"allowedHosts"`
+}
+
+func DefaultHTTPToolConfig() HTTPToolConfig {
+	return HTTPToolConfig{Timeout: 30, MaxResponseChars: 20000}
+}
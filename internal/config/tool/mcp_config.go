@@ -7,4 +7,12 @@ type MCPServerConfig struct {
 	Env     map[string]string `json:"env"`
 	URL     string            `json:"url"`
 	Headers map[string]string `json:"headers"`
+	// Transport selects the HTTP transport: "" posts each request and reads a
+	// JSON or text/event-stream reply; "sse" uses a long-lived event stream.
+	Transport string `json:"transport"`
+	// Prefix replaces the default "mcp_<server>" prefix of registered tool
+	// names (e.g. "gh" gives "gh_<tool>").
+	Prefix string `json:"prefix"`
+	// InitTimeout bounds the initialize handshake in seconds; 0 means 10.
+	InitTimeout int `json:"initTimeout"`
 }
@@ -0,0 +1,10 @@
+package tool
+
+// ReadFileToolConfig configures the read_file tool.
+type ReadFileToolConfig struct {
+	MaxBytes int `json:"maxBytes"` // larger files are truncated; line ranges still work
+}
+
+func DefaultReadFileToolConfig() ReadFileToolConfig {
+	return ReadFileToolConfig{MaxBytes: 2 << 20}
+}
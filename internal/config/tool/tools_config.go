@@ -4,14 +4,46 @@ package tool
 type ToolsConfig struct {
 	Web                 WebToolsConfig             `json:"web"`
 	Exec                ExecToolConfig             `json:"exec"`
+	HTTP                HTTPToolConfig             `json:"http"`
+	Download            DownloadToolConfig         `json:"download"`
+	DescribeImage       DescribeImageToolConfig    `json:"describeImage"`
+	Grep                GrepToolConfig             `json:"grep"`
+	ReadFile            ReadFileToolConfig         `json:"readFile"`
 	RestrictToWorkspace bool                       `json:"restrictToWorkspace"`
 	MCPServers          map[string]MCPServerConfig `json:"mcpServers"`
+
+	// RequireApproval lists tools (e.g. "exec", "write_file") that only run
+	// after the user replies yes in the chat; unanswered requests are denied
+	// after ApprovalTimeout seconds.
+	RequireApproval []string `json:"requireApproval"`
+	ApprovalTimeout int      `json:"approvalTimeout"`
+
+	// MaxResultBytes caps each tool result passed back to the model (0 = no
+	// cap); ResultLimits overrides it per tool name.
+	MaxResultBytes int            `json:"maxResultBytes"`
+	ResultLimits   map[string]int `json:"resultLimits"`
+
+	// TimeoutSeconds bounds each tool call except exec, which has its own
+	// tools.exec.timeout; 0 disables it.
+	TimeoutSeconds int `json:"timeoutSeconds"`
+
+	// Audit appends a record of every tool call to a JSONL file.
+	Audit AuditConfig `json:"audit"`
 }
 
 func DefaultToolConfigs() ToolsConfig {
 	return ToolsConfig{
-		Web:        DefaultWebToolsConfig(),
-		Exec:       DefaultExecToolConfig(),
-		MCPServers: map[string]MCPServerConfig{},
+		Web:             DefaultWebToolsConfig(),
+		Exec:            DefaultExecToolConfig(),
+		HTTP:            DefaultHTTPToolConfig(),
+		Download:        DefaultDownloadToolConfig(),
+		DescribeImage:   DefaultDescribeImageToolConfig(),
+		Grep:            DefaultGrepToolConfig(),
+		ReadFile:        DefaultReadFileToolConfig(),
+		MCPServers:      map[string]MCPServerConfig{},
+		ApprovalTimeout: 300,
+		MaxResultBytes:  100_000,
+		TimeoutSeconds:  60,
+		Audit:           DefaultAuditConfig(),
 	}
 }
@@ -1,20 +1,47 @@
 package tool
 
-// WebSearchConfig configures the Brave web-search tool.
+// Search providers accepted by WebSearchConfig.Provider.
+const (
+	SearchProviderBrave     = "brave"
+	SearchProviderSearXNG   = "searxng"
+	SearchProviderGoogleCSE = "google_cse"
+)
+
+// WebSearchConfig configures the web-search tool.
+// APIKey is the Brave subscription token or the Google API key, depending on
+// Provider. InstanceURL is the SearXNG base URL; CX is the Google CSE engine ID.
 type WebSearchConfig struct {
-	APIKey     string `json:"apiKey"`
-	MaxResults int    `json:"maxResults"`
+	Provider    string `json:"provider"` // "brave" | "searxng" | "google_cse"
+	APIKey      string `json:"apiKey"`
+	MaxResults  int    `json:"maxResults"`
+	InstanceURL string `json:"instanceUrl"`
+	CX          string `json:"cx"`
 }
 
 func DefaultWebSearchConfig() WebSearchConfig {
-	return WebSearchConfig{MaxResults: 5}
+	return WebSearchConfig{Provider: SearchProviderBrave, MaxResults: 5}
+}
+
+// WebFetchConfig configures the web-fetch tool.
+// By default URLs resolving to private, loopback, or link-local addresses are
+// refused; AllowPrivateNetworks lifts that, AllowedHosts exempts single hosts.
+type WebFetchConfig struct {
+	MaxChars             int      `json:"maxChars"`
+	AllowPrivateNetworks bool     `json:"allowPrivateNetworks"`
+	AllowedHosts         []string `json:"allowedHosts"`
+	CacheTTLSeconds      int      `json:"cacheTtlSeconds"` // 0 disables the result cache
+}
+
+func DefaultWebFetchConfig() WebFetchConfig {
+	return WebFetchConfig{MaxChars: 50000, CacheTTLSeconds: 300}
 }
 
 // WebToolsConfig groups web-related tool settings.
 type WebToolsConfig struct {
 	Search WebSearchConfig `json:"search"`
+	Fetch  WebFetchConfig  `json:"fetch"`
 }
 
 func DefaultWebToolsConfig() WebToolsConfig {
-	return WebToolsConfig{Search: DefaultWebSearchConfig()}
+	return WebToolsConfig{Search: DefaultWebSearchConfig(), Fetch: DefaultWebFetchConfig()}
 }
@@ -23,7 +23,10 @@ import (
 	robfigcron "github.com/robfig/cron/v3"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	croncfg "github.com/crystaldolphin/crystaldolphin/internal/config/cron"
+	"github.com/crystaldolphin/crystaldolphin/internal/metrics"
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/atomicfile"
 )
 
 type CronSchedule struct {
@@ -35,11 +38,31 @@ type CronSchedule struct {
 }
 
 type CronPayload struct {
-	Kind    string  `json:"kind"` // "agent_turn"
-	Message string  `json:"message"`
-	Deliver bool    `json:"deliver"`
-	Channel *string `json:"channel,omitempty"`
-	To      *string `json:"to,omitempty"`
+	Kind    string   `json:"kind"` // "agent_turn"
+	Message string   `json:"message"`
+	Deliver bool     `json:"deliver"`
+	Channel *string  `json:"channel,omitempty"`
+	To      *string  `json:"to,omitempty"`     // first recipient; kept for nanobot compatibility
+	ToList  []string `json:"toList,omitempty"` // all recipients when there is more than one
+}
+
+// Recipients returns every delivery target (To first, then ToList) without duplicates.
+func (p CronPayload) Recipients() []string {
+	var out []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	if p.To != nil {
+		add(*p.To)
+	}
+	for _, id := range p.ToList {
+		add(id)
+	}
+	return out
 }
 
 type CronJobState struct {
@@ -79,9 +102,18 @@ type JobManager struct {
 	storePath string
 	onJob     OnJobFunc
 
+	// Execution slots bounding concurrent onJob calls; nil means unlimited.
+	slots        chan struct{}
+	skipWhenBusy bool
+
 	mu    sync.Mutex
 	store cronStore
 
+	// running tracks in-flight executions so Start can wait for their final
+	// save; stopped (guarded by mu) rejects runs once shutdown begins.
+	running sync.WaitGroup
+	stopped bool
+
 	// Active timers / cron entries keyed by job ID.
 	timers    map[string]*time.Timer
 	robfig    *robfigcron.Cron
@@ -91,8 +123,10 @@ type JobManager struct {
 // NewService creates a CronService.
 // storePath is the path to jobs.json (e.g. ~/.nanobot/cron/jobs.json).
 func NewService(storePath string) *JobManager {
+	def := croncfg.DefaultCronConfig()
 	return &JobManager{
 		storePath: storePath,
+		slots:     make(chan struct{}, def.MaxConcurrent),
 		timers:    make(map[string]*time.Timer),
 		robfig:    robfigcron.New(robfigcron.WithSeconds()),
 		robfigIDs: make(map[string]robfigcron.EntryID),
@@ -103,6 +137,18 @@ func NewService(storePath string) *JobManager {
 // Must be set before Start().
 func (s *JobManager) OnJobFunc(fn OnJobFunc) { s.onJob = fn }
 
+// SetConcurrency bounds how many jobs may execute at once.
+// maxConcurrent <= 0 removes the limit. policy is croncfg.OverflowQueue
+// (wait for a free slot) or croncfg.OverflowSkip (drop the run).
+// Must be set before Start().
+func (s *JobManager) SetConcurrency(maxConcurrent int, policy string) {
+	s.slots = nil
+	if maxConcurrent > 0 {
+		s.slots = make(chan struct{}, maxConcurrent)
+	}
+	s.skipWhenBusy = policy == croncfg.OverflowSkip
+}
+
 // Start loads jobs from disk, (re)computes next-run times, and arms all timers.
 // Blocks until ctx is cancelled.
 func (s *JobManager) Start(ctx context.Context) error {
@@ -123,10 +169,12 @@ func (s *JobManager) Start(ctx context.Context) error {
 
 	<-s.robfig.Stop().Done()
 	s.mu.Lock()
+	s.stopped = true
 	for _, t := range s.timers {
 		t.Stop()
 	}
 	s.mu.Unlock()
+	s.running.Wait()
 	return ctx.Err()
 }
 
@@ -137,6 +185,23 @@ func (s *JobManager) AddJob(
 	everyMs int64, cronExpr, tz string, atMs int64,
 	deliver bool, channel bus.Channel, to string, deleteAfterRun bool,
 ) (string, error) {
+	var recipients []string
+	if to != "" {
+		recipients = []string{to}
+	}
+	job, err := s.addJob(name, message, kind, everyMs, cronExpr, tz, atMs, deliver, channel, recipients, deleteAfterRun)
+	return job.ID, err
+}
+
+func (s *JobManager) addJob(
+	name, message, kind string,
+	everyMs int64, cronExpr, tz string, atMs int64,
+	deliver bool, channel bus.Channel, recipients []string, deleteAfterRun bool,
+) (CronJob, error) {
+	if len(recipients) > 0 && !deliver {
+		return CronJob{}, fmt.Errorf("recipients given but deliver is false")
+	}
+
 	sched := CronSchedule{Kind: kind}
 	switch kind {
 	case "every":
@@ -149,7 +214,7 @@ func (s *JobManager) AddJob(
 	case "at":
 		sched.AtMs = &atMs
 	default:
-		return "", fmt.Errorf("unknown schedule kind %q", kind)
+		return CronJob{}, fmt.Errorf("unknown schedule kind %q", kind)
 	}
 
 	payload := CronPayload{
@@ -162,8 +227,12 @@ func (s *JobManager) AddJob(
 		ch := string(channel)
 		payload.Channel = &ch
 	}
-	if to != "" {
-		payload.To = &to
+	if len(recipients) > 0 {
+		first := recipients[0]
+		payload.To = &first
+	}
+	if len(recipients) > 1 {
+		payload.ToList = recipients
 	}
 
 	now := nowMs()
@@ -187,7 +256,7 @@ func (s *JobManager) AddJob(
 	s.mu.Unlock()
 
 	slog.Info("cron: added job", "name", name, "id", id, "kind", kind)
-	return id, nil
+	return job, nil
 }
 
 // ListJobs returns summaries of all enabled jobs.
@@ -256,20 +325,47 @@ func (s *JobManager) ListAllJobs(includeDisabled bool) []CronJob {
 }
 
 // AddJobFull is the CLI-level add (takes a fully-formed CronJob minus ID/times).
+// to may list several recipients; the response is delivered to each of them.
 func (s *JobManager) AddJobFull(name, message, kind string, everyMs int64, cronExpr, tz string, atMs int64,
-	deliver bool, channel, to string, deleteAfterRun bool) (CronJob, error) {
-	id, err := s.AddJob(name, message, kind, everyMs, cronExpr, tz, atMs, deliver, bus.Channel(channel), to, deleteAfterRun)
-	if err != nil {
-		return CronJob{}, err
+	deliver bool, channel string, to []string, deleteAfterRun bool) (CronJob, error) {
+	return s.addJob(name, message, kind, everyMs, cronExpr, tz, atMs, deliver, bus.Channel(channel), to, deleteAfterRun)
+}
+
+// JobMessage builds the agent message for a run of job. It is addressed to
+// the job's delivery chat (the CLI by default) so replies and the message
+// tool reach it, under the job's own session key, and is scheduled at cron
+// priority behind interactive messages.
+func JobMessage(job CronJob) bus.AgentMessage {
+	ch := bus.ChannelCLI
+	chatID := "direct"
+	if job.Payload.Channel != nil {
+		ch = bus.Channel(*job.Payload.Channel)
+	}
+	if job.Payload.To != nil {
+		chatID = *job.Payload.To
+	}
+	return bus.NewAgentMessageBuilder(ch, bus.SenderIdCLI, chatID, job.Payload.Message).
+		RoutingKey("cron:" + job.ID).
+		Priority(bus.DefaultPriority(bus.ChannelCron)).
+		Build()
+}
+
+// Deliver publishes resp to every recipient of job on channelBus.
+// It is a no-op unless job.Payload.Deliver is set. Returns the number of
+// messages published.
+func Deliver(channelBus *bus.ChannelBus, job CronJob, resp string) int {
+	if !job.Payload.Deliver {
+		return 0
 	}
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for _, j := range s.store.Jobs {
-		if j.ID == id {
-			return j, nil
-		}
+	ch := bus.ChannelCLI
+	if job.Payload.Channel != nil {
+		ch = bus.Channel(*job.Payload.Channel)
+	}
+	recipients := job.Payload.Recipients()
+	for _, to := range recipients {
+		channelBus.Publish(bus.NewChannelMessage(ch, to, resp))
 	}
-	return CronJob{}, fmt.Errorf("job not found after add")
+	return len(recipients)
 }
 
 // EnableJob enables or disables a job.
@@ -353,6 +449,10 @@ func (s *JobManager) armJobLocked(ctx context.Context, job CronJob) {
 			s.executeJob(ctx, job)
 			// Re-arm for next tick.
 			s.mu.Lock()
+			if s.stopped {
+				s.mu.Unlock()
+				return
+			}
 			// Refresh job from store in case it changed.
 			for _, j := range s.store.Jobs {
 				if j.ID == job.ID && j.Enabled {
@@ -416,7 +516,29 @@ func (s *JobManager) cancelTimerLocked(id string) {
 }
 
 func (s *JobManager) executeJob(ctx context.Context, job CronJob) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.running.Add(1)
+	s.mu.Unlock()
+	defer s.running.Done()
+
 	startMs := nowMs()
+
+	if !s.acquireSlot(ctx) {
+		if ctx.Err() != nil {
+			// Shutting down while queued for a slot: not a skipped run.
+			return
+		}
+		slog.Warn("cron: skipping job, all execution slots busy", "name", job.Name, "id", job.ID)
+		s.recordRun(job, startMs, "skipped", nil)
+		metrics.CronRuns.Inc("skipped")
+		return
+	}
+	defer s.releaseSlot()
+
 	slog.Info("cron: executing job", "name", job.Name, "id", job.ID)
 
 	var lastStatus = "ok"
@@ -431,6 +553,41 @@ func (s *JobManager) executeJob(ctx context.Context, job CronJob) {
 		}
 	}
 
+	s.recordRun(job, startMs, lastStatus, lastErr)
+	metrics.CronRuns.Inc(lastStatus)
+}
+
+// acquireSlot reserves an execution slot. With the skip policy it fails
+// immediately when saturated; otherwise it waits until a slot frees up or
+// ctx is cancelled.
+func (s *JobManager) acquireSlot(ctx context.Context) bool {
+	if s.slots == nil {
+		return true
+	}
+	if s.skipWhenBusy {
+		select {
+		case s.slots <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *JobManager) releaseSlot() {
+	if s.slots != nil {
+		<-s.slots
+	}
+}
+
+// recordRun stores the outcome of a run and advances the job's schedule.
+func (s *JobManager) recordRun(job CronJob, startMs int64, lastStatus string, lastErr *string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for i := range s.store.Jobs {
@@ -470,29 +627,48 @@ func (s *JobManager) executeJob(ctx context.Context, job CronJob) {
 // Persistence
 // --------------------------------------------------------------------------
 
+// backupPath is where the previous good version of jobs.json is kept.
+func (s *JobManager) backupPath() string { return s.storePath + ".bak" }
+
 func (s *JobManager) loadLocked() error {
 	if len(s.store.Jobs) > 0 {
 		return nil // already loaded
 	}
-	data, err := os.ReadFile(s.storePath)
+	st, err := readStore(s.storePath)
 	if os.IsNotExist(err) {
 		s.store = cronStore{Version: 1}
 		return nil
 	}
 	if err != nil {
-		return err
+		bak, bakErr := readStore(s.backupPath())
+		if bakErr != nil {
+			return err
+		}
+		slog.Warn("cron: jobs file unreadable, recovered from backup", "path", s.storePath, "err", err)
+		st = bak
+	}
+	s.store = st
+	return nil
+}
+
+func readStore(path string) (cronStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cronStore{}, err
 	}
 	var st cronStore
 	if err := json.Unmarshal(data, &st); err != nil {
-		return err
+		return cronStore{}, err
 	}
 	if st.Version == 0 {
 		st.Version = 1
 	}
-	s.store = st
-	return nil
+	return st, nil
 }
 
+// saveLocked atomically replaces jobs.json, first copying the current file to
+// jobs.json.bak if it is valid JSON so a corrupt write never loses every
+// schedule.
 func (s *JobManager) saveLocked() {
 	if err := os.MkdirAll(filepath.Dir(s.storePath), 0o755); err != nil {
 		slog.Warn("cron: mkdir failed", "err", err)
@@ -503,7 +679,12 @@ func (s *JobManager) saveLocked() {
 		slog.Warn("cron: marshal failed", "err", err)
 		return
 	}
-	if err := os.WriteFile(s.storePath, data, 0o644); err != nil {
+	if prev, err := os.ReadFile(s.storePath); err == nil && json.Valid(prev) {
+		if err := atomicfile.WriteFile(s.backupPath(), prev, 0o644); err != nil {
+			slog.Warn("cron: backup failed", "err", err)
+		}
+	}
+	if err := atomicfile.WriteFile(s.storePath, data, 0o644); err != nil {
 		slog.Warn("cron: write failed", "err", err)
 	}
 }
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
 )
 
 // newTestManager creates a JobManager backed by a temp file.
@@ -18,14 +21,22 @@ func newTestManager(t *testing.T) (*JobManager, string) {
 	return NewService(path), path
 }
 
-// startManager starts the manager in the background and returns a cancel func.
+// startManager starts the manager in the background and returns a cancel func
+// that waits for Start to return, so no save races the TempDir cleanup.
 func startManager(t *testing.T, m *JobManager) context.CancelFunc {
 	t.Helper()
 	ctx, cancel := context.WithCancel(context.Background())
-	go func() { _ = m.Start(ctx) }()
+	done := make(chan struct{})
+	go func() {
+		_ = m.Start(ctx)
+		close(done)
+	}()
 	// Give Start() a moment to arm timers.
 	time.Sleep(20 * time.Millisecond)
-	return cancel
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
 // ─── AddJob ────────────────────────────────────────────────────────────────
@@ -257,6 +268,25 @@ func TestPersistence_MissingFile(t *testing.T) {
 	}
 }
 
+func TestPersistence_RecoversFromBackup(t *testing.T) {
+	m, path := newTestManager(t)
+	first, _ := m.AddJob("first", "hello", "every", 5000, "", "", 0, false, "", "", false)
+	m.AddJob("second", "hello", "every", 5000, "", "", 0, false, "", "", false)
+
+	// The second save rotated the one-job file into the backup.
+	if _, err := os.Stat(path + ".bak"); err != nil {
+		t.Fatalf("expected backup file: %v", err)
+	}
+
+	// Simulate a torn write of the primary file.
+	os.WriteFile(path, []byte(`{"version":1,"jobs":[{"id":`), 0o644)
+
+	jobs := NewService(path).ListAllJobs(true)
+	if len(jobs) != 1 || jobs[0].ID != first {
+		t.Fatalf("expected the backed-up job %s, got %+v", first, jobs)
+	}
+}
+
 // ─── computeNextRun ────────────────────────────────────────────────────────
 
 func TestComputeNextRun_Every(t *testing.T) {
@@ -416,6 +446,121 @@ func TestRunJob_NotFound(t *testing.T) {
 	}
 }
 
+// ─── Concurrency ───────────────────────────────────────────────────────────
+
+func TestExecuteJob_MaxConcurrentSerializes(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.SetConcurrency(1, "queue")
+
+	var inFlight, peak, count atomic.Int32
+	m.OnJobFunc(func(_ context.Context, _ CronJob) (string, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		count.Add(1)
+		return "", nil
+	})
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, _ := m.AddJob("job", "msg", "every", 60000, "", "", 0, false, "", "", false)
+		ids = append(ids, id)
+	}
+
+	done := make(chan struct{})
+	for _, id := range ids {
+		go func(id string) {
+			m.RunJob(context.Background(), id, true)
+			done <- struct{}{}
+		}(id)
+	}
+	for range ids {
+		<-done
+	}
+
+	if n := count.Load(); n != 5 {
+		t.Errorf("expected 5 executions, got %d", n)
+	}
+	if p := peak.Load(); p != 1 {
+		t.Errorf("expected at most 1 concurrent execution, got %d", p)
+	}
+}
+
+func TestExecuteJob_SkipPolicyRecordsSkipped(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.SetConcurrency(1, "skip")
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	m.OnJobFunc(func(_ context.Context, _ CronJob) (string, error) {
+		close(started)
+		<-release
+		return "", nil
+	})
+
+	busy, _ := m.AddJob("busy", "msg", "every", 60000, "", "", 0, false, "", "", false)
+	skipped, _ := m.AddJob("skipped", "msg", "every", 60000, "", "", 0, false, "", "", false)
+
+	finished := make(chan struct{})
+	go func() {
+		m.RunJob(context.Background(), busy, true)
+		close(finished)
+	}()
+	<-started
+	m.RunJob(context.Background(), skipped, true)
+	close(release)
+	<-finished
+
+	for _, j := range m.ListAllJobs(true) {
+		if j.ID != skipped {
+			continue
+		}
+		if j.State.LastStatus == nil || *j.State.LastStatus != "skipped" {
+			t.Errorf("expected lastStatus=skipped, got %v", j.State.LastStatus)
+		}
+	}
+}
+
+func TestExecuteJob_CancelledWhileQueuedRecordsNothing(t *testing.T) {
+	m, _ := newTestManager(t)
+	m.SetConcurrency(1, "queue")
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	m.OnJobFunc(func(_ context.Context, _ CronJob) (string, error) {
+		close(started)
+		<-release
+		return "", nil
+	})
+
+	busy, _ := m.AddJob("busy", "msg", "every", 60000, "", "", 0, false, "", "", false)
+	queued, _ := m.AddJob("queued", "msg", "every", 60000, "", "", 0, false, "", "", false)
+
+	finished := make(chan struct{})
+	go func() {
+		m.RunJob(context.Background(), busy, true)
+		close(finished)
+	}()
+	<-started
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.RunJob(ctx, queued, true)
+	close(release)
+	<-finished
+
+	for _, j := range m.ListAllJobs(true) {
+		if j.ID == queued && j.State.LastStatus != nil {
+			t.Errorf("expected no recorded run, got lastStatus=%q", *j.State.LastStatus)
+		}
+	}
+}
+
 // ─── Timer firing ──────────────────────────────────────────────────────────
 
 func TestEveryJob_FiresAfterInterval(t *testing.T) {
@@ -461,7 +606,7 @@ func TestAtJob_FiresOnce(t *testing.T) {
 
 func TestAddJobFull_ReturnsJob(t *testing.T) {
 	m, _ := newTestManager(t)
-	job, err := m.AddJobFull("full", "msg", "every", 1000, "", "", 0, false, "", "", false)
+	job, err := m.AddJobFull("full", "msg", "every", 1000, "", "", 0, false, "", nil, false)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -472,3 +617,41 @@ func TestAddJobFull_ReturnsJob(t *testing.T) {
 		t.Error("expected non-empty id")
 	}
 }
+
+func TestAddJobFull_RecipientsRequireDeliver(t *testing.T) {
+	m, _ := newTestManager(t)
+	_, err := m.AddJobFull("digest", "msg", "every", 1000, "", "", 0, false, "telegram", []string{"1", "2"}, false)
+	if err == nil {
+		t.Fatal("expected error when recipients are given without deliver")
+	}
+}
+
+// ─── Deliver ───────────────────────────────────────────────────────────────
+
+func TestDeliver_FansOutToAllRecipients(t *testing.T) {
+	m, _ := newTestManager(t)
+	job, err := m.AddJobFull("digest", "msg", "cron", 0, "0 9 * * *", "", 0, true, "telegram", []string{"111", "222", "333"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Payload.To == nil || *job.Payload.To != "111" {
+		t.Errorf("expected To to hold the first recipient, got %v", job.Payload.To)
+	}
+
+	channelBus := bus.NewChannelBus(10)
+	if n := Deliver(channelBus, job, "daily digest"); n != 3 {
+		t.Fatalf("expected 3 deliveries, got %d", n)
+	}
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		msg := <-channelBus.Subscribe()
+		if msg.Channel() != "telegram" || msg.Content() != "daily digest" {
+			t.Errorf("unexpected message: %s %q", msg.Channel(), msg.Content())
+		}
+		got = append(got, msg.ChatId())
+	}
+	if strings.Join(got, ",") != "111,222,333" {
+		t.Errorf("unexpected recipients: %v", got)
+	}
+}
@@ -2,12 +2,20 @@ package dependency
 
 import (
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/dig"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/agent"
 	"github.com/crystaldolphin/crystaldolphin/internal/bus"
 	"github.com/crystaldolphin/crystaldolphin/internal/config"
+	agentcfg "github.com/crystaldolphin/crystaldolphin/internal/config/agent"
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
 	"github.com/crystaldolphin/crystaldolphin/internal/cron"
 	"github.com/crystaldolphin/crystaldolphin/internal/mcp"
 	"github.com/crystaldolphin/crystaldolphin/internal/providers"
@@ -98,9 +106,15 @@ func New(cfg *config.Config) (*ServiceContainer, error) {
 	if err := d.Provide(newContextBuilder); err != nil {
 		return nil, err
 	}
+	if err := d.Provide(newApprovalGate); err != nil {
+		return nil, err
+	}
 	if err := d.Provide(newMCPManager); err != nil {
 		return nil, err
 	}
+	if err := d.Provide(newAuditLog); err != nil {
+		return nil, err
+	}
 	if err := d.Provide(newAgentLoop); err != nil {
 		return nil, err
 	}
@@ -130,30 +144,65 @@ func newProvider(cfg *config.Config) (schema.LLMProvider, error) {
 	model := cfg.Agents.Defaults.Model
 	result := cfg.MatchProvider(model)
 
+	modelErr := providers.CheckModel(model)
 	if result.Provider == nil && !isOAuthProvider(result.Name) {
+		if modelErr != nil {
+			return nil, fmt.Errorf("%w — edit agents.defaults.model in %s", modelErr, config.ConfigPath())
+		}
 		return nil, fmt.Errorf("no API key configured for model %q — edit %s", model, config.ConfigPath())
 	}
+	if spec := providers.FindByName(result.Name); modelErr != nil && spec != nil && !spec.IsGateway && !spec.IsLocal && !spec.IsDirect {
+		// A fallback provider was picked for a model it likely doesn't serve.
+		slog.Warn("model not recognised; requests may fail", "provider", result.Name, "err", modelErr)
+	}
 
 	apiKey := ""
 	apiBase := ""
+	cacheTTL := ""
+	toolResultsAsUser := false
 	var extraHeaders map[string]string
 	if result.Provider != nil {
 		apiKey = result.Provider.APIKey
 		apiBase = result.Provider.APIBase
 		extraHeaders = result.Provider.ExtraHeaders
+		cacheTTL = result.Provider.CacheTTL
+		toolResultsAsUser = result.Provider.ToolResultsAsUser
 	}
 	if apiBase == "" {
 		apiBase = cfg.GetAPIBase(model)
 	}
 	return providers.New(providers.Params{
-		APIKey:       apiKey,
-		APIBase:      apiBase,
-		ExtraHeaders: extraHeaders,
-		DefaultModel: model,
-		ProviderName: result.Name,
+		APIKey:            apiKey,
+		APIBase:           apiBase,
+		ExtraHeaders:      extraHeaders,
+		DefaultModel:      model,
+		ProviderName:      result.Name,
+		CacheTTL:          cacheTTL,
+		DebugHTTPLog:      debugHTTPLog(cfg),
+		ToolResultsAsUser: toolResultsAsUser,
+		Breaker: providers.BreakerConfig{
+			Threshold: cfg.Providers.CircuitBreaker.FailureThreshold,
+			Window:    time.Duration(cfg.Providers.CircuitBreaker.WindowSeconds) * time.Second,
+			Cooldown:  time.Duration(cfg.Providers.CircuitBreaker.CooldownSeconds) * time.Second,
+		},
 	}), nil
 }
 
+// debugHTTPLog returns the provider HTTP debug log path, or "" when
+// debugging is off in both the config and CRYSTALDOLPHIN_DEBUG_HTTP.
+func debugHTTPLog(cfg *config.Config) string {
+	enabled := cfg.Providers.DebugHTTP
+	if v := os.Getenv("CRYSTALDOLPHIN_DEBUG_HTTP"); v != "" {
+		enabled, _ = strconv.ParseBool(v)
+	}
+	if !enabled {
+		return ""
+	}
+	path := filepath.Join(config.DataDir(), "logs", "http-debug.log")
+	slog.Warn("provider HTTP debug logging enabled", "path", path)
+	return path
+}
+
 func isOAuthProvider(name string) bool {
 	spec := providers.FindByName(name)
 	return spec != nil && spec.IsOAuth
@@ -171,14 +220,22 @@ func newConsoleBus() *bus.ConsoleBus {
 	return bus.NewConsoleBus(100)
 }
 
-func newSessionManager(cfg *config.Config) (*session.Manager, error) {
-	return session.NewManager(cfg.WorkspacePath())
+func newSessionManager(cfg *config.Config) (session.SessionStore, error) {
+	switch backend := cfg.Agents.Defaults.SessionStore; backend {
+	case "", agentcfg.SessionStoreJSONL:
+		return session.NewManager(cfg.WorkspacePath())
+	case agentcfg.SessionStoreMemory:
+		return session.NewInMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown session store %q", backend)
+	}
 }
 
 func newCronService(cfg *config.Config) *cron.JobManager {
 	cronPath := config.DataDir() + "/cron/jobs.json"
-	_ = cfg // reserved for future per-config cron settings
-	return cron.NewService(cronPath)
+	svc := cron.NewService(cronPath)
+	svc.SetConcurrency(cfg.Cron.MaxConcurrent, cfg.Cron.OverflowPolicy)
+	return svc
 }
 
 func resolveLLMModel(cfg *config.Config, p schema.LLMProvider) LLMModel {
@@ -190,23 +247,36 @@ func resolveLLMModel(cfg *config.Config, p schema.LLMProvider) LLMModel {
 	return LLMModel(m)
 }
 
-func newSubAgentToolRegistry(cfg *config.Config) SubagentRegistry {
+func newSubAgentToolRegistry(cfg *config.Config, p schema.LLMProvider, m LLMModel) SubagentRegistry {
 	workspace := cfg.WorkspacePath()
 	allowedDir := ""
 	if cfg.Tools.RestrictToWorkspace {
 		allowedDir = workspace
 	}
 
-	registry := tools.NewRegistryBuilder().
-		Tool(tools.NewReadFileTool(workspace, allowedDir)).
+	builder := tools.NewRegistryBuilder().
+		Tool(tools.NewReadFileTool(workspace, allowedDir, cfg.Tools.ReadFile.MaxBytes)).
 		Tool(tools.NewWriteFileTool(workspace, allowedDir)).
+		Tool(tools.NewAppendFileTool(workspace, allowedDir)).
 		Tool(tools.NewEditFileTool(workspace, allowedDir)).
-		Tool(tools.NewExecTool(workspace, cfg.Tools.Exec.Timeout, cfg.Tools.RestrictToWorkspace)).
-		Tool(tools.NewWebSearchTool(cfg.Tools.Web.Search.APIKey, cfg.Tools.Web.Search.MaxResults)).
-		Tool(tools.NewWebFetchTool(0)).
-		Build()
+		Tool(tools.NewMoveFileTool(workspace, allowedDir)).
+		Tool(tools.NewGrepTool(workspace, allowedDir, cfg.Tools.Grep.MaxMatches)).
+		Tool(tools.NewTreeTool(workspace, allowedDir)).
+		Tool(tools.NewGitTool(workspace, allowedDir)).
+		Tool(tools.NewExecTool(workspace, cfg.Tools.Exec, cfg.Tools.RestrictToWorkspace)).
+		Tool(tools.NewCalcTool()).
+		Tool(tools.NewDateTimeTool()).
+		Tool(tools.NewWebSearchTool(cfg.Tools.Web.Search)).
+		Tool(tools.NewWebFetchTool(cfg.Tools.Web.Fetch)).
+		Tool(tools.NewDownloadTool(workspace, allowedDir, cfg.Tools.Download))
+	if cfg.Tools.HTTP.Enabled {
+		builder.Tool(tools.NewHTTPRequestTool(cfg.Tools.HTTP))
+	}
+	if cfg.Tools.DescribeImage.Enabled {
+		builder.Tool(newDescribeImageTool(cfg, workspace, allowedDir, p, m))
+	}
 
-	return SubagentRegistry{registry}
+	return SubagentRegistry{builder.Build()}
 }
 
 func newAgentFactory(
@@ -215,6 +285,8 @@ func newAgentFactory(
 	m LLMModel,
 	subReg SubagentRegistry,
 	mcpMgr *mcp.Manager,
+	approvals *agent.ApprovalGate,
+	audit *agent.AuditLog,
 ) *agent.AgentFactory {
 	coreSettings := schema.NewAgentSettings(
 		string(m),
@@ -223,6 +295,11 @@ func newAgentFactory(
 		cfg.Agents.Defaults.MaxTokens,
 		cfg.Agents.Defaults.MemoryWindow,
 	)
+	coreSettings.MaxContinuations = cfg.Agents.Defaults.MaxContinuations
+	coreSettings.MaxToolResultBytes = cfg.Tools.MaxResultBytes
+	coreSettings.ToolResultLimits = cfg.Tools.ResultLimits
+	coreSettings.ToolTimeout = time.Duration(cfg.Tools.TimeoutSeconds) * time.Second
+	coreSettings.DryRun = cfg.Agents.Defaults.DryRun
 
 	subSettings := schema.NewAgentSettings(
 		string(m),
@@ -231,8 +308,13 @@ func newAgentFactory(
 		cfg.Agents.Defaults.MaxTokens,
 		0,
 	)
+	subSettings.MaxContinuations = cfg.Agents.Defaults.MaxContinuations
+	subSettings.MaxToolResultBytes = cfg.Tools.MaxResultBytes
+	subSettings.ToolResultLimits = cfg.Tools.ResultLimits
+	subSettings.ToolTimeout = time.Duration(cfg.Tools.TimeoutSeconds) * time.Second
+	subSettings.DryRun = cfg.Agents.Defaults.DryRun
 
-	return agent.NewFactory(p, coreSettings, subSettings, subReg.Registry, mcpMgr, cfg.WorkspacePath())
+	return agent.NewFactory(p, coreSettings, subSettings, subReg.Registry, mcpMgr, approvals, audit, cfg.WorkspacePath())
 }
 
 func newSubagentManager(factory *agent.AgentFactory, inbound *bus.AgentBus) *agent.SubagentManager {
@@ -241,10 +323,13 @@ func newSubagentManager(factory *agent.AgentFactory, inbound *bus.AgentBus) *age
 
 func newAgentRegistry(
 	cfg *config.Config,
+	p schema.LLMProvider,
+	m LLMModel,
 	outbound *bus.ChannelBus,
 	subMgr *agent.SubagentManager,
 	cronMgr *cron.JobManager,
 	mem schema.MemoryStore,
+	sessions session.SessionStore,
 ) AgentRegistry {
 	workspace := cfg.WorkspacePath()
 	allowedDir := ""
@@ -252,45 +337,121 @@ func newAgentRegistry(
 		allowedDir = workspace
 	}
 
-	registry := tools.NewRegistryBuilder().
-		Tool(tools.NewReadFileTool(workspace, allowedDir)).
+	builder := tools.NewRegistryBuilder().
+		Tool(tools.NewReadFileTool(workspace, allowedDir, cfg.Tools.ReadFile.MaxBytes)).
 		Tool(tools.NewWriteFileTool(workspace, allowedDir)).
+		Tool(tools.NewAppendFileTool(workspace, allowedDir)).
 		Tool(tools.NewEditFileTool(workspace, allowedDir)).
+		Tool(tools.NewDeleteFileTool(workspace, allowedDir)).
+		Tool(tools.NewMoveFileTool(workspace, allowedDir)).
+		Tool(tools.NewGrepTool(workspace, allowedDir, cfg.Tools.Grep.MaxMatches)).
+		Tool(tools.NewTreeTool(workspace, allowedDir)).
+		Tool(tools.NewGitTool(workspace, allowedDir)).
 		Tool(tools.NewListDirTool(workspace, allowedDir)).
-		Tool(tools.NewExecTool(workspace, cfg.Tools.Exec.Timeout, cfg.Tools.RestrictToWorkspace)).
-		Tool(tools.NewWebSearchTool(cfg.Tools.Web.Search.APIKey, cfg.Tools.Web.Search.MaxResults)).
-		Tool(tools.NewWebFetchTool(0)).
+		Tool(tools.NewExecTool(workspace, cfg.Tools.Exec, cfg.Tools.RestrictToWorkspace)).
+		Tool(tools.NewCalcTool()).
+		Tool(tools.NewDateTimeTool()).
+		Tool(tools.NewWebSearchTool(cfg.Tools.Web.Search)).
+		Tool(tools.NewWebFetchTool(cfg.Tools.Web.Fetch)).
+		Tool(tools.NewDownloadTool(workspace, allowedDir, cfg.Tools.Download)).
 		Tool(tools.NewMessageTool(outbound)).
 		Tool(tools.NewSpawnTool(subMgr)).
 		Tool(tools.NewCronTool(cronMgr)).
 		Tool(tools.NewSaveMemoryTool(mem)).
-		Build()
+		Tool(tools.NewSearchMemoryTool(mem)).
+		Tool(tools.NewEditMemoryTool(mem)).
+		Tool(tools.NewSearchSessionsTool(sessions, cfg.Agents.Defaults.WorkspaceScope))
+	if cfg.Tools.HTTP.Enabled {
+		builder.Tool(tools.NewHTTPRequestTool(cfg.Tools.HTTP))
+	}
+	if cfg.Tools.DescribeImage.Enabled {
+		builder.Tool(newDescribeImageTool(cfg, workspace, allowedDir, p, m))
+	}
 
-	return AgentRegistry{registry}
+	return AgentRegistry{builder.Build()}
+}
+
+// newDescribeImageTool uses tools.describeImage.model, or the agent's model.
+func newDescribeImageTool(cfg *config.Config, workspace, allowedDir string, p schema.LLMProvider, m LLMModel) *tools.DescribeImageTool {
+	model := cfg.Tools.DescribeImage.Model
+	if model == "" {
+		model = string(m)
+	}
+	return tools.NewDescribeImageTool(workspace, allowedDir, p, model)
 }
 
 func newMemoryStore(cfg *config.Config) (schema.MemoryStore, error) {
-	mem, err := agent.NewMemoryStore(cfg.WorkspacePath())
+	mem, err := agent.NewMemoryStoreWithEmbedder(cfg.WorkspacePath(), newEmbedder(cfg))
 	if err != nil || mem == nil {
 		return &agent.FileMemoryStore{}, nil
 	}
 	return mem, nil
 }
 
-func newCompactor(cfg *config.Config, mem schema.MemoryStore, saver *session.Manager, p schema.LLMProvider, m LLMModel, reg AgentRegistry) schema.MemoryCompactor {
-	return agent.NewCompactor(mem, saver, p, string(m), cfg.Agents.Defaults.MemoryWindow, reg.Registry)
+// newEmbedder returns the embedder for agents.defaults.embeddingModel, or nil
+// when none is configured or no provider key matches it.
+func newEmbedder(cfg *config.Config) schema.Embedder {
+	model := cfg.Agents.Defaults.EmbeddingModel
+	if model == "" {
+		return nil
+	}
+	result := cfg.MatchProvider(model)
+	if result.Provider == nil || result.Provider.APIKey == "" {
+		slog.Warn("No API key configured for embedding model; semantic memory recall disabled", "model", model)
+		return nil
+	}
+	spec := providers.FindByName(result.Name)
+	apiBase := result.Provider.APIBase
+	if apiBase == "" && spec != nil {
+		apiBase = spec.DefaultAPIBase
+	}
+	// Gateways route "provider/model" names; direct APIs want the bare model.
+	if spec == nil || !spec.IsGateway {
+		if _, bare, ok := strings.Cut(model, "/"); ok {
+			model = bare
+		}
+	}
+	return providers.NewOpenAIEmbedder(result.Provider.APIKey, apiBase, model, result.Provider.ExtraHeaders)
+}
+
+func newCompactor(cfg *config.Config, mem schema.MemoryStore, saver session.SessionStore, p schema.LLMProvider, m LLMModel, reg AgentRegistry) schema.MemoryCompactor {
+	model := string(m)
+	if cfg.Agents.Defaults.ConsolidationModel != "" {
+		model = cfg.Agents.Defaults.ConsolidationModel
+	}
+	return agent.NewCompactor(mem, saver, p, model, cfg.Agents.Defaults.MemoryWindow, cfg.Agents.Defaults.MaxMemoryChars, reg.Registry)
 }
 
 func newSkillsLoader(cfg *config.Config) schema.SkillLoader {
-	return agent.NewSkillsLoader(cfg.WorkspacePath(), "")
+	return agent.NewCachedSkillsLoader(agent.NewSkillsLoader(cfg.WorkspacePath(), ""))
 }
 
 func newContextBuilder(cfg *config.Config, mem schema.MemoryStore, sl schema.SkillLoader) *agent.PromptContext {
 	return agent.NewContextBuilder(cfg.WorkspacePath(), mem, sl)
 }
 
+func newApprovalGate(cfg *config.Config, outbound *bus.ChannelBus) *agent.ApprovalGate {
+	timeout := cfg.Tools.ApprovalTimeout
+	if timeout <= 0 {
+		timeout = toolcfg.DefaultToolConfigs().ApprovalTimeout
+	}
+	return agent.NewApprovalGate(cfg.Tools.RequireApproval, agent.NewBusApprover(outbound, time.Duration(timeout)*time.Second))
+}
+
+// newAuditLog opens the tool audit log; it is nil when tools.audit is off.
+func newAuditLog(cfg *config.Config) (*agent.AuditLog, error) {
+	if !cfg.Tools.Audit.Enabled {
+		return nil, nil
+	}
+	log, err := agent.NewAuditLog(cfg.AuditPath(), cfg.Tools.Audit.MaxFieldChars)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return log, nil
+}
+
 func newMCPManager(cfg *config.Config) *mcp.Manager {
-	return mcp.NewManager(cfg.Tools.MCPServers)
+	return mcp.NewManager(cfg.Tools.MCPServers, filepath.Join(config.DataDir(), "media"))
 }
 
 func newAgentLoop(
@@ -299,12 +460,22 @@ func newAgentLoop(
 	factory *agent.AgentFactory,
 	cfg *config.Config,
 	m LLMModel,
-	sessions *session.Manager,
+	sessions session.SessionStore,
 	consolidator schema.MemoryCompactor,
 	subMgr *agent.SubagentManager,
 	reg AgentRegistry,
 	cb *agent.PromptContext,
-) schema.AgentLooper {
+) (schema.AgentLooper, error) {
+	switch scope := cfg.Agents.Defaults.WorkspaceScope; scope {
+	case "", agentcfg.WorkspaceScopeShared:
+	case agentcfg.WorkspaceScopeChannel, agentcfg.WorkspaceScopeSession:
+		if cfg.Tools.Exec.Sandbox != toolcfg.ExecSandboxDocker {
+			slog.Warn("exec is disabled in scoped workspaces unless tools.exec.sandbox is docker", "workspaceScope", scope)
+		}
+	default:
+		return nil, fmt.Errorf("unknown workspace scope %q (want shared, channel or session)", scope)
+	}
+
 	settings := schema.NewAgentSettings(
 		string(m),
 		cfg.Agents.Defaults.MaxToolIter,
@@ -312,6 +483,14 @@ func newAgentLoop(
 		cfg.Agents.Defaults.MaxTokens,
 		cfg.Agents.Defaults.MemoryWindow,
 	)
-
-	return agent.NewAgentLoop(inbound, outbound, factory, settings, sessions, consolidator, reg.Registry, subMgr, cb)
+	settings.HistoryIncludeTools = cfg.Agents.Defaults.HistoryIncludeTools
+	settings.MaxContinuations = cfg.Agents.Defaults.MaxContinuations
+	settings.MaxToolResultBytes = cfg.Tools.MaxResultBytes
+	settings.ToolResultLimits = cfg.Tools.ResultLimits
+	settings.ToolTimeout = time.Duration(cfg.Tools.TimeoutSeconds) * time.Second
+	settings.MaxConcurrentTurns = cfg.Agents.Defaults.MaxConcurrentTurns
+	settings.WorkspaceScope = cfg.Agents.Defaults.WorkspaceScope
+	settings.DryRun = cfg.Agents.Defaults.DryRun
+
+	return agent.NewAgentLoop(inbound, outbound, factory, settings, sessions, consolidator, reg.Registry, subMgr, cb), nil
 }
@@ -0,0 +1,76 @@
+// Package logging configures the global slog logger from the logging config
+// section: level, text or JSON output, an optional rotated log file, and
+// redaction of secrets in every record.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	logcfg "github.com/crystaldolphin/crystaldolphin/internal/config/logging"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/logfile"
+)
+
+// FileName is the log file written under the logs dir when logging.file is set.
+const FileName = "crystaldolphin.log"
+
+// Setup installs the slog default handler described by cfg, writing to
+// stderr and, when cfg.File is set, to a rotated file in logDir. It returns
+// a function that closes the file. On error the default logger is unchanged.
+func Setup(cfg logcfg.LoggingConfig, logDir string) (func() error, error) {
+	var w io.Writer = os.Stderr
+	closeFn := func() error { return nil }
+	if cfg.File {
+		maxBytes := int64(cfg.MaxSizeMB) << 20
+		if maxBytes <= 0 {
+			maxBytes = 10 << 20
+		}
+		f := logfile.NewRotating(filepath.Join(logDir, FileName), maxBytes, max(cfg.MaxBackups, 1))
+		w = io.MultiWriter(os.Stderr, f)
+		closeFn = f.Close
+	}
+
+	h, err := NewHandler(cfg, w)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(slog.New(h))
+	return closeFn, nil
+}
+
+// NewHandler returns a text or JSON handler writing records at or above
+// cfg.Level to w, with secrets redacted.
+func NewHandler(cfg logcfg.LoggingConfig, w io.Writer) (slog.Handler, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	switch strings.ToLower(cfg.Format) {
+	case "", logcfg.FormatText:
+		return slog.NewTextHandler(w, opts), nil
+	case logcfg.FormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("logging.format: unknown format %q (want text or json)", cfg.Format)
+	}
+}
+
+// ParseLevel parses a level name; "" means info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("logging.level: unknown level %q (want debug, info, warn or error)", s)
+}
@@ -0,0 +1,32 @@
+package logging
+
+import (
+	"log/slog"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/redact"
+)
+
+// redactAttr is the handlers' ReplaceAttr: it blanks string values under
+// secret-looking keys and masks credentials inside any other string or
+// error value, including the message itself. Numeric attributes such as
+// prompt_tokens are left alone.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if redact.IsSecretKey(a.Key) {
+			return slog.String(a.Key, redact.Placeholder)
+		}
+		if s := redact.String(a.Value.String()); s != a.Value.String() {
+			return slog.String(a.Key, s)
+		}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			if s := redact.String(err.Error()); s != err.Error() {
+				return slog.String(a.Key, s)
+			}
+		} else if redact.IsSecretKey(a.Key) {
+			return slog.String(a.Key, redact.Placeholder)
+		}
+	}
+	return a
+}
@@ -5,8 +5,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os/exec"
 	"strings"
@@ -15,22 +17,52 @@ import (
 	"time"
 )
 
+// Supervision settings for stdio servers that exit unexpectedly.
+const (
+	maxRestartAttempts = 3
+	restartBackoff     = 200 * time.Millisecond // doubled after each failed attempt
+)
+
 // client manages JSON-RPC communication with a single MCP server (stdio or HTTP).
 type client struct {
 	name       string
 	cfg        ServerConfig
 	httpClient *http.Client
+	mediaDir   string // where image content from tool results is saved
 
-	// Stdio fields (non-nil when command-based)
+	// Stdio fields (non-nil when command-based). conn carries requests
+	// concurrently; mu guards conn and serialises restarts, so calls that
+	// need a restart wait for it. procMu guards cmd so close can kill the
+	// process while a restart holds mu.
 	cmd    *exec.Cmd
-	stdin  io.WriteCloser
-	stdout *bufio.Reader
+	conn   *rpcConn
+	procMu sync.Mutex
+	closed atomic.Bool
 
 	mu     sync.Mutex
 	nextID int64
 	ready  atomic.Bool
+
+	// hasResources records whether initialize advertised the resources
+	// capability. HTTP servers are never initialized and leave it false.
+	hasResources atomic.Bool
+
+	// sse is the open session when cfg.Transport is TransportSSE; mu
+	// serialises reconnects.
+	sse atomic.Pointer[sseSession]
+
+	// onToolsChanged, if set before connect, is called (in its own
+	// goroutine) when the server reports that its tool list changed.
+	onToolsChanged func()
 }
 
+// transportError reports that the stdio connection to the server broke
+// (write failed or stdout closed), as opposed to an error returned by the server.
+type transportError struct{ err error }
+
+func (e *transportError) Error() string { return e.err.Error() }
+func (e *transportError) Unwrap() error { return e.err }
+
 func newClient(name string, cfg ServerConfig) *client {
 	return &client{
 		name: name,
@@ -46,6 +78,9 @@ func (c *client) connect(ctx context.Context) error {
 	if c.cfg.Command != "" {
 		return c.connectStdio(ctx)
 	}
+	if c.cfg.URL != "" && c.cfg.Transport == TransportSSE {
+		return c.connectSSE(ctx)
+	}
 	if c.cfg.URL != "" {
 		// HTTP MCP: no persistent connection needed; just mark ready.
 		c.ready.Store(true)
@@ -55,38 +90,147 @@ func (c *client) connect(ctx context.Context) error {
 }
 
 func (c *client) connectStdio(ctx context.Context) error {
-	args := c.cfg.Args
-	c.cmd = exec.CommandContext(ctx, c.cfg.Command, args...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.startLocked(ctx); err != nil {
+		return err
+	}
+	c.ready.Store(true)
+	return nil
+}
+
+// initTimeout is how long the initialize handshake may take.
+func (c *client) initTimeout() time.Duration {
+	if c.cfg.InitTimeout > 0 {
+		return c.cfg.InitTimeout
+	}
+	return defaultInitTimeout
+}
+
+// startLocked launches the subprocess, starts the background reader for its
+// stdout, and runs initialize. The process is not bound to ctx, which only
+// limits initialization: the server is shared across turns and lives until
+// close. A server that does not answer initialize within initTimeout is killed.
+func (c *client) startLocked(ctx context.Context) error {
+	cmd := exec.Command(c.cfg.Command, c.cfg.Args...)
 	if c.cfg.Env != nil {
 		for k, v := range c.cfg.Env {
-			c.cmd.Env = append(c.cmd.Env, k+"="+v)
+			cmd.Env = append(cmd.Env, k+"="+v)
 		}
 	}
 
-	stdinPipe, err := c.cmd.StdinPipe()
+	stdinPipe, err := cmd.StdinPipe()
 	if err != nil {
 		return fmt.Errorf("stdin pipe: %w", err)
 	}
-	stdoutPipe, err := c.cmd.StdoutPipe()
+	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("stdout pipe: %w", err)
 	}
-	c.stdin = stdinPipe
-	c.stdout = bufio.NewReader(stdoutPipe)
-
-	if err := c.cmd.Start(); err != nil {
+	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("start MCP server: %w", err)
 	}
+	c.procMu.Lock()
+	c.cmd = cmd
+	c.procMu.Unlock()
 
-	// Initialize: send JSON-RPC initialize request.
-	if err := c.initialize(ctx); err != nil {
-		c.cmd.Process.Kill() //nolint:errcheck
+	var writeMu sync.Mutex
+	c.conn = newRPCConn(c, func(_ context.Context, msg any) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if _, err := fmt.Fprintf(stdinPipe, "%s\n", data); err != nil {
+			return &transportError{fmt.Errorf("write to MCP stdin: %w", err)}
+		}
+		return nil
+	})
+	go readStdio(stdoutPipe, c.conn)
+
+	timeout := c.initTimeout()
+	initCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := c.initialize(initCtx, c.conn); err != nil {
+		c.killProcess()
+		if ctx.Err() == nil && initCtx.Err() != nil {
+			return fmt.Errorf("initialize: no response within %s", timeout)
+		}
 		return fmt.Errorf("initialize: %w", err)
 	}
-	c.ready.Store(true)
 	return nil
 }
 
+// readStdio feeds the server's stdout to conn line by line until the pipe
+// closes, then fails any requests still waiting.
+func readStdio(stdout io.Reader, conn *rpcConn) {
+	sc := bufio.NewScanner(stdout)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	for sc.Scan() {
+		if line := bytes.TrimSpace(sc.Bytes()); len(line) > 0 {
+			conn.dispatch(line)
+		}
+	}
+	err := sc.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	conn.shutdown(&transportError{fmt.Errorf("read MCP stdout: %w", err)})
+}
+
+// restartLocked replaces a dead subprocess, retrying with backoff. The client
+// is not ready until it succeeds.
+func (c *client) restartLocked(ctx context.Context) error {
+	c.ready.Store(false)
+	c.killProcess()
+
+	var err error
+	backoff := restartBackoff
+	for attempt := 1; attempt <= maxRestartAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+		if c.closed.Load() {
+			return fmt.Errorf("client closed")
+		}
+		if err = c.startLocked(ctx); err == nil {
+			c.ready.Store(true)
+			slog.Info("MCP server restarted", "server", c.name, "attempt", attempt)
+			return nil
+		}
+		slog.Warn("MCP server restart failed", "server", c.name, "attempt", attempt, "err", err)
+	}
+	return err
+}
+
+// killProcess kills and reaps the current subprocess, if any.
+func (c *client) killProcess() {
+	c.procMu.Lock()
+	defer c.procMu.Unlock()
+	if c.cmd == nil || c.cmd.Process == nil {
+		return
+	}
+	c.cmd.Process.Kill() //nolint:errcheck
+	c.cmd.Wait()         //nolint:errcheck
+	c.cmd = nil
+}
+
+// close stops the subprocess (or closes the event stream) for good; it is
+// not restarted afterwards.
+func (c *client) close() {
+	c.closed.Store(true)
+	c.killProcess()
+	if s := c.sse.Load(); s != nil {
+		s.cancel()
+	}
+}
+
 // listTools returns the tools exposed by this MCP server.
 func (c *client) listTools(ctx context.Context) ([]map[string]any, error) {
 	resp, err := c.call(ctx, "tools/list", nil)
@@ -114,24 +258,14 @@ func (c *client) callTool(ctx context.Context, toolName string, args map[string]
 	}
 
 	var result struct {
-		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		} `json:"content"`
+		Content []contentBlock `json:"content"`
 	}
 
 	if err := json.Unmarshal(resp, &result); err != nil {
 		return string(resp), nil
 	}
 
-	var parts []string
-	for _, block := range result.Content {
-		if block.Text != "" {
-			parts = append(parts, block.Text)
-		}
-	}
-
-	out := strings.Join(parts, "\n")
+	out := renderContent(result.Content, c.mediaDir)
 	if out == "" {
 		out = "(no output)"
 	}
@@ -142,24 +276,31 @@ func (c *client) callTool(ctx context.Context, toolName string, args map[string]
 // JSON-RPC plumbing
 // ---------------------------------------------------------------------------
 
-func (c *client) initialize(ctx context.Context) error {
-	params := map[string]any{
+// initialize runs the initialize handshake over conn and records the
+// server's capabilities.
+func (c *client) initialize(ctx context.Context, conn *rpcConn) error {
+	resp, err := conn.request(ctx, "initialize", map[string]any{
 		"protocolVersion": "2024-11-05",
 		"capabilities":    map[string]any{},
 		"clientInfo":      map[string]any{"name": "crystaldolphin", "version": "1.0"},
-	}
-	_, err := c.call(ctx, "initialize", params)
+	})
 	if err != nil {
 		return err
 	}
-	// Send initialized notification (no response expected)
-	notif := map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"}
-	data, _ := json.Marshal(notif)
-	_, _ = fmt.Fprintf(c.stdin, "%s\n", data)
-	return nil
+	var result struct {
+		Capabilities struct {
+			Resources json.RawMessage `json:"resources"`
+		} `json:"capabilities"`
+	}
+	_ = json.Unmarshal(resp, &result)
+	c.hasResources.Store(result.Capabilities.Resources != nil)
+	return conn.notify(ctx, "notifications/initialized")
 }
 
 func (c *client) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	if c.cfg.URL != "" && c.cfg.Transport == TransportSSE {
+		return c.callSSE(ctx, method, params)
+	}
 	if c.cfg.URL != "" {
 		return c.callHTTP(ctx, method, params)
 	}
@@ -170,8 +311,8 @@ func (c *client) nextRequestID() int64 {
 	return atomic.AddInt64(&c.nextID, 1)
 }
 
-func (c *client) callStdio(ctx context.Context, method string, params any) (json.RawMessage, error) {
-	id := c.nextRequestID()
+// newRequest builds a JSON-RPC request envelope.
+func newRequest(id int64, method string, params any) map[string]any {
 	req := map[string]any{
 		"jsonrpc": "2.0",
 		"id":      id,
@@ -180,70 +321,54 @@ func (c *client) callStdio(ctx context.Context, method string, params any) (json
 	if params != nil {
 		req["params"] = params
 	}
-	data, err := json.Marshal(req)
+	return req
+}
+
+// callStdio sends one request to the subprocess. If the connection has
+// broken (the server crashed or exited), the server is restarted and the
+// call retried once.
+func (c *client) callStdio(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	conn, err := c.stdioConn(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	resp, err := conn.request(ctx, method, params)
+	var te *transportError
+	if !errors.As(err, &te) || c.closed.Load() {
+		return resp, err
+	}
+
+	slog.Warn("MCP server connection lost; restarting", "server", c.name, "err", err)
+	if conn, err = c.stdioConn(ctx, conn); err != nil {
+		return nil, fmt.Errorf("MCP server %q crashed and could not be restarted: %w", c.name, err)
+	}
+	return conn.request(ctx, method, params)
+}
 
+// stdioConn returns the live connection, first restarting the server if an
+// earlier restart gave up or if the current connection is broken, the one
+// the caller saw fail. Concurrent callers that hit the same broken connection
+// restart it only once.
+func (c *client) stdioConn(ctx context.Context, broken *rpcConn) (*rpcConn, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-
-	if _, err := fmt.Fprintf(c.stdin, "%s\n", data); err != nil {
-		return nil, fmt.Errorf("write to MCP stdin: %w", err)
+	if c.closed.Load() {
+		return nil, fmt.Errorf("MCP server %q: client closed", c.name)
 	}
-
-	// Read response lines until we get one with our id.
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-		line, err := c.stdout.ReadString('\n')
-		if err != nil {
-			return nil, fmt.Errorf("read MCP stdout: %w", err)
-		}
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		var resp map[string]any
-		if err := json.Unmarshal([]byte(line), &resp); err != nil {
-			continue // skip non-JSON lines (server log output)
-		}
-		// Check ID match.
-		respID, _ := resp["id"]
-		switch v := respID.(type) {
-		case float64:
-			if int64(v) != id {
-				continue
+	if !c.ready.Load() || (broken != nil && c.conn == broken) {
+		if err := c.restartLocked(ctx); err != nil {
+			if broken != nil {
+				return nil, err
 			}
-		case int64:
-			if v != id {
-				continue
-			}
-		default:
-			continue
-		}
-		if errObj, ok := resp["error"]; ok {
-			return nil, fmt.Errorf("MCP error: %v", errObj)
+			return nil, fmt.Errorf("MCP server %q is not running: %w", c.name, err)
 		}
-		result, _ := json.Marshal(resp["result"])
-		return json.RawMessage(result), nil
 	}
+	return c.conn, nil
 }
 
 func (c *client) callHTTP(ctx context.Context, method string, params any) (json.RawMessage, error) {
 	id := c.nextRequestID()
-	req := map[string]any{
-		"jsonrpc": "2.0",
-		"id":      id,
-		"method":  method,
-	}
-	if params != nil {
-		req["params"] = params
-	}
-	data, err := json.Marshal(req)
+	data, err := json.Marshal(newRequest(id, method, params))
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +378,7 @@ func (c *client) callHTTP(ctx context.Context, method string, params any) (json.
 		return nil, err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
 	for k, v := range c.cfg.Headers {
 		httpReq.Header.Set(k, v)
 	}
@@ -263,6 +389,12 @@ func (c *client) callHTTP(ctx context.Context, method string, params any) (json.
 	}
 	defer resp.Body.Close()
 
+	// Streamable HTTP servers may answer with an event stream instead of a
+	// single JSON body.
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return c.readSSEResponse(resp.Body, id)
+	}
+
 	var rpcResp map[string]any
 	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
 		return nil, err
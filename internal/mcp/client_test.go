@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFakeStdioServer is not a real test: when MCP_FAKE_SERVER is set, the
+// test binary acts as a minimal stdio MCP server for TestStdio_* below.
+func TestFakeStdioServer(t *testing.T) {
+	if os.Getenv("MCP_FAKE_SERVER") != "1" {
+		t.Skip("helper process")
+	}
+	if os.Getenv("MCP_FAKE_SILENT") == "1" {
+		io.Copy(io.Discard, os.Stdin) //nolint:errcheck // never answer anything
+		os.Exit(0)
+	}
+	tools := []map[string]any{{"name": "ping", "description": "Reply with pong"}}
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		var req struct {
+			ID     *int64 `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Name   string `json:"name"`
+				URI    string `json:"uri"`
+				Cursor string `json:"cursor"`
+			} `json:"params"`
+		}
+		if json.Unmarshal(sc.Bytes(), &req) != nil || req.ID == nil {
+			continue // notification
+		}
+		var result any
+		switch req.Method {
+		case "initialize":
+			if d, err := time.ParseDuration(os.Getenv("MCP_FAKE_DELAY")); err == nil {
+				time.Sleep(d)
+			}
+			if f, err := os.OpenFile(os.Getenv("MCP_FAKE_LOG"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err == nil {
+				fmt.Fprintln(f, "initialize")
+				f.Close()
+			}
+			result = map[string]any{
+				"protocolVersion": "2024-11-05",
+				"capabilities":    map[string]any{"tools": map[string]any{}, "resources": map[string]any{}},
+			}
+		case "tools/list":
+			result = map[string]any{"tools": tools}
+		case "tools/call":
+			if req.Params.Name == "swap" {
+				// Replace ping with echo and announce it, as servers do at runtime.
+				tools = []map[string]any{{"name": "echo", "description": "Echo"}}
+				fmt.Println(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)
+			}
+			result = map[string]any{"content": []map[string]any{
+				{"type": "text", "text": fmt.Sprintf("pong from %d", os.Getpid())},
+			}}
+		case "resources/list":
+			if req.Params.Cursor == "" {
+				result = map[string]any{
+					"resources":  []map[string]any{{"uri": "file:///notes.md", "name": "notes", "mimeType": "text/markdown"}},
+					"nextCursor": "page2",
+				}
+			} else {
+				result = map[string]any{
+					"resources": []map[string]any{{"uri": "file:///logo.bin", "description": "Company logo"}},
+				}
+			}
+		case "resources/read":
+			content := map[string]any{"uri": req.Params.URI, "mimeType": "text/markdown", "text": "# Notes"}
+			if req.Params.URI == "file:///logo.bin" {
+				content = map[string]any{"uri": req.Params.URI, "mimeType": "application/octet-stream", "blob": "AAEC"}
+			}
+			result = map[string]any{"contents": []map[string]any{content}}
+		default:
+			result = map[string]any{}
+		}
+		out, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": *req.ID, "result": result})
+		fmt.Println(string(out))
+	}
+	os.Exit(0)
+}
+
+func TestStdio_RestartsCrashedServer(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "init.log")
+	c := newClient("fake", ServerConfig{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeStdioServer$"},
+		Env:     map[string]string{"MCP_FAKE_SERVER": "1", "MCP_FAKE_LOG": logPath},
+	})
+	ctx := context.Background()
+	if err := c.connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+
+	first, err := c.callTool(ctx, "ping", nil)
+	if err != nil || !strings.HasPrefix(first, "pong from ") {
+		t.Fatalf("unexpected first result %q, err %v", first, err)
+	}
+
+	// Crash the server behind the client's back.
+	c.procMu.Lock()
+	proc := c.cmd.Process
+	c.procMu.Unlock()
+	if err := proc.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	proc.Wait() //nolint:errcheck
+
+	second, err := c.callTool(ctx, "ping", nil)
+	if err != nil {
+		t.Fatalf("expected call to recover after restart: %v", err)
+	}
+	if second == first || !strings.HasPrefix(second, "pong from ") {
+		t.Errorf("expected a reply from a new process, got %q then %q", first, second)
+	}
+	if !c.ready.Load() {
+		t.Error("client should be ready after restart")
+	}
+	data, _ := os.ReadFile(logPath)
+	if n := strings.Count(string(data), "initialize"); n != 2 {
+		t.Errorf("expected initialize to run twice, ran %d times", n)
+	}
+}
+
+func TestStdio_ClosedClientIsNotRestarted(t *testing.T) {
+	c := newClient("fake", ServerConfig{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeStdioServer$"},
+		Env:     map[string]string{"MCP_FAKE_SERVER": "1", "MCP_FAKE_LOG": filepath.Join(t.TempDir(), "log")},
+	})
+	if err := c.connect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.close()
+
+	if _, err := c.callTool(context.Background(), "ping", nil); err == nil {
+		t.Error("expected closed client to fail instead of restarting")
+	}
+	c.procMu.Lock()
+	defer c.procMu.Unlock()
+	if c.cmd != nil {
+		t.Error("closed client should not have a running process")
+	}
+}
+
+func TestStdio_InitializeTimeout(t *testing.T) {
+	c := newClient("silent", ServerConfig{
+		Command:     os.Args[0],
+		Args:        []string{"-test.run=^TestFakeStdioServer$"},
+		Env:         map[string]string{"MCP_FAKE_SERVER": "1", "MCP_FAKE_SILENT": "1"},
+		InitTimeout: 200 * time.Millisecond,
+	})
+	start := time.Now()
+	err := c.connect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no response within 200ms") {
+		t.Fatalf("expected initialize timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("connect took %s despite a 200ms timeout", elapsed)
+	}
+	c.procMu.Lock()
+	defer c.procMu.Unlock()
+	if c.cmd != nil {
+		t.Error("unresponsive server should have been killed")
+	}
+}
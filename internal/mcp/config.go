@@ -1,10 +1,21 @@
 package mcp
 
+import "time"
+
+// defaultInitTimeout bounds the initialize handshake when ServerConfig.InitTimeout is unset.
+const defaultInitTimeout = 10 * time.Second
+
+// TransportSSE selects the HTTP+SSE transport: requests are POSTed to the
+// endpoint announced on an event stream, and replies arrive on that stream.
+const TransportSSE = "sse"
+
 // ServerConfig holds the connection parameters for a single MCP server.
 type ServerConfig struct {
-	Command string
-	Args    []string
-	Env     map[string]string
-	URL     string
-	Headers map[string]string
+	Command     string
+	Args        []string
+	Env         map[string]string
+	URL         string
+	Headers     map[string]string
+	Transport   string        // "" or TransportSSE; only used with URL
+	InitTimeout time.Duration // 0 means defaultInitTimeout
 }
@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 
 	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
@@ -12,81 +15,229 @@ import (
 
 // Manager owns the lifecycle of all MCP server connections for a single agent.
 type Manager struct {
-	servers map[string]toolcfg.MCPServerConfig
-	clients []*client
-	once    sync.Once
+	servers  map[string]toolcfg.MCPServerConfig
+	mediaDir string
+	parallel int // connect worker limit
+	once     sync.Once
+
+	// mu guards the registration state below. ConnectOnce holds it until
+	// every server is registered, so a tool refresh triggered meanwhile waits.
+	mu        sync.Mutex
+	ts        schema.ToolRegistrar
+	clients   []*client
+	toolNames map[*client]map[string]string // MCP tool name -> registered name
 }
 
+// maxParallelConnects bounds how many servers start at once, so a large
+// config doesn't spawn every subprocess simultaneously.
+const maxParallelConnects = 4
+
+// refreshTimeout bounds the tools/list call made after a list_changed notification.
+const refreshTimeout = 30 * time.Second
+
 // NewManager returns a Manager configured with the given MCP servers.
-func NewManager(servers map[string]toolcfg.MCPServerConfig) *Manager {
-	return &Manager{servers: servers}
+// Images returned by MCP tools are saved under mediaDir.
+func NewManager(servers map[string]toolcfg.MCPServerConfig, mediaDir string) *Manager {
+	return &Manager{servers: servers, mediaDir: mediaDir, parallel: maxParallelConnects}
 }
 
 // ConnectOnce connects to all configured MCP servers and registers their
 // discovered tools into ts. It is safe to call concurrently; connection happens
 // at most once. Failed servers are logged and skipped (non-fatal).
+//
+// Servers connect in parallel (at most maxParallelConnects at a time), so one
+// slow or unresponsive server only costs its own initialize timeout. Tools
+// are then registered in server name order as <prefix>_<tool>, sanitized for
+// providers; a name already taken gets a numeric suffix, which stays stable
+// across restarts.
 func (m *Manager) ConnectOnce(ctx context.Context, ts schema.ToolRegistrar) {
 	m.once.Do(func() {
-		for name, cfg := range m.servers {
-			c := newClient(name, toServerConfig(cfg))
-			if err := c.connect(ctx); err != nil {
-				slog.Error("MCP server connect failed", "server", name, "err", err)
-				continue
-			}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.ts = ts
+		m.toolNames = map[*client]map[string]string{}
 
-			toolDefs, err := c.listTools(ctx)
-			if err != nil {
-				slog.Error("MCP server list_tools failed", "server", name, "err", err)
-				continue
-			}
+		names := slices.Sorted(maps.Keys(m.servers))
+		servers := make([]*connectedServer, len(names))
+		sem := make(chan struct{}, max(m.parallel, 1))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				servers[i] = m.connectServer(ctx, name)
+			}()
+		}
+		wg.Wait()
 
-			for _, toolDef := range toolDefs {
-				toolName, _ := toolDef["name"].(string)
-				if toolName == "" {
-					continue
-				}
-				desc, _ := toolDef["description"].(string)
-				inputSchema, _ := toolDef["inputSchema"].(map[string]any)
-				if inputSchema == nil {
-					inputSchema = map[string]any{"type": "object", "properties": map[string]any{}}
-				}
-
-				schemaBytes, _ := json.Marshal(inputSchema)
-
-				w := &toolWrapper{
-					client:      c,
-					name:        "mcp_" + name + "_" + toolName,
-					origName:    toolName,
-					description: desc,
-					parameters:  json.RawMessage(schemaBytes),
-				}
-
-				ts.Add(w)
-
-				slog.Debug("MCP tool registered", "server", name, "tool", w.name)
+		for i, name := range names {
+			if s := servers[i]; s != nil {
+				m.register(name, s)
 			}
-			slog.Info("MCP server connected", "server", name, "tools", len(toolDefs))
-			m.clients = append(m.clients, c)
 		}
 	})
 }
 
+// connectedServer is a server that connected and listed its tools.
+type connectedServer struct {
+	client    *client
+	toolDefs  []map[string]any
+	resources bool
+}
+
+// connectServer connects one server and discovers its tools and resource
+// support. It returns nil, after logging, if the server is unusable.
+func (m *Manager) connectServer(ctx context.Context, name string) *connectedServer {
+	start := time.Now()
+	c := newClient(name, toServerConfig(m.servers[name]))
+	c.mediaDir = m.mediaDir
+	c.onToolsChanged = func() { m.refreshTools(c) }
+	if err := c.connect(ctx); err != nil {
+		slog.Error("MCP server connect failed", "server", name, "elapsed", time.Since(start), "err", err)
+		return nil
+	}
+
+	toolDefs, err := c.listTools(ctx)
+	if err != nil {
+		slog.Error("MCP server list_tools failed", "server", name, "elapsed", time.Since(start), "err", err)
+		c.close()
+		return nil
+	}
+	s := &connectedServer{client: c, toolDefs: toolDefs, resources: supportsResources(ctx, c)}
+	slog.Debug("MCP server ready", "server", name, "elapsed", time.Since(start))
+	return s
+}
+
+// register adds a connected server's tools to m.ts. The caller holds mu.
+func (m *Manager) register(name string, s *connectedServer) {
+	c := s.client
+	prefix := toolPrefix(name, m.servers[name].Prefix)
+	names := map[string]string{}
+	for _, toolDef := range s.toolDefs {
+		w := newToolWrapper(c, toolDef)
+		if w == nil {
+			continue
+		}
+		w.name = uniqueToolName(m.ts, name, prefix, w.origName)
+		names[w.origName] = w.name
+		m.ts.Add(w)
+
+		slog.Debug("MCP tool registered", "server", name, "tool", w.name)
+	}
+	m.toolNames[c] = names
+	if s.resources {
+		list := &listResourcesTool{client: c, name: uniqueToolName(m.ts, name, prefix, "list_resources")}
+		m.ts.Add(list)
+		read := &readResourceTool{client: c, name: uniqueToolName(m.ts, name, prefix, "read_resource")}
+		m.ts.Add(read)
+		list.readName, read.listName = read.name, list.name
+		slog.Debug("MCP resource tools registered", "server", name)
+	}
+	slog.Info("MCP server connected", "server", name, "tools", len(s.toolDefs))
+	m.clients = append(m.clients, c)
+}
+
+// refreshTools re-lists c's tools after a tools/list_changed notification:
+// new tools are registered, changed ones replaced under their existing name,
+// and tools the server no longer offers are removed.
+func (m *Manager) refreshTools(c *client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names, ok := m.toolNames[c]
+	if !ok || c.closed.Load() {
+		return // the server failed to connect, or the manager is closed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	toolDefs, err := c.listTools(ctx)
+	if err != nil {
+		slog.Warn("MCP tool refresh failed", "server", c.name, "err", err)
+		return
+	}
+
+	prefix := toolPrefix(c.name, m.servers[c.name].Prefix)
+	seen := map[string]bool{}
+	var added, removed int
+	for _, toolDef := range toolDefs {
+		w := newToolWrapper(c, toolDef)
+		if w == nil {
+			continue
+		}
+		seen[w.origName] = true
+		if name, ok := names[w.origName]; ok {
+			w.name = name
+		} else {
+			w.name = uniqueToolName(m.ts, c.name, prefix, w.origName)
+			names[w.origName] = w.name
+			added++
+		}
+		m.ts.Add(w)
+	}
+	for origName, name := range names {
+		if !seen[origName] {
+			m.ts.Remove(name)
+			delete(names, origName)
+			removed++
+		}
+	}
+	slog.Info("MCP tools refreshed", "server", c.name, "tools", len(names), "added", added, "removed", removed)
+}
+
+// newToolWrapper builds an (unnamed) wrapper from a tools/list entry, or
+// returns nil if the entry has no name.
+func newToolWrapper(c *client, toolDef map[string]any) *toolWrapper {
+	toolName, _ := toolDef["name"].(string)
+	if toolName == "" {
+		return nil
+	}
+	desc, _ := toolDef["description"].(string)
+	inputSchema, _ := toolDef["inputSchema"].(map[string]any)
+	if inputSchema == nil {
+		inputSchema = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+
+	schemaBytes, _ := json.Marshal(inputSchema)
+
+	return &toolWrapper{
+		client:      c,
+		origName:    toolName,
+		description: desc,
+		parameters:  json.RawMessage(schemaBytes),
+	}
+}
+
+// supportsResources reports whether c's server exposes resources. Stdio and
+// SSE servers say so in initialize; plain HTTP servers are not initialized,
+// so a successful resources/list is taken as support.
+func supportsResources(ctx context.Context, c *client) bool {
+	if c.cfg.URL != "" && c.cfg.Transport != TransportSSE {
+		_, err := c.listResources(ctx)
+		return err == nil
+	}
+	return c.hasResources.Load()
+}
+
 // Close stops all subprocess-based MCP servers owned by this manager.
 func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	for _, c := range m.clients {
-		if c.cmd != nil && c.cmd.Process != nil {
-			c.cmd.Process.Kill() //nolint:errcheck
-		}
+		c.close()
 	}
 }
 
 // toServerConfig converts a config-layer MCPServerConfig to the internal ServerConfig.
 func toServerConfig(c toolcfg.MCPServerConfig) ServerConfig {
 	return ServerConfig{
-		Command: c.Command,
-		Args:    c.Args,
-		Env:     c.Env,
-		URL:     c.URL,
-		Headers: c.Headers,
+		Command:     c.Command,
+		Args:        c.Args,
+		Env:         c.Env,
+		URL:         c.URL,
+		Headers:     c.Headers,
+		Transport:   c.Transport,
+		InitTimeout: time.Duration(c.InitTimeout) * time.Second,
 	}
 }
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+func TestConnect_UnresponsiveServersDoNotBlockOthers(t *testing.T) {
+	server := func(silent string) toolcfg.MCPServerConfig {
+		return toolcfg.MCPServerConfig{
+			Command:     os.Args[0],
+			Args:        []string{"-test.run=^TestFakeStdioServer$"},
+			Env:         map[string]string{"MCP_FAKE_SERVER": "1", "MCP_FAKE_SILENT": silent, "MCP_FAKE_LOG": filepath.Join(t.TempDir(), "log")},
+			InitTimeout: 1,
+		}
+	}
+	m := NewManager(map[string]toolcfg.MCPServerConfig{
+		"hung1": server("1"),
+		"hung2": server("1"),
+		"ok":    server(""),
+	}, t.TempDir())
+	defer m.Close()
+
+	ts := tools.NewToolList()
+	start := time.Now()
+	m.ConnectOnce(context.Background(), ts)
+	if elapsed := time.Since(start); elapsed > 1900*time.Millisecond {
+		t.Errorf("servers should time out in parallel, ConnectOnce took %s", elapsed)
+	}
+	if ts.Get("mcp_ok_ping") == nil {
+		t.Errorf("healthy server's tools should be registered, got %v", toolNames(ts))
+	}
+	if ts.Get("mcp_hung1_ping") != nil || len(m.clients) != 1 {
+		t.Errorf("unresponsive servers should be skipped, got %d clients", len(m.clients))
+	}
+}
+
+func TestConnect_RefreshesToolsOnListChanged(t *testing.T) {
+	m := NewManager(map[string]toolcfg.MCPServerConfig{"fake": {
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeStdioServer$"},
+		Env:     map[string]string{"MCP_FAKE_SERVER": "1", "MCP_FAKE_LOG": filepath.Join(t.TempDir(), "log")},
+	}}, t.TempDir())
+	defer m.Close()
+
+	ts := tools.NewToolList()
+	ctx := context.Background()
+	m.ConnectOnce(ctx, ts)
+	if ts.Get("mcp_fake_ping") == nil {
+		t.Fatalf("expected initial tool, got %v", toolNames(ts))
+	}
+
+	// The server swaps its tools and sends notifications/tools/list_changed.
+	if _, err := m.clients[0].callTool(ctx, "swap", nil); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for ts.Get("mcp_fake_echo") == nil || ts.Get("mcp_fake_ping") != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("tool list not refreshed, got %v", toolNames(ts))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ts.Get("mcp_fake_list_resources") == nil {
+		t.Error("resource tools should survive a tool refresh")
+	}
+}
+
+func TestConnect_RegistersAllServersRegardlessOfConnectOrder(t *testing.T) {
+	servers := map[string]toolcfg.MCPServerConfig{}
+	// Later names start faster, so servers finish connecting in reverse order.
+	for i, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		servers[name] = toolcfg.MCPServerConfig{
+			Command: os.Args[0],
+			Args:    []string{"-test.run=^TestFakeStdioServer$"},
+			Env: map[string]string{
+				"MCP_FAKE_SERVER": "1",
+				"MCP_FAKE_LOG":    filepath.Join(t.TempDir(), "log"),
+				"MCP_FAKE_DELAY":  (time.Duration(6-i) * 20 * time.Millisecond).String(),
+			},
+		}
+	}
+	m := NewManager(servers, t.TempDir())
+	m.parallel = 2
+	defer m.Close()
+
+	ts := tools.NewToolList()
+	m.ConnectOnce(context.Background(), ts)
+
+	var pings []string
+	for _, name := range toolNames(ts) {
+		if strings.HasSuffix(name, "_ping") {
+			pings = append(pings, name)
+		}
+	}
+	want := []string{"mcp_a_ping", "mcp_b_ping", "mcp_c_ping", "mcp_d_ping", "mcp_e_ping", "mcp_f_ping"}
+	if !slices.Equal(pings, want) {
+		t.Errorf("got tools %v, want %v in name order", pings, want)
+	}
+	if len(m.clients) != len(servers) {
+		t.Errorf("expected %d connected clients, got %d", len(servers), len(m.clients))
+	}
+}
+
+// toolNames lists the registered tool names, for failure messages.
+func toolNames(ts *tools.ToolList) []string {
+	var names []string
+	for _, def := range ts.Definitions() {
+		names = append(names, def["function"].(map[string]any)["name"].(string))
+	}
+	return names
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// contentBlock is one entry of a tools/call result's "content" array.
+// Only the fields used for text, image, and resource blocks are decoded.
+type contentBlock struct {
+	Type     string           `json:"type"` // text | image | resource | resource_link | audio
+	Text     string           `json:"text"`
+	Data     string           `json:"data"` // base64, for image blocks
+	MimeType string           `json:"mimeType"`
+	URI      string           `json:"uri"` // resource_link
+	Resource *embeddedContent `json:"resource"`
+}
+
+// embeddedContent is the payload of a "resource" block.
+type embeddedContent struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Blob     string `json:"blob"` // base64
+}
+
+// imageExts maps image MIME types to file extensions for saved images.
+var imageExts = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
+// renderContent flattens result blocks into the tool's string result. Text is
+// joined as-is; images are written to mediaDir and referenced by path so the
+// agent can attach them (e.g. via the message tool's media argument).
+func renderContent(blocks []contentBlock, mediaDir string) string {
+	var parts []string
+	for _, b := range blocks {
+		switch b.Type {
+		case "image":
+			parts = append(parts, saveImageRef(b.Data, b.MimeType, "", mediaDir))
+		case "resource":
+			if b.Resource == nil {
+				continue
+			}
+			switch {
+			case b.Resource.Text != "":
+				parts = append(parts, b.Resource.Text)
+			case b.Resource.Blob != "" && strings.HasPrefix(b.Resource.MimeType, "image/"):
+				parts = append(parts, saveImageRef(b.Resource.Blob, b.Resource.MimeType, b.Resource.URI, mediaDir))
+			case b.Resource.URI != "":
+				parts = append(parts, fmt.Sprintf("[resource: %s]", b.Resource.URI))
+			}
+		case "resource_link":
+			if b.URI == "" {
+				continue
+			}
+			if strings.HasPrefix(b.MimeType, "image/") {
+				parts = append(parts, fmt.Sprintf("[image: %s]", b.URI))
+			} else {
+				parts = append(parts, fmt.Sprintf("[resource: %s]", b.URI))
+			}
+		default:
+			if b.Text != "" {
+				parts = append(parts, b.Text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// saveImageRef decodes base64 image data into mediaDir and returns a line
+// referencing the saved file, or a note explaining why it was not saved.
+func saveImageRef(data, mimeType, uri, mediaDir string) string {
+	path, err := saveImage(data, mimeType, mediaDir)
+	if err != nil {
+		slog.Warn("MCP image not saved", "mime", mimeType, "err", err)
+		if uri != "" {
+			return fmt.Sprintf("[image: %s (not saved: %s)]", uri, err)
+		}
+		return fmt.Sprintf("[image omitted: %s]", err)
+	}
+	return fmt.Sprintf("[image saved: %s (%s)]", path, mimeType)
+}
+
+// saveImage writes base64 data to mediaDir under a content-addressed name,
+// so repeated results reuse the same file.
+func saveImage(data, mimeType, mediaDir string) (string, error) {
+	if mediaDir == "" {
+		return "", fmt.Errorf("no media directory configured")
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64: %w", err)
+	}
+	ext, ok := imageExts[mimeType]
+	if !ok {
+		ext = ".img"
+	}
+	sum := sha256.Sum256(raw)
+	path := filepath.Join(mediaDir, "mcp_"+hex.EncodeToString(sum[:8])+ext)
+	if err := os.MkdirAll(mediaDir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// fakeToolServer answers tools/call with the given content blocks.
+func fakeToolServer(t *testing.T, content []map[string]any) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int64  `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "tools/call" {
+			t.Errorf("unexpected method %q", req.Method)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  map[string]any{"content": content},
+		})
+	}))
+}
+
+func TestCallTool_SavesAndReferencesImageBlocks(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nfake-image-bytes")
+	srv := fakeToolServer(t, []map[string]any{
+		{"type": "text", "text": "Here is the chart"},
+		{"type": "image", "data": base64.StdEncoding.EncodeToString(png), "mimeType": "image/png"},
+	})
+	defer srv.Close()
+
+	mediaDir := t.TempDir()
+	c := newClient("charts", ServerConfig{URL: srv.URL})
+	c.mediaDir = mediaDir
+
+	out, err := c.callTool(context.Background(), "render", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := regexp.MustCompile(`^Here is the chart\n\[image saved: (\S+) \(image/png\)\]$`).FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("unexpected result: %q", out)
+	}
+	saved, err := os.ReadFile(m[1])
+	if err != nil {
+		t.Fatalf("referenced image not readable: %v", err)
+	}
+	if !bytes.Equal(saved, png) {
+		t.Errorf("saved image bytes differ")
+	}
+	if filepath.Dir(m[1]) != mediaDir {
+		t.Errorf("expected image under %s, got %s", mediaDir, m[1])
+	}
+}
+
+func TestCallTool_TextOnlyUnchanged(t *testing.T) {
+	srv := fakeToolServer(t, []map[string]any{
+		{"type": "text", "text": "line one"},
+		{"type": "text", "text": "line two"},
+	})
+	defer srv.Close()
+
+	c := newClient("echo", ServerConfig{URL: srv.URL})
+	out, err := c.callTool(context.Background(), "echo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "line one\nline two" {
+		t.Errorf("unexpected result: %q", out)
+	}
+}
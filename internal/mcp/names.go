@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// maxToolNameLen is the longest function name providers accept.
+const maxToolNameLen = 64
+
+var invalidToolNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// sanitizeToolName lowercases s, replaces runs of characters providers reject
+// in function names with "_", and truncates it to maxToolNameLen.
+func sanitizeToolName(s string) string {
+	s = invalidToolNameChars.ReplaceAllString(strings.ToLower(s), "_")
+	if len(s) > maxToolNameLen {
+		s = s[:maxToolNameLen]
+	}
+	return s
+}
+
+// toolPrefix returns the tool name prefix for a server: the configured
+// override, or "mcp_<server>".
+func toolPrefix(server, override string) string {
+	if override != "" {
+		return override
+	}
+	return "mcp_" + server
+}
+
+// uniqueToolName sanitizes prefix_suffix and, if a tool of that name is
+// already registered (a built-in or another server's tool), appends _2, _3, …
+// until it is free. Collisions are logged rather than overwriting silently.
+func uniqueToolName(ts schema.ToolRegistrar, server, prefix, suffix string) string {
+	name := sanitizeToolName(prefix + "_" + suffix)
+	if ts.Get(name) == nil {
+		return name
+	}
+	for i := 2; ; i++ {
+		tag := fmt.Sprintf("_%d", i)
+		candidate := name[:min(len(name), maxToolNameLen-len(tag))] + tag
+		if ts.Get(candidate) == nil {
+			slog.Warn("MCP tool name collision; renamed", "server", server, "tool", suffix, "taken", name, "name", candidate)
+			return candidate
+		}
+	}
+}
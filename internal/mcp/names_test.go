@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+func TestSanitizeToolName(t *testing.T) {
+	for in, want := range map[string]string{
+		"mcp_GitHub_Search Issues":       "mcp_github_search_issues",
+		"mcp_fs_read.file/v2":            "mcp_fs_read_file_v2",
+		"mcp_x_ok-name_1":                "mcp_x_ok-name_1",
+		"mcp_" + strings.Repeat("a", 80): "mcp_" + strings.Repeat("a", 60),
+	} {
+		if got := sanitizeToolName(in); got != want {
+			t.Errorf("sanitizeToolName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// builtinTool stands in for a tool registered before MCP servers connect.
+type builtinTool struct{ name string }
+
+func (b builtinTool) Name() string                { return b.name }
+func (b builtinTool) Description() string         { return "built-in" }
+func (b builtinTool) Parameters() json.RawMessage { return json.RawMessage(`{}`) }
+func (b builtinTool) Execute(context.Context, map[string]any) (string, error) {
+	return "builtin", nil
+}
+
+func TestConnect_DedupesToolNames(t *testing.T) {
+	fake := func(prefix string) toolcfg.MCPServerConfig {
+		return toolcfg.MCPServerConfig{
+			Command: os.Args[0],
+			Args:    []string{"-test.run=^TestFakeStdioServer$"},
+			Env:     map[string]string{"MCP_FAKE_SERVER": "1", "MCP_FAKE_LOG": filepath.Join(t.TempDir(), "log")},
+			Prefix:  prefix,
+		}
+	}
+	m := NewManager(map[string]toolcfg.MCPServerConfig{
+		"alpha": fake(""),
+		"Alpha": fake(""), // sanitizes to the same prefix as "alpha"
+		"gamma": fake("Docs.V2"),
+	}, t.TempDir())
+	defer m.Close()
+
+	ts := tools.NewToolList()
+	ts.Add(builtinTool{name: "mcp_alpha_ping"})
+	m.ConnectOnce(context.Background(), ts)
+
+	if out, _ := ts.Get("mcp_alpha_ping").Execute(context.Background(), nil); out != "builtin" {
+		t.Error("built-in tool was overwritten by an MCP tool")
+	}
+	for _, name := range []string{"mcp_alpha_ping_2", "mcp_alpha_ping_3", "docs_v2_ping"} {
+		tool := ts.Get(name)
+		if tool == nil {
+			t.Errorf("expected %s to be registered; got %v", name, toolNames(ts))
+			continue
+		}
+		if out, err := tool.Execute(context.Background(), nil); err != nil || !strings.HasPrefix(out, "pong from ") {
+			t.Errorf("%s: got %q, err %v", name, out, err)
+		}
+	}
+	if desc := ts.Get("mcp_alpha_list_resources_2").Description(); !strings.Contains(desc, "mcp_alpha_read_resource_2") {
+		t.Errorf("list tool should point at its renamed read tool, got %q", desc)
+	}
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// maxResourcePages bounds how many resources/list pages are followed.
+const maxResourcePages = 20
+
+// resourceInfo is one entry of a resources/list result.
+type resourceInfo struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MimeType    string `json:"mimeType"`
+}
+
+// listResources returns the resources exposed by this MCP server, following
+// nextCursor pagination.
+func (c *client) listResources(ctx context.Context) ([]resourceInfo, error) {
+	var (
+		all    []resourceInfo
+		cursor string
+	)
+	for page := 0; page < maxResourcePages; page++ {
+		var params any
+		if cursor != "" {
+			params = map[string]any{"cursor": cursor}
+		}
+		resp, err := c.call(ctx, "resources/list", params)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Resources  []resourceInfo `json:"resources"`
+			NextCursor string         `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Resources...)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	return all, nil
+}
+
+// readResource fetches the resource at uri and renders its contents like a
+// tool result: text as-is, image blobs saved to mediaDir, other blobs noted.
+func (c *client) readResource(ctx context.Context, uri string) (string, error) {
+	resp, err := c.call(ctx, "resources/read", map[string]any{"uri": uri})
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Contents []embeddedContent `json:"contents"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return string(resp), nil
+	}
+
+	var parts []string
+	for _, rc := range result.Contents {
+		switch {
+		case rc.Text != "":
+			parts = append(parts, rc.Text)
+		case rc.Blob != "" && strings.HasPrefix(rc.MimeType, "image/"):
+			parts = append(parts, saveImageRef(rc.Blob, rc.MimeType, rc.URI, c.mediaDir))
+		case rc.Blob != "":
+			raw, _ := base64.StdEncoding.DecodeString(rc.Blob)
+			parts = append(parts, fmt.Sprintf("[binary resource: %s (%s, %d bytes)]", rc.URI, rc.MimeType, len(raw)))
+		}
+	}
+	if len(parts) == 0 {
+		return "(empty resource)", nil
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// formatResources renders a resource listing, one URI per line.
+func formatResources(resources []resourceInfo) string {
+	if len(resources) == 0 {
+		return "No resources available."
+	}
+	var sb strings.Builder
+	for _, r := range resources {
+		sb.WriteString("- " + r.URI)
+		if r.Name != "" && r.Name != r.URI {
+			sb.WriteString(" — " + r.Name)
+		}
+		if r.MimeType != "" {
+			sb.WriteString(" (" + r.MimeType + ")")
+		}
+		if r.Description != "" {
+			sb.WriteString(": " + r.Description)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// listResourcesTool exposes resources/list, by default as
+// mcp_<server>_list_resources.
+type listResourcesTool struct {
+	client   *client
+	name     string
+	readName string // the matching readResourceTool
+}
+
+func (t *listResourcesTool) Name() string { return t.name }
+func (t *listResourcesTool) Description() string {
+	return fmt.Sprintf("List the resources (files, docs, data) exposed by the %q MCP server. "+
+		"Read one with %s.", t.client.name, t.readName)
+}
+func (t *listResourcesTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{}}`)
+}
+
+func (t *listResourcesTool) Execute(ctx context.Context, _ map[string]any) (string, error) {
+	resources, err := t.client.listResources(ctx)
+	if err != nil {
+		return "", err
+	}
+	return formatResources(resources), nil
+}
+
+// readResourceTool exposes resources/read, by default as
+// mcp_<server>_read_resource.
+type readResourceTool struct {
+	client   *client
+	name     string
+	listName string // the matching listResourcesTool
+}
+
+func (t *readResourceTool) Name() string { return t.name }
+func (t *readResourceTool) Description() string {
+	return fmt.Sprintf("Read a resource from the %q MCP server by URI "+
+		"(see %s).", t.client.name, t.listName)
+}
+func (t *readResourceTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{"uri":{"type":"string","description":"Resource URI"}},"required":["uri"]}`)
+}
+
+func (t *readResourceTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	uri, _ := params["uri"].(string)
+	if uri == "" {
+		return "Error: uri is required", nil
+	}
+	return t.client.readResource(ctx, uri)
+}
+
+var (
+	_ schema.Tool = (*listResourcesTool)(nil)
+	_ schema.Tool = (*readResourceTool)(nil)
+)
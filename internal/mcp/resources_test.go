@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+	"github.com/crystaldolphin/crystaldolphin/internal/tools"
+)
+
+func TestConnect_RegistersResourceTools(t *testing.T) {
+	m := NewManager(map[string]toolcfg.MCPServerConfig{"fake": {
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestFakeStdioServer$"},
+		Env:     map[string]string{"MCP_FAKE_SERVER": "1", "MCP_FAKE_LOG": filepath.Join(t.TempDir(), "log")},
+	}}, t.TempDir())
+	defer m.Close()
+
+	ts := tools.NewToolList()
+	ctx := context.Background()
+	m.ConnectOnce(ctx, ts)
+
+	list := ts.Get("mcp_fake_list_resources")
+	if list == nil {
+		t.Fatalf("list tool not registered; got %v", toolNames(ts))
+	}
+	out, err := list.Execute(ctx, nil)
+	want := "- file:///notes.md — notes (text/markdown)\n- file:///logo.bin: Company logo"
+	if err != nil || out != want {
+		t.Errorf("list: got %q, err %v; want %q", out, err, want)
+	}
+
+	read := ts.Get("mcp_fake_read_resource")
+	if out, err := read.Execute(ctx, map[string]any{"uri": "file:///notes.md"}); err != nil || out != "# Notes" {
+		t.Errorf("read text: got %q, err %v", out, err)
+	}
+	out, err = read.Execute(ctx, map[string]any{"uri": "file:///logo.bin"})
+	if err != nil || out != "[binary resource: file:///logo.bin (application/octet-stream, 3 bytes)]" {
+		t.Errorf("read blob: got %q, err %v", out, err)
+	}
+	if out, _ := read.Execute(ctx, map[string]any{}); out != "Error: uri is required" {
+		t.Errorf("expected missing uri error, got %q", out)
+	}
+}
+
+func TestConnect_SkipsResourceToolsWhenUnsupported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int64  `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		if req.Method == "tools/list" {
+			resp["result"] = map[string]any{"tools": []any{}}
+		} else {
+			resp["error"] = map[string]any{"code": -32601, "message": "Method not found"}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	m := NewManager(map[string]toolcfg.MCPServerConfig{"remote": {URL: srv.URL}}, t.TempDir())
+	ts := tools.NewToolList()
+	m.ConnectOnce(context.Background(), ts)
+	if len(ts.Definitions()) != 0 {
+		t.Errorf("expected no resource tools for a server without resources, got %v", toolNames(ts))
+	}
+}
@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// rpcMessage is any JSON-RPC message: a response (id + result/error), a
+// notification (method only), or a server-to-client request (id + method).
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// id returns the message's numeric id, if it has one.
+func (m rpcMessage) id() (int64, bool) {
+	if len(m.ID) == 0 || string(m.ID) == "null" {
+		return 0, false
+	}
+	var n int64
+	if err := json.Unmarshal(m.ID, &n); err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func (m rpcMessage) result() (json.RawMessage, error) {
+	if len(m.Error) > 0 && string(m.Error) != "null" {
+		var errObj any
+		_ = json.Unmarshal(m.Error, &errObj)
+		return nil, fmt.Errorf("MCP error: %v", errObj)
+	}
+	if len(m.Result) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	return m.Result, nil
+}
+
+// handleNotification handles a server-initiated notification. A changed tool
+// list triggers onToolsChanged; server log messages are surfaced at info
+// level; the rest (progress, ...) are only logged at debug.
+func (c *client) handleNotification(method string, params json.RawMessage) {
+	switch method {
+	case "notifications/tools/list_changed":
+		slog.Debug("MCP tool list changed", "server", c.name)
+		if c.onToolsChanged != nil {
+			go c.onToolsChanged() // it calls back into the server; don't block the reader
+		}
+	case "notifications/message":
+		slog.Info("MCP server log", "server", c.name, "params", string(params))
+	default:
+		slog.Debug("MCP notification", "server", c.name, "method", method)
+	}
+}
+
+// rpcConn multiplexes requests over a connection whose incoming messages are
+// read by a background goroutine (a stdio pipe or an SSE stream). Responses
+// are matched to waiting callers by id, notifications go to the client, and
+// server-to-client requests are answered.
+type rpcConn struct {
+	c    *client
+	send func(ctx context.Context, msg any) error
+
+	mu      sync.Mutex
+	pending map[int64]chan rpcMessage
+	err     error // why the connection ended; set before done is closed
+	done    chan struct{}
+}
+
+func newRPCConn(c *client, send func(ctx context.Context, msg any) error) *rpcConn {
+	return &rpcConn{c: c, send: send, pending: map[int64]chan rpcMessage{}, done: make(chan struct{})}
+}
+
+// dispatch routes one incoming message. Non-JSON input (e.g. server log
+// output on stdout) is ignored.
+func (r *rpcConn) dispatch(data []byte) {
+	var m rpcMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return
+	}
+	id, hasID := m.id()
+	switch {
+	case m.Method != "" && len(m.ID) > 0:
+		go r.answer(m)
+	case m.Method != "":
+		r.c.handleNotification(m.Method, m.Params)
+	case hasID:
+		r.mu.Lock()
+		ch := r.pending[id]
+		delete(r.pending, id)
+		r.mu.Unlock()
+		if ch != nil {
+			ch <- m
+		}
+	}
+}
+
+// answer replies to a server-to-client request. Only ping is supported.
+func (r *rpcConn) answer(m rpcMessage) {
+	reply := map[string]any{"jsonrpc": "2.0", "id": m.ID}
+	if m.Method == "ping" {
+		reply["result"] = map[string]any{}
+	} else {
+		reply["error"] = map[string]any{"code": -32601, "message": "Method not found: " + m.Method}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := r.send(ctx, reply); err != nil {
+		slog.Warn("MCP reply to server request failed", "server", r.c.name, "method", m.Method, "err", err)
+	}
+}
+
+// request sends one request and waits for its response.
+func (r *rpcConn) request(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	id := r.c.nextRequestID()
+	ch := make(chan rpcMessage, 1)
+	r.mu.Lock()
+	if r.err != nil {
+		r.mu.Unlock()
+		return nil, r.err
+	}
+	r.pending[id] = ch
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, id)
+		r.mu.Unlock()
+	}()
+
+	if err := r.send(ctx, newRequest(id, method, params)); err != nil {
+		return nil, err
+	}
+	select {
+	case m := <-ch:
+		return m.result()
+	case <-r.done:
+		return nil, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// notify sends a notification; no response is expected.
+func (r *rpcConn) notify(ctx context.Context, method string) error {
+	return r.send(ctx, map[string]any{"jsonrpc": "2.0", "method": method})
+}
+
+// shutdown marks the connection closed with err, failing waiting requests.
+// The reader calls it once when its input ends.
+func (r *rpcConn) shutdown(err error) {
+	r.mu.Lock()
+	r.err = err
+	r.mu.Unlock()
+	close(r.done)
+}
+
+// alive reports whether the connection is still open.
+func (r *rpcConn) alive() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
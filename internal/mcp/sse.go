@@ -0,0 +1,260 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// sseEvent is one dispatched Server-Sent Event.
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readSSE parses a text/event-stream, calling fn for each event until fn
+// returns false (nil error) or the stream ends (io.EOF or the read error).
+func readSSE(r io.Reader, fn func(sseEvent) bool) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	var (
+		ev   sseEvent
+		data []string
+	)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if len(data) > 0 {
+				ev.data = strings.Join(data, "\n")
+				if !fn(ev) {
+					return nil
+				}
+			}
+			ev, data = sseEvent{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment / keep-alive
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// readSSEResponse reads a text/event-stream reply to a POST until the
+// response with the given id arrives, handling notifications on the way.
+func (c *client) readSSEResponse(body io.Reader, id int64) (json.RawMessage, error) {
+	var (
+		result json.RawMessage
+		rerr   error
+		found  bool
+	)
+	err := readSSE(body, func(ev sseEvent) bool {
+		if ev.name != "" && ev.name != "message" {
+			return true
+		}
+		var m rpcMessage
+		if json.Unmarshal([]byte(ev.data), &m) != nil {
+			return true
+		}
+		if m.Method != "" {
+			if _, isRequest := m.id(); !isRequest {
+				c.handleNotification(m.Method, m.Params)
+			}
+			return true
+		}
+		if mid, ok := m.id(); ok && mid == id {
+			result, rerr = m.result()
+			found = true
+			return false
+		}
+		return true
+	})
+	if found {
+		return result, rerr
+	}
+	return nil, fmt.Errorf("MCP event stream ended without a response: %w", err)
+}
+
+// sseSession is an open HTTP+SSE connection: requests are POSTed to the
+// announced endpoint and their responses arrive on the event stream.
+type sseSession struct {
+	*rpcConn
+	endpoint string
+	cancel   context.CancelFunc // closes the event stream
+}
+
+// openSSE opens the event stream, waits for the endpoint event, and runs
+// initialize over the new session, all within initTimeout.
+func (c *client) openSSE(ctx context.Context) (*sseSession, error) {
+	timeout := c.initTimeout()
+	ctx, cancelInit := context.WithTimeout(ctx, timeout)
+	defer cancelInit()
+
+	// The stream outlives ctx, but must not hang before the session is up.
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stopInit := context.AfterFunc(ctx, cancel)
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, c.cfg.URL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	for k, v := range c.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	// The stream is long-lived, so it must not inherit httpClient's timeout.
+	resp, err := (&http.Client{Transport: c.httpClient.Transport}).Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("open event stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("open event stream: unexpected response %s (%s)", resp.Status, resp.Header.Get("Content-Type"))
+	}
+
+	s := &sseSession{cancel: cancel}
+	s.rpcConn = newRPCConn(c, s.post)
+	endpoint := make(chan string, 1)
+	go s.run(resp.Body, endpoint)
+
+	select {
+	case ep := <-endpoint:
+		if s.endpoint, err = resolveEndpoint(c.cfg.URL, ep); err != nil {
+			cancel()
+			return nil, err
+		}
+	case <-s.done:
+		return nil, s.err
+	case <-ctx.Done():
+		cancel()
+		return nil, fmt.Errorf("open event stream: no endpoint event within %s: %w", timeout, ctx.Err())
+	}
+
+	if err := c.initialize(ctx, s.rpcConn); err != nil {
+		cancel()
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+	if !stopInit() {
+		return nil, fmt.Errorf("initialize: no response within %s", timeout)
+	}
+	return s, nil
+}
+
+// resolveEndpoint resolves the endpoint event's URI against the stream URL.
+// It must stay on the same origin, since configured headers are sent to it.
+func resolveEndpoint(streamURL, endpoint string) (string, error) {
+	base, err := url.Parse(streamURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	u := base.ResolveReference(ref)
+	if u.Scheme != base.Scheme || u.Host != base.Host {
+		return "", fmt.Errorf("endpoint %q is not on the same origin as %s", endpoint, streamURL)
+	}
+	return u.String(), nil
+}
+
+// run reads the event stream until it closes, routing each message.
+func (s *sseSession) run(body io.ReadCloser, endpoint chan<- string) {
+	defer body.Close()
+	err := readSSE(body, func(ev sseEvent) bool {
+		switch ev.name {
+		case "endpoint":
+			select {
+			case endpoint <- ev.data:
+			default:
+			}
+		case "", "message":
+			s.dispatch([]byte(ev.data))
+		}
+		return true
+	})
+	s.shutdown(&transportError{fmt.Errorf("MCP event stream closed: %w", err)})
+}
+
+// post sends one JSON-RPC message to the session endpoint.
+func (s *sseSession) post(ctx context.Context, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.c.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("MCP POST %s: %s", s.endpoint, resp.Status)
+	}
+	return nil
+}
+
+// connectSSE opens the first SSE session.
+func (c *client) connectSSE(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, err := c.openSSE(ctx)
+	if err != nil {
+		return err
+	}
+	c.sse.Store(s)
+	c.ready.Store(true)
+	return nil
+}
+
+// callSSE sends a request over the SSE session, reopening the event stream
+// first if it has closed.
+func (c *client) callSSE(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	c.mu.Lock()
+	s := c.sse.Load()
+	if s == nil || !s.alive() {
+		if c.closed.Load() {
+			c.mu.Unlock()
+			return nil, fmt.Errorf("MCP server %q: client closed", c.name)
+		}
+		c.ready.Store(false)
+		var err error
+		if s, err = c.openSSE(ctx); err != nil {
+			c.mu.Unlock()
+			return nil, fmt.Errorf("MCP server %q: reconnect event stream: %w", c.name, err)
+		}
+		slog.Info("MCP event stream reconnected", "server", c.name)
+		c.sse.Store(s)
+		c.ready.Store(true)
+	}
+	c.mu.Unlock()
+	return s.request(ctx, method, params)
+}
@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordedSSEServer replays an HTTP+SSE session: the event stream announces
+// /messages, and replies to POSTed requests are pushed back over the stream.
+// Two tools/call requests are answered in reverse order, preceded by a log
+// notification and a server-initiated ping.
+type recordedSSEServer struct {
+	*httptest.Server
+	events chan string
+
+	mu        sync.Mutex
+	calls     []rpcMessage
+	pingReply chan rpcMessage
+}
+
+func newRecordedSSEServer(t *testing.T) *recordedSSEServer {
+	t.Helper()
+	s := &recordedSSEServer{events: make(chan string, 16), pingReply: make(chan rpcMessage, 1)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\nevent: endpoint\ndata: /messages?session=s1\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case ev := <-s.events:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", ev)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("POST /messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("session") != "s1" {
+			t.Errorf("unexpected session in %s", r.URL)
+		}
+		var m rpcMessage
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		w.WriteHeader(http.StatusAccepted)
+		s.handle(m)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *recordedSSEServer) push(v any) {
+	data, _ := json.Marshal(v)
+	s.events <- string(data)
+}
+
+func (s *recordedSSEServer) handle(m rpcMessage) {
+	switch m.Method {
+	case "initialize":
+		s.push(map[string]any{"jsonrpc": "2.0", "id": m.ID, "result": map[string]any{
+			"protocolVersion": "2024-11-05",
+			"capabilities":    map[string]any{"resources": map[string]any{}},
+		}})
+	case "tools/call":
+		s.mu.Lock()
+		s.calls = append(s.calls, m)
+		calls := append([]rpcMessage(nil), s.calls...)
+		s.mu.Unlock()
+		if len(calls) < 2 {
+			return
+		}
+		s.push(map[string]any{"jsonrpc": "2.0", "method": "notifications/message",
+			"params": map[string]any{"level": "info", "data": "working"}})
+		s.push(map[string]any{"jsonrpc": "2.0", "id": "srv-1", "method": "ping"})
+		for i := len(calls) - 1; i >= 0; i-- {
+			var p struct {
+				Name string `json:"name"`
+			}
+			_ = json.Unmarshal(calls[i].Params, &p)
+			s.push(map[string]any{"jsonrpc": "2.0", "id": calls[i].ID, "result": map[string]any{
+				"content": []map[string]any{{"type": "text", "text": "result for " + p.Name}},
+			}})
+		}
+	case "":
+		if string(m.ID) == `"srv-1"` {
+			s.pingReply <- m
+		}
+	}
+}
+
+func TestSSE_CorrelatesResponsesByID(t *testing.T) {
+	srv := newRecordedSSEServer(t)
+	c := newClient("sse", ServerConfig{URL: srv.URL + "/sse", Transport: TransportSSE})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+	if !c.hasResources.Load() {
+		t.Error("expected resources capability from initialize")
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 2)
+	for i, name := range []string{"a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := c.callTool(ctx, name, nil)
+			if err != nil {
+				t.Errorf("call %s: %v", name, err)
+			}
+			results[i] = out
+		}()
+	}
+	wg.Wait()
+	if results[0] != "result for a" || results[1] != "result for b" {
+		t.Errorf("responses not matched to their requests: %q", results)
+	}
+
+	select {
+	case m := <-srv.pingReply:
+		if string(m.Result) != "{}" {
+			t.Errorf("unexpected ping reply %+v", m)
+		}
+	case <-ctx.Done():
+		t.Error("server ping was not answered")
+	}
+}
+
+func TestSSE_RejectsCrossOriginEndpoint(t *testing.T) {
+	if _, err := resolveEndpoint("http://a.example/sse", "http://b.example/messages"); err == nil {
+		t.Error("expected cross-origin endpoint to be rejected")
+	}
+	got, err := resolveEndpoint("http://a.example/mcp/sse", "messages?s=1")
+	if err != nil || got != "http://a.example/mcp/messages?s=1" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
+
+func TestHTTP_EventStreamResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			t.Errorf("client should accept event streams, got Accept %q", r.Header.Get("Accept"))
+		}
+		var req rpcMessage
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+		fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"id\":999,\"result\":{}}\n\n")
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s,\n", req.ID)
+		fmt.Fprint(w, "data: \"result\":{\"content\":[{\"type\":\"text\",\"text\":\"streamed\"}]}}\n\n")
+	}))
+	defer srv.Close()
+
+	c := newClient("http", ServerConfig{URL: srv.URL})
+	out, err := c.callTool(context.Background(), "x", nil)
+	if err != nil || out != "streamed" {
+		t.Errorf("got %q, err %v", out, err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package metrics keeps in-process counters and histograms and serves them
+// in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics published by the gateway.
+var (
+	LLMRequests = NewCounterVec("crystaldolphin_llm_requests_total",
+		"LLM chat requests by provider, model and status.", "provider", "model", "status")
+	LLMDuration = NewHistogramVec("crystaldolphin_llm_request_duration_seconds",
+		"LLM chat request latency.", DefaultBuckets, "provider", "model")
+	ToolExecutions = NewCounterVec("crystaldolphin_tool_executions_total",
+		"Tool executions by tool name and outcome.", "tool", "outcome")
+	ToolDuration = NewHistogramVec("crystaldolphin_tool_duration_seconds",
+		"Tool execution latency.", DefaultBuckets, "tool")
+	CronRuns = NewCounterVec("crystaldolphin_cron_runs_total",
+		"Cron job runs by status.", "status")
+	ChannelMessages = NewCounterVec("crystaldolphin_channel_messages_total",
+		"Chat messages by channel and direction (inbound or outbound).", "channel", "direction")
+)
+
+// DefaultBuckets are histogram upper bounds in seconds, spanning fast tool
+// calls to slow LLM completions.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// Default is the registry all package-level metrics belong to.
+var Default = &Registry{}
+
+func init() {
+	Default.Register(LLMRequests, LLMDuration, ToolExecutions, ToolDuration, CronRuns, ChannelMessages)
+}
+
+// Collector is a metric family that can write itself in text format.
+type Collector interface {
+	Name() string
+	write(w io.Writer)
+}
+
+// Registry is an ordered set of metric families.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// Register adds collectors to r.
+func (r *Registry) Register(cs ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, cs...)
+}
+
+// Names returns the names of the registered metric families.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, len(r.collectors))
+	for i, c := range r.collectors {
+		names[i] = c.Name()
+	}
+	return names
+}
+
+// WriteText writes every registered family in Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) {
+	r.mu.Lock()
+	cs := append([]Collector(nil), r.collectors...)
+	r.mu.Unlock()
+	for _, c := range cs {
+		c.write(w)
+	}
+}
+
+// Handler serves r at any path, for mounting on /metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// Since returns the seconds elapsed since start, for Observe calls.
+func Since(start time.Time) float64 { return time.Since(start).Seconds() }
+
+// CounterVec is a counter family partitioned by label values.
+type CounterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64 // label key → value
+}
+
+// NewCounterVec creates a counter family with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{name: name, help: help, labels: labels, values: map[string]float64{}}
+}
+
+func (c *CounterVec) Name() string { return c.name }
+
+// Inc adds one to the counter for labelValues, given in label order.
+func (c *CounterVec) Inc(labelValues ...string) {
+	key := labelKey(c.labels, labelValues)
+	c.mu.Lock()
+	c.values[key]++
+	c.mu.Unlock()
+}
+
+// Value returns the current count for labelValues.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := labelKey(c.labels, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, key, formatFloat(c.values[key]))
+	}
+}
+
+// HistogramVec is a histogram family partitioned by label values.
+type HistogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogram // label key → series
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, non-cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec creates a histogram family with the given bucket upper
+// bounds (ascending) and label names.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return &HistogramVec{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogram{}}
+}
+
+func (h *HistogramVec) Name() string { return h.name }
+
+// Observe records v for labelValues, given in label order.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := labelKey(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cum uint64
+		for i, le := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(key, "le", formatFloat(le)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, withLabel(key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, key, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, key, s.count)
+	}
+}
+
+// labelKey renders label pairs as `{a="x",b="y"}`, or "" without labels.
+// Missing values are empty strings; extra values are ignored.
+func labelKey(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		b.WriteString(n + "=" + strconv.Quote(v))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// withLabel appends name="value" to a rendered label key.
+func withLabel(key, name, value string) string {
+	pair := name + "=" + strconv.Quote(value)
+	if key == "" {
+		return "{" + pair + "}"
+	}
+	return key[:len(key)-1] + "," + pair + "}"
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
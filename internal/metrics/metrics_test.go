@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	c := NewCounterVec("test_events_total", "Events.", "kind")
+	h := NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "op")
+	r := &Registry{}
+	r.Register(c, h)
+
+	c.Inc("a")
+	c.Inc("a")
+	c.Inc(`b"q`)
+	h.Observe(0.05, "get")
+	h.Observe(0.5, "get")
+	h.Observe(5, "get")
+
+	var out strings.Builder
+	r.WriteText(&out)
+	for _, line := range []string{
+		"# TYPE test_events_total counter",
+		`test_events_total{kind="a"} 2`,
+		`test_events_total{kind="b\"q"} 1`,
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{op="get",le="0.1"} 1`,
+		`test_latency_seconds_bucket{op="get",le="1"} 2`,
+		`test_latency_seconds_bucket{op="get",le="+Inf"} 3`,
+		`test_latency_seconds_sum{op="get"} 5.55`,
+		`test_latency_seconds_count{op="get"} 3`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("missing line %q in:\n%s", line, out.String())
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// BreakerConfig tunes a Breaker. A zero Threshold disables it.
+type BreakerConfig struct {
+	Threshold int           // consecutive retryable failures that open the circuit
+	Window    time.Duration // failures further apart than this start a new count
+	Cooldown  time.Duration // how long the circuit stays open before a probe
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a circuit breaker around an LLMProvider. After Threshold
+// consecutive retryable failures within Window it opens, and calls fail fast
+// with a "provider temporarily unavailable" error for Cooldown. Then a single
+// probe call is let through (half-open): success closes the circuit, another
+// retryable failure reopens it. Non-retryable failures such as HTTP 400 do
+// not count, since they say nothing about the provider being down.
+type Breaker struct {
+	inner schema.LLMProvider
+	cfg   BreakerConfig
+	now   func() time.Time
+
+	mu           sync.Mutex
+	state        breakerState
+	failures     int
+	lastFailure  time.Time
+	openedAt     time.Time
+	probeRunning bool
+}
+
+// NewBreaker wraps inner in a circuit breaker configured by cfg.
+func NewBreaker(inner schema.LLMProvider, cfg BreakerConfig) *Breaker {
+	return &Breaker{inner: inner, cfg: cfg, now: time.Now}
+}
+
+func (b *Breaker) DefaultModel() string { return b.inner.DefaultModel() }
+
+// Chat implements schema.LLMProvider.
+func (b *Breaker) Chat(
+	ctx context.Context,
+	messages schema.Messages,
+	tools []map[string]any,
+	opts schema.ChatOptions,
+) (schema.LLMResponse, error) {
+	if wait, ok := b.allow(); !ok {
+		return errResponse(fmt.Sprintf("provider temporarily unavailable after repeated failures; try again in %s",
+			wait.Round(time.Second)))
+	}
+	resp, err := b.inner.Chat(ctx, messages, tools, opts)
+	if errors.Is(err, context.Canceled) {
+		// The caller gave up; that says nothing about the provider.
+		b.abandon()
+		return resp, err
+	}
+	b.record(isRetryableFailure(resp, err))
+	return resp, err
+}
+
+// allow reports whether a call may proceed; when it may not, it returns the
+// time left until the next probe.
+func (b *Breaker) allow() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		remaining := b.openedAt.Add(b.cfg.Cooldown).Sub(b.now())
+		if remaining > 0 {
+			return remaining, false
+		}
+		b.setState(breakerHalfOpen)
+		b.probeRunning = true
+		return 0, true
+	case breakerHalfOpen:
+		if b.probeRunning {
+			return 0, false // one probe at a time
+		}
+		b.probeRunning = true
+		return 0, true
+	default:
+		return 0, true
+	}
+}
+
+// record updates the circuit with the outcome of a call that allow let through.
+func (b *Breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if b.state == breakerHalfOpen {
+		b.probeRunning = false
+		if failed {
+			b.openedAt = now
+			b.setState(breakerOpen)
+		} else {
+			b.failures = 0
+			b.setState(breakerClosed)
+		}
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	if b.failures > 0 && now.Sub(b.lastFailure) > b.cfg.Window {
+		b.failures = 0
+	}
+	b.failures++
+	b.lastFailure = now
+	if b.state == breakerClosed && b.failures >= b.cfg.Threshold {
+		b.openedAt = now
+		b.setState(breakerOpen)
+	}
+}
+
+// abandon releases a half-open probe whose outcome is unknown.
+func (b *Breaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen {
+		b.probeRunning = false
+	}
+}
+
+func (b *Breaker) setState(s breakerState) {
+	if b.state == s {
+		return
+	}
+	slog.Warn("provider circuit breaker", "model", b.inner.DefaultModel(), "from", b.state, "to", s)
+	b.state = s
+}
+
+// isRetryableFailure reports whether a Chat outcome suggests the provider is
+// unavailable: a transport error, or an error response for a timeout, rate
+// limit or server error.
+func isRetryableFailure(resp schema.LLMResponse, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp.FinishReason != "error" || resp.Content == nil {
+		return false
+	}
+	msg := *resp.Content
+	if rest, ok := strings.CutPrefix(msg, "HTTP "); ok {
+		code, _ := strconv.Atoi(strings.SplitN(rest, ":", 2)[0])
+		return code >= 500 || code == 429 || code == 408
+	}
+	for _, prefix := range []string{"rate limited", "Error calling", "Error reading", "ChatGPT usage quota"} {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return false
+}
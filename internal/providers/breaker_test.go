@@ -0,0 +1,158 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// flakyProvider fails with reply until it is set to succeed, counting calls.
+type flakyProvider struct {
+	reply schema.LLMResponse
+	err   error
+	calls int
+}
+
+func (p *flakyProvider) Chat(context.Context, schema.Messages, []map[string]any, schema.ChatOptions) (schema.LLMResponse, error) {
+	p.calls++
+	return p.reply, p.err
+}
+func (p *flakyProvider) DefaultModel() string { return "test" }
+
+func (p *flakyProvider) fail(msg string) {
+	p.reply = schema.LLMResponse{Content: &msg, FinishReason: "error"}
+}
+
+func (p *flakyProvider) succeed() {
+	ok := "ok"
+	p.reply = schema.LLMResponse{Content: &ok, FinishReason: "stop"}
+}
+
+func newTestBreaker(p *flakyProvider) (*Breaker, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	b := NewBreaker(p, BreakerConfig{Threshold: 3, Window: time.Minute, Cooldown: 30 * time.Second})
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func chat(b *Breaker) schema.LLMResponse {
+	resp, _ := b.Chat(context.Background(), schema.NewMessages(), nil, schema.ChatOptions{})
+	return resp
+}
+
+func TestBreaker_OpenHalfOpenClose(t *testing.T) {
+	p := &flakyProvider{}
+	b, now := newTestBreaker(p)
+
+	p.fail("HTTP 503: overloaded")
+	for range 3 {
+		chat(b)
+	}
+	if b.state != breakerOpen || p.calls != 3 {
+		t.Fatalf("expected open after 3 failures, got %s with %d calls", b.state, p.calls)
+	}
+
+	// Open: calls short-circuit without reaching the provider.
+	resp := chat(b)
+	if p.calls != 3 || resp.FinishReason != "error" || !strings.Contains(*resp.Content, "temporarily unavailable") {
+		t.Fatalf("expected short-circuit, got %+v after %d calls", resp, p.calls)
+	}
+
+	// Cooldown over: one probe fails and reopens the circuit.
+	*now = now.Add(31 * time.Second)
+	chat(b)
+	if b.state != breakerOpen || p.calls != 4 {
+		t.Fatalf("expected failed probe to reopen, got %s with %d calls", b.state, p.calls)
+	}
+	chat(b)
+	if p.calls != 4 {
+		t.Fatalf("expected reopened circuit to short-circuit, got %d calls", p.calls)
+	}
+
+	// Next probe succeeds and closes the circuit.
+	*now = now.Add(31 * time.Second)
+	p.succeed()
+	if resp := chat(b); resp.FinishReason != "stop" || b.state != breakerClosed {
+		t.Fatalf("expected probe to close the circuit, got %+v in state %s", resp, b.state)
+	}
+	chat(b)
+	if p.calls != 6 {
+		t.Errorf("expected calls to pass through once closed, got %d", p.calls)
+	}
+}
+
+func TestBreaker_HalfOpenAllowsOneProbe(t *testing.T) {
+	p := &flakyProvider{}
+	b, now := newTestBreaker(p)
+	p.fail("HTTP 500: boom")
+	for range 3 {
+		chat(b)
+	}
+	*now = now.Add(31 * time.Second)
+
+	if _, ok := b.allow(); !ok {
+		t.Fatal("expected the first call after cooldown to probe")
+	}
+	if b.state != breakerHalfOpen {
+		t.Fatalf("expected half-open, got %s", b.state)
+	}
+	if _, ok := b.allow(); ok {
+		t.Error("expected a second call to short-circuit while the probe runs")
+	}
+}
+
+func TestBreaker_CountsOnlyRetryableFailuresInWindow(t *testing.T) {
+	p := &flakyProvider{}
+	b, now := newTestBreaker(p)
+
+	p.fail("HTTP 400: bad request")
+	for range 5 {
+		chat(b)
+	}
+	if b.state != breakerClosed {
+		t.Fatalf("expected 4xx failures not to open the circuit, got %s", b.state)
+	}
+
+	p.reply, p.err = schema.LLMResponse{}, errors.New("connection refused")
+	chat(b)
+	chat(b)
+	*now = now.Add(2 * time.Minute)
+	chat(b)
+	if b.state != breakerClosed || b.failures != 1 {
+		t.Fatalf("expected stale failures to reset the count, got %s with %d failures", b.state, b.failures)
+	}
+
+	p.err = context.Canceled
+	chat(b)
+	if b.failures != 1 {
+		t.Errorf("expected a cancelled call not to count, got %d failures", b.failures)
+	}
+}
+
+func TestIsRetryableFailure(t *testing.T) {
+	errResp := func(msg string) schema.LLMResponse {
+		return schema.LLMResponse{Content: &msg, FinishReason: "error"}
+	}
+	cases := []struct {
+		resp schema.LLMResponse
+		err  error
+		want bool
+	}{
+		{errResp("HTTP 502: bad gateway"), nil, true},
+		{errResp("HTTP 429: rate limit exceeded"), nil, true},
+		{errResp("HTTP 401: invalid key"), nil, false},
+		{errResp("rate limited by the provider; try again in 1m0s"), nil, true},
+		{errResp("Error calling Codex: dial tcp: timeout"), nil, true},
+		{schema.LLMResponse{}, errors.New("HTTP request: EOF"), true},
+		{schema.LLMResponse{FinishReason: "stop"}, nil, false},
+	}
+	for _, c := range cases {
+		if got := isRetryableFailure(c.resp, c.err); got != c.want {
+			t.Errorf("isRetryableFailure(%+v, %v) = %v, want %v", c.resp.Content, c.err, got, c.want)
+		}
+	}
+}
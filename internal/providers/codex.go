@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -78,7 +79,7 @@ func (p *CodexProvider) Chat(
 		"input":               inputItems,
 		"text":                map[string]any{"verbosity": "medium"},
 		"include":             []string{"reasoning.encrypted_content"},
-		"prompt_cache_key":    codexCacheKey(messages),
+		"prompt_cache_key":    codexCacheKey(system, tools),
 		"tool_choice":         "auto",
 		"parallel_tool_calls": true,
 	}
@@ -449,9 +450,18 @@ func splitCodexToolCallID(id any) (callID, itemID string) {
 	return s, ""
 }
 
-func codexCacheKey(messages schema.Messages) string {
-	// Simple deterministic hash using the JSON representation.
-	b, _ := messages.HashKey()
+// codexCacheKey derives the prompt_cache_key from the stable request prefix
+// (system instructions + tool definitions) so every turn of a conversation
+// maps to the same key. Tools are sorted by name so the key does not depend on
+// the order the caller passed them in.
+func codexCacheKey(system string, tools []map[string]any) string {
+	sorted := make([]map[string]any, len(tools))
+	copy(sorted, tools)
+	sort.Slice(sorted, func(i, j int) bool {
+		return codexToolName(sorted[i]) < codexToolName(sorted[j])
+	})
+
+	b, _ := json.Marshal(map[string]any{"instructions": system, "tools": sorted})
 
 	// Use a basic FNV-like approach rather than importing crypto/sha256 just for this.
 	h := uint64(14695981039346656037)
@@ -463,6 +473,12 @@ func codexCacheKey(messages schema.Messages) string {
 	return fmt.Sprintf("%016x", h)
 }
 
+func codexToolName(t map[string]any) string {
+	fn, _ := t["function"].(map[string]any)
+	name, _ := fn["name"].(string)
+	return name
+}
+
 var codexFinishReasonMap = map[string]string{
 	"completed":  "stop",
 	"incomplete": "length",
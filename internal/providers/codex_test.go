@@ -0,0 +1,34 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+func TestCodexCacheKey_StableAcrossTurns(t *testing.T) {
+	tools := []map[string]any{
+		{"type": "function", "function": map[string]any{"name": "read_file"}},
+		{"type": "function", "function": map[string]any{"name": "exec"}},
+	}
+
+	turn1 := schema.NewMessages(
+		schema.NewSystemMessage("You are crystaldolphin."),
+		schema.NewUserMessage("hello"),
+	)
+	turn2 := turn1.Copy()
+	turn2.AddUser("what's the weather?")
+
+	sys1, _ := convertMessagesForCodex(turn1)
+	sys2, _ := convertMessagesForCodex(turn2)
+
+	k1 := codexCacheKey(sys1, tools)
+	k2 := codexCacheKey(sys2, []map[string]any{tools[1], tools[0]})
+	if k1 != k2 {
+		t.Errorf("expected identical cache keys, got %q and %q", k1, k2)
+	}
+
+	if k3 := codexCacheKey("different instructions", tools); k3 == k1 {
+		t.Error("expected a different cache key for different instructions")
+	}
+}
@@ -0,0 +1,129 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCooldownWait is the longest a call blocks on a rate-limit cooldown;
+	// longer cooldowns fail fast instead.
+	maxCooldownWait = 30 * time.Second
+	// rateLimitRetries is how many times a call that hit a 429 with
+	// Retry-After is retried once the cooldown passes.
+	rateLimitRetries = 1
+)
+
+// cooldown gates requests to a provider after it answers 429, so concurrent
+// and later turns wait out the Retry-After window together instead of
+// hammering the endpoint. The zero value is ready to use.
+type cooldown struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// rateLimitedError is returned when the remaining cooldown exceeds
+// maxCooldownWait.
+type rateLimitedError struct {
+	remaining time.Duration
+}
+
+func (e *rateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited by the provider; try again in %s", e.remaining.Round(time.Second))
+}
+
+// trip extends the cooldown to at least d from now.
+func (c *cooldown) trip(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if until := time.Now().Add(d); until.After(c.until) {
+		c.until = until
+	}
+}
+
+// wait blocks until the cooldown has passed. It fails fast with a
+// *rateLimitedError when the wait would exceed maxCooldownWait.
+func (c *cooldown) wait(ctx context.Context) error {
+	c.mu.Lock()
+	remaining := time.Until(c.until)
+	c.mu.Unlock()
+	if remaining <= 0 {
+		return nil
+	}
+	if remaining > maxCooldownWait {
+		return &rateLimitedError{remaining: remaining}
+	}
+	t := time.NewTimer(remaining)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given as delay-seconds or an
+// HTTP date. It returns 0 when the header is absent or unparseable.
+func parseRetryAfter(h string) time.Duration {
+	h = strings.TrimSpace(h)
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(h, 64); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(0, time.Until(t))
+	}
+	return 0
+}
+
+// send performs req behind the provider's cooldown and returns the status and
+// body. A 429 carrying Retry-After trips the shared cooldown and the request
+// is retried once after it passes; other statuses are returned as-is.
+func (p *OpenAIProvider) send(req *http.Request) (int, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := p.cooldown.wait(req.Context()); err != nil {
+			return 0, nil, err
+		}
+		r := req
+		if attempt > 0 {
+			body, err := req.GetBody()
+			if err != nil {
+				return 0, nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		resp, err := p.httpClient.Do(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		raw, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return 0, nil, fmt.Errorf("read response: %w", err)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if d := parseRetryAfter(resp.Header.Get("Retry-After")); d > 0 {
+				slog.Warn("provider rate limited", "retryAfter", d, "attempt", attempt+1)
+				p.cooldown.trip(d)
+				if attempt < rateLimitRetries && req.GetBody != nil {
+					continue
+				}
+			}
+		}
+		return resp.StatusCode, raw, nil
+	}
+}
@@ -0,0 +1,126 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+func TestChat_RateLimitCooldownGatesConcurrentCalls(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		arrivals []time.Time
+	)
+	limited := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		first := len(arrivals) == 1
+		mu.Unlock()
+		if first {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"slow down"}}`))
+			close(limited)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider("key", srv.URL, "gpt-4o", "openai", nil)
+	chat := func() (schema.LLMResponse, error) {
+		msgs := schema.NewMessages()
+		msgs.AddUser("hi")
+		return p.Chat(context.Background(), msgs, nil, schema.NewChatOptions("", 100, 0.5))
+	}
+
+	var wg sync.WaitGroup
+	results := make([]schema.LLMResponse, 2)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = chat()
+	}()
+	<-limited
+	waitForCooldown(t, &p.cooldown)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[1], _ = chat()
+	}()
+	wg.Wait()
+
+	for i, r := range results {
+		if r.Content == nil || *r.Content != "ok" {
+			t.Errorf("call %d: expected ok after cooldown, got %+v", i, r)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(arrivals) != 3 {
+		t.Fatalf("expected 3 requests (429, retry, concurrent), got %d", len(arrivals))
+	}
+	for _, at := range arrivals[1:] {
+		if gap := at.Sub(arrivals[0]); gap < 900*time.Millisecond {
+			t.Errorf("request sent %s after the 429; expected it to wait out Retry-After", gap)
+		}
+	}
+}
+
+// waitForCooldown blocks until c has been tripped.
+func waitForCooldown(t *testing.T, c *cooldown) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		tripped := !c.until.IsZero()
+		c.mu.Unlock()
+		if tripped {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("cooldown was never tripped")
+}
+
+func TestChat_LongCooldownFailsFast(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected while cooling down")
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider("key", srv.URL, "gpt-4o", "openai", nil)
+	p.cooldown.trip(2 * time.Minute)
+
+	msgs := schema.NewMessages()
+	msgs.AddUser("hi")
+	resp, err := p.Chat(context.Background(), msgs, nil, schema.NewChatOptions("", 100, 0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.FinishReason != "error" || resp.Content == nil || !strings.Contains(*resp.Content, "rate limited") {
+		t.Errorf("expected fast rate-limit error, got %+v", resp)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("3"); d != 3*time.Second {
+		t.Errorf("seconds: got %s", d)
+	}
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(date); d < 8*time.Second || d > 10*time.Second {
+		t.Errorf("http date: got %s", d)
+	}
+	for _, h := range []string{"", "soon", "-1"} {
+		if d := parseRetryAfter(h); d != 0 {
+			t.Errorf("%q: expected 0, got %s", h, d)
+		}
+	}
+}
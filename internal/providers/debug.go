@@ -0,0 +1,144 @@
+package providers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/redact"
+)
+
+const (
+	// debugLogMaxBytes is the size at which the HTTP debug log is rotated.
+	debugLogMaxBytes = 10 << 20
+	// debugLogBackups is how many rotated debug logs are kept (.1 … .N).
+	debugLogBackups = 3
+)
+
+// accountHeader identifies a Codex account; it is not a credential by name,
+// so redact.IsSecretKey does not catch it.
+const accountHeader = "chatgpt-account-id"
+
+// debugTransport logs every request and response body passing through next
+// to out, with credentials redacted. It is used only for diagnosing provider
+// quirks: bodies are buffered in full, so SSE responses are logged (and
+// delivered) only once the stream ends.
+type debugTransport struct {
+	next http.RoundTripper
+	out  io.Writer
+	mu   sync.Mutex // serialises entries from concurrent turns
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s %s %s\n", start.Format(time.RFC3339Nano), req.Method, redactURL(req.URL))
+	writeHeaders(&b, redactHeaders(req.Header))
+	fmt.Fprintf(&b, "\n%s\n", reqBody)
+	if err != nil {
+		fmt.Fprintf(&b, "--- error after %s: %v\n\n", time.Since(start).Round(time.Millisecond), err)
+		t.write(b.String())
+		return nil, err
+	}
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	fmt.Fprintf(&b, "--- %s after %s\n", resp.Status, time.Since(start).Round(time.Millisecond))
+	writeHeaders(&b, resp.Header)
+	fmt.Fprintf(&b, "\n%s\n", respBody)
+	if readErr != nil {
+		fmt.Fprintf(&b, "--- read error: %v\n", readErr)
+	}
+	b.WriteString("\n")
+	t.write(b.String())
+	if readErr != nil {
+		return nil, readErr
+	}
+	return resp, nil
+}
+
+func (t *debugTransport) write(entry string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = io.WriteString(t.out, entry)
+}
+
+// withDebugLog returns client with its transport wrapped to log to out.
+func withDebugLog(client *http.Client, out io.Writer) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c := *client
+	c.Transport = &debugTransport{next: next, out: out}
+	return &c
+}
+
+// redactHeaders returns a copy of h with credential values replaced. An
+// Authorization scheme ("Bearer") is kept so auth-shape issues stay visible.
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for k, vs := range out {
+		if !redact.IsSecretKey(k) && !strings.EqualFold(k, accountHeader) {
+			continue
+		}
+		for i, v := range vs {
+			if scheme, _, ok := strings.Cut(v, " "); ok && strings.EqualFold(k, "authorization") {
+				vs[i] = scheme + " " + redact.Placeholder
+			} else {
+				vs[i] = redact.Placeholder
+			}
+		}
+	}
+	return out
+}
+
+// redactURL returns u as a string with credential query parameters ("key"
+// and any name redact.IsSecretKey matches) redacted.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	changed := false
+	for p := range q {
+		if strings.EqualFold(p, "key") || redact.IsSecretKey(p) {
+			q.Set(p, redact.Placeholder)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+func writeHeaders(b *strings.Builder, h http.Header) {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s: %s\n", k, strings.Join(h[k], ", "))
+	}
+}
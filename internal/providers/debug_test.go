@@ -0,0 +1,54 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+func TestDebugTransport_RedactsCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"model"`) {
+			t.Errorf("server got a drained request body: %q", body)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	var log bytes.Buffer
+	p := NewOpenAIProvider("sk-secret-openai", srv.URL, "gpt-4o", "custom",
+		map[string]string{"x-api-key": "secret-extra", "X-Trace": "visible"})
+	p.httpClient = withDebugLog(p.httpClient, &log)
+
+	resp, err := p.Chat(context.Background(), schema.NewMessages(schema.NewUserMessage("hello")), nil, schema.ChatOptions{})
+	if err != nil || resp.Content == nil || *resp.Content != "hi" {
+		t.Fatalf("unexpected response %+v, err %v", resp, err)
+	}
+
+	out := log.String()
+	for _, secret := range []string{"sk-secret-openai", "secret-extra"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("debug log leaks %q:\n%s", secret, out)
+		}
+	}
+	for _, want := range []string{"Authorization: Bearer [REDACTED]", "X-Api-Key: [REDACTED]", "X-Trace: visible", `"hello"`, `"content":"hi"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug log missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/v1/models?key=abc123&alt=sse", nil)
+	got := redactURL(req.URL)
+	if strings.Contains(got, "abc123") || !strings.Contains(got, "alt=sse") {
+		t.Errorf("redactURL = %q", got)
+	}
+}
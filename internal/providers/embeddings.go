@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIEmbedder implements schema.Embedder against an OpenAI-compatible
+// POST {apiBase}/embeddings endpoint.
+type OpenAIEmbedder struct {
+	apiKey       string
+	apiBase      string
+	model        string
+	extraHeaders map[string]string
+	httpClient   *http.Client
+}
+
+// NewOpenAIEmbedder creates an embedder; an empty apiBase means OpenAI.
+func NewOpenAIEmbedder(apiKey, apiBase, model string, extraHeaders map[string]string) *OpenAIEmbedder {
+	if apiBase == "" {
+		apiBase = "https://api.openai.com/v1"
+	}
+	return &OpenAIEmbedder{
+		apiKey:       apiKey,
+		apiBase:      strings.TrimRight(apiBase, "/"),
+		model:        model,
+		extraHeaders: extraHeaders,
+		httpClient:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Model returns the embedding model name.
+func (e *OpenAIEmbedder) Model() string { return e.model }
+
+// Embed returns one vector per text, in order.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.apiBase+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	for k, v := range e.extraHeaders {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read embeddings response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings request: %s: %s", resp.Status, strings.TrimSpace(string(raw)))
+	}
+
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("parse embeddings response: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embeddings response is missing input %d", i)
+		}
+	}
+	return vectors, nil
+}
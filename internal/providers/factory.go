@@ -1,6 +1,11 @@
 package providers
 
-import "github.com/crystaldolphin/crystaldolphin/internal/schema"
+import (
+	"log/slog"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/logfile"
+)
 
 // Params are the raw values needed to construct any schema.LLMProvider.
 // Extracted from config.Config by the caller to avoid an import cycle.
@@ -10,6 +15,26 @@ type Params struct {
 	ExtraHeaders map[string]string
 	DefaultModel string
 	ProviderName string // registry name, e.g. "openrouter", "anthropic"
+
+	// DebugHTTPLog, when set, is a file that receives every raw request and
+	// response body with credentials redacted. It is rotated by size.
+	DebugHTTPLog string
+
+	// CacheTTL is the prompt-cache TTL, "5m" or "1h"; "" keeps the 5-minute
+	// default. Ignored by Codex.
+	CacheTTL string
+
+	// ToolResultsAsUser encodes tool results as user messages for endpoints
+	// that reject role "tool". Ignored by Codex.
+	ToolResultsAsUser bool
+
+	// Breaker wraps the provider in a circuit breaker; a zero Threshold
+	// disables it.
+	Breaker BreakerConfig
+
+	// Middleware for the OpenAI-compatible / Anthropic provider; ignored by Codex.
+	RequestHooks  []RequestHook
+	ResponseHooks []ResponseHook
 }
 
 // New creates the appropriate schema.LLMProvider for the given params.
@@ -19,9 +44,37 @@ type Params struct {
 //   - otherwise    → OpenAIProvider (direct HTTP, handles all OpenAI-compat providers
 //     including Anthropic native API)
 func New(p Params) schema.LLMProvider {
+	provider := newProvider(p)
+	if p.Breaker.Threshold > 0 {
+		return NewBreaker(provider, p.Breaker)
+	}
+	return provider
+}
+
+func newProvider(p Params) schema.LLMProvider {
 	if p.ProviderName == "openai_codex" ||
 		p.ProviderName == "openai-codex" {
-		return NewCodexProvider(p.DefaultModel)
+		provider := NewCodexProvider(p.DefaultModel)
+		if p.DebugHTTPLog != "" {
+			provider.httpClient = withDebugLog(provider.httpClient, logfile.NewRotating(p.DebugHTTPLog, debugLogMaxBytes, debugLogBackups))
+		}
+		return provider
+	}
+	provider := NewOpenAIProvider(p.APIKey, p.APIBase, p.DefaultModel, p.ProviderName, p.ExtraHeaders)
+	if p.DebugHTTPLog != "" {
+		provider.httpClient = withDebugLog(provider.httpClient, logfile.NewRotating(p.DebugHTTPLog, debugLogMaxBytes, debugLogBackups))
+	}
+	if err := provider.SetCacheTTL(p.CacheTTL); err != nil {
+		slog.Warn("ignoring cacheTtl", "provider", p.ProviderName, "err", err)
+	}
+	if p.ToolResultsAsUser {
+		provider.SetToolResultsAsUser(true)
+	}
+	for _, h := range p.RequestHooks {
+		provider.AddRequestHook(h)
+	}
+	for _, h := range p.ResponseHooks {
+		provider.AddResponseHook(h)
 	}
-	return NewOpenAIProvider(p.APIKey, p.APIBase, p.DefaultModel, p.ProviderName, p.ExtraHeaders)
+	return provider
 }
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -25,8 +25,23 @@ type OpenAIProvider struct {
 	spec         *ProviderSpec // non-nil for standard providers
 	isAnthropic  bool
 	httpClient   *http.Client
+	cooldown     cooldown // shared rate-limit gate; see send
+	cacheTTL     string   // prompt-cache TTL; "" is the API default (5m)
+	toolsAsUser  bool     // config override; see toolResultsAsUser
+
+	requestHooks  []RequestHook
+	responseHooks []ResponseHook
 }
 
+// RequestHook inspects or mutates a request body just before it is encoded
+// and sent. The body is in the wire format of the endpoint being called
+// (OpenAI chat/completions or Anthropic messages).
+type RequestHook func(body map[string]any)
+
+// ResponseHook inspects or mutates a parsed response before Chat returns it.
+// It is not called for transport or HTTP-status errors.
+type ResponseHook func(resp *schema.LLMResponse)
+
 // NewOpenAIProvider constructs a provider from raw config values.
 // The caller extracts these from config.Config to avoid an import cycle.
 func NewOpenAIProvider(
@@ -73,6 +88,35 @@ func NewOpenAIProvider(
 
 func (p *OpenAIProvider) DefaultModel() string { return p.defaultModel }
 
+// AddRequestHook appends h to the hooks run, in order, on every request body.
+// Hooks must be added before the provider is used.
+func (p *OpenAIProvider) AddRequestHook(h RequestHook) {
+	p.requestHooks = append(p.requestHooks, h)
+}
+
+// AddResponseHook appends h to the hooks run, in order, on every parsed
+// response. Hooks must be added before the provider is used.
+func (p *OpenAIProvider) AddResponseHook(h ResponseHook) {
+	p.responseHooks = append(p.responseHooks, h)
+}
+
+func (p *OpenAIProvider) runRequestHooks(body map[string]any) {
+	for _, h := range p.requestHooks {
+		h(body)
+	}
+}
+
+// runResponseHooks applies the response hooks to a successfully parsed resp.
+func (p *OpenAIProvider) runResponseHooks(resp schema.LLMResponse, err error) (schema.LLMResponse, error) {
+	if err != nil {
+		return resp, err
+	}
+	for _, h := range p.responseHooks {
+		h(&resp)
+	}
+	return resp, nil
+}
+
 // Chat implements schema.LLMProvider. It dispatches to Anthropic or OpenAI-compat paths.
 func (p *OpenAIProvider) Chat(
 	ctx context.Context,
@@ -88,7 +132,7 @@ func (p *OpenAIProvider) Chat(
 
 	// Prompt caching (Anthropic + OpenRouter).
 	if p.supportsPromptCaching(origModel) {
-		messages, tools = applyCacheControl(messages, tools)
+		messages, tools = applyCacheControl(messages, tools, p.cacheTTL)
 	}
 
 	maxTokens := opts.MaxTokens
@@ -100,7 +144,7 @@ func (p *OpenAIProvider) Chat(
 		return p.chatAnthropic(ctx, messages, tools, p.resolveModel(model), maxTokens, opts.Temperature)
 	}
 
-	return p.chatOpenAI(ctx, messages, tools, p.resolveModel(model), maxTokens, opts.Temperature)
+	return p.chatOpenAI(ctx, messages, tools, p.resolveModel(model), maxTokens, opts.Temperature, opts.N)
 }
 
 // ---------------------------------------------------------------------------
@@ -114,18 +158,23 @@ func (p *OpenAIProvider) chatOpenAI(
 	model string,
 	maxTokens int,
 	temperature float64,
+	n int,
 ) (schema.LLMResponse, error) {
 	body := map[string]any{
 		"model":       model,
-		"messages":    sanitizeMessages(messages),
+		"messages":    sanitizeMessages(messages, p.toolResultsAsUser()),
 		"max_tokens":  maxTokens,
 		"temperature": temperature,
 	}
+	if n > 1 {
+		body["n"] = n
+	}
 	if len(tools) > 0 {
 		body["tools"] = tools
 		body["tool_choice"] = "auto"
 	}
 	p.applyModelOverrides(model, body)
+	p.runRequestHooks(body)
 
 	data, err := json.Marshal(body)
 	if err != nil {
@@ -143,21 +192,18 @@ func (p *OpenAIProvider) chatOpenAI(
 		req.Header.Set(k, v)
 	}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return schema.LLMResponse{}, fmt.Errorf("HTTP request: %w", err)
+	status, raw, err := p.send(req)
+	if rl := (*rateLimitedError)(nil); errors.As(err, &rl) {
+		return errResponse(rl.Error())
 	}
-	defer resp.Body.Close()
-
-	raw, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return schema.LLMResponse{}, fmt.Errorf("read response: %w", err)
+		return schema.LLMResponse{}, fmt.Errorf("HTTP request: %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return errResponse(fmt.Sprintf("HTTP %d: %s", resp.StatusCode, friendlyHTTPError(resp.StatusCode, raw)))
+	if status != http.StatusOK {
+		return errResponse(fmt.Sprintf("HTTP %d: %s", status, friendlyHTTPError(status, raw)))
 	}
 
-	return parseOpenAIResponse(raw)
+	return p.runResponseHooks(parseOpenAIResponse(raw))
 }
 
 // ---------------------------------------------------------------------------
@@ -180,12 +226,13 @@ func (p *OpenAIProvider) chatAnthropic(
 		"max_tokens":  maxTokens,
 		"temperature": temperature,
 	}
-	if system != "" {
+	if system != nil {
 		body["system"] = system
 	}
 	if len(tools) > 0 {
 		body["tools"] = convertToolsToAnthropic(tools)
 	}
+	p.runRequestHooks(body)
 
 	data, err := json.Marshal(body)
 	if err != nil {
@@ -204,21 +251,18 @@ func (p *OpenAIProvider) chatAnthropic(
 		req.Header.Set(k, v)
 	}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return schema.LLMResponse{}, fmt.Errorf("anthropic HTTP request: %w", err)
+	status, raw, err := p.send(req)
+	if rl := (*rateLimitedError)(nil); errors.As(err, &rl) {
+		return errResponse(rl.Error())
 	}
-	defer resp.Body.Close()
-
-	raw, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return schema.LLMResponse{}, fmt.Errorf("read anthropic response: %w", err)
+		return schema.LLMResponse{}, fmt.Errorf("anthropic HTTP request: %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return errResponse(fmt.Sprintf("HTTP %d: %s", resp.StatusCode, friendlyHTTPError(resp.StatusCode, raw)))
+	if status != http.StatusOK {
+		return errResponse(fmt.Sprintf("HTTP %d: %s", status, friendlyHTTPError(status, raw)))
 	}
 
-	return parseAnthropicResponse(raw)
+	return p.runResponseHooks(parseAnthropicResponse(raw))
 }
 
 // ---------------------------------------------------------------------------
@@ -290,9 +334,40 @@ func (p *OpenAIProvider) supportsPromptCaching(model string) bool {
 	return spec != nil && spec.SupportsPromptCaching
 }
 
-// applyCacheControl injects cache_control ephemeral blocks on the last system
-// message content block and the last tool definition.
-func applyCacheControl(messages schema.Messages, tools []map[string]any) (schema.Messages, []map[string]any) {
+// Prompt-cache TTLs accepted by SetCacheTTL.
+const (
+	CacheTTL5m = "5m"
+	CacheTTL1h = "1h"
+)
+
+// SetCacheTTL sets the lifetime of prompt-cache breakpoints: CacheTTL5m (the
+// default, also used for "") or CacheTTL1h, which costs more to write but
+// keeps long-lived system prompts cached across sparse turns.
+func (p *OpenAIProvider) SetCacheTTL(ttl string) error {
+	switch ttl {
+	case "", CacheTTL5m:
+		p.cacheTTL = ""
+	case CacheTTL1h:
+		p.cacheTTL = ttl
+	default:
+		return fmt.Errorf("unsupported prompt cache TTL %q (want %q or %q)", ttl, CacheTTL5m, CacheTTL1h)
+	}
+	return nil
+}
+
+// cacheControl returns a cache_control block; ttl is omitted when empty so
+// the default 5-minute cache keeps the exact wire shape it always had.
+func cacheControl(ttl string) map[string]any {
+	cc := map[string]any{"type": "ephemeral"}
+	if ttl != "" {
+		cc["ttl"] = ttl
+	}
+	return cc
+}
+
+// applyCacheControl injects cache_control ephemeral blocks, with the given
+// ttl, on the last system message content block and the last tool definition.
+func applyCacheControl(messages schema.Messages, tools []map[string]any, ttl string) (schema.Messages, []map[string]any) {
 	out := schema.NewMessages()
 	out.Messages = make([]schema.Message, len(messages.Messages))
 	for i, msg := range messages.Messages {
@@ -301,7 +376,7 @@ func applyCacheControl(messages schema.Messages, tools []map[string]any) (schema
 			switch c := msg.Content.(type) {
 			case string:
 				newMsg.Content = []any{
-					map[string]any{"type": "text", "text": c, "cache_control": map[string]any{"type": "ephemeral"}},
+					map[string]any{"type": "text", "text": c, "cache_control": cacheControl(ttl)},
 				}
 			case []any:
 				arr := make([]any, len(c))
@@ -309,7 +384,7 @@ func applyCacheControl(messages schema.Messages, tools []map[string]any) (schema
 				if len(arr) > 0 {
 					if m, ok := arr[len(arr)-1].(map[string]any); ok {
 						last := copyAnyMap(m)
-						last["cache_control"] = map[string]any{"type": "ephemeral"}
+						last["cache_control"] = cacheControl(ttl)
 						arr[len(arr)-1] = last
 					}
 				}
@@ -327,7 +402,7 @@ func applyCacheControl(messages schema.Messages, tools []map[string]any) (schema
 	newTools := make([]map[string]any, len(tools))
 	copy(newTools, tools)
 	last := copyMap(newTools[len(newTools)-1])
-	last["cache_control"] = map[string]any{"type": "ephemeral"}
+	last["cache_control"] = cacheControl(ttl)
 	newTools[len(newTools)-1] = last
 	return out, newTools
 }
@@ -365,14 +440,58 @@ func messageToWireMap(m schema.Message) map[string]any {
 	return wire
 }
 
-func sanitizeMessages(messages schema.Messages) []map[string]any {
+// toolResultAsUserMap encodes a tool result as a user message for endpoints
+// that do not accept role "tool". The tool name and call ID are kept in a
+// header line so the model can still match the result to its call.
+func toolResultAsUserMap(m schema.Message) map[string]any {
+	var content string
+	switch c := m.Content.(type) {
+	case string:
+		content = c
+	case *string:
+		if c != nil {
+			content = *c
+		}
+	default:
+		if c != nil {
+			b, _ := json.Marshal(c)
+			content = string(b)
+		}
+	}
+	return map[string]any{
+		"role":    schema.RoleUser,
+		"content": fmt.Sprintf("[Tool result: %s (id: %s)]\n%s", m.ToolName, m.ToolCallID, content),
+	}
+}
+
+func sanitizeMessages(messages schema.Messages, toolResultsAsUser bool) []map[string]any {
 	out := make([]map[string]any, 0, len(messages.Messages))
 	for _, m := range messages.Messages {
+		if toolResultsAsUser && m.Role == schema.RoleTool {
+			out = append(out, toolResultAsUserMap(m))
+			continue
+		}
 		out = append(out, messageToWireMap(m))
 	}
 	return out
 }
 
+// SetToolResultsAsUser forces tool results to be encoded as user messages,
+// for endpoints whose registry spec does not say they need it.
+func (p *OpenAIProvider) SetToolResultsAsUser(on bool) { p.toolsAsUser = on }
+
+// toolResultsAsUser reports whether tool results must be encoded as user
+// messages: when configured, or when the matched provider's spec says so.
+func (p *OpenAIProvider) toolResultsAsUser() bool {
+	if p.toolsAsUser {
+		return true
+	}
+	if p.gateway != nil {
+		return p.gateway.ToolResultsAsUser
+	}
+	return p.spec != nil && p.spec.ToolResultsAsUser
+}
+
 // ---------------------------------------------------------------------------
 // Model overrides
 // ---------------------------------------------------------------------------
@@ -404,18 +523,25 @@ func (p *OpenAIProvider) applyModelOverrides(model string, body map[string]any)
 
 // convertMessagesToAnthropic converts typed messages to Anthropic's wire format.
 // Returns (system_prompt, converted_messages).
-func convertMessagesToAnthropic(messages schema.Messages) (string, []map[string]any) {
-	var system string
+func convertMessagesToAnthropic(messages schema.Messages) (any, []map[string]any) {
+	var systemBlocks []map[string]any
 	var out []map[string]any
 
 	for _, msg := range messages.Messages {
 		switch msg.Role {
 		case "system":
-			if s, ok := msg.Content.(string); ok {
-				if system != "" {
-					system += "\n\n"
+			switch c := msg.Content.(type) {
+			case string:
+				if c != "" {
+					systemBlocks = append(systemBlocks, map[string]any{"type": "text", "text": c})
+				}
+			case []any:
+				// Content blocks, e.g. from applyCacheControl.
+				for _, b := range c {
+					if m, ok := b.(map[string]any); ok && m["type"] == "text" {
+						systemBlocks = append(systemBlocks, m)
+					}
 				}
-				system += s
 			}
 
 		case "user":
@@ -464,7 +590,26 @@ func convertMessagesToAnthropic(messages schema.Messages) (string, []map[string]
 			out = append(out, map[string]any{"role": "assistant", "content": blocks})
 		}
 	}
-	return system, out
+	return anthropicSystem(systemBlocks), out
+}
+
+// anthropicSystem returns the system field for the Messages API: nil when
+// there is no system prompt, a plain string when no block carries
+// cache_control, and the blocks themselves otherwise so the cache breakpoint
+// reaches the API.
+func anthropicSystem(blocks []map[string]any) any {
+	if len(blocks) == 0 {
+		return nil
+	}
+	texts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		if _, ok := b["cache_control"]; ok {
+			return blocks
+		}
+		text, _ := b["text"].(string)
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, "\n\n")
 }
 
 // convertToolsToAnthropic converts OpenAI function schemas to Anthropic tool format.
@@ -481,7 +626,7 @@ func convertToolsToAnthropic(tools []map[string]any) []map[string]any {
 			"description":  fn["description"],
 			"input_schema": fn["parameters"],
 		}
-		// Forward cache_control if present (prompt caching).
+		// Forward cache_control, including any ttl, if present (prompt caching).
 		if cc, ok := t["cache_control"]; ok {
 			at["cache_control"] = cc
 		}
@@ -494,29 +639,73 @@ func normalizeContentForAnthropic(content any) any {
 	if content == nil {
 		return []any{map[string]any{"type": "input_text", "text": ""}}
 	}
-	if s, ok := content.(string); ok {
-		return s // Anthropic accepts plain string for user messages
+	switch c := content.(type) {
+	case string:
+		return c // Anthropic accepts plain string for user messages
+	case []map[string]any:
+		out := make([]any, len(c))
+		for i, b := range c {
+			out[i] = imageBlockForAnthropic(b)
+		}
+		return out
+	case []any:
+		out := make([]any, len(c))
+		for i, b := range c {
+			if m, ok := b.(map[string]any); ok {
+				out[i] = imageBlockForAnthropic(m)
+			} else {
+				out[i] = b
+			}
+		}
+		return out
 	}
 	return content
 }
 
+// imageBlockForAnthropic converts an OpenAI image_url block to an Anthropic
+// image block (base64 for data: URLs, url otherwise); other blocks are
+// returned unchanged.
+func imageBlockForAnthropic(b map[string]any) any {
+	if b["type"] != "image_url" {
+		return b
+	}
+	iu, _ := b["image_url"].(map[string]any)
+	url, _ := iu["url"].(string)
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		if meta, data, ok := strings.Cut(rest, ","); ok && strings.HasSuffix(meta, ";base64") {
+			return map[string]any{
+				"type": "image",
+				"source": map[string]any{
+					"type":       "base64",
+					"media_type": strings.TrimSuffix(meta, ";base64"),
+					"data":       data,
+				},
+			}
+		}
+	}
+	return map[string]any{"type": "image", "source": map[string]any{"type": "url", "url": url}}
+}
+
 // ---------------------------------------------------------------------------
 // Response parsers
 // ---------------------------------------------------------------------------
 
+// openAIToolCall is one entry of a choice's "tool_calls" array.
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
 // openAIRespBody is the subset of the OpenAI chat completion response we care about.
 type openAIRespBody struct {
 	Choices []struct {
 		Message struct {
-			Content          any `json:"content"`
-			ReasoningContent any `json:"reasoning_content"`
-			ToolCalls        []struct {
-				ID       string `json:"id"`
-				Function struct {
-					Name      string `json:"name"`
-					Arguments string `json:"arguments"`
-				} `json:"function"`
-			} `json:"tool_calls"`
+			Content          any              `json:"content"`
+			ReasoningContent any              `json:"reasoning_content"`
+			ToolCalls        []openAIToolCall `json:"tool_calls"`
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
@@ -536,10 +725,32 @@ func parseOpenAIResponse(raw []byte) (schema.LLMResponse, error) {
 		return schema.LLMResponse{}, fmt.Errorf("empty choices in response")
 	}
 
-	msg := body.Choices[0].Message
+	choices := make([]schema.Choice, 0, len(body.Choices))
+	for _, c := range body.Choices {
+		choices = append(choices, parseOpenAIChoice(c.Message.Content, c.Message.ReasoningContent, c.Message.ToolCalls, c.FinishReason))
+	}
+
+	usage := map[string]int{
+		"prompt_tokens":     body.Usage.PromptTokens,
+		"completion_tokens": body.Usage.CompletionTokens,
+		"total_tokens":      body.Usage.TotalTokens,
+	}
+
+	first := choices[0]
+	return schema.LLMResponse{
+		Content:          first.Content,
+		ToolCalls:        first.ToolCalls,
+		FinishReason:     first.FinishReason,
+		Usage:            usage,
+		ReasoningContent: first.ReasoningContent,
+		Choices:          choices,
+	}, nil
+}
 
+// parseOpenAIChoice normalises one entry of the "choices" array.
+func parseOpenAIChoice(rawContent, rawReasoning any, rawToolCalls []openAIToolCall, finish string) schema.Choice {
 	var content *string
-	switch c := msg.Content.(type) {
+	switch c := rawContent.(type) {
 	case string:
 		if c != "" {
 			content = &c
@@ -547,7 +758,7 @@ func parseOpenAIResponse(raw []byte) (schema.LLMResponse, error) {
 	}
 
 	var reasoningContent *string
-	switch r := msg.ReasoningContent.(type) {
+	switch r := rawReasoning.(type) {
 	case string:
 		if r != "" {
 			reasoningContent = &r
@@ -555,7 +766,7 @@ func parseOpenAIResponse(raw []byte) (schema.LLMResponse, error) {
 	}
 
 	var toolCalls []schema.ToolCallRequest
-	for _, tc := range msg.ToolCalls {
+	for _, tc := range rawToolCalls {
 		args, err := repairJSON(tc.Function.Arguments)
 		if err != nil {
 			slog.Warn("failed to parse tool arguments", "tool", tc.Function.Name, "err", err)
@@ -568,24 +779,16 @@ func parseOpenAIResponse(raw []byte) (schema.LLMResponse, error) {
 		})
 	}
 
-	usage := map[string]int{
-		"prompt_tokens":     body.Usage.PromptTokens,
-		"completion_tokens": body.Usage.CompletionTokens,
-		"total_tokens":      body.Usage.TotalTokens,
-	}
-
-	finish := body.Choices[0].FinishReason
 	if finish == "" {
 		finish = "stop"
 	}
 
-	return schema.LLMResponse{
+	return schema.Choice{
 		Content:          content,
 		ToolCalls:        toolCalls,
 		FinishReason:     finish,
-		Usage:            usage,
 		ReasoningContent: reasoningContent,
-	}, nil
+	}
 }
 
 // anthropicRespBody models the Anthropic Messages API response.
@@ -634,6 +837,8 @@ func parseAnthropicResponse(raw []byte) (schema.LLMResponse, error) {
 	finish := "stop"
 	if body.StopReason == "tool_use" {
 		finish = "tool_calls"
+	} else if body.StopReason == "max_tokens" {
+		finish = "length" // OpenAI's name, which the agent loop checks for
 	} else if body.StopReason != "" && body.StopReason != "end_turn" {
 		finish = body.StopReason
 	}
@@ -0,0 +1,208 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+func TestParseOpenAIResponse_MultipleChoices(t *testing.T) {
+	raw := []byte(`{
+		"choices": [
+			{"message": {"content": "first"}, "finish_reason": "stop"},
+			{"message": {"content": "second"}, "finish_reason": "length"},
+			{"message": {"content": null, "tool_calls": [
+				{"id": "call_1", "function": {"name": "exec", "arguments": "{\"command\":\"ls\"}"}}
+			]}, "finish_reason": "tool_calls"}
+		],
+		"usage": {"prompt_tokens": 10, "completion_tokens": 20, "total_tokens": 30}
+	}`)
+
+	resp, err := parseOpenAIResponse(raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Choices) != 3 {
+		t.Fatalf("expected 3 choices, got %d", len(resp.Choices))
+	}
+	if resp.Content == nil || *resp.Content != "first" {
+		t.Errorf("expected top-level content to mirror choice 0, got %v", resp.Content)
+	}
+	if c := resp.Choices[1]; c.Content == nil || *c.Content != "second" || c.FinishReason != "length" {
+		t.Errorf("unexpected choice 1: %+v", c)
+	}
+	if c := resp.Choices[2]; len(c.ToolCalls) != 1 || c.ToolCalls[0].Arguments["command"] != "ls" {
+		t.Errorf("unexpected choice 2 tool calls: %+v", c.ToolCalls)
+	}
+}
+
+func TestParseAnthropicResponse_MaxTokensIsLength(t *testing.T) {
+	resp, err := parseAnthropicResponse([]byte(`{"content":[{"type":"text","text":"cut"}],"stop_reason":"max_tokens"}`))
+	if err != nil || resp.FinishReason != "length" {
+		t.Errorf("expected finish reason length, got %q (err %v)", resp.FinishReason, err)
+	}
+}
+
+func TestSanitizeMessages_ToolResultsAsUser(t *testing.T) {
+	msgs := schema.Messages{Messages: []schema.Message{
+		schema.NewUserMessage("list files"),
+		schema.NewToolResultMessage("call_1", "exec", "a.txt\nb.txt"),
+	}}
+
+	p := &OpenAIProvider{spec: &ProviderSpec{Name: "legacy", ToolResultsAsUser: true}}
+	out := sanitizeMessages(msgs, p.toolResultsAsUser())
+
+	got := out[1]
+	if got["role"] != schema.RoleUser {
+		t.Fatalf("expected tool result encoded as user, got role %v", got["role"])
+	}
+	if _, ok := got["tool_call_id"]; ok {
+		t.Error("expected no tool_call_id on user-encoded result")
+	}
+	content, _ := got["content"].(string)
+	if !strings.Contains(content, "exec") || !strings.Contains(content, "call_1") || !strings.Contains(content, "a.txt\nb.txt") {
+		t.Errorf("unexpected content: %q", content)
+	}
+
+	std := sanitizeMessages(msgs, (&OpenAIProvider{}).toolResultsAsUser())
+	if std[1]["role"] != schema.RoleTool {
+		t.Errorf("expected standard tool role by default, got %v", std[1]["role"])
+	}
+
+	configured, ok := New(Params{ProviderName: "custom", APIBase: "http://localhost:8000/v1", ToolResultsAsUser: true}).(*OpenAIProvider)
+	if !ok || !configured.toolResultsAsUser() {
+		t.Error("expected providers.<name>.toolResultsAsUser to switch the encoding on")
+	}
+}
+
+func TestChat_RequestAndResponseHooks(t *testing.T) {
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"choices":[{"message":{"content":"hello"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider("key", srv.URL, "gpt-4o", "openai", nil)
+	p.AddRequestHook(func(body map[string]any) {
+		delete(body, "temperature")
+		body["user"] = "tenant-7"
+	})
+	p.AddResponseHook(func(resp *schema.LLMResponse) {
+		s := strings.ToUpper(*resp.Content)
+		resp.Content = &s
+	})
+
+	msgs := schema.NewMessages()
+	msgs.AddUser("hi")
+	resp, err := p.Chat(context.Background(), msgs, nil, schema.NewChatOptions("", 100, 0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := sent["temperature"]; ok {
+		t.Errorf("expected temperature to be stripped, got body %v", sent)
+	}
+	if sent["user"] != "tenant-7" {
+		t.Errorf("expected injected user field, got %v", sent["user"])
+	}
+	if resp.Content == nil || *resp.Content != "HELLO" {
+		t.Errorf("expected response hook to run, got %v", resp.Content)
+	}
+}
+
+func TestChatAnthropic_CacheTTL(t *testing.T) {
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = nil
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn"}`))
+	}))
+	defer srv.Close()
+
+	msgs := schema.NewMessages()
+	msgs.AddSystem("You are a long-lived assistant.")
+	msgs.AddUser("hi")
+	tools := []map[string]any{{"type": "function", "function": map[string]any{"name": "exec", "parameters": map[string]any{}}}}
+
+	cacheControls := func() (system, tool map[string]any) {
+		blocks, _ := sent["system"].([]any)
+		if len(blocks) != 1 {
+			t.Fatalf("expected the system prompt as one cached block, got %v", sent["system"])
+		}
+		system, _ = blocks[0].(map[string]any)["cache_control"].(map[string]any)
+		tool, _ = sent["tools"].([]any)[0].(map[string]any)["cache_control"].(map[string]any)
+		return system, tool
+	}
+
+	p := NewOpenAIProvider("key", srv.URL, "claude-sonnet-4-5", "anthropic", nil)
+	if _, err := p.Chat(context.Background(), msgs, tools, schema.NewChatOptions("", 100, 0)); err != nil {
+		t.Fatal(err)
+	}
+	system, tool := cacheControls()
+	if system["type"] != "ephemeral" || tool["type"] != "ephemeral" {
+		t.Fatalf("expected ephemeral cache_control, got %v and %v", system, tool)
+	}
+	if _, ok := system["ttl"]; ok {
+		t.Errorf("expected no ttl by default, got %v", system)
+	}
+	if _, ok := tool["ttl"]; ok {
+		t.Errorf("expected no ttl by default, got %v", tool)
+	}
+
+	if err := p.SetCacheTTL(CacheTTL1h); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Chat(context.Background(), msgs, tools, schema.NewChatOptions("", 100, 0)); err != nil {
+		t.Fatal(err)
+	}
+	system, tool = cacheControls()
+	if system["ttl"] != "1h" || tool["ttl"] != "1h" {
+		t.Errorf("expected ttl 1h on system and tools, got %v and %v", system, tool)
+	}
+
+	if err := p.SetCacheTTL("24h"); err == nil {
+		t.Error("expected an unsupported ttl to be rejected")
+	}
+}
+
+func TestConvertMessagesToAnthropic_PlainSystemStaysString(t *testing.T) {
+	msgs := schema.NewMessages()
+	msgs.AddSystem("one")
+	msgs.AddSystem("two")
+	system, _ := convertMessagesToAnthropic(msgs)
+	if system != "one\n\ntwo" {
+		t.Errorf("expected joined system string, got %#v", system)
+	}
+	if system, _ := convertMessagesToAnthropic(schema.NewMessages()); system != nil {
+		t.Errorf("expected no system prompt, got %#v", system)
+	}
+}
+
+func TestNormalizeContentForAnthropic_Images(t *testing.T) {
+	content := []map[string]any{
+		{"type": "image_url", "image_url": map[string]any{"url": "data:image/png;base64,AAAA"}},
+		{"type": "image_url", "image_url": map[string]any{"url": "https://example.com/cat.jpg"}},
+		{"type": "text", "text": "what is this?"},
+	}
+	out, ok := normalizeContentForAnthropic(content).([]any)
+	if !ok || len(out) != 3 {
+		t.Fatalf("expected three blocks, got %#v", out)
+	}
+	b64, _ := out[0].(map[string]any)["source"].(map[string]any)
+	if b64["type"] != "base64" || b64["media_type"] != "image/png" || b64["data"] != "AAAA" {
+		t.Errorf("unexpected base64 image block: %v", out[0])
+	}
+	remote, _ := out[1].(map[string]any)["source"].(map[string]any)
+	if remote["type"] != "url" || remote["url"] != "https://example.com/cat.jpg" {
+		t.Errorf("unexpected url image block: %v", out[1])
+	}
+	if out[2].(map[string]any)["text"] != "what is this?" {
+		t.Errorf("expected text block unchanged, got %v", out[2])
+	}
+}
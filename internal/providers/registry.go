@@ -44,6 +44,9 @@ type ProviderSpec struct {
 
 	// Provider supports cache_control on content blocks (Anthropic prompt caching)
 	SupportsPromptCaching bool
+
+	// Endpoint rejects role "tool"; send tool results as role "user" instead
+	ToolResultsAsUser bool
 }
 
 // Label returns the display name, defaulting to Title-cased Name.
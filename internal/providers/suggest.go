@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxModelSuggestions caps how many providers a ModelNotFoundError proposes.
+const maxModelSuggestions = 3
+
+// Suggestion is a registry keyword that resembles part of an unrecognised
+// model name.
+type Suggestion struct {
+	Keyword  string // e.g. "claude"
+	Provider string // display name, e.g. "Anthropic"
+}
+
+// ModelNotFoundError reports a model name that no registry entry recognises,
+// with the closest provider keywords.
+type ModelNotFoundError struct {
+	Model       string
+	Suggestions []Suggestion
+}
+
+func (e *ModelNotFoundError) Error() string {
+	msg := fmt.Sprintf("unknown model %q: no provider recognises it", e.Model)
+	if len(e.Suggestions) == 0 {
+		return msg + "; prefix it with a provider name, e.g. \"anthropic/claude-opus-4-5\""
+	}
+	quoted := make([]string, len(e.Suggestions))
+	for i, s := range e.Suggestions {
+		quoted[i] = fmt.Sprintf("%q (%s)", s.Keyword, s.Provider)
+	}
+	return msg + "; did you mean " + strings.Join(quoted, " or ") + "?"
+}
+
+// CheckModel returns a *ModelNotFoundError when model neither carries a known
+// provider prefix (gateways included) nor contains a standard provider's
+// keyword. A nil result does not mean a provider is configured for it.
+func CheckModel(model string) error {
+	if prefix, _, ok := strings.Cut(strings.ToLower(model), "/"); ok {
+		if FindByName(strings.ReplaceAll(prefix, "-", "_")) != nil {
+			return nil
+		}
+	}
+	if FindByModel(model) != nil {
+		return nil
+	}
+	return &ModelNotFoundError{Model: model, Suggestions: SuggestProviders(model)}
+}
+
+// SuggestProviders returns registry keywords within a small edit distance of
+// a token of model (so "claud-opus" suggests "claude"), closest first and at
+// most one per provider.
+func SuggestProviders(model string) []Suggestion {
+	tokens := strings.FieldsFunc(strings.ToLower(model), func(r rune) bool {
+		return strings.ContainsRune("/-_.: ", r)
+	})
+
+	type candidate struct {
+		Suggestion
+		dist, order int
+	}
+	best := map[string]candidate{} // provider name → closest keyword
+	for order, spec := range PROVIDERS {
+		for _, kw := range append([]string{spec.Name}, spec.Keywords...) {
+			kw = strings.ToLower(kw)
+			limit := len(kw) / 3
+			if limit == 0 {
+				continue // too short to guess at
+			}
+			for _, tok := range tokens {
+				d := levenshtein(tok, kw)
+				if d == 0 || d > limit {
+					continue
+				}
+				if c, ok := best[spec.Name]; !ok || d < c.dist {
+					best[spec.Name] = candidate{Suggestion{Keyword: kw, Provider: spec.Label()}, d, order}
+				}
+			}
+		}
+	}
+
+	cands := make([]candidate, 0, len(best))
+	for _, c := range best {
+		cands = append(cands, c)
+	}
+	sort.Slice(cands, func(i, j int) bool {
+		if cands[i].dist != cands[j].dist {
+			return cands[i].dist < cands[j].dist
+		}
+		return cands[i].order < cands[j].order
+	})
+	if len(cands) > maxModelSuggestions {
+		cands = cands[:maxModelSuggestions]
+	}
+	out := make([]Suggestion, len(cands))
+	for i, c := range cands {
+		out[i] = c.Suggestion
+	}
+	return out
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
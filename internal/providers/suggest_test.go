@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckModel_TypoSuggestsIntendedProvider(t *testing.T) {
+	err := CheckModel("claud-opus")
+	var nf *ModelNotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("expected ModelNotFoundError, got %v", err)
+	}
+	if len(nf.Suggestions) == 0 || nf.Suggestions[0].Keyword != "claude" || nf.Suggestions[0].Provider != "Anthropic" {
+		t.Fatalf("expected claude/Anthropic as the first suggestion, got %+v", nf.Suggestions)
+	}
+	if !strings.Contains(err.Error(), `did you mean "claude" (Anthropic)`) {
+		t.Errorf("unexpected message: %s", err)
+	}
+
+	if err := CheckModel("gemnii-2.5-pro"); err == nil || !strings.Contains(err.Error(), `"gemini"`) {
+		t.Errorf("expected gemini suggestion, got %v", err)
+	}
+}
+
+func TestCheckModel_KnownModels(t *testing.T) {
+	for _, m := range []string{"anthropic/claude-opus-4-5", "gpt-4o", "openrouter/mistral-large", "deepseek-chat"} {
+		if err := CheckModel(m); err != nil {
+			t.Errorf("%s: unexpected error %v", m, err)
+		}
+	}
+}
+
+func TestCheckModel_NoSuggestion(t *testing.T) {
+	err := CheckModel("zzzzzz")
+	var nf *ModelNotFoundError
+	if !errors.As(err, &nf) || len(nf.Suggestions) != 0 {
+		t.Fatalf("expected an error without suggestions, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package repl
+
+import (
+	"strings"
+)
+
+// CommandKind classifies a line typed at the REPL prompt.
+type CommandKind int
+
+const (
+	CmdEmpty   CommandKind = iota // blank line
+	CmdExit                       // exit, quit, :q, /exit, /quit
+	CmdClear                      // /clear — clear the screen
+	CmdHistory                    // /history — list recent input
+	CmdAgent                      // an agent slash command (/new, /model, /stop, /status, /help)
+	CmdUnknown                    // a /word that is not a known command
+	CmdMessage                    // anything else, sent to the agent as a message
+)
+
+// agentCommands are the slash commands handled by the agent loop.
+var agentCommands = map[string]bool{
+	"/new":    true,
+	"/model":  true,
+	"/stop":   true,
+	"/status": true,
+	"/help":   true,
+}
+
+var exitCommands = map[string]bool{
+	"exit":  true,
+	"quit":  true,
+	":q":    true,
+	"/exit": true,
+	"/quit": true,
+}
+
+// Command is a parsed input line.
+type Command struct {
+	Kind CommandKind
+	Name string // lower-cased command word for CmdAgent / CmdUnknown, e.g. "/model"
+	Text string // the trimmed line, as sent to the agent
+}
+
+// ParseLine classifies line. Only a leading "/word" made of letters is
+// treated as a command, so a message such as "/etc/hosts is empty" still
+// reaches the agent.
+func ParseLine(line string) Command {
+	text := strings.TrimSpace(line)
+	if text == "" {
+		return Command{Kind: CmdEmpty}
+	}
+	if exitCommands[strings.ToLower(text)] {
+		return Command{Kind: CmdExit, Text: text}
+	}
+
+	word, _, _ := strings.Cut(text, " ")
+	name := strings.ToLower(word)
+	if !isCommandWord(name) {
+		return Command{Kind: CmdMessage, Text: text}
+	}
+	switch {
+	case name == "/clear":
+		return Command{Kind: CmdClear, Name: name, Text: text}
+	case name == "/history":
+		return Command{Kind: CmdHistory, Name: name, Text: text}
+	case agentCommands[name]:
+		// The agent matches commands case-insensitively except /model, whose
+		// argument is a model name; lower-case only the command word.
+		return Command{Kind: CmdAgent, Name: name, Text: name + text[len(word):]}
+	default:
+		return Command{Kind: CmdUnknown, Name: name, Text: text}
+	}
+}
+
+// isCommandWord reports whether w is "/" followed by one or more letters.
+func isCommandWord(w string) bool {
+	if len(w) < 2 || w[0] != '/' {
+		return false
+	}
+	for _, r := range w[1:] {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package repl
+
+import "testing"
+
+func TestParseLine(t *testing.T) {
+	cases := []struct {
+		line string
+		kind CommandKind
+		name string
+		text string
+	}{
+		{"", CmdEmpty, "", ""},
+		{"   ", CmdEmpty, "", ""},
+		{"exit", CmdExit, "", "exit"},
+		{" QUIT ", CmdExit, "", "QUIT"},
+		{":q", CmdExit, "", ":q"},
+		{"/exit", CmdExit, "", "/exit"},
+		{"/clear", CmdClear, "/clear", "/clear"},
+		{"/history", CmdHistory, "/history", "/history"},
+		{"/new", CmdAgent, "/new", "/new"},
+		{"/STATUS", CmdAgent, "/status", "/status"},
+		{"/Model GPT-4o", CmdAgent, "/model", "/model GPT-4o"},
+		{"/frobnicate now", CmdUnknown, "/frobnicate", "/frobnicate now"},
+		{"hello there", CmdMessage, "", "hello there"},
+		{"/etc/hosts is empty", CmdMessage, "", "/etc/hosts is empty"},
+		{"/ nothing", CmdMessage, "", "/ nothing"},
+		{"/new2", CmdMessage, "", "/new2"},
+	}
+	for _, c := range cases {
+		got := ParseLine(c.line)
+		if got.Kind != c.kind || got.Name != c.name || got.Text != c.text {
+			t.Errorf("ParseLine(%q) = %+v, want kind %d name %q text %q", c.line, got, c.kind, c.name, c.text)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrInterrupt is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupt = errors.New("interrupted")
+
+// Control keys understood by the editor.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = '\r'
+	keyNewline   = '\n'
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// Editor reads lines from a terminal with basic emacs-style editing: arrow
+// keys, Home/End, Ctrl-A/E/K/U/W, and Up/Down (Ctrl-P/N) through History.
+// When the input is not a terminal it reads plain lines instead.
+type Editor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	fd      int // terminal file descriptor, or -1
+	history *History
+}
+
+// NewEditor creates an Editor reading from in. history may be nil.
+func NewEditor(in *os.File, out io.Writer, history *History) *Editor {
+	fd := int(in.Fd())
+	if !isTerminal(fd) {
+		fd = -1
+	}
+	return &Editor{in: bufio.NewReader(in), out: out, fd: fd, history: history}
+}
+
+// ReadLine shows prompt and returns the next line without its newline.
+// It returns ErrInterrupt on Ctrl-C and io.EOF on Ctrl-D at an empty line
+// or at end of input.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if e.fd >= 0 {
+		if restore, err := makeRaw(e.fd); err == nil {
+			defer restore()
+			return e.edit(prompt)
+		}
+	}
+	fmt.Fprint(e.out, prompt)
+	line, err := e.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// edit runs the editing loop on a terminal already in raw mode.
+func (e *Editor) edit(prompt string) (string, error) {
+	var (
+		buf     []rune
+		pos     int
+		histIdx = e.historyLen()
+		draft   []rune // the unsent line while browsing history
+	)
+	setLine := func(s []rune) {
+		buf = append([]rune(nil), s...)
+		pos = len(buf)
+	}
+	e.redraw(prompt, buf, pos)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if len(buf) > 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return string(buf), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyNewline:
+			fmt.Fprint(e.out, "\r\n")
+			return string(buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupt
+		case keyCtrlD:
+			if len(buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(buf) {
+				buf = append(buf[:pos], buf[pos+1:]...)
+			}
+		case keyBackspace, keyDelete:
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+			}
+		case keyCtrlA:
+			pos = 0
+		case keyCtrlE:
+			pos = len(buf)
+		case keyCtrlB:
+			pos = max(pos-1, 0)
+		case keyCtrlF:
+			pos = min(pos+1, len(buf))
+		case keyCtrlK:
+			buf = buf[:pos]
+		case keyCtrlU:
+			buf = append([]rune(nil), buf[pos:]...)
+			pos = 0
+		case keyCtrlW:
+			start := pos
+			for start > 0 && buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && buf[start-1] != ' ' {
+				start--
+			}
+			buf = append(buf[:start], buf[pos:]...)
+			pos = start
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP:
+			histIdx, draft = e.historyPrev(histIdx, buf, draft, setLine)
+		case keyCtrlN:
+			histIdx = e.historyNext(histIdx, draft, setLine)
+		case keyEscape:
+			switch e.readEscape() {
+			case "A":
+				histIdx, draft = e.historyPrev(histIdx, buf, draft, setLine)
+			case "B":
+				histIdx = e.historyNext(histIdx, draft, setLine)
+			case "C":
+				pos = min(pos+1, len(buf))
+			case "D":
+				pos = max(pos-1, 0)
+			case "H", "1~", "7~":
+				pos = 0
+			case "F", "4~", "8~":
+				pos = len(buf)
+			case "3~":
+				if pos < len(buf) {
+					buf = append(buf[:pos], buf[pos+1:]...)
+				}
+			}
+		default:
+			if r >= ' ' {
+				buf = append(buf[:pos], append([]rune{r}, buf[pos:]...)...)
+				pos++
+			}
+		}
+		e.redraw(prompt, buf, pos)
+	}
+}
+
+// readEscape consumes the rest of an escape sequence after ESC and returns
+// its parameter and final bytes, e.g. "A" for ESC [ A or "3~" for ESC [ 3 ~.
+func (e *Editor) readEscape() string {
+	intro, err := e.in.ReadByte()
+	if err != nil || (intro != '[' && intro != 'O') {
+		return ""
+	}
+	var seq []byte
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, b)
+		if b >= 0x40 && b <= 0x7e {
+			return string(seq)
+		}
+	}
+}
+
+// historyPrev replaces the line with the previous history entry, saving
+// the unsent line the first time the user leaves it.
+func (e *Editor) historyPrev(idx int, buf, draft []rune, setLine func([]rune)) (int, []rune) {
+	if idx <= 0 {
+		return idx, draft
+	}
+	if idx == e.historyLen() {
+		draft = append([]rune(nil), buf...)
+	}
+	idx--
+	setLine([]rune(e.history.At(idx)))
+	return idx, draft
+}
+
+// historyNext moves toward the newest entry and back to the unsent line.
+func (e *Editor) historyNext(idx int, draft []rune, setLine func([]rune)) int {
+	n := e.historyLen()
+	if idx >= n {
+		return idx
+	}
+	idx++
+	if idx == n {
+		setLine(draft)
+	} else {
+		setLine([]rune(e.history.At(idx)))
+	}
+	return idx
+}
+
+func (e *Editor) historyLen() int {
+	if e.history == nil {
+		return 0
+	}
+	return e.history.Len()
+}
+
+// redraw repaints the prompt and line and puts the cursor at pos.
+func (e *Editor) redraw(prompt string, buf []rune, pos int) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(buf))
+	if back := len(buf) - pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,69 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string, h *History) *Editor {
+	return &Editor{in: bufio.NewReader(strings.NewReader(input)), out: io.Discard, fd: -1, history: h}
+}
+
+func TestEditor_Editing(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"abc\r", "abc"},
+		{"abc\x1b[D\x1b[DX\r", "aXbc"},
+		{"abc\x7f\x7fd\r", "ad"},
+		{"world\x01hello \r", "hello world"},
+		{"one two\x17three\r", "one three"},
+		{"abcdef\x1b[D\x1b[D\x0b\r", "abcd"},
+		{"abcdef\x1b[D\x1b[D\x15\r", "ef"},
+		{"abc\x1b[H\x1b[3~\r", "bc"},
+		{"héllo\x1b[D\x7f\r", "hélo"},
+	}
+	for _, c := range cases {
+		got, err := newTestEditor(c.input, nil).edit("> ")
+		if err != nil || got != c.want {
+			t.Errorf("edit(%q) = %q, %v; want %q", c.input, got, err, c.want)
+		}
+	}
+}
+
+func TestEditor_HistoryAndControlKeys(t *testing.T) {
+	h := &History{max: 10}
+	h.Add("first")
+	h.Add("second")
+
+	// Up twice, down once returns the newer entry; down past the end restores the draft.
+	if got, _ := newTestEditor("\x1b[A\x1b[A\x1b[B\r", h).edit("> "); got != "second" {
+		t.Errorf("expected second, got %q", got)
+	}
+	if got, _ := newTestEditor("dra\x1b[A\x1b[Bft\r", h).edit("> "); got != "draft" {
+		t.Errorf("expected draft restored, got %q", got)
+	}
+
+	if _, err := newTestEditor("abc\x03", h).edit("> "); !errors.Is(err, ErrInterrupt) {
+		t.Errorf("expected ErrInterrupt on Ctrl-C, got %v", err)
+	}
+	if _, err := newTestEditor("\x04", h).edit("> "); err != io.EOF {
+		t.Errorf("expected io.EOF on Ctrl-D, got %v", err)
+	}
+
+	// Without a terminal, ReadLine reads plain lines.
+	e := newTestEditor("plain line\r\nnext\n", h)
+	if got, _ := e.ReadLine("> "); got != "plain line" {
+		t.Errorf("got %q", got)
+	}
+	if got, _ := e.ReadLine("> "); got != "next" {
+		t.Errorf("got %q", got)
+	}
+	if _, err := e.ReadLine("> "); err != io.EOF {
+		t.Errorf("expected io.EOF at end of input, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/atomicfile"
+)
+
+// DefaultHistorySize is the number of lines kept by the chat REPL.
+const DefaultHistorySize = 1000
+
+// History is the REPL's input history: one entry per line in a plain text
+// file, oldest first, capped at a fixed number of entries.
+type History struct {
+	path    string
+	max     int
+	entries []string
+}
+
+// LoadHistory reads the history file at path, keeping the newest max
+// entries. A missing file yields an empty history.
+func LoadHistory(path string, max int) (*History, error) {
+	if max <= 0 {
+		max = DefaultHistorySize
+	}
+	h := &History{path: path, max: max}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	h.trim()
+	return h, scanner.Err()
+}
+
+// Add records line, reporting whether it was kept. Blank lines and repeats
+// of the previous entry are skipped; embedded newlines become spaces so an
+// entry always fits on one line of the file.
+func (h *History) Add(line string) bool {
+	line = strings.Join(strings.Fields(strings.ReplaceAll(line, "\n", " ")), " ")
+	if line == "" {
+		return false
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return false
+	}
+	h.entries = append(h.entries, line)
+	h.trim()
+	return true
+}
+
+// Len returns the number of entries.
+func (h *History) Len() int { return len(h.entries) }
+
+// At returns entry i, oldest first.
+func (h *History) At(i int) string { return h.entries[i] }
+
+// Entries returns a copy of the entries, oldest first.
+func (h *History) Entries() []string {
+	return append([]string(nil), h.entries...)
+}
+
+// Save writes the history to its file. The file is replaced atomically and
+// is private to the user, since typed lines can contain secrets.
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, e := range h.entries {
+		b.WriteString(e)
+		b.WriteByte('\n')
+	}
+	return atomicfile.WriteFile(h.path, []byte(b.String()), 0o600)
+}
+
+// trim drops the oldest entries beyond the cap.
+func (h *History) trim() {
+	if extra := len(h.entries) - h.max; extra > 0 {
+		h.entries = append([]string(nil), h.entries[extra:]...)
+	}
+}
@@ -0,0 +1,58 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistory_PersistsAcrossLoads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "chat_history")
+	h, err := LoadHistory(path, 3)
+	if err != nil || h.Len() != 0 {
+		t.Fatalf("expected an empty history for a missing file, got %d entries, %v", h.Len(), err)
+	}
+
+	for _, line := range []string{"one", "  ", "two", "two", "three\nlines", "four"} {
+		h.Add(line)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected a private history file, got %v", perm)
+	}
+
+	loaded, err := LoadHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"two", "three lines", "four"}
+	if got := loaded.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A smaller cap on load keeps the newest entries.
+	small, _ := LoadHistory(path, 1)
+	if got := small.Entries(); !reflect.DeepEqual(got, []string{"four"}) {
+		t.Errorf("expected newest entry kept, got %q", got)
+	}
+}
+
+func TestHistory_AddSkipsBlankAndRepeats(t *testing.T) {
+	h := &History{max: 10}
+	if h.Add("") || h.Add(" \t ") {
+		t.Error("expected blank lines skipped")
+	}
+	if !h.Add("hello") || h.Add("hello") || !h.Add("bye") || !h.Add("hello") {
+		t.Error("expected only consecutive repeats skipped")
+	}
+	if h.Len() != 3 {
+		t.Errorf("expected 3 entries, got %d", h.Len())
+	}
+}
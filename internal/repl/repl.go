@@ -0,0 +1,196 @@
+// Package repl implements the interactive `crystaldolphin chat` loop: a line
+// editor with persistent history in front of AgentLoop.ProcessDirect.
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/config"
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/cmdutils"
+)
+
+const (
+	prompt          = "You: "
+	historyListSize = 20 // lines shown by /history
+)
+
+const replHelp = "REPL: /history — recent input, /clear — clear the screen, exit or Ctrl+D — quit, Ctrl+C — cancel the running turn"
+
+// HistoryPath returns the chat history file under the data dir.
+func HistoryPath() string {
+	return filepath.Join(config.DataDir(), "chat_history")
+}
+
+// REPL reads lines from an Editor and runs each one as an agent turn with
+// ProcessDirect, printing progress and out-of-band replies (message tool,
+// approval prompts, subagent results) from the channel bus as they arrive.
+type REPL struct {
+	loop     schema.AgentLooper
+	inbound  *bus.AgentBus
+	outbound <-chan bus.ChannelMessage
+	editor   *Editor
+	history  *History
+	out      io.Writer
+
+	key     string
+	channel bus.Channel
+	chatID  string
+}
+
+// New creates a REPL for the session at key. inbound carries approval
+// answers back to the running AgentLoop; outbound is the channel bus the
+// loop publishes progress on. history may be nil.
+func New(loop schema.AgentLooper, inbound *bus.AgentBus, outbound <-chan bus.ChannelMessage, editor *Editor, history *History, key string, out io.Writer) *REPL {
+	channel, chatID := bus.ParseRoutingKey(key)
+	return &REPL{
+		loop:     loop,
+		inbound:  inbound,
+		outbound: outbound,
+		editor:   editor,
+		history:  history,
+		out:      out,
+		key:      key,
+		channel:  channel,
+		chatID:   chatID,
+	}
+}
+
+// Run reads and handles lines until exit, end of input, or ctx is cancelled.
+func (r *REPL) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		r.drain()
+		line, err := r.editor.ReadLine(prompt)
+		if errors.Is(err, ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(r.out, "Goodbye!")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		cmd := ParseLine(line)
+		switch cmd.Kind {
+		case CmdEmpty:
+			continue
+		case CmdExit:
+			fmt.Fprintln(r.out, "Goodbye!")
+			return nil
+		case CmdClear:
+			fmt.Fprint(r.out, "\x1b[H\x1b[2J")
+		case CmdHistory:
+			r.printHistory()
+		case CmdUnknown:
+			fmt.Fprintf(r.out, "Unknown command %s. Type /help for the list.\n", cmd.Name)
+		default:
+			r.record(line)
+			r.turn(ctx, cmd.Text)
+			if cmd.Kind == CmdAgent && cmd.Name == "/help" {
+				fmt.Fprintf(r.out, "%s\n\n", replHelp)
+			}
+		}
+	}
+	return nil
+}
+
+// turn runs one agent turn. Ctrl-C (SIGINT, since the terminal is back in
+// cooked mode) cancels it; the agent then replies "Stopped.".
+func (r *REPL) turn(ctx context.Context, text string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	msg := bus.NewAgentMessage(r.channel, bus.SenderIdCLI, r.chatID, text, r.key)
+	done := make(chan string, 1)
+	go func() { done <- r.loop.ProcessDirect(ctx, msg) }()
+
+	for {
+		select {
+		case out := <-r.outbound:
+			if !r.print(out) {
+				continue
+			}
+			if len(out.Buttons()) > 0 {
+				r.answer()
+			}
+		case <-interrupts:
+			fmt.Fprintln(r.out, "\n  ↳ cancelling...")
+			cancel()
+		case reply := <-done:
+			r.drain()
+			cmdutils.FprintResponse(r.out, reply)
+			return
+		}
+	}
+}
+
+// answer reads the user's reply to an approval prompt and sends it to the
+// agent loop, which resolves the pending approval. Ctrl-C denies.
+func (r *REPL) answer() {
+	line, err := r.editor.ReadLine("Approve? ")
+	if err != nil {
+		line = "no"
+	}
+	r.inbound.Publish(bus.NewAgentMessage(r.channel, bus.SenderIdCLI, r.chatID, line, r.key))
+}
+
+// drain prints channel-bus messages that arrived between turns.
+func (r *REPL) drain() {
+	for {
+		select {
+		case out := <-r.outbound:
+			r.print(out)
+		default:
+			return
+		}
+	}
+}
+
+// print writes msg if it belongs to this chat and reports whether it did.
+func (r *REPL) print(msg bus.ChannelMessage) bool {
+	if msg.Channel() != r.channel || msg.ChatId() != r.chatID || msg.Content() == "" {
+		return false
+	}
+	if prog, _ := msg.Metadata()["_progress"].(bool); prog {
+		fmt.Fprintf(r.out, "  ↳ %s\n", msg.Content())
+		return true
+	}
+	cmdutils.FprintResponse(r.out, msg.Content())
+	return true
+}
+
+// record adds line to the history and persists it.
+func (r *REPL) record(line string) {
+	if r.history == nil || !r.history.Add(line) {
+		return
+	}
+	if err := r.history.Save(); err != nil {
+		slog.Warn("Failed to save chat history", "err", err)
+	}
+}
+
+func (r *REPL) printHistory() {
+	if r.history == nil || r.history.Len() == 0 {
+		fmt.Fprintln(r.out, "No history yet.")
+		return
+	}
+	entries := r.history.Entries()
+	start := max(len(entries)-historyListSize, 0)
+	for i := start; i < len(entries); i++ {
+		fmt.Fprintf(r.out, "%4d  %s\n", i+1, entries[i])
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package repl
+
+import "errors"
+
+// isTerminal always reports false here; the editor falls back to plain
+// line reading.
+func isTerminal(int) bool { return false }
+
+func makeRaw(int) (func(), error) {
+	return nil, errors.New("line editing is not supported on this platform")
+}
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"context"
+	"time"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/bus"
 )
@@ -12,6 +13,35 @@ type AgentSettings struct {
 	Temperature  float64
 	MaxTokens    int
 	MemoryWindow int
+
+	// MaxContinuations bounds the automatic follow-up requests made when a
+	// reply stops with finish_reason "length"; 0 disables them.
+	MaxContinuations int
+
+	// MaxToolResultBytes caps each tool result fed back to the model;
+	// ToolResultLimits overrides it per tool name. 0 means no cap.
+	MaxToolResultBytes int
+	ToolResultLimits   map[string]int
+
+	// ToolTimeout bounds each tool call other than exec; 0 means no bound.
+	ToolTimeout time.Duration
+
+	// HistoryIncludeTools replays persisted tool calls and results in the
+	// session history sent to the model; by default only user/assistant
+	// text is replayed.
+	HistoryIncludeTools bool
+
+	// MaxConcurrentTurns bounds how many inbound messages are processed at
+	// once; further messages wait in a priority queue.
+	MaxConcurrentTurns int
+
+	// WorkspaceScope is agentcfg.WorkspaceScope*: whether file and exec
+	// tools get a per-channel or per-session workspace subdirectory.
+	WorkspaceScope string
+
+	// DryRun feeds the model a placeholder result for every tool call
+	// instead of executing the tool.
+	DryRun bool
 }
 
 func NewAgentSettings(model string, maxIter int, temperature float64, maxTokens int, memoryWindow int) AgentSettings {
@@ -25,15 +55,20 @@ func NewAgentSettings(model string, maxIter int, temperature float64, maxTokens
 }
 
 type AgentLooper interface {
-	// ProcessDirect processes a message outside the bus (CLI, cron, heartbeat).
+	// ProcessDirect processes a message outside the bus (CLI, one-off runs).
 	// Returns the final text response.
 	ProcessDirect(ctx context.Context, msg bus.AgentMessage) string
+	// ProcessQueued processes scheduled work (cron, heartbeat) outside the
+	// bus, waiting for a turn slot behind interactive messages. Requires Run.
+	ProcessQueued(ctx context.Context, msg bus.AgentMessage) string
 	// Run starts the main agent loop,
 	// processing messages from the bus until context is cancelled.
 	Run(ctx context.Context) error
 }
 
 // Agent executes a single LLM ↔ tool loop for one request.
+// Execute returns the final reply, the names of the tools used, the
+// intermediate steps, and the token usage of the final reply.
 type Agent interface {
-	Execute(ctx context.Context, conversation Messages, onProgress func(string)) (string, []string)
+	Execute(ctx context.Context, conversation Messages, onProgress func(string)) (string, []string, Messages, map[string]int)
 }
@@ -1,6 +1,9 @@
 package schema
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // ChannelSession is the subset of session.ChannelSession required by
 // MemoryStore.Consolidate. Defined here to avoid an import cycle
@@ -35,6 +38,30 @@ type MemoryStore interface {
 	WriteLongTerm(content string) error
 	AppendHistory(entry string) error
 	GetMemoryContext() string
+	// Search returns up to limit paragraphs containing every word of query
+	// (case-insensitive): HISTORY.md entries newest first, then MEMORY.md
+	// paragraphs when includeLongTerm is set. limit <= 0 means no limit.
+	Search(query string, limit int, includeLongTerm bool) []MemoryMatch
+	// RecallMemory returns the k HISTORY.md entries most similar in meaning
+	// to query, ranked by embedding similarity. Without an Embedder, or when
+	// embedding fails, it falls back to Search.
+	RecallMemory(ctx context.Context, query string, k int) []MemoryMatch
+}
+
+// Embedder turns texts into vectors for semantic memory recall.
+type Embedder interface {
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model names the embedding model; vectors from different models are
+	// never compared.
+	Model() string
+}
+
+// MemoryMatch is one paragraph found by MemoryStore.Search.
+type MemoryMatch struct {
+	Source    string // "HISTORY.md" or "MEMORY.md"
+	Timestamp string // "YYYY-MM-DD HH:MM" of a history entry; "" if it has none
+	Text      string
 }
 
 // MemoryCompactor orchestrates memory consolidation: it selects old messages,
@@ -42,4 +69,21 @@ type MemoryStore interface {
 type MemoryCompactor interface {
 	Compact(ctx context.Context, s ChannelSession, archiveAll bool) error
 	Schedule(key string, sess ChannelSession, archiveAll bool)
+	// Status reports the consolidation state for a Schedule key.
+	Status(key string) CompactionStatus
+}
+
+// Consolidation states reported by MemoryCompactor.Status.
+const (
+	CompactionIdle    = "idle"    // nothing running; the last run (if any) succeeded
+	CompactionRunning = "running" // a consolidation is in progress
+	CompactionQueued  = "queued"  // running, with another run pending
+	CompactionFailed  = "failed"  // nothing running; the last run failed
+)
+
+// CompactionStatus is the consolidation state of one session key.
+type CompactionStatus struct {
+	State      string
+	LastError  string    // error from the last finished run, if it failed
+	FinishedAt time.Time // when the last run finished; zero if none has
 }
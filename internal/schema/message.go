@@ -64,10 +64,12 @@ type Message struct {
 	Role             MessageRole
 	Content          any // string | *string | []ContentBlock
 	ToolCalls        []ToolCall
-	ToolCallID       string   // "tool" role only
-	ToolName         string   // "tool" role only
-	ReasoningContent *string  // "assistant" role only
-	ToolsUsed        []string // session-only: names of tools used this turn; not sent to LLM
+	ToolCallID       string         // "tool" role only
+	ToolName         string         // "tool" role only
+	ReasoningContent *string        // "assistant" role only
+	ToolsUsed        []string       // session-only: names of tools used this turn; not sent to LLM
+	ToolArgs         map[string]any // session-only: redacted arguments of a tool result; not sent to LLM
+	Usage            map[string]int // session-only: prompt_tokens/completion_tokens spent on an assistant reply; not sent to LLM
 }
 
 func NewSystemMessage(content any) Message {
@@ -101,3 +103,9 @@ func NewToolResultMessage(toolCallID, toolName, result string) Message {
 		ToolName:   toolName,
 	}
 }
+
+// IsToolStep reports whether m is an intermediate tool call or tool result
+// rather than user/assistant text.
+func (m Message) IsToolStep() bool {
+	return m.Role == RoleTool || (m.Role == RoleAssistant && len(m.ToolCalls) > 0)
+}
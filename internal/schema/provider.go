@@ -7,6 +7,7 @@ type ChatOptions struct {
 	Model       string
 	MaxTokens   int
 	Temperature float64
+	N           int // number of candidate completions; 0 or 1 means a single one
 }
 
 type ToolCallRequest struct {
@@ -17,13 +18,24 @@ type ToolCallRequest struct {
 
 type ToolCallResponse = ToolCallRequest
 
+// Choice is one candidate completion returned by the provider.
+type Choice struct {
+	Content          *string
+	ToolCalls        []ToolCallResponse
+	FinishReason     string
+	ReasoningContent *string
+}
+
 // LLMResponse is the normalised response from any LLM provider.
+// The top-level fields mirror the first choice; Choices holds every
+// candidate when the request asked for more than one (ChatOptions.N > 1).
 type LLMResponse struct {
 	Content          *string // nil when the response contains only tool calls
 	ToolCalls        []ToolCallResponse
 	FinishReason     string
-	Usage            map[string]int // "input_tokens", "output_tokens"
+	Usage            map[string]int // "prompt_tokens", "completion_tokens", "total_tokens"
 	ReasoningContent *string        // DeepSeek-R1 / Kimi thinking block
+	Choices          []Choice
 }
 
 // HasToolCalls reports whether the response contains at least one tool call.
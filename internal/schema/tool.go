@@ -32,4 +32,8 @@ type ToolRegistry interface {
 // tools without importing internal/tools.
 type ToolRegistrar interface {
 	Add(t Tool) Tool
+	// Get returns the registered tool with the given name, or nil.
+	Get(name string) Tool
+	// Remove unregisters the tool with the given name, if present.
+	Remove(name string)
 }
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/cron"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+)
+
+// adminMaxBody caps the size of an admin request body.
+const adminMaxBody = 1 << 20
+
+// SessionExporter is the part of session.Manager the admin API uses.
+type SessionExporter interface {
+	List() []session.SessionInfo
+	Export(key, format string) ([]byte, error)
+}
+
+// AdminServer exposes cron jobs and sessions over a JSON REST API:
+//
+//	GET    /api/cron/jobs[?all=true]
+//	POST   /api/cron/jobs
+//	DELETE /api/cron/jobs/{id}
+//	POST   /api/cron/jobs/{id}/enable
+//	POST   /api/cron/jobs/{id}/disable
+//	GET    /api/sessions
+//	GET    /api/sessions/{key}[?format=md|json]
+type AdminServer struct {
+	cron      *cron.JobManager
+	sessions  SessionExporter
+	authToken string
+}
+
+// NewAdminServer creates an AdminServer. authToken is required as a bearer
+// token on every request.
+func NewAdminServer(jobs *cron.JobManager, sessions SessionExporter, authToken string) *AdminServer {
+	return &AdminServer{cron: jobs, sessions: sessions, authToken: authToken}
+}
+
+// Register adds the admin routes to mux.
+func (s *AdminServer) Register(mux *http.ServeMux) {
+	mux.Handle("GET /api/cron/jobs", s.auth(s.handleListJobs))
+	mux.Handle("POST /api/cron/jobs", s.auth(s.handleAddJob))
+	mux.Handle("DELETE /api/cron/jobs/{id}", s.auth(s.handleRemoveJob))
+	mux.Handle("POST /api/cron/jobs/{id}/enable", s.auth(s.handleEnableJob(true)))
+	mux.Handle("POST /api/cron/jobs/{id}/disable", s.auth(s.handleEnableJob(false)))
+	mux.Handle("GET /api/sessions", s.auth(s.handleListSessions))
+	mux.Handle("GET /api/sessions/{key}", s.auth(s.handleExportSession))
+}
+
+func (s *AdminServer) auth(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Never serve the admin API unauthenticated.
+		if s.authToken == "" || !authorized(r, s.authToken) {
+			writeAdminError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		h(w, r)
+	})
+}
+
+func (s *AdminServer) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs := s.cron.ListAllJobs(r.URL.Query().Get("all") == "true")
+	if jobs == nil {
+		jobs = []cron.CronJob{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": jobs})
+}
+
+// addJobRequest mirrors the `cron add` flags. Exactly one of Every (seconds),
+// Cron or At (RFC 3339) selects the schedule.
+type addJobRequest struct {
+	Name    string   `json:"name"`
+	Message string   `json:"message"`
+	Every   int      `json:"every"`
+	Cron    string   `json:"cron"`
+	TZ      string   `json:"tz"`
+	At      string   `json:"at"`
+	Deliver bool     `json:"deliver"`
+	Channel string   `json:"channel"`
+	To      []string `json:"to"`
+}
+
+func (s *AdminServer) handleAddJob(w http.ResponseWriter, r *http.Request) {
+	var req addJobRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, adminMaxBody)).Decode(&req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Name == "" || req.Message == "" {
+		writeAdminError(w, http.StatusBadRequest, "name and message are required")
+		return
+	}
+	if req.TZ != "" && req.Cron == "" {
+		writeAdminError(w, http.StatusBadRequest, "tz can only be used with cron")
+		return
+	}
+
+	var (
+		kind    string
+		everyMs int64
+		atMs    int64
+	)
+	switch {
+	case req.Every > 0:
+		kind = "every"
+		everyMs = int64(req.Every) * 1000
+	case req.Cron != "":
+		kind = "cron"
+	case req.At != "":
+		kind = "at"
+		at, err := time.Parse(time.RFC3339, req.At)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid at: want RFC 3339")
+			return
+		}
+		atMs = at.UnixMilli()
+	default:
+		writeAdminError(w, http.StatusBadRequest, "one of every, cron or at is required")
+		return
+	}
+
+	job, err := s.cron.AddJobFull(req.Name, req.Message, kind, everyMs, req.Cron, req.TZ, atMs,
+		req.Deliver, req.Channel, req.To, kind == "at")
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, job)
+}
+
+func (s *AdminServer) handleRemoveJob(w http.ResponseWriter, r *http.Request) {
+	if !s.cron.RemoveJob(r.PathValue("id")) {
+		writeAdminError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *AdminServer) handleEnableJob(enabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, ok := s.cron.EnableJob(r.PathValue("id"), enabled)
+		if !ok {
+			writeAdminError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	}
+}
+
+// sessionSummary is the JSON form of a session.SessionInfo.
+type sessionSummary struct {
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (s *AdminServer) handleListSessions(w http.ResponseWriter, _ *http.Request) {
+	infos := s.sessions.List()
+	out := make([]sessionSummary, len(infos))
+	for i, info := range infos {
+		out[i] = sessionSummary{Key: info.Key, CreatedAt: info.CreatedAt, UpdatedAt: info.UpdatedAt}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": out})
+}
+
+func (s *AdminServer) handleExportSession(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = session.ExportJSON
+	}
+	data, err := s.sessions.Export(r.PathValue("key"), format)
+	switch {
+	case errors.Is(err, session.ErrNotFound):
+		writeAdminError(w, http.StatusNotFound, "session not found")
+		return
+	case err != nil:
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if format == session.ExportJSON {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	}
+	_, _ = w.Write(data)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
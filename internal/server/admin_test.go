@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/cron"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+)
+
+func newTestAdminServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	dir := t.TempDir()
+	sessions, err := session.NewManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	NewAdminServer(cron.NewService(filepath.Join(dir, "jobs.json")), sessions, "secret").Register(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func adminRequest(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func listJobs(t *testing.T, base string, all bool) []cron.CronJob {
+	t.Helper()
+	url := base + "/api/cron/jobs"
+	if all {
+		url += "?all=true"
+	}
+	var out struct {
+		Jobs []cron.CronJob `json:"jobs"`
+	}
+	if err := json.NewDecoder(adminRequest(t, http.MethodGet, url, "").Body).Decode(&out); err != nil {
+		t.Fatalf("decode jobs: %v", err)
+	}
+	return out.Jobs
+}
+
+func TestAdminCron_CRUD(t *testing.T) {
+	srv := newTestAdminServer(t)
+
+	resp := adminRequest(t, http.MethodPost, srv.URL+"/api/cron/jobs",
+		`{"name":"digest","message":"Summarise the news","every":3600}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	var job cron.CronJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+	if job.ID == "" || job.Name != "digest" || job.Schedule.Kind != "every" || *job.Schedule.EveryMs != 3_600_000 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	if jobs := listJobs(t, srv.URL, false); len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Fatalf("expected the new job listed, got %+v", jobs)
+	}
+
+	if resp := adminRequest(t, http.MethodPost, srv.URL+"/api/cron/jobs/"+job.ID+"/disable", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("disable: expected 200, got %d", resp.StatusCode)
+	}
+	if jobs := listJobs(t, srv.URL, false); len(jobs) != 0 {
+		t.Errorf("expected disabled job hidden by default, got %d", len(jobs))
+	}
+	if jobs := listJobs(t, srv.URL, true); len(jobs) != 1 || jobs[0].Enabled {
+		t.Errorf("expected disabled job listed with all=true, got %+v", jobs)
+	}
+	if resp := adminRequest(t, http.MethodPost, srv.URL+"/api/cron/jobs/"+job.ID+"/enable", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("enable: expected 200, got %d", resp.StatusCode)
+	}
+
+	if resp := adminRequest(t, http.MethodDelete, srv.URL+"/api/cron/jobs/"+job.ID, ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", resp.StatusCode)
+	}
+	if resp := adminRequest(t, http.MethodDelete, srv.URL+"/api/cron/jobs/"+job.ID, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("second delete: expected 404, got %d", resp.StatusCode)
+	}
+	if jobs := listJobs(t, srv.URL, true); len(jobs) != 0 {
+		t.Errorf("expected no jobs after delete, got %d", len(jobs))
+	}
+}
+
+func TestAdminCron_AddValidation(t *testing.T) {
+	srv := newTestAdminServer(t)
+	at := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	cases := map[string]int{
+		`{"name":"x","message":"y"}`:                        http.StatusBadRequest, // no schedule
+		`{"name":"x","message":"y","every":60,"tz":"UTC"}`:  http.StatusBadRequest, // tz without cron
+		`{"name":"x","message":"y","at":"tomorrow"}`:        http.StatusBadRequest,
+		`{"message":"y","every":60}`:                        http.StatusBadRequest,
+		`{"name":"x","message":"y","every":60,"to":["u1"]}`: http.StatusBadRequest, // recipients without deliver
+		`{"name":"x","message":"y","at":"` + at + `"}`:      http.StatusCreated,
+		`{"name":"x","message":"y","cron":"0 0 9 * * *"}`:   http.StatusCreated,
+	}
+	for body, want := range cases {
+		if resp := adminRequest(t, http.MethodPost, srv.URL+"/api/cron/jobs", body); resp.StatusCode != want {
+			t.Errorf("POST %s: expected %d, got %d", body, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestAdmin_RequiresToken(t *testing.T) {
+	srv := newTestAdminServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/cron/jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", resp.StatusCode)
+	}
+
+	if resp := adminRequest(t, http.MethodGet, srv.URL+"/api/sessions/telegram:missing", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing session, got %d", resp.StatusCode)
+	}
+}
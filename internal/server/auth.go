@@ -0,0 +1,27 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// authorized reports whether r carries token as a bearer token. An empty
+// token disables the check.
+func authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	want := "Bearer " + token
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) == 1
+}
+
+// RequireToken wraps h so requests without the bearer token get 401.
+func RequireToken(token string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
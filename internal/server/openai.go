@@ -0,0 +1,280 @@
+// Package server provides HTTP front ends to the agent loop.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// openAIMaxBody caps the size of a chat completion request body.
+const openAIMaxBody = 4 << 20
+
+// openAITurnTimeout bounds one agent turn, matching ProcessDirect callers.
+const openAITurnTimeout = 5 * time.Minute
+
+// chatCompletionRequest is the subset of the OpenAI request body used here.
+type chatCompletionRequest struct {
+	Model    string              `json:"model"`
+	Messages []chatCompletionMsg `json:"messages"`
+	Stream   bool                `json:"stream"`
+	User     string              `json:"user"`
+}
+
+type chatCompletionMsg struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type chatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []chatCompletionChoice `json:"choices"`
+	Usage   *chatCompletionUsage   `json:"usage,omitempty"`
+}
+
+type chatCompletionChoice struct {
+	Index        int                 `json:"index"`
+	Message      *chatCompletionText `json:"message,omitempty"`
+	Delta        *chatCompletionText `json:"delta,omitempty"`
+	FinishReason *string             `json:"finish_reason"`
+}
+
+type chatCompletionText struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// chatCompletionUsage is always zero: the agent loop does not report token
+// counts for a turn, but clients expect the field.
+type chatCompletionUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// OpenAIServer exposes the agent loop as an OpenAI-compatible
+// POST /v1/chat/completions endpoint.
+//
+// Each request runs one agent turn on the "openai" channel. A request with a
+// user field continues that user's session, which already holds the earlier
+// turns, so only its last user message is sent to the agent. A request
+// without one is stateless: it runs in a throwaway session that is never
+// saved, with the request's earlier messages passed along as context.
+type OpenAIServer struct {
+	loop      schema.AgentLooper
+	model     string
+	authToken string
+}
+
+// NewOpenAIServer creates an OpenAIServer. model is reported in responses;
+// authToken, when non-empty, is required as a bearer token.
+func NewOpenAIServer(loop schema.AgentLooper, model, authToken string) *OpenAIServer {
+	return &OpenAIServer{loop: loop, model: model, authToken: authToken}
+}
+
+// Register adds the OpenAI routes to mux.
+func (s *OpenAIServer) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/v1/chat/completions", s.requireAuth(s.handleChatCompletions))
+	mux.HandleFunc("/v1/models", s.requireAuth(s.handleModels))
+}
+
+func (s *OpenAIServer) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, s.authToken) {
+			writeOpenAIError(w, http.StatusUnauthorized, "invalid_api_key", "invalid or missing bearer token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *OpenAIServer) handleModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"object": "list",
+		"data": []map[string]any{
+			{"id": s.model, "object": "model", "owned_by": "crystaldolphin"},
+		},
+	})
+}
+
+func (s *OpenAIServer) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
+		return
+	}
+
+	var req chatCompletionRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, openAIMaxBody)).Decode(&req); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "invalid JSON body")
+		return
+	}
+	last := lastUserIndex(req.Messages)
+	content := ""
+	if last >= 0 {
+		content = messageText(req.Messages[last].Content)
+	}
+	if content == "" {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "messages must include a user message")
+		return
+	}
+
+	chatID := req.User
+	stateless := chatID == ""
+	if stateless {
+		chatID = "req-" + randomID()
+		content = withHistory(req.Messages[:last], content)
+	}
+	msg := bus.NewAgentMessageBuilder(bus.ChannelOpenAI, chatID, chatID, content)
+	if stateless {
+		msg.Ephemeral()
+	}
+
+	id := "chatcmpl-" + randomID()
+	created := time.Now().Unix()
+	if req.Stream {
+		s.startStream(w, id, created)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), openAITurnTimeout)
+	defer cancel()
+	reply := s.loop.ProcessDirect(ctx, msg.Build())
+
+	if req.Stream {
+		s.finishStream(w, id, created, reply)
+		return
+	}
+
+	stop := "stop"
+	writeJSON(w, http.StatusOK, chatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: created,
+		Model:   s.model,
+		Choices: []chatCompletionChoice{{
+			Message:      &chatCompletionText{Role: "assistant", Content: reply},
+			FinishReason: &stop,
+		}},
+		Usage: &chatCompletionUsage{},
+	})
+}
+
+// startStream sends the event-stream headers and the role chunk, so the
+// client sees the response start while the turn runs. The reply itself is
+// not streamed as it is generated: finishStream sends it as one content
+// chunk when the turn is done.
+func (s *OpenAIServer) startStream(w http.ResponseWriter, id string, created int64) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	s.writeChunk(w, id, created, chatCompletionChoice{Delta: &chatCompletionText{Role: "assistant"}})
+}
+
+// finishStream sends the reply, an empty delta with finish_reason, then
+// [DONE].
+func (s *OpenAIServer) finishStream(w http.ResponseWriter, id string, created int64, reply string) {
+	stop := "stop"
+	s.writeChunk(w, id, created, chatCompletionChoice{Delta: &chatCompletionText{Content: reply}})
+	s.writeChunk(w, id, created, chatCompletionChoice{Delta: &chatCompletionText{}, FinishReason: &stop})
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeChunk sends one chat.completion.chunk event and flushes it.
+func (s *OpenAIServer) writeChunk(w http.ResponseWriter, id string, created int64, choice chatCompletionChoice) {
+	data, _ := json.Marshal(chatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion.chunk",
+		Created: created,
+		Model:   s.model,
+		Choices: []chatCompletionChoice{choice},
+	})
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// lastUserIndex returns the index of the last user message, or -1.
+func lastUserIndex(msgs []chatCompletionMsg) int {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == "user" {
+			return i
+		}
+	}
+	return -1
+}
+
+// messageText returns a message's text. Content may be a string or an array
+// of parts, of which only text parts are kept.
+func messageText(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
+
+// withHistory prefixes content with the earlier messages of a stateless
+// request, one "ROLE: text" line each, so the agent sees the conversation
+// the client sent.
+func withHistory(earlier []chatCompletionMsg, content string) string {
+	var lines []string
+	for _, m := range earlier {
+		if text := messageText(m.Content); text != "" {
+			lines = append(lines, strings.ToUpper(m.Role)+": "+text)
+		}
+	}
+	if len(lines) == 0 {
+		return content
+	}
+	return "[Conversation so far]\n" + strings.Join(lines, "\n") + "\n\n[Current message]\n" + content
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeOpenAIError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{"message": message, "type": errType},
+	})
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+)
+
+// fakeLoop records the last message and answers with a fixed reply.
+type fakeLoop struct {
+	got   bus.AgentMessage
+	reply string
+}
+
+func (f *fakeLoop) ProcessDirect(_ context.Context, msg bus.AgentMessage) string {
+	f.got = msg
+	return f.reply
+}
+
+func (f *fakeLoop) ProcessQueued(ctx context.Context, msg bus.AgentMessage) string {
+	return f.ProcessDirect(ctx, msg)
+}
+
+func (f *fakeLoop) Run(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }
+
+func newTestOpenAIServer(t *testing.T, loop *fakeLoop, token string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	NewOpenAIServer(loop, "test-model", token).Register(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postCompletion(t *testing.T, url, token, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url+"/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestChatCompletions_ResponseShape(t *testing.T) {
+	loop := &fakeLoop{reply: "Hi there!"}
+	srv := newTestOpenAIServer(t, loop, "tok")
+
+	body := `{"model":"x","user":"alice","messages":[
+		{"role":"system","content":"be nice"},
+		{"role":"user","content":"first"},
+		{"role":"assistant","content":"ok"},
+		{"role":"user","content":[{"type":"text","text":"hello"}]}]}`
+	resp := postCompletion(t, srv.URL, "tok", body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var out chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Object != "chat.completion" || !strings.HasPrefix(out.ID, "chatcmpl-") || out.Model != "test-model" {
+		t.Errorf("unexpected envelope: %+v", out)
+	}
+	if len(out.Choices) != 1 || out.Choices[0].Message == nil ||
+		out.Choices[0].Message.Role != "assistant" || out.Choices[0].Message.Content != "Hi there!" ||
+		out.Choices[0].FinishReason == nil || *out.Choices[0].FinishReason != "stop" {
+		t.Errorf("unexpected choices: %+v", out.Choices)
+	}
+
+	if loop.got.Channel() != bus.ChannelOpenAI || loop.got.ChatId() != "alice" || loop.got.Content() != "hello" {
+		t.Errorf("unexpected agent message: channel=%s chat=%s content=%q",
+			loop.got.Channel(), loop.got.ChatId(), loop.got.Content())
+	}
+	if loop.got.Ephemeral() {
+		t.Error("a request with user should keep its session")
+	}
+}
+
+func TestChatCompletions_StatelessWithoutUser(t *testing.T) {
+	loop := &fakeLoop{reply: "ok"}
+	srv := newTestOpenAIServer(t, loop, "tok")
+
+	body := `{"messages":[
+		{"role":"system","content":"be brief"},
+		{"role":"user","content":"my name is Ann"},
+		{"role":"assistant","content":"Hi Ann"},
+		{"role":"user","content":"what is my name?"}]}`
+	postCompletion(t, srv.URL, "tok", body)
+	first := loop.got
+	want := "[Conversation so far]\nSYSTEM: be brief\nUSER: my name is Ann\nASSISTANT: Hi Ann\n\n[Current message]\nwhat is my name?"
+	if first.Content() != want {
+		t.Errorf("expected earlier messages to be passed along, got %q", first.Content())
+	}
+
+	postCompletion(t, srv.URL, "tok", `{"messages":[{"role":"user","content":"hi"}]}`)
+	if loop.got.Content() != "hi" {
+		t.Errorf("expected a lone message to be sent as is, got %q", loop.got.Content())
+	}
+	if !strings.HasPrefix(first.ChatId(), "req-") || first.ChatId() == loop.got.ChatId() {
+		t.Errorf("expected a separate session per request without user, got %q and %q", first.ChatId(), loop.got.ChatId())
+	}
+	if !first.Ephemeral() || !loop.got.Ephemeral() {
+		t.Error("expected requests without user to run in ephemeral sessions")
+	}
+}
+
+func TestChatCompletions_Stream(t *testing.T) {
+	srv := newTestOpenAIServer(t, &fakeLoop{reply: "streamed"}, "tok")
+
+	resp := postCompletion(t, srv.URL, "tok", `{"stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected event stream, got %q", ct)
+	}
+
+	var chunks []chatCompletionResponse
+	done := false
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+		var c chatCompletionResponse
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			t.Fatalf("decode chunk: %v", err)
+		}
+		chunks = append(chunks, c)
+	}
+	if !done || len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks then [DONE], got %d chunks (done=%v)", len(chunks), done)
+	}
+	var content string
+	for _, c := range chunks {
+		if c.Object != "chat.completion.chunk" || len(c.Choices) != 1 || c.Choices[0].Delta == nil {
+			t.Fatalf("unexpected chunk: %+v", c)
+		}
+		content += c.Choices[0].Delta.Content
+	}
+	if chunks[0].Choices[0].Delta.Role != "assistant" || content != "streamed" {
+		t.Errorf("unexpected stream: role=%q content=%q", chunks[0].Choices[0].Delta.Role, content)
+	}
+	if fr := chunks[2].Choices[0].FinishReason; fr == nil || *fr != "stop" {
+		t.Errorf("expected finish_reason stop on the last chunk, got %v", fr)
+	}
+}
+
+func TestChatCompletions_AuthAndValidation(t *testing.T) {
+	srv := newTestOpenAIServer(t, &fakeLoop{}, "tok")
+
+	if resp := postCompletion(t, srv.URL, "wrong", `{"messages":[{"role":"user","content":"hi"}]}`); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 with a bad token, got %d", resp.StatusCode)
+	}
+	if resp := postCompletion(t, srv.URL, "tok", `{"messages":[{"role":"system","content":"x"}]}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 without a user message, got %d", resp.StatusCode)
+	}
+}
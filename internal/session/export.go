@@ -0,0 +1,203 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// Export formats accepted by Manager.Export.
+const (
+	ExportMarkdown = "md"
+	ExportJSON     = "json"
+)
+
+// ErrNotFound is returned by Export when no session is stored under the key.
+var ErrNotFound = errors.New("not found")
+
+// exportResultChars caps how much of each tool result a Markdown export shows.
+const exportResultChars = 200
+
+// exportEntry is one message line of a session file, with its timestamp.
+type exportEntry struct {
+	raw       json.RawMessage
+	msg       schema.Message
+	timestamp time.Time
+}
+
+// Export renders the saved session for key as a Markdown transcript
+// (ExportMarkdown) or a JSON array (ExportJSON). The JSON array holds the
+// metadata object followed by every message exactly as stored on disk.
+func (m *Manager) Export(key, format string) ([]byte, error) {
+	if format != ExportMarkdown && format != ExportJSON {
+		return nil, fmt.Errorf("unsupported export format %q (want %s or %s)", format, ExportMarkdown, ExportJSON)
+	}
+	meta, entries, err := m.readEntries(key)
+	if err != nil {
+		return nil, err
+	}
+	if format == ExportJSON {
+		return exportJSON(meta, entries)
+	}
+	return exportMarkdown(key, meta, entries), nil
+}
+
+// readEntries reads the metadata line and messages of key's session file.
+func (m *Manager) readEntries(key string) (map[string]any, []exportEntry, error) {
+	f, err := os.Open(m.sessionPath(key))
+	if os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("session %q %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	meta := map[string]any{"_type": "metadata", "key": key}
+	var entries []exportEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		var data map[string]any
+		if len(line) == 0 || json.Unmarshal(line, &data) != nil {
+			continue
+		}
+		if data["_type"] == "metadata" {
+			meta = data
+			continue
+		}
+		e := exportEntry{raw: json.RawMessage(slices.Clone(line)), msg: wireToMessage(data)}
+		if ts, ok := data["timestamp"].(string); ok {
+			e.timestamp, _ = time.Parse(time.RFC3339, ts)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read session %q: %w", key, err)
+	}
+	return meta, entries, nil
+}
+
+func exportJSON(meta map[string]any, entries []exportEntry) ([]byte, error) {
+	out := make([]any, 0, len(entries)+1)
+	out = append(out, meta)
+	for _, e := range entries {
+		out = append(out, e.raw)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return nil, fmt.Errorf("encode session: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// exportMarkdown renders a role-labelled transcript. Tool calls and their
+// results are summarised as list items under the assistant turn that made them.
+func exportMarkdown(key string, meta map[string]any, entries []exportEntry) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Session %s\n\n", key)
+	for _, field := range []struct{ label, name string }{{"Created", "created_at"}, {"Updated", "updated_at"}} {
+		if ts, ok := meta[field.name].(string); ok && ts != "" {
+			fmt.Fprintf(&sb, "- %s: %s\n", field.label, ts)
+		}
+	}
+	fmt.Fprintf(&sb, "- Messages: %d\n", len(entries))
+	if extra, ok := meta["metadata"].(map[string]any); ok {
+		for _, k := range slices.Sorted(maps.Keys(extra)) {
+			v, _ := json.Marshal(extra[k])
+			fmt.Fprintf(&sb, "- %s: %s\n", k, v)
+		}
+	}
+
+	var lastRole schema.MessageRole
+	for _, e := range entries {
+		msg := e.msg
+		text := strings.TrimSpace(exportText(msg.Content))
+
+		// Tool results attach to the preceding assistant turn.
+		if msg.Role == schema.RoleTool {
+			if lastRole != schema.RoleAssistant && lastRole != schema.RoleTool {
+				sb.WriteString("\n### Tool\n\n")
+			}
+			fmt.Fprintf(&sb, "- Result of `%s`: %s\n", msg.ToolName, summarise(text))
+			lastRole = msg.Role
+			continue
+		}
+		// A bare tool-call step continues the assistant turn above it.
+		continued := msg.Role == schema.RoleAssistant && text == "" && len(msg.ToolCalls) > 0 &&
+			(lastRole == schema.RoleAssistant || lastRole == schema.RoleTool)
+		if !continued {
+			sb.WriteString("\n### " + roleLabel(msg.Role))
+			if !e.timestamp.IsZero() {
+				sb.WriteString(" · " + e.timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+			}
+			sb.WriteString("\n\n")
+			if text != "" {
+				sb.WriteString(text + "\n")
+				if len(msg.ToolCalls) > 0 {
+					sb.WriteString("\n")
+				}
+			}
+		}
+		for _, tc := range msg.ToolCalls {
+			args, _ := json.Marshal(tc.Arguments)
+			fmt.Fprintf(&sb, "- Called `%s` with `%s`\n", tc.Name, args)
+		}
+		lastRole = msg.Role
+	}
+	return []byte(sb.String())
+}
+
+func roleLabel(role schema.MessageRole) string {
+	if role == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(string(role[:1])) + string(role[1:])
+}
+
+// exportText returns the text of content as loaded from disk, where
+// multimodal content is a list of block objects; images become placeholders.
+func exportText(content any) string {
+	blocks, ok := content.([]any)
+	if !ok {
+		return messageText(schema.Message{Content: content})
+	}
+	var parts []string
+	for _, b := range blocks {
+		block, _ := b.(map[string]any)
+		switch block["type"] {
+		case "text":
+			if s, _ := block["text"].(string); s != "" {
+				parts = append(parts, s)
+			}
+		case "image_url":
+			parts = append(parts, "[image]")
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// summarise flattens a tool result to one line of at most exportResultChars.
+func summarise(text string) string {
+	if text == "" {
+		return "(empty)"
+	}
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= exportResultChars {
+		return string(runes)
+	}
+	return string(runes[:exportResultChars]) + "…"
+}
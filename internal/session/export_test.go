@@ -0,0 +1,78 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+const exportFixture = `{"_type":"metadata","key":"telegram:42","created_at":"2026-01-01T00:00:00Z","updated_at":"2026-02-01T12:00:05Z","metadata":{"lang":"en"},"last_consolidated":0}
+{"role":"user","content":"What's the weather?","timestamp":"2026-02-01T12:00:00Z"}
+{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"web_search","arguments":"{\"query\":\"weather\"}"}}],"timestamp":"2026-02-01T12:00:01Z"}
+{"role":"tool","content":"Sunny,\n22°C","tool_call_id":"call_1","name":"web_search","tool_args":{"query":"weather"},"timestamp":"2026-02-01T12:00:02Z"}
+{"role":"assistant","content":"It's sunny and 22°C.","timestamp":"2026-02-01T12:00:03Z","tools_used":["web_search"]}
+`
+
+func exportManager(t *testing.T) *Manager {
+	t.Helper()
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(mgr.sessionPath("telegram:42"), []byte(exportFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return mgr
+}
+
+func TestExport_Markdown(t *testing.T) {
+	out, err := exportManager(t).Export("telegram:42", ExportMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Session telegram:42\n\n" +
+		"- Created: 2026-01-01T00:00:00Z\n" +
+		"- Updated: 2026-02-01T12:00:05Z\n" +
+		"- Messages: 4\n" +
+		"- lang: \"en\"\n" +
+		"\n### User · 2026-02-01 12:00:00 UTC\n\nWhat's the weather?\n" +
+		"\n### Assistant · 2026-02-01 12:00:01 UTC\n\n" +
+		"- Called `web_search` with `{\"query\":\"weather\"}`\n" +
+		"- Result of `web_search`: Sunny, 22°C\n" +
+		"\n### Assistant · 2026-02-01 12:00:03 UTC\n\nIt's sunny and 22°C.\n"
+	if string(out) != want {
+		t.Errorf("markdown export mismatch:\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestExport_JSON(t *testing.T) {
+	out, err := exportManager(t).Export("telegram:42", ExportJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []map[string]any
+	if err := json.Unmarshal(out, &entries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("expected metadata + 4 messages, got %d", len(entries))
+	}
+	if entries[0]["_type"] != "metadata" || entries[0]["key"] != "telegram:42" {
+		t.Errorf("unexpected metadata entry: %v", entries[0])
+	}
+	if tool := entries[3]; tool["role"] != "tool" || tool["name"] != "web_search" || tool["timestamp"] != "2026-02-01T12:00:02Z" {
+		t.Errorf("expected tool message kept verbatim, got %v", tool)
+	}
+}
+
+func TestExport_Errors(t *testing.T) {
+	mgr := exportManager(t)
+	if _, err := mgr.Export("telegram:42", "html"); err == nil || !strings.Contains(err.Error(), "unsupported export format") {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+	if _, err := mgr.Export("telegram:missing", ExportJSON); !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -22,9 +23,11 @@ import (
 	"time"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/atomicfile"
 )
 
-// Manager loads and persists sessions as JSONL files.
+// Manager loads and persists sessions as JSONL files. It is the default
+// SessionStore.
 type Manager struct {
 	sessionsDir string   // workspace/sessions/
 	cache       sync.Map // key → *Session
@@ -41,6 +44,8 @@ func NewManager(workspace string) (*Manager, error) {
 	return &Manager{sessionsDir: dir}, nil
 }
 
+var _ SessionStore = (*Manager)(nil)
+
 // GetOrCreate returns the cached session for key, loading from disk if needed,
 // or creating an empty new one.
 func (m *Manager) GetOrCreate(key string) *ChannelSessionImpl {
@@ -95,7 +100,7 @@ func (m *Manager) Save(s *ChannelSessionImpl) error {
 		}
 	}
 
-	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0o644); err != nil {
 		return fmt.Errorf("write session %s: %w", path, err)
 	}
 
@@ -120,51 +125,112 @@ func (m *Manager) Invalidate(key string) {
 	m.cache.Delete(key)
 }
 
-// ListSessions returns metadata for all sessions, sorted newest-first.
-func (m *Manager) ListSessions() []map[string]any {
+// List returns metadata for all session files, sorted newest-first.
+func (m *Manager) List() []SessionInfo {
 	entries, _ := filepath.Glob(filepath.Join(m.sessionsDir, "*.jsonl"))
-	var out []map[string]any
+	var out []SessionInfo
 
 	for _, path := range entries {
-		f, err := os.Open(path)
-		if err != nil {
+		if info, ok := readSessionInfo(path); ok {
+			out = append(out, info)
+		}
+	}
+
+	sortSessionInfos(out)
+	return out
+}
+
+// PruneOlderThan deletes session files whose updated_at is more than d ago
+// and returns how many were removed. Sessions held in the cache are in use
+// and always kept, as are files without a readable updated_at.
+func (m *Manager) PruneOlderThan(d time.Duration) (int, error) {
+	if d <= 0 {
+		return 0, fmt.Errorf("prune age must be positive, got %s", d)
+	}
+	cutoff := time.Now().Add(-d)
+	entries, err := filepath.Glob(filepath.Join(m.sessionsDir, "*.jsonl"))
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		removed int
+		errs    []error
+	)
+	for _, path := range entries {
+		info, ok := readSessionInfo(path)
+		if !ok || info.UpdatedAt.IsZero() || !info.UpdatedAt.Before(cutoff) {
 			continue
 		}
-		scanner := bufio.NewScanner(f)
-		if scanner.Scan() {
-			var data map[string]any
-			if json.Unmarshal(scanner.Bytes(), &data) == nil &&
-				data["_type"] == "metadata" {
-				key, _ := data["key"].(string)
-				if key == "" {
-					// Fall back: derive from filename
-					base := filepath.Base(path)
-					key = strings.TrimSuffix(base, ".jsonl")
-					key = strings.Replace(key, "_", ":", 1)
-				}
-				out = append(out, map[string]any{
-					"key":        key,
-					"created_at": data["created_at"],
-					"updated_at": data["updated_at"],
-					"path":       path,
-				})
-			}
+		if _, active := m.cache.Load(info.Key); active {
+			continue
 		}
-		f.Close()
+		if err := os.Remove(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
 	}
+	return removed, errors.Join(errs...)
+}
 
-	// Sort newest-first by updated_at string (ISO format sorts lexicographically).
-	for i := 0; i < len(out)-1; i++ {
-		for j := i + 1; j < len(out); j++ {
-			ai, _ := out[i]["updated_at"].(string)
-			aj, _ := out[j]["updated_at"].(string)
-			if aj > ai {
-				out[i], out[j] = out[j], out[i]
-			}
-		}
+// readSessionInfo reads the metadata line of the session file at path.
+func readSessionInfo(path string) (SessionInfo, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return SessionInfo{}, false
 	}
+	defer f.Close()
 
-	return out
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	if !scanner.Scan() {
+		return SessionInfo{}, false
+	}
+	var data map[string]any
+	if json.Unmarshal(scanner.Bytes(), &data) != nil || data["_type"] != "metadata" {
+		return SessionInfo{}, false
+	}
+	key, _ := data["key"].(string)
+	if key == "" {
+		// Fall back: derive from filename
+		base := filepath.Base(path)
+		key = strings.TrimSuffix(base, ".jsonl")
+		key = strings.Replace(key, "_", ":", 1)
+	}
+	info := SessionInfo{Key: key}
+	if ts, ok := data["created_at"].(string); ok {
+		info.CreatedAt, _ = time.Parse(time.RFC3339, ts)
+	}
+	if ts, ok := data["updated_at"].(string); ok {
+		info.UpdatedAt, _ = time.Parse(time.RFC3339, ts)
+	}
+	return info, true
+}
+
+// Search scans every session file for messages containing query. Cached
+// sessions are searched in memory so unsaved messages are included.
+func (m *Manager) Search(query string, limit int) []SearchHit {
+	needle := strings.ToLower(query)
+	if needle == "" {
+		return nil
+	}
+	var hits []SearchHit
+	for _, info := range m.List() {
+		if limit > 0 && len(hits) >= limit {
+			break
+		}
+		var sess *ChannelSessionImpl
+		if v, ok := m.cache.Load(info.Key); ok {
+			sess = v.(*ChannelSessionImpl)
+		} else if loaded, ok := m.load(info.Key).(*ChannelSessionImpl); ok {
+			sess = loaded
+		} else {
+			continue
+		}
+		hits = searchSession(sess, info, needle, hits, limit)
+	}
+	return hits
 }
 
 // ---------------------------------------------------------------------------
@@ -180,6 +246,9 @@ type wireMessage struct {
 	Name             string             `json:"name,omitempty"`
 	ReasoningContent string             `json:"reasoning_content,omitempty"`
 	ToolsUsed        []string           `json:"tools_used,omitempty"`
+	ToolArgs         map[string]any     `json:"tool_args,omitempty"`
+	PromptTokens     int                `json:"prompt_tokens,omitempty"`
+	CompletionTokens int                `json:"completion_tokens,omitempty"`
 	Timestamp        string             `json:"timestamp"`
 }
 
@@ -189,6 +258,10 @@ func messageToWire(msg schema.Message) wireMessage {
 		Role:      msg.Role,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		ToolsUsed: msg.ToolsUsed,
+		ToolArgs:  msg.ToolArgs,
+
+		PromptTokens:     msg.Usage["prompt_tokens"],
+		CompletionTokens: msg.Usage["completion_tokens"],
 	}
 
 	switch v := msg.Content.(type) {
@@ -259,6 +332,17 @@ func wireToMessage(data map[string]any) schema.Message {
 	if rc, ok := data["reasoning_content"].(string); ok && rc != "" {
 		msg.ReasoningContent = &rc
 	}
+	if ta, ok := data["tool_args"].(map[string]any); ok {
+		msg.ToolArgs = ta
+	}
+	for _, k := range []string{"prompt_tokens", "completion_tokens"} {
+		if n, ok := data[k].(float64); ok && n > 0 {
+			if msg.Usage == nil {
+				msg.Usage = make(map[string]int, 2)
+			}
+			msg.Usage[k] = int(n)
+		}
+	}
 	if tu, ok := data["tools_used"].([]any); ok {
 		for _, t := range tu {
 			if s, ok := t.(string); ok {
@@ -0,0 +1,113 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// InMemoryStore is a SessionStore that keeps sessions in process memory only.
+// It suits tests and ephemeral deployments; nothing survives a restart.
+//
+// Save stores the session instance itself, so Invalidate followed by
+// GetOrCreate returns the last saved state, mirroring a reload from disk.
+type InMemoryStore struct {
+	mu    sync.Mutex
+	saved map[string]*ChannelSessionImpl // last Save per key
+	live  map[string]*ChannelSessionImpl // instances handed out by GetOrCreate
+}
+
+var _ SessionStore = (*InMemoryStore)(nil)
+
+// NewInMemoryStore returns an empty InMemoryStore.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		saved: make(map[string]*ChannelSessionImpl),
+		live:  make(map[string]*ChannelSessionImpl),
+	}
+}
+
+func (m *InMemoryStore) GetOrCreate(key string) *ChannelSessionImpl {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.live[key]; ok {
+		return s
+	}
+	s, ok := m.saved[key]
+	if !ok {
+		now := time.Now()
+		s = &ChannelSessionImpl{
+			Key:       key,
+			Entries:   schema.NewMessages(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Metadata:  map[string]any{},
+		}
+	}
+	m.live[key] = s
+	return s
+}
+
+func (m *InMemoryStore) Save(s *ChannelSessionImpl) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.saved[s.Key] = s
+	m.live[s.Key] = s
+	return nil
+}
+
+// SaveCompacted implements schema.SessionSaver.
+func (m *InMemoryStore) SaveCompacted(s schema.ChannelSession) error {
+	sess, ok := s.(*ChannelSessionImpl)
+	if !ok {
+		return fmt.Errorf("session.InMemoryStore.SaveCompacted: unexpected type %T", s)
+	}
+	return m.Save(sess)
+}
+
+func (m *InMemoryStore) Invalidate(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.live, key)
+}
+
+func (m *InMemoryStore) List() []SessionInfo {
+	m.mu.Lock()
+	out := make([]SessionInfo, 0, len(m.saved))
+	for _, s := range m.saved {
+		s.mu.Lock()
+		out = append(out, SessionInfo{Key: s.Key, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt})
+		s.mu.Unlock()
+	}
+	m.mu.Unlock()
+
+	sortSessionInfos(out)
+	return out
+}
+
+func (m *InMemoryStore) Search(query string, limit int) []SearchHit {
+	needle := strings.ToLower(query)
+	if needle == "" {
+		return nil
+	}
+	var hits []SearchHit
+	for _, info := range m.List() {
+		if limit > 0 && len(hits) >= limit {
+			break
+		}
+		m.mu.Lock()
+		s := m.saved[info.Key]
+		if live, ok := m.live[info.Key]; ok {
+			s = live
+		}
+		m.mu.Unlock()
+		hits = searchSession(s, info, needle, hits, limit)
+	}
+	return hits
+}
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPruneOlderThan(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	fixtures := map[string]time.Time{
+		"cli:fresh":     now.Add(-time.Hour),
+		"cli:stale":     now.Add(-40 * 24 * time.Hour),
+		"cli:ancient":   now.Add(-400 * 24 * time.Hour),
+		"cli:active":    now.Add(-90 * 24 * time.Hour), // old on disk but cached
+		"cli:undatable": {},
+	}
+	for key, updated := range fixtures {
+		ts := ""
+		if !updated.IsZero() {
+			ts = updated.UTC().Format(time.RFC3339)
+		}
+		line := fmt.Sprintf(`{"_type":"metadata","key":%q,"created_at":"2025-01-01T00:00:00Z","updated_at":%q,"metadata":{},"last_consolidated":0}`+"\n", key, ts)
+		if err := os.WriteFile(mgr.sessionPath(key), []byte(line), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mgr.GetOrCreate("cli:active")
+
+	n, err := mgr.PruneOlderThan(30 * 24 * time.Hour)
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 sessions pruned, got %d (err %v)", n, err)
+	}
+	for key := range fixtures {
+		_, statErr := os.Stat(mgr.sessionPath(key))
+		gone := os.IsNotExist(statErr)
+		if want := key == "cli:stale" || key == "cli:ancient"; gone != want {
+			t.Errorf("%s: removed=%v, want %v", key, gone, want)
+		}
+	}
+	if files, _ := filepath.Glob(filepath.Join(mgr.sessionsDir, "*.jsonl")); len(files) != 3 {
+		t.Errorf("expected 3 session files left, got %d", len(files))
+	}
+
+	if _, err := mgr.PruneOlderThan(0); err == nil {
+		t.Error("expected error for non-positive age")
+	}
+}
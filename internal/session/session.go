@@ -7,6 +7,8 @@ import (
 	"log/slog"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/llmutils"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/redact"
 )
 
 // ChannelSessionImpl holds one conversation's messages and metadata.
@@ -58,8 +60,9 @@ func (s *ChannelSessionImpl) AddUser(content string) {
 	s.UpdatedAt = time.Now()
 }
 
-// AddAssistant appends an assistant message to the session.
-func (s *ChannelSessionImpl) AddAssistant(content string, toolsUsed []string) {
+// AddAssistant appends an assistant message to the session. usage holds the
+// prompt_tokens/completion_tokens of the reply and may be nil.
+func (s *ChannelSessionImpl) AddAssistant(content string, toolsUsed []string, usage map[string]int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -68,24 +71,90 @@ func (s *ChannelSessionImpl) AddAssistant(content string, toolsUsed []string) {
 		Role:      "assistant",
 		Content:   &c,
 		ToolsUsed: toolsUsed,
+		Usage:     usage,
 	}
 
 	s.Entries.Add(msg)
 	s.UpdatedAt = time.Now()
 }
 
+// maxPersistedToolResult caps the size of a tool result stored in the session.
+const maxPersistedToolResult = 2000
+
+// AddSteps appends the intermediate tool-call and tool-result messages of a
+// turn (as returned by the agent loop) to the session.
+func (s *ChannelSessionImpl) AddSteps(steps schema.Messages) {
+	for _, m := range steps.Messages {
+		switch m.Role {
+		case schema.RoleAssistant:
+			content, _ := m.Content.(*string)
+			s.AddToolCalls(content, m.ToolCalls, m.Usage)
+		case schema.RoleTool:
+			content, _ := m.Content.(string)
+			s.AddToolResult(m.ToolCallID, m.ToolName, m.ToolArgs, content)
+		}
+	}
+}
+
+// AddToolCalls appends an assistant message that invoked tools, with
+// secret-looking arguments redacted. usage may be nil.
+func (s *ChannelSessionImpl) AddToolCalls(content *string, toolCalls []schema.ToolCall, usage map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	redacted := make([]schema.ToolCall, len(toolCalls))
+	for i, tc := range toolCalls {
+		redacted[i] = schema.NewToolCall(tc.ID, tc.Name, redact.Args(tc.Arguments))
+	}
+	s.Entries.AddAssistant(content, redacted, nil)
+	s.Entries.Messages[len(s.Entries.Messages)-1].Usage = usage
+	s.UpdatedAt = time.Now()
+}
+
+// AddToolResult appends a tool-result message recording the tool name,
+// its redacted arguments, and the result capped to maxPersistedToolResult.
+func (s *ChannelSessionImpl) AddToolResult(toolCallID, toolName string, args map[string]any, result string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := schema.NewToolResultMessage(toolCallID, toolName, llmutils.Truncate(result, maxPersistedToolResult))
+	msg.ToolArgs = redact.Args(args)
+
+	s.Entries.Add(msg)
+	s.UpdatedAt = time.Now()
+}
+
 // History returns the last messages for the LLM.
-func (s *ChannelSessionImpl) History(maxMessages int) schema.Messages {
+// Unless includeTools is set, persisted tool calls and results are skipped so
+// the model only sees user/assistant text. Tool results orphaned by the window
+// cut are always dropped. Token usage is bookkeeping and is stripped.
+func (s *ChannelSessionImpl) History(maxMessages int, includeTools bool) schema.Messages {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	msgs := s.Entries.Messages
+	if !includeTools {
+		filtered := make([]schema.Message, 0, len(msgs))
+		for _, m := range msgs {
+			if m.IsToolStep() {
+				continue
+			}
+			filtered = append(filtered, m)
+		}
+		msgs = filtered
+	}
 	if maxMessages > 0 && len(msgs) > maxMessages {
 		msgs = msgs[len(msgs)-maxMessages:]
 	}
+	for len(msgs) > 0 && msgs[0].Role == schema.RoleTool {
+		msgs = msgs[1:]
+	}
 
 	out := schema.NewMessages()
 	out.Messages = append(out.Messages, msgs...)
+	for i := range out.Messages {
+		out.Messages[i].Usage = nil
+	}
 	return out
 }
 
@@ -105,6 +174,32 @@ func (s *ChannelSessionImpl) Clear() {
 	s.UpdatedAt = time.Now()
 }
 
+// metaModel is the Metadata key holding the session's /model override.
+const metaModel = "model"
+
+// Model returns the model chosen for this session with /model, or "" to use
+// the configured default.
+func (s *ChannelSessionImpl) Model() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	model, _ := s.Metadata[metaModel].(string)
+	return model
+}
+
+// SetModel stores a per-session model override; "" removes it.
+func (s *ChannelSessionImpl) SetModel(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if model == "" {
+		delete(s.Metadata, metaModel)
+		return
+	}
+	if s.Metadata == nil {
+		s.Metadata = map[string]any{}
+	}
+	s.Metadata[metaModel] = model
+}
+
 // LastCompacted returns the consolidation pointer.
 // Caller must hold s.mu.
 func (s *ChannelSessionImpl) LastCompacted() int {
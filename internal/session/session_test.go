@@ -0,0 +1,145 @@
+package session
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+	"github.com/crystaldolphin/crystaldolphin/internal/shared/redact"
+)
+
+func TestAddSteps_ToolResultsPersisted(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := map[string]any{"url": "https://example.com", "apiKey": "sk-123"}
+	var steps schema.Messages
+	steps.AddAssistant(nil, []schema.ToolCall{schema.NewToolCall("call_1", "web_fetch", args)}, nil)
+	res := schema.NewToolResultMessage("call_1", "web_fetch", strings.Repeat("x", maxPersistedToolResult+500))
+	res.ToolArgs = args
+	steps.Add(res)
+
+	ses := mgr.GetOrCreate("cli:direct")
+	ses.AddUser("fetch it")
+	ses.AddSteps(steps)
+	ses.AddAssistant("done", []string{"web_fetch"}, nil)
+	if err := mgr.Save(ses); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := os.ReadFile(mgr.sessionPath("cli:direct"))
+	if strings.Contains(string(raw), "sk-123") {
+		t.Error("expected secret argument to be redacted on disk")
+	}
+
+	mgr.Invalidate("cli:direct")
+	msgs := mgr.GetOrCreate("cli:direct").Messages().Messages
+	if len(msgs) != 4 {
+		t.Fatalf("expected 4 persisted messages, got %d", len(msgs))
+	}
+	tool := msgs[2]
+	if tool.Role != schema.RoleTool || tool.ToolName != "web_fetch" || tool.ToolCallID != "call_1" {
+		t.Errorf("unexpected tool message: %+v", tool)
+	}
+	if tool.ToolArgs["url"] != "https://example.com" || tool.ToolArgs["apiKey"] != redact.Placeholder {
+		t.Errorf("unexpected tool args: %v", tool.ToolArgs)
+	}
+	if content, _ := tool.Content.(string); len(content) > maxPersistedToolResult+3 {
+		t.Errorf("expected result capped, got %d chars", len(content))
+	}
+}
+
+func TestHistory_ExcludesToolsByDefault(t *testing.T) {
+	ses := &ChannelSessionImpl{Entries: schema.NewMessages()}
+	ses.AddUser("hi")
+	ses.AddToolCalls(nil, []schema.ToolCall{schema.NewToolCall("call_1", "exec", nil)}, nil)
+	ses.AddToolResult("call_1", "exec", nil, "ok")
+	ses.AddAssistant("hello", []string{"exec"}, nil)
+
+	if got := ses.History(0, false).Len(); got != 2 {
+		t.Errorf("expected 2 messages without tools, got %d", got)
+	}
+	if got := ses.History(0, true).Len(); got != 4 {
+		t.Errorf("expected 4 messages with tools, got %d", got)
+	}
+	// A window that starts on a tool result drops the orphan.
+	if h := ses.History(2, true); h.Len() != 1 || h.Messages[0].Role != schema.RoleAssistant {
+		t.Errorf("expected orphaned tool result dropped, got %+v", h.Messages)
+	}
+}
+
+func TestSave_IgnoresStaleTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ses := mgr.GetOrCreate("cli:direct")
+	ses.AddUser("hello")
+	if err := mgr.Save(ses); err != nil {
+		t.Fatal(err)
+	}
+	path := mgr.sessionPath("cli:direct")
+	good, _ := os.ReadFile(path)
+
+	// A crash left a truncated temp file behind.
+	if err := os.WriteFile(path+".tmp-crashed", good[:len(good)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr.Invalidate("cli:direct")
+	if msgs := mgr.GetOrCreate("cli:direct").Messages().Messages; len(msgs) != 1 {
+		t.Errorf("expected the good session to load, got %d messages", len(msgs))
+	}
+	if n := len(mgr.List()); n != 1 {
+		t.Errorf("temp files should not be listed as sessions, got %d", n)
+	}
+}
+
+func TestUsage_PersistedButNotInHistory(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ses := mgr.GetOrCreate("cli:direct")
+	ses.AddUser("hi")
+	ses.AddToolCalls(nil, []schema.ToolCall{schema.NewToolCall("call_1", "exec", nil)}, map[string]int{"prompt_tokens": 90, "completion_tokens": 5})
+	ses.AddToolResult("call_1", "exec", nil, "ok")
+	ses.AddAssistant("hello", nil, map[string]int{"prompt_tokens": 120, "completion_tokens": 7})
+	if err := mgr.Save(ses); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := os.ReadFile(mgr.sessionPath("cli:direct"))
+	if !strings.Contains(string(raw), `"prompt_tokens":120,"completion_tokens":7`) {
+		t.Errorf("expected usage written to disk, got:\n%s", raw)
+	}
+
+	mgr.Invalidate("cli:direct")
+	loaded := mgr.GetOrCreate("cli:direct")
+	msgs := loaded.Messages().Messages
+	if u := msgs[len(msgs)-1].Usage; u["prompt_tokens"] != 120 || u["completion_tokens"] != 7 {
+		t.Errorf("expected usage restored, got %v", u)
+	}
+	if u := msgs[1].Usage; u["prompt_tokens"] != 90 || u["completion_tokens"] != 5 {
+		t.Errorf("expected tool-call usage restored, got %v", u)
+	}
+	if msgs[0].Usage != nil {
+		t.Errorf("expected no usage on the user message, got %v", msgs[0].Usage)
+	}
+
+	for _, m := range loaded.History(0, true).Messages {
+		if m.Usage != nil {
+			t.Errorf("expected usage stripped from history, got %v on %s", m.Usage, m.Role)
+		}
+	}
+	if msgs[len(msgs)-1].Usage == nil {
+		t.Error("History must not clear usage on the stored messages")
+	}
+}
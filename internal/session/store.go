@@ -0,0 +1,91 @@
+package session
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// SessionStore loads and persists conversation sessions. Manager (JSONL files)
+// is the default implementation; InMemoryStore keeps everything in process.
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	schema.SessionSaver
+
+	// GetOrCreate returns the session for key, loading it from the backend or
+	// creating an empty one. Repeated calls return the same instance until
+	// Invalidate is called.
+	GetOrCreate(key string) *ChannelSessionImpl
+	// Save persists the session.
+	Save(s *ChannelSessionImpl) error
+	// Invalidate drops any cached instance for key so the next GetOrCreate
+	// reloads it (used after /new).
+	Invalidate(key string)
+	// List returns all stored sessions, newest first.
+	List() []SessionInfo
+	// Search returns up to limit messages whose text contains query
+	// (case-insensitive), newest session first. limit <= 0 means no limit.
+	Search(query string, limit int) []SearchHit
+}
+
+// SessionInfo summarises one stored session.
+type SessionInfo struct {
+	Key       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SearchHit is one message matched by SessionStore.Search.
+type SearchHit struct {
+	Key       string
+	UpdatedAt time.Time // when the session was last updated
+	Index     int       // position of the message in the session
+	Role      schema.MessageRole
+	Content   string
+}
+
+// sortSessionInfos orders infos newest-first by UpdatedAt.
+func sortSessionInfos(infos []SessionInfo) {
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].UpdatedAt.After(infos[j].UpdatedAt)
+	})
+}
+
+// searchSession appends the messages of s matching the lower-cased needle to
+// hits, stopping once limit hits are collected (limit <= 0 means no limit).
+func searchSession(s *ChannelSessionImpl, info SessionInfo, needle string, hits []SearchHit, limit int) []SearchHit {
+	for i, msg := range s.Messages().Messages {
+		if limit > 0 && len(hits) >= limit {
+			break
+		}
+		text := messageText(msg)
+		if text != "" && strings.Contains(strings.ToLower(text), needle) {
+			hits = append(hits, SearchHit{Key: s.Key, UpdatedAt: info.UpdatedAt, Index: i, Role: msg.Role, Content: text})
+		}
+	}
+	return hits
+}
+
+// messageText returns the plain-text content of msg, joining the text blocks
+// of multimodal messages.
+func messageText(msg schema.Message) string {
+	switch v := msg.Content.(type) {
+	case string:
+		return v
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case []schema.ContentBlock:
+		var parts []string
+		for _, b := range v {
+			if b.Type == "text" && b.Text != "" {
+				parts = append(parts, b.Text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
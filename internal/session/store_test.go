@@ -0,0 +1,102 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+// storeFactories lists every SessionStore implementation run through the
+// shared contract below.
+var storeFactories = map[string]func(t *testing.T) SessionStore{
+	"jsonl": func(t *testing.T) SessionStore {
+		mgr, err := NewManager(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mgr
+	},
+	"memory": func(*testing.T) SessionStore { return NewInMemoryStore() },
+}
+
+func TestSessionStore_Contract(t *testing.T) {
+	for name, newStore := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			t.Run("GetOrCreateReturnsSameInstance", func(t *testing.T) {
+				store := newStore(t)
+				a := store.GetOrCreate("cli:direct")
+				if b := store.GetOrCreate("cli:direct"); a != b {
+					t.Error("expected the same session instance for repeated GetOrCreate")
+				}
+				if a.Len() != 0 {
+					t.Errorf("expected an empty new session, got %d messages", a.Len())
+				}
+			})
+
+			t.Run("SaveSurvivesInvalidate", func(t *testing.T) {
+				store := newStore(t)
+				s := store.GetOrCreate("telegram:42")
+				s.AddUser("remember the milk")
+				s.AddAssistant("noted", nil, nil)
+				if err := store.Save(s); err != nil {
+					t.Fatal(err)
+				}
+
+				store.Invalidate("telegram:42")
+				reloaded := store.GetOrCreate("telegram:42")
+				if reloaded.Len() != 2 {
+					t.Fatalf("expected 2 messages after reload, got %d", reloaded.Len())
+				}
+				if got := messageText(reloaded.Messages().Messages[0]); got != "remember the milk" {
+					t.Errorf("unexpected first message %q", got)
+				}
+			})
+
+			t.Run("ListNewestFirst", func(t *testing.T) {
+				store := newStore(t)
+				older := store.GetOrCreate("slack:a")
+				older.AddUser("hi")
+				older.UpdatedAt = time.Now().Add(-time.Hour)
+				newer := store.GetOrCreate("slack:b")
+				newer.AddUser("hi")
+				store.Save(older)
+				// The JSONL manager stamps updated_at at save time, so save
+				// the newer session a second later.
+				if name == "jsonl" {
+					time.Sleep(1100 * time.Millisecond)
+				}
+				store.Save(newer)
+
+				infos := store.List()
+				if len(infos) != 2 {
+					t.Fatalf("expected 2 sessions, got %d", len(infos))
+				}
+				if infos[0].Key != "slack:b" || infos[1].Key != "slack:a" {
+					t.Errorf("expected newest first, got %s, %s", infos[0].Key, infos[1].Key)
+				}
+			})
+
+			t.Run("SearchCaseInsensitiveWithLimit", func(t *testing.T) {
+				store := newStore(t)
+				s := store.GetOrCreate("discord:1")
+				s.AddUser("Deploy the STAGING cluster")
+				s.AddAssistant("staging deploy started", nil, nil)
+				s.AddUser("unrelated")
+				store.Save(s)
+
+				hits := store.Search("staging", 0)
+				if len(hits) != 2 {
+					t.Fatalf("expected 2 hits, got %d: %+v", len(hits), hits)
+				}
+				if hits[0].Key != "discord:1" || hits[0].Index != 0 {
+					t.Errorf("unexpected first hit %+v", hits[0])
+				}
+				if got := store.Search("staging", 1); len(got) != 1 {
+					t.Errorf("expected limit to cap hits at 1, got %d", len(got))
+				}
+				if got := store.Search("", 0); len(got) != 0 {
+					t.Errorf("expected no hits for an empty query, got %d", len(got))
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Package atomicfile replaces files so readers never see a partial write.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// syncFile flushes f to stable storage; tests replace it to simulate a crash.
+var syncFile = (*os.File).Sync
+
+// WriteFile writes data to a temp file in path's directory, fsyncs it, and
+// renames it over path with the given permissions, so a crash or power loss
+// mid-write leaves either the old or the new content, never a truncated file.
+// Temp files are named <path>.tmp-* and are removed when the write fails.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := syncFile(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,48 @@
+package atomicfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile_ReplacesContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("new"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "new" {
+		t.Errorf("content = %q, want %q", got, "new")
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("perm = %o, want 600", perm)
+	}
+}
+
+func TestWriteFile_FailedSyncKeepsOldContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte("good"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	syncFile = func(*os.File) error { return errors.New("disk gone") }
+	defer func() { syncFile = (*os.File).Sync }()
+	if err := WriteFile(path, []byte("lost"), 0o644); err == nil {
+		t.Fatal("expected write to fail")
+	}
+
+	if got, _ := os.ReadFile(path); string(got) != "good" {
+		t.Errorf("file changed by a failed write: %q", got)
+	}
+	if tmps, _ := filepath.Glob(path + ".tmp-*"); len(tmps) != 0 {
+		t.Errorf("failed write should clean up its temp file, found %v", tmps)
+	}
+}
@@ -1,13 +1,22 @@
 package cmdutils
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 const logo = "🐬"
 
 func PrintResponse(text string) {
+	FprintResponse(os.Stdout, text)
+}
+
+// FprintResponse writes an agent reply to w in the same format as PrintResponse.
+func FprintResponse(w io.Writer, text string) {
 	if text == "" {
 		return
 	}
 
-	fmt.Printf("\n%s crystaldolphin\n%s\n\n", logo, text)
+	fmt.Fprintf(w, "\n%s crystaldolphin\n%s\n\n", logo, text)
 }
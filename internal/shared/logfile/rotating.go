@@ -0,0 +1,81 @@
+// Package logfile provides a size-rotated, append-only log file.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Rotating is an append-only log file that is renamed to path.1 (shifting
+// older backups up to path.N) once it grows past maxBytes. The file and its
+// directory are created on first write, private to the user.
+type Rotating struct {
+	path     string
+	maxBytes int64
+	backups  int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewRotating creates a Rotating file at path that keeps backups old files.
+func NewRotating(path string, maxBytes int64, backups int) *Rotating {
+	return &Rotating{path: path, maxBytes: maxBytes, backups: backups}
+}
+
+func (r *Rotating) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f != nil && r.size+int64(len(p)) > r.maxBytes {
+		r.f.Close()
+		r.f = nil
+		r.rotate()
+	}
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file. A later Write reopens it.
+func (r *Rotating) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (r *Rotating) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *Rotating) rotate() {
+	for i := r.backups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	_ = os.Rename(r.path, r.path+".1")
+}
@@ -0,0 +1,23 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotating_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	r := NewRotating(path, 10, 3)
+
+	for _, s := range []string{"aaaaaaaa", "bbbbbbbb", "cccccccc"} {
+		if _, err := r.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for file, want := range map[string]string{path: "cccccccc", path + ".1": "bbbbbbbb", path + ".2": "aaaaaaaa"} {
+		if data, err := os.ReadFile(file); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", filepath.Base(file), data, err, want)
+		}
+	}
+}
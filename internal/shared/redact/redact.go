@@ -0,0 +1,69 @@
+// Package redact masks credentials before they reach logs, session files or
+// the tool audit log.
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces a redacted value.
+const Placeholder = "[REDACTED]"
+
+// secretKeyParts are substrings of argument, attribute, header and query
+// parameter names whose values are always redacted.
+var secretKeyParts = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "auth", "credential", "cookie"}
+
+// secretPatterns catch credentials embedded in free text, e.g. a Telegram bot
+// token inside a request URL returned by the HTTP client.
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`), "$1 " + Placeholder},
+	{regexp.MustCompile(`(?i)([?&](?:key|api_key|apikey|token|access_token|auth|password|secret)=)[^&\s"']+`), "${1}" + Placeholder},
+	{regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`), "${1}" + Placeholder + "@"},
+	{regexp.MustCompile(`/bot\d+:[A-Za-z0-9_-]+`), "/bot" + Placeholder},
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`), "sk-" + Placeholder},
+	{regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), "xox-" + Placeholder},
+}
+
+// IsSecretKey reports whether the value named key must be redacted. Matching
+// is case-insensitive and treats "-" as "_", so header names match too.
+func IsSecretKey(key string) bool {
+	k := strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, part := range secretKeyParts {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Args returns a copy of tool-call arguments with secret-looking values
+// replaced. Nested objects are redacted recursively.
+func Args(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		if IsSecretKey(k) {
+			out[k] = Placeholder
+			continue
+		}
+		if nested, ok := v.(map[string]any); ok {
+			v = Args(nested)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// String masks every credential pattern found in s.
+func String(s string) string {
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"password":      true,
+		"apiKey":        true,
+		"X-Api-Key":     true,
+		"Authorization": true,
+		"access_token":  true,
+		"path":          false,
+		"url":           false,
+	} {
+		if got := IsSecretKey(key); got != want {
+			t.Errorf("IsSecretKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestArgs_RedactsNestedAndKeepsOriginal(t *testing.T) {
+	args := map[string]any{
+		"url":     "https://example.com",
+		"headers": map[string]any{"Authorization": "Bearer abc", "Accept": "text/html"},
+		"token":   "t0k",
+	}
+	got := Args(args)
+	if got["url"] != "https://example.com" || got["token"] != Placeholder {
+		t.Errorf("unexpected redaction: %v", got)
+	}
+	headers := got["headers"].(map[string]any)
+	if headers["Authorization"] != Placeholder || headers["Accept"] != "text/html" {
+		t.Errorf("expected nested secret redacted, got %v", headers)
+	}
+	if args["token"] != "t0k" {
+		t.Error("Args must not modify its input")
+	}
+	if Args(nil) != nil {
+		t.Error("expected nil for nil args")
+	}
+}
+
+func TestString_MasksEmbeddedCredentials(t *testing.T) {
+	s := String("GET https://api.telegram.org/bot123:AAH-secret/getMe?key=abc123 Authorization: Bearer eyJhbGciOi")
+	for _, leaked := range []string{"AAH-secret", "abc123", "eyJhbGciOi"} {
+		if strings.Contains(s, leaked) {
+			t.Errorf("%q leaked: %s", leaked, s)
+		}
+	}
+}
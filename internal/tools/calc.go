@@ -0,0 +1,387 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	calcMaxExprChars = 1000 // longer expressions are rejected
+	calcMaxDepth     = 100  // nesting limit for parentheses and calls
+)
+
+// calcConstants are the named constants an expression may use.
+var calcConstants = map[string]float64{
+	"pi":  math.Pi,
+	"e":   math.E,
+	"tau": 2 * math.Pi,
+}
+
+// calcFunc is a math function with a fixed number of arguments; arity -1
+// accepts one or more.
+type calcFunc struct {
+	arity int
+	fn    func(args []float64) float64
+}
+
+func calcUnary(f func(float64) float64) calcFunc {
+	return calcFunc{1, func(a []float64) float64 { return f(a[0]) }}
+}
+
+func calcBinary(f func(float64, float64) float64) calcFunc {
+	return calcFunc{2, func(a []float64) float64 { return f(a[0], a[1]) }}
+}
+
+var calcFuncs = map[string]calcFunc{
+	"sqrt":  calcUnary(math.Sqrt),
+	"cbrt":  calcUnary(math.Cbrt),
+	"abs":   calcUnary(math.Abs),
+	"exp":   calcUnary(math.Exp),
+	"ln":    calcUnary(math.Log),
+	"log":   calcUnary(math.Log),
+	"log10": calcUnary(math.Log10),
+	"log2":  calcUnary(math.Log2),
+	"sin":   calcUnary(math.Sin),
+	"cos":   calcUnary(math.Cos),
+	"tan":   calcUnary(math.Tan),
+	"asin":  calcUnary(math.Asin),
+	"acos":  calcUnary(math.Acos),
+	"atan":  calcUnary(math.Atan),
+	"floor": calcUnary(math.Floor),
+	"ceil":  calcUnary(math.Ceil),
+	"round": calcUnary(math.Round),
+	"trunc": calcUnary(math.Trunc),
+	"pow":   calcBinary(math.Pow),
+	"atan2": calcBinary(math.Atan2),
+	"hypot": calcBinary(math.Hypot),
+	"mod":   calcBinary(math.Mod),
+	"min": {-1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {-1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+}
+
+// CalcTool evaluates arithmetic expressions in-process, so the agent does not
+// need the shell (or its own arithmetic) for maths.
+type CalcTool struct{}
+
+func NewCalcTool() *CalcTool { return &CalcTool{} }
+
+func (t *CalcTool) Name() string { return "calc" }
+func (t *CalcTool) Description() string {
+	return "Evaluate a mathematical expression and return the numeric result. Supports + - * / % ^ (or **), " +
+		"parentheses, constants pi, e, tau and functions sqrt, cbrt, abs, exp, ln, log (natural), log10, log2, " +
+		"sin, cos, tan, asin, acos, atan, atan2, floor, ceil, round, trunc, pow, hypot, mod, min, max. " +
+		"Use it for any arithmetic instead of computing by hand."
+}
+func (t *CalcTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"expression": {
+				"type": "string",
+				"description": "Expression to evaluate, e.g. (1.5 + 2) * sqrt(16) / 2^3"
+			}
+		},
+		"required": ["expression"]
+	}`)
+}
+
+func (t *CalcTool) Execute(_ context.Context, params map[string]any) (string, error) {
+	expr, _ := params["expression"].(string)
+	if strings.TrimSpace(expr) == "" {
+		return "Error: expression is required", nil
+	}
+	v, err := evalExpression(expr)
+	if err != nil {
+		return fmt.Sprintf("Error: %s", err), nil
+	}
+	return formatCalcResult(v), nil
+}
+
+// evalExpression parses and evaluates expr. Anything other than numbers,
+// operators, parentheses, commas and the known constants and functions is
+// rejected.
+func evalExpression(expr string) (float64, error) {
+	if len(expr) > calcMaxExprChars {
+		return 0, fmt.Errorf("expression longer than %d characters", calcMaxExprChars)
+	}
+	p := &calcParser{src: expr}
+	v, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.src[p.pos], p.pos+1)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return v, nil
+}
+
+// formatCalcResult prints integral values without an exponent and everything
+// else in the shortest form that round-trips.
+func formatCalcResult(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// calcParser is a recursive-descent parser that evaluates as it goes:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/" | "%") unary }
+//	unary   = ("+" | "-") unary | power
+//	power   = primary [ ("^" | "**") unary ]
+//	primary = number | name | name "(" expr { "," expr } ")" | "(" expr ")"
+type calcParser struct {
+	src   string
+	pos   int
+	depth int
+}
+
+func (p *calcParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte, or 0 at the end.
+func (p *calcParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *calcParser) parseExpr() (float64, error) {
+	v, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+':
+			p.pos++
+			r, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			v += r
+		case '-':
+			p.pos++
+			r, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			v -= r
+		default:
+			return v, nil
+		}
+	}
+}
+
+func (p *calcParser) parseTerm() (float64, error) {
+	v, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '/' && op != '%' && (op != '*' || strings.HasPrefix(p.src[p.pos:], "**")) {
+			return v, nil
+		}
+		p.pos++
+		r, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			v *= r
+		case '/':
+			if r == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			v /= r
+		case '%':
+			if r == 0 {
+				return 0, fmt.Errorf("modulo by zero")
+			}
+			v = math.Mod(v, r)
+		}
+	}
+}
+
+func (p *calcParser) parseUnary() (float64, error) {
+	switch p.peek() {
+	case '+', '-':
+		neg := p.src[p.pos] == '-'
+		p.pos++
+		if err := p.enter(); err != nil {
+			return 0, err
+		}
+		defer p.leave()
+		v, err := p.parseUnary()
+		if neg {
+			v = -v
+		}
+		return v, err
+	}
+	return p.parsePower()
+}
+
+func (p *calcParser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case p.peek() == '^':
+		p.pos++
+	case strings.HasPrefix(p.src[p.pos:], "**"):
+		p.pos += 2
+	default:
+		return base, nil
+	}
+	if err := p.enter(); err != nil {
+		return 0, err
+	}
+	defer p.leave()
+	exp, err := p.parseUnary() // right-associative: 2^3^2 = 2^9
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exp), nil
+}
+
+func (p *calcParser) parsePrimary() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return 0, fmt.Errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		if err := p.enter(); err != nil {
+			return 0, err
+		}
+		defer p.leave()
+		v, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return v, nil
+	case c >= '0' && c <= '9' || c == '.':
+		return p.parseNumber()
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_':
+		return p.parseName()
+	default:
+		return 0, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+	}
+}
+
+func (p *calcParser) parseNumber() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+		p.pos++
+	}
+	// Exponent, e.g. 1.5e-3. A bare "e" is not consumed, so "2e" is rejected
+	// rather than read as implicit multiplication.
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		end := p.pos + 1
+		if end < len(p.src) && (p.src[end] == '+' || p.src[end] == '-') {
+			end++
+		}
+		if end < len(p.src) && isDigit(p.src[end]) {
+			for end < len(p.src) && isDigit(p.src[end]) {
+				end++
+			}
+			p.pos = end
+		}
+	}
+	v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", p.src[start:p.pos])
+	}
+	return v, nil
+}
+
+func (p *calcParser) parseName() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.src) && (isLetter(p.src[p.pos]) || isDigit(p.src[p.pos]) || p.src[p.pos] == '_') {
+		p.pos++
+	}
+	name := strings.ToLower(p.src[start:p.pos])
+
+	if p.peek() != '(' {
+		if v, ok := calcConstants[name]; ok {
+			return v, nil
+		}
+		return 0, fmt.Errorf("unknown name %q", p.src[start:p.pos])
+	}
+	f, ok := calcFuncs[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown function %q", p.src[start:p.pos])
+	}
+	p.pos++ // (
+	if err := p.enter(); err != nil {
+		return 0, err
+	}
+	defer p.leave()
+
+	var args []float64
+	for {
+		v, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+		if c := p.peek(); c == ',' {
+			p.pos++
+			continue
+		} else if c == ')' {
+			p.pos++
+			break
+		}
+		return 0, fmt.Errorf("expected , or ) in call to %s", name)
+	}
+	if f.arity >= 0 && len(args) != f.arity {
+		return 0, fmt.Errorf("%s takes %d argument(s), got %d", name, f.arity, len(args))
+	}
+	return f.fn(args), nil
+}
+
+func (p *calcParser) enter() error {
+	p.depth++
+	if p.depth > calcMaxDepth {
+		return fmt.Errorf("expression nested too deeply")
+	}
+	return nil
+}
+
+func (p *calcParser) leave() { p.depth-- }
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
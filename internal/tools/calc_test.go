@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCalc_Evaluates(t *testing.T) {
+	cases := map[string]string{
+		"2 + 3 * 4":                "14",
+		"(2 + 3) * 4":              "20",
+		"10 - 4 - 3":               "3",
+		"2 ^ 3 ^ 2":                "512",
+		"2 ** 10":                  "1024",
+		"-2 ^ 2":                   "-4",
+		"7 % 3":                    "1",
+		"1 / 4":                    "0.25",
+		"1.5e3 + 1":                "1501",
+		"sqrt(16) + pow(2, 3)":     "12",
+		"log(e)":                   "1",
+		"log10(1000)":              "3",
+		"max(1, 7, 3) - min(4, 2)": "5",
+		"round(pi * 100) / 100":    "3.14",
+	}
+	tool := NewCalcTool()
+	for expr, want := range cases {
+		got, err := tool.Execute(context.Background(), map[string]any{"expression": expr})
+		if err != nil || got != want {
+			t.Errorf("calc(%q) = %q, %v; want %q", expr, got, err, want)
+		}
+	}
+}
+
+func TestCalc_RejectsNonMath(t *testing.T) {
+	cases := []string{
+		"",
+		"rm -rf /",
+		"os.Exit(1)",
+		"$(whoami)",
+		"2 +",
+		"(1 + 2",
+		"1 / 0",
+		"sqrt(-1)",
+		"pow(2)",
+		"foo(1)",
+		"1 2",
+		"2e",
+		strings.Repeat("(", 200) + "1" + strings.Repeat(")", 200),
+	}
+	tool := NewCalcTool()
+	for _, expr := range cases {
+		got, err := tool.Execute(context.Background(), map[string]any{"expression": expr})
+		if err != nil || !strings.HasPrefix(got, "Error:") {
+			t.Errorf("calc(%q) = %q, %v; want an Error: result", expr, got, err)
+		}
+	}
+}
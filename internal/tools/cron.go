@@ -4,8 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	robfigcron "github.com/robfig/cron/v3"
+
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 )
 
@@ -23,7 +29,8 @@ func NewCronTool(svc schema.CronService) *CronTool {
 func (t *CronTool) Name() string { return "cron" }
 
 func (t *CronTool) Description() string {
-	return "Schedule reminders and recurring tasks. Actions: add, list, remove."
+	return "Schedule reminders and recurring tasks. Actions: add, list, remove. " +
+		"For add, prefer time_of_day/days/interval over raw cron_expr, e.g. days=[\"weekdays\"], time_of_day=\"09:00\"."
 }
 
 func (t *CronTool) Parameters() json.RawMessage {
@@ -39,6 +46,19 @@ func (t *CronTool) Parameters() json.RawMessage {
 				"type": "string",
 				"description": "Reminder message (for add)"
 			},
+			"time_of_day": {
+				"type": "string",
+				"description": "Time of day as HH:MM (24h), e.g. '09:00'; runs daily unless days is set"
+			},
+			"days": {
+				"type": "array",
+				"items": {"type": "string"},
+				"description": "Days to run on with time_of_day: 'daily', 'weekdays', 'weekends', or day names like 'mon', 'friday'"
+			},
+			"interval": {
+				"type": "string",
+				"description": "Repeat interval like '30m', '2h', or '1d' (for recurring tasks)"
+			},
 			"every_seconds": {
 				"type": "integer",
 				"description": "Interval in seconds (for recurring tasks)"
@@ -49,7 +69,7 @@ func (t *CronTool) Parameters() json.RawMessage {
 			},
 			"tz": {
 				"type": "string",
-				"description": "IANA timezone for cron expressions (e.g. 'America/Vancouver')"
+				"description": "IANA timezone for time_of_day and cron_expr (e.g. 'America/Vancouver')"
 			},
 			"at": {
 				"type": "string",
@@ -89,34 +109,9 @@ func (t *CronTool) addJob(ctx context.Context, params map[string]any) string {
 		return "Error: no session context (channel/chat_id)"
 	}
 
-	var kind string
-	var everyMs, atMs int64
-	var cronExpr, tz string
-	deleteAfterRun := false
-
-	if v, ok := numericToInt64(params["every_seconds"]); ok && v > 0 {
-		kind = "every"
-		everyMs = v * 1000
-	} else if expr, ok := params["cron_expr"].(string); ok && expr != "" {
-		kind = "cron"
-		cronExpr = expr
-		if tzVal, ok := params["tz"].(string); ok {
-			tz = tzVal
-		}
-	} else if atStr, ok := params["at"].(string); ok && atStr != "" {
-		dt, err := time.Parse(time.RFC3339, atStr)
-		if err != nil {
-			// Try without timezone (local)
-			dt, err = time.ParseInLocation("2006-01-02T15:04:05", atStr, time.Local)
-			if err != nil {
-				return fmt.Sprintf("Error: invalid 'at' datetime %q: %v", atStr, err)
-			}
-		}
-		kind = "at"
-		atMs = dt.UnixMilli()
-		deleteAfterRun = true
-	} else {
-		return "Error: either every_seconds, cron_expr, or at is required"
+	sched, err := resolveSchedule(params, time.Now())
+	if err != nil {
+		return "Error: " + err.Error()
 	}
 
 	name := message
@@ -125,12 +120,13 @@ func (t *CronTool) addJob(ctx context.Context, params map[string]any) string {
 	}
 
 	id, err := t.svc.AddJob(
-		name, message, kind, everyMs, cronExpr, tz, atMs,
-		true, tc.Channel, tc.ChatID, deleteAfterRun)
+		name, message, sched.kind, sched.everyMs, sched.expr, sched.tz, sched.atMs,
+		true, tc.Channel, tc.ChatID, sched.kind == "at")
 	if err != nil {
 		return fmt.Sprintf("Error creating job: %v", err)
 	}
-	return fmt.Sprintf("Created job '%s' (id: %s)", name, id)
+	return fmt.Sprintf("Created job '%s' (id: %s)\nSchedule: %s\nNext run: %s",
+		name, id, sched.desc, sched.next.Format("Mon 2006-01-02 15:04 MST"))
 }
 
 func (t *CronTool) listJobs() string {
@@ -169,3 +165,203 @@ func numericToInt64(v any) (int64, bool) {
 	}
 	return 0, false
 }
+
+// cronSchedule is a validated schedule ready for CronService.AddJob, with a
+// human-readable description and its next run for echoing back.
+type cronSchedule struct {
+	kind    string // "every" | "cron" | "at"
+	everyMs int64
+	expr    string
+	tz      string
+	atMs    int64
+	desc    string
+	next    time.Time
+}
+
+// cronParser matches the 5-field expressions accepted by the cron service.
+var cronParser = robfigcron.NewParser(
+	robfigcron.Minute | robfigcron.Hour | robfigcron.Dom | robfigcron.Month | robfigcron.Dow,
+)
+
+// dayNumbers maps day names to cron day-of-week numbers.
+var dayNumbers = map[string]int{
+	"sunday": 0, "monday": 1, "tuesday": 2, "wednesday": 3,
+	"thursday": 4, "friday": 5, "saturday": 6,
+}
+
+// resolveSchedule turns add parameters into a cronSchedule. Structured
+// time_of_day/days are translated into a cron expression so the model does
+// not have to write one.
+func resolveSchedule(params map[string]any, now time.Time) (cronSchedule, error) {
+	tz, _ := params["tz"].(string)
+	loc := time.Local
+	if tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return cronSchedule{}, fmt.Errorf("unknown timezone %q", tz)
+		}
+		loc = l
+	}
+	timeOfDay, _ := params["time_of_day"].(string)
+	days := stringList(params["days"])
+	interval, _ := params["interval"].(string)
+
+	if v, ok := numericToInt64(params["every_seconds"]); ok && v > 0 {
+		return everySchedule(time.Duration(v)*time.Second, now), nil
+	}
+	if interval != "" {
+		d, err := parseInterval(interval)
+		if err != nil {
+			return cronSchedule{}, err
+		}
+		return everySchedule(d, now), nil
+	}
+	if timeOfDay != "" || len(days) > 0 {
+		if timeOfDay == "" {
+			return cronSchedule{}, fmt.Errorf("time_of_day is required with days")
+		}
+		tod, err := time.Parse("15:04", timeOfDay)
+		if err != nil {
+			return cronSchedule{}, fmt.Errorf("invalid time_of_day %q: use HH:MM (24h)", timeOfDay)
+		}
+		dow, dayDesc, err := parseDays(days)
+		if err != nil {
+			return cronSchedule{}, err
+		}
+		expr := fmt.Sprintf("%d %d * * %s", tod.Minute(), tod.Hour(), dow)
+		return cronExprSchedule(expr, tz, loc, fmt.Sprintf("%s at %s", dayDesc, tod.Format("15:04")), now)
+	}
+	if expr, _ := params["cron_expr"].(string); expr != "" {
+		return cronExprSchedule(expr, tz, loc, fmt.Sprintf("cron '%s'", expr), now)
+	}
+	if atStr, _ := params["at"].(string); atStr != "" {
+		dt, err := time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			// Try without timezone, in tz (or local)
+			dt, err = time.ParseInLocation("2006-01-02T15:04:05", atStr, loc)
+			if err != nil {
+				return cronSchedule{}, fmt.Errorf("invalid 'at' datetime %q: %v", atStr, err)
+			}
+		}
+		if !dt.After(now) {
+			return cronSchedule{}, fmt.Errorf("'at' datetime %q is in the past", atStr)
+		}
+		return cronSchedule{kind: "at", atMs: dt.UnixMilli(), desc: "once", next: dt}, nil
+	}
+	return cronSchedule{}, fmt.Errorf("one of time_of_day, interval, every_seconds, cron_expr, or at is required")
+}
+
+func everySchedule(d time.Duration, now time.Time) cronSchedule {
+	return cronSchedule{kind: "every", everyMs: d.Milliseconds(), desc: "every " + d.String(), next: now.Add(d)}
+}
+
+func cronExprSchedule(expr, tz string, loc *time.Location, desc string, now time.Time) (cronSchedule, error) {
+	parsed, err := cronParser.Parse(expr)
+	if err != nil {
+		return cronSchedule{}, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	desc = fmt.Sprintf("%s (cron '%s', %s)", desc, expr, loc)
+	return cronSchedule{kind: "cron", expr: expr, tz: tz, desc: desc, next: parsed.Next(now.In(loc))}, nil
+}
+
+// parseInterval accepts Go durations ("90m", "2h") plus whole days ("1d").
+func parseInterval(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		var days int
+		days, err = strconv.Atoi(n)
+		d = time.Duration(days) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d < time.Minute {
+		return 0, fmt.Errorf("invalid interval %q: use e.g. '30m', '2h', or '1d' (at least 1m)", s)
+	}
+	return d, nil
+}
+
+// parseDays converts day names and shortcuts into a cron day-of-week field
+// and a description. No days means every day.
+func parseDays(days []string) (string, string, error) {
+	if len(days) == 0 {
+		return "*", "every day", nil
+	}
+	seen := make(map[int]bool)
+	for _, raw := range days {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		switch name {
+		case "daily", "everyday", "every day":
+			return "*", "every day", nil
+		case "weekdays", "weekday":
+			for d := 1; d <= 5; d++ {
+				seen[d] = true
+			}
+			continue
+		case "weekends", "weekend":
+			seen[0], seen[6] = true, true
+			continue
+		}
+		n, ok := dayNumber(name)
+		if !ok {
+			return "", "", fmt.Errorf("unknown day %q", raw)
+		}
+		seen[n] = true
+	}
+
+	var nums []int
+	for n := range seen {
+		nums = append(nums, n)
+	}
+	sort.Ints(nums)
+	switch {
+	case len(nums) == 7:
+		return "*", "every day", nil
+	case slices.Equal(nums, []int{1, 2, 3, 4, 5}):
+		return "1-5", "weekdays", nil
+	case slices.Equal(nums, []int{0, 6}):
+		return "0,6", "weekends", nil
+	}
+	fields := make([]string, len(nums))
+	names := make([]string, len(nums))
+	for i, n := range nums {
+		fields[i] = strconv.Itoa(n)
+		names[i] = time.Weekday(n).String()[:3]
+	}
+	return strings.Join(fields, ","), strings.Join(names, ", "), nil
+}
+
+// dayNumber matches a full day name or a prefix of at least three letters.
+func dayNumber(name string) (int, bool) {
+	if len(name) < 3 {
+		return 0, false
+	}
+	for full, n := range dayNumbers {
+		if strings.HasPrefix(full, name) {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// stringList accepts a JSON array of strings or a comma-separated string.
+func stringList(v any) []string {
+	switch x := v.(type) {
+	case string:
+		if x == "" {
+			return nil
+		}
+		return strings.Split(x, ",")
+	case []any:
+		out := make([]string, 0, len(x))
+		for _, e := range x {
+			if s, ok := e.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return x
+	}
+	return nil
+}
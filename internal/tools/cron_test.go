@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// fakeCronService records the last AddJob call.
+type fakeCronService struct {
+	kind, expr, tz string
+	everyMs        int64
+}
+
+func (f *fakeCronService) AddJob(
+	name, message, kind string,
+	everyMs int64, cronExpr, tz string, atMs int64,
+	deliver bool, channel bus.Channel, to string, deleteAfterRun bool,
+) (string, error) {
+	f.kind, f.expr, f.tz, f.everyMs = kind, cronExpr, tz, everyMs
+	return "job1", nil
+}
+func (f *fakeCronService) ListJobs() []schema.CronJobSummary { return nil }
+func (f *fakeCronService) RemoveJob(id string) bool          { return false }
+
+func TestResolveSchedule_WeekdaysAtNine(t *testing.T) {
+	// Saturday noon UTC; the next weekday 09:00 is Monday.
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	sched, err := resolveSchedule(map[string]any{
+		"days":        []any{"weekdays"},
+		"time_of_day": "09:00",
+		"tz":          "UTC",
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sched.kind != "cron" || sched.expr != "0 9 * * 1-5" || sched.tz != "UTC" {
+		t.Errorf("unexpected schedule: %+v", sched)
+	}
+	if want := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC); !sched.next.Equal(want) {
+		t.Errorf("expected next run %s, got %s", want, sched.next)
+	}
+	if !strings.HasPrefix(sched.desc, "weekdays at 09:00") {
+		t.Errorf("unexpected description: %q", sched.desc)
+	}
+}
+
+func TestResolveSchedule_DaysAndIntervals(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		params map[string]any
+		expr   string
+		every  time.Duration
+	}{
+		{map[string]any{"time_of_day": "18:30", "tz": "UTC"}, "30 18 * * *", 0},
+		{map[string]any{"time_of_day": "07:05", "days": "mon, wed,Fri", "tz": "UTC"}, "5 7 * * 1,3,5", 0},
+		{map[string]any{"time_of_day": "10:00", "days": []any{"weekends"}, "tz": "UTC"}, "0 10 * * 0,6", 0},
+		{map[string]any{"interval": "2h"}, "", 2 * time.Hour},
+		{map[string]any{"interval": "1d"}, "", 24 * time.Hour},
+	}
+	for _, c := range cases {
+		sched, err := resolveSchedule(c.params, now)
+		if err != nil {
+			t.Errorf("%v: %v", c.params, err)
+			continue
+		}
+		if sched.expr != c.expr || time.Duration(sched.everyMs)*time.Millisecond != c.every {
+			t.Errorf("%v: unexpected schedule %+v", c.params, sched)
+		}
+	}
+
+	for _, bad := range []map[string]any{
+		{"days": []any{"weekdays"}},
+		{"time_of_day": "9am"},
+		{"time_of_day": "09:00", "days": []any{"someday"}},
+		{"time_of_day": "09:00", "tz": "Mars/Base"},
+		{"interval": "10s"},
+	} {
+		if _, err := resolveSchedule(bad, now); err == nil {
+			t.Errorf("%v: expected validation error", bad)
+		}
+	}
+}
+
+func TestCronTool_AddEchoesResolvedSchedule(t *testing.T) {
+	svc := &fakeCronService{}
+	tool := NewCronTool(svc)
+	ctx := WithTurn(context.Background(), TurnContext{Channel: bus.ChannelCLI, ChatID: "direct"})
+
+	out, _ := tool.Execute(ctx, map[string]any{
+		"action":      "add",
+		"message":     "Stand-up",
+		"days":        []any{"weekdays"},
+		"time_of_day": "09:00",
+		"tz":          "America/Vancouver",
+	})
+	if svc.kind != "cron" || svc.expr != "0 9 * * 1-5" || svc.tz != "America/Vancouver" {
+		t.Errorf("unexpected job: %+v", svc)
+	}
+	if !strings.Contains(out, "Schedule: weekdays at 09:00") || !strings.Contains(out, "Next run: ") {
+		t.Errorf("expected schedule echo, got %q", out)
+	}
+}
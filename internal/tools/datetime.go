@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// datetimeLayouts are accepted for timestamps without an explicit offset,
+// which are read in the operation's source timezone.
+var datetimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// DateTimeTool answers time questions in-process: the current time in a
+// timezone, conversion between zones, and the difference between two times.
+type DateTimeTool struct {
+	now func() time.Time
+}
+
+func NewDateTimeTool() *DateTimeTool { return &DateTimeTool{now: time.Now} }
+
+func (t *DateTimeTool) Name() string { return "datetime" }
+func (t *DateTimeTool) Description() string {
+	return "Timezone-aware date and time operations. action=now: current time in timezone. " +
+		"action=convert: express time (from timezone from) in timezone to. " +
+		"action=diff: elapsed time and calendar days from start to end. " +
+		"Timezones are IANA names (e.g. Asia/Tokyo, America/New_York) or UTC; " +
+		"times are ISO 8601, with or without an offset. Results are ISO 8601."
+}
+func (t *DateTimeTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["now", "convert", "diff"]
+			},
+			"timezone": {
+				"type": "string",
+				"description": "now/diff: IANA timezone (default UTC); for diff, used for times without an offset and for calendar days"
+			},
+			"time": {
+				"type": "string",
+				"description": "convert: time to convert, e.g. 2025-03-09T09:30 or 2025-03-09T09:30:00+02:00"
+			},
+			"from": {
+				"type": "string",
+				"description": "convert: timezone of time when it has no offset (default UTC)"
+			},
+			"to": {
+				"type": "string",
+				"description": "convert: target timezone"
+			},
+			"start": {
+				"type": "string",
+				"description": "diff: start time, or \"now\""
+			},
+			"end": {
+				"type": "string",
+				"description": "diff: end time, or \"now\""
+			}
+		},
+		"required": ["action"]
+	}`)
+}
+
+func (t *DateTimeTool) Execute(_ context.Context, params map[string]any) (string, error) {
+	action, _ := params["action"].(string)
+	var (
+		out string
+		err error
+	)
+	switch action {
+	case "now":
+		out, err = t.current(params)
+	case "convert":
+		out, err = t.convert(params)
+	case "diff":
+		out, err = t.diff(params)
+	default:
+		return fmt.Sprintf("Error: unknown action %q (want now, convert or diff)", action), nil
+	}
+	if err != nil {
+		return fmt.Sprintf("Error: %s", err), nil
+	}
+	return out, nil
+}
+
+func (t *DateTimeTool) current(params map[string]any) (string, error) {
+	loc, err := loadLocation(params["timezone"])
+	if err != nil {
+		return "", err
+	}
+	return describeTime(t.now().In(loc)), nil
+}
+
+func (t *DateTimeTool) convert(params map[string]any) (string, error) {
+	from, err := loadLocation(params["from"])
+	if err != nil {
+		return "", err
+	}
+	toName, _ := params["to"].(string)
+	if toName == "" {
+		return "", fmt.Errorf("to is required")
+	}
+	to, err := loadLocation(toName)
+	if err != nil {
+		return "", err
+	}
+	raw, _ := params["time"].(string)
+	ts, err := t.parseTime(raw, from)
+	if err != nil {
+		return "", err
+	}
+	return describeTime(ts.In(to)), nil
+}
+
+func (t *DateTimeTool) diff(params map[string]any) (string, error) {
+	loc, err := loadLocation(params["timezone"])
+	if err != nil {
+		return "", err
+	}
+	rawStart, _ := params["start"].(string)
+	rawEnd, _ := params["end"].(string)
+	start, err := t.parseTime(rawStart, loc)
+	if err != nil {
+		return "", fmt.Errorf("start: %w", err)
+	}
+	end, err := t.parseTime(rawEnd, loc)
+	if err != nil {
+		return "", fmt.Errorf("end: %w", err)
+	}
+
+	elapsed := end.Sub(start)
+	return fmt.Sprintf("start: %s\nend: %s\nelapsed: %s (%d seconds, %.2f hours)\ncalendar days: %d",
+		start.Format(time.RFC3339), end.Format(time.RFC3339),
+		elapsed, int64(elapsed.Seconds()), elapsed.Hours(), calendarDays(start.In(loc), end.In(loc))), nil
+}
+
+// parseTime reads raw as RFC 3339, "now", or one of datetimeLayouts in loc.
+func (t *DateTimeTool) parseTime(raw string, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "":
+		return time.Time{}, fmt.Errorf("time is required")
+	case strings.EqualFold(raw, "now"):
+		return t.now().In(loc), nil
+	}
+	if ts, err := time.Parse(time.RFC3339, raw); err == nil {
+		return ts, nil
+	}
+	for _, layout := range datetimeLayouts {
+		if ts, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse time %q; use ISO 8601 such as 2025-03-09T09:30 or 2025-03-09T09:30:00Z", raw)
+}
+
+// loadLocation resolves an IANA timezone name; empty means UTC.
+func loadLocation(v any) (*time.Location, error) {
+	name, _ := v.(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// describeTime formats ts as ISO 8601 with its zone name and weekday.
+func describeTime(ts time.Time) string {
+	zone, _ := ts.Zone()
+	return fmt.Sprintf("%s (%s, %s)", ts.Format(time.RFC3339), zone, ts.Weekday())
+}
+
+// calendarDays counts midnights crossed from start to end in their location,
+// so a DST day of 23 or 25 hours still counts as one day.
+func calendarDays(start, end time.Time) int {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := end.Date()
+	a := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	return int(math.Round(b.Sub(a).Hours() / 24))
+}
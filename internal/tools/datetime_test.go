@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func runDateTime(t *testing.T, tool *DateTimeTool, params map[string]any) string {
+	t.Helper()
+	out, err := tool.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestDateTime_Now(t *testing.T) {
+	tool := NewDateTimeTool()
+	tool.now = func() time.Time { return time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC) }
+
+	out := runDateTime(t, tool, map[string]any{"action": "now", "timezone": "Asia/Tokyo"})
+	if !strings.HasPrefix(out, "2025-01-15T21:00:00+09:00 (JST, Wednesday)") {
+		t.Errorf("unexpected now: %q", out)
+	}
+}
+
+func TestDateTime_ConvertAcrossDST(t *testing.T) {
+	tool := NewDateTimeTool()
+	cases := []struct {
+		time, from, to, want string
+	}{
+		// New York switches to EDT at 02:00 on 2025-03-09; London stays on GMT.
+		{"2025-03-08T09:00", "America/New_York", "Europe/London", "2025-03-08T14:00:00Z"},
+		{"2025-03-10T09:00", "America/New_York", "Europe/London", "2025-03-10T13:00:00Z"},
+		{"2025-03-10T09:00:00-04:00", "", "Asia/Tokyo", "2025-03-10T22:00:00+09:00"},
+	}
+	for _, c := range cases {
+		out := runDateTime(t, tool, map[string]any{"action": "convert", "time": c.time, "from": c.from, "to": c.to})
+		if !strings.HasPrefix(out, c.want) {
+			t.Errorf("convert %s %s→%s = %q, want prefix %q", c.time, c.from, c.to, out, c.want)
+		}
+	}
+}
+
+func TestDateTime_DiffAcrossDST(t *testing.T) {
+	tool := NewDateTimeTool()
+
+	// Noon to noon over the spring-forward night is two days but only 47 hours.
+	out := runDateTime(t, tool, map[string]any{
+		"action": "diff", "timezone": "America/New_York",
+		"start": "2025-03-08T12:00", "end": "2025-03-10T12:00",
+	})
+	if !strings.Contains(out, "elapsed: 47h0m0s (169200 seconds") || !strings.Contains(out, "calendar days: 2") {
+		t.Errorf("unexpected spring diff: %q", out)
+	}
+
+	// Over fall-back the same span is 49 hours.
+	out = runDateTime(t, tool, map[string]any{
+		"action": "diff", "timezone": "America/New_York",
+		"start": "2025-11-01T12:00", "end": "2025-11-03T12:00",
+	})
+	if !strings.Contains(out, "elapsed: 49h0m0s") || !strings.Contains(out, "calendar days: 2") {
+		t.Errorf("unexpected fall diff: %q", out)
+	}
+}
+
+func TestDateTime_Errors(t *testing.T) {
+	tool := NewDateTimeTool()
+	for _, params := range []map[string]any{
+		{"action": "now", "timezone": "Mars/Olympus"},
+		{"action": "convert", "time": "2025-01-01T00:00"},
+		{"action": "convert", "time": "next tuesday", "to": "UTC"},
+		{"action": "diff", "start": "2025-01-01"},
+		{"action": "sleep"},
+	} {
+		if out := runDateTime(t, tool, params); !strings.HasPrefix(out, "Error:") {
+			t.Errorf("%v: expected an error, got %q", params, out)
+		}
+	}
+}
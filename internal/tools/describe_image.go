@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+const (
+	describeImageMaxBytes  = 10 << 20 // larger images are refused
+	describeImageCacheSize = 128      // descriptions kept in memory
+	describeImageMaxTokens = 1024
+	describeImagePrompt    = "Describe this image in detail. Transcribe any visible text exactly."
+)
+
+// DescribeImageTool sends a local image to a vision-capable model and returns
+// its description, so the agent can reason about images it only has a path
+// for (e.g. channel media or a download_file result). Descriptions are cached
+// by image content and prompt.
+type DescribeImageTool struct {
+	workspace  string
+	allowedDir string
+	provider   schema.LLMProvider
+	model      string
+
+	mu    sync.Mutex
+	cache map[string]string
+	order []string // insertion order, for eviction
+}
+
+// NewDescribeImageTool creates a DescribeImageTool that calls provider with
+// model, which must accept image input.
+func NewDescribeImageTool(workspace, allowedDir string, provider schema.LLMProvider, model string) *DescribeImageTool {
+	return &DescribeImageTool{
+		workspace:  workspace,
+		allowedDir: allowedDir,
+		provider:   provider,
+		model:      model,
+		cache:      make(map[string]string),
+	}
+}
+
+func (t *DescribeImageTool) Name() string { return "describe_image" }
+func (t *DescribeImageTool) Description() string {
+	return "Look at a local image file (PNG, JPEG, GIF, WebP) with a vision model and return a text description. " +
+		"Pass prompt to ask a specific question about the image instead."
+}
+func (t *DescribeImageTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"path": {
+				"type": "string",
+				"description": "Path to the image file"
+			},
+			"prompt": {
+				"type": "string",
+				"description": "Question or instruction about the image (default: describe it in detail)"
+			}
+		},
+		"required": ["path"]
+	}`)
+}
+
+func (t *DescribeImageTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	path, _ := params["path"].(string)
+	if path == "" {
+		return "Error: path is required", nil
+	}
+	prompt, _ := params["prompt"].(string)
+	if strings.TrimSpace(prompt) == "" {
+		prompt = describeImagePrompt
+	}
+
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	resolved, err := resolvePath(path, workspace, allowedDir)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if info.IsDir() {
+		return fmt.Sprintf("Error: %s is a directory", path), nil
+	}
+	if info.Size() > describeImageMaxBytes {
+		return fmt.Sprintf("Error: image is %d bytes, over the %d byte limit", info.Size(), describeImageMaxBytes), nil
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	mimeType := imageMIMEType(resolved, data)
+	if mimeType == "" {
+		return fmt.Sprintf("Error: %s is not a supported image (PNG, JPEG, GIF, WebP)", path), nil
+	}
+
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:]) + "|" + prompt
+	if desc, ok := t.cached(key); ok {
+		return desc, nil
+	}
+
+	msgs := schema.NewMessages(schema.NewUserMessage([]map[string]any{
+		{
+			"type":      "image_url",
+			"image_url": map[string]any{"url": fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))},
+		},
+		{"type": "text", "text": prompt},
+	}))
+	resp, err := t.provider.Chat(ctx, msgs, nil, schema.NewChatOptions(t.model, describeImageMaxTokens, 0.2))
+	if err != nil {
+		return fmt.Sprintf("Error: vision model call failed: %v", err), nil
+	}
+	desc := ""
+	if resp.Content != nil {
+		desc = strings.TrimSpace(*resp.Content)
+	}
+	if resp.FinishReason == "error" {
+		return fmt.Sprintf("Error: vision model call failed: %s", desc), nil
+	}
+	if desc == "" {
+		return "Error: the model returned no description", nil
+	}
+	t.store(key, desc)
+	return desc, nil
+}
+
+func (t *DescribeImageTool) cached(key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	desc, ok := t.cache[key]
+	return desc, ok
+}
+
+// store caches desc, evicting the oldest entry once the cache is full.
+func (t *DescribeImageTool) store(key, desc string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.cache[key]; ok {
+		return
+	}
+	if len(t.order) >= describeImageCacheSize {
+		delete(t.cache, t.order[0])
+		t.order = t.order[1:]
+	}
+	t.cache[key] = desc
+	t.order = append(t.order, key)
+}
+
+// imageMIMEType returns the image MIME type of a file from its extension,
+// falling back to content sniffing, or "" if it is not a supported image.
+func imageMIMEType(path string, data []byte) string {
+	mimeType, _, _ := strings.Cut(mime.TypeByExtension(strings.ToLower(filepath.Ext(path))), ";")
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	switch mimeType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+		return mimeType
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// visionProvider is a stub multimodal provider that records the requests it
+// receives and answers with a fixed description.
+type visionProvider struct {
+	calls int
+	sent  []schema.Messages
+	opts  []schema.ChatOptions
+	reply schema.LLMResponse
+}
+
+func (p *visionProvider) Chat(_ context.Context, msgs schema.Messages, _ []map[string]any, opts schema.ChatOptions) (schema.LLMResponse, error) {
+	p.calls++
+	p.sent = append(p.sent, msgs)
+	p.opts = append(p.opts, opts)
+	return p.reply, nil
+}
+func (p *visionProvider) DefaultModel() string { return "vision-test" }
+
+// pngHeader is enough of a PNG for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newVisionProvider(reply string) *visionProvider {
+	return &visionProvider{reply: schema.LLMResponse{Content: &reply, FinishReason: "stop"}}
+}
+
+func TestDescribeImage_SendsImageAndCaches(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cat.png"), pngHeader, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := newVisionProvider("A cat on a sofa.")
+	tool := NewDescribeImageTool(dir, dir, p, "gpt-4o")
+
+	out, err := tool.Execute(context.Background(), map[string]any{"path": "cat.png"})
+	if err != nil || out != "A cat on a sofa." {
+		t.Fatalf("unexpected result %q, %v", out, err)
+	}
+	if p.opts[0].Model != "gpt-4o" {
+		t.Errorf("expected configured model, got %q", p.opts[0].Model)
+	}
+	blocks, ok := p.sent[0].Messages[0].Content.([]map[string]any)
+	if !ok || len(blocks) != 2 {
+		t.Fatalf("expected image and text blocks, got %#v", p.sent[0].Messages[0].Content)
+	}
+	url, _ := blocks[0]["image_url"].(map[string]any)["url"].(string)
+	if !strings.HasPrefix(url, "data:image/png;base64,") || blocks[1]["text"] != describeImagePrompt {
+		t.Errorf("unexpected blocks: %v / %v", url, blocks[1])
+	}
+
+	// Same content under another name is served from the cache.
+	if err := os.WriteFile(filepath.Join(dir, "copy.png"), pngHeader, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := tool.Execute(context.Background(), map[string]any{"path": "copy.png"}); out != "A cat on a sofa." || p.calls != 1 {
+		t.Errorf("expected cached description, got %q after %d calls", out, p.calls)
+	}
+	// A different prompt is a different question.
+	tool.Execute(context.Background(), map[string]any{"path": "cat.png", "prompt": "What colour is the cat?"})
+	if p.calls != 2 {
+		t.Errorf("expected a new call for a new prompt, got %d calls", p.calls)
+	}
+}
+
+func TestDescribeImage_Rejects(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("just text"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := newVisionProvider("unused")
+	tool := NewDescribeImageTool(dir, dir, p, "")
+
+	for _, path := range []string{"notes.txt", "missing.png", "../outside.png"} {
+		if out, _ := tool.Execute(context.Background(), map[string]any{"path": path}); !strings.HasPrefix(out, "Error:") {
+			t.Errorf("%s: expected an error, got %q", path, out)
+		}
+	}
+	if p.calls != 0 {
+		t.Errorf("expected no model calls, got %d", p.calls)
+	}
+}
+
+func TestDescribeImage_ModelError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.png"), pngHeader, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	msg := "HTTP 400: model does not support image input"
+	p := &visionProvider{reply: schema.LLMResponse{Content: &msg, FinishReason: "error"}}
+	tool := NewDescribeImageTool(dir, dir, p, "")
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "a.png"})
+	if !strings.Contains(out, "does not support image input") {
+		t.Errorf("expected the model error surfaced, got %q", out)
+	}
+	tool.Execute(context.Background(), map[string]any{"path": "a.png"})
+	if p.calls != 2 {
+		t.Errorf("expected errors not to be cached, got %d calls", p.calls)
+	}
+}
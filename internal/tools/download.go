@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+// DownloadTool saves a remote URL to a file, for binaries (images, datasets,
+// archives) that web_fetch would reduce to text. It shares web_fetch's URL
+// validation, private-address guard and redirect limit.
+type DownloadTool struct {
+	workspace  string
+	allowedDir string
+	maxBytes   int64
+	guard      *hostGuard
+	httpClient *http.Client
+}
+
+// NewDownloadTool creates a DownloadTool. MaxBytes defaults to 50 MiB and
+// Timeout to 120s.
+func NewDownloadTool(workspace, allowedDir string, cfg toolcfg.DownloadToolConfig) *DownloadTool {
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 50 << 20
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 120 * time.Second
+	}
+	guard := newHostGuard(cfg.AllowPrivateNetworks, cfg.AllowedHosts)
+	return &DownloadTool{
+		workspace:  workspace,
+		allowedDir: allowedDir,
+		maxBytes:   maxBytes,
+		guard:      guard,
+		httpClient: newGuardedClient(timeout, guard),
+	}
+}
+
+func (t *DownloadTool) Name() string { return "download_file" }
+func (t *DownloadTool) Description() string {
+	return fmt.Sprintf("Download a URL and save the raw response body to a file (max %d bytes), "+
+		"e.g. an image or dataset for later use. Parent directories are created; an existing file is overwritten. "+
+		"Returns the saved path and byte count.", t.maxBytes)
+}
+func (t *DownloadTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"url": {
+				"type": "string",
+				"description": "URL to download (http/https)"
+			},
+			"path": {
+				"type": "string",
+				"description": "Destination file path"
+			}
+		},
+		"required": ["url", "path"]
+	}`)
+}
+
+func (t *DownloadTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	rawURL, _ := params["url"].(string)
+	path, _ := params["path"].(string)
+	if rawURL == "" || path == "" {
+		return "Error: url and path are required", nil
+	}
+	if err := validateURL(rawURL); err != nil {
+		return fmt.Sprintf("Error: URL validation failed: %v", err), nil
+	}
+	parsed, _ := url.Parse(rawURL)
+	if err := t.guard.check(ctx, parsed); err != nil {
+		return fmt.Sprintf("Error: URL validation failed: %v", err), nil
+	}
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	dest, err := resolvePath(path, workspace, allowedDir)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		return fmt.Sprintf("Error: %s is a directory", path), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	req.Header.Set("User-Agent", webUserAgent)
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Sprintf("Error: HTTP %d", resp.StatusCode), nil
+	}
+	if resp.ContentLength > t.maxBytes {
+		return fmt.Sprintf("Error: download is %d bytes, over the %d byte limit", resp.ContentLength, t.maxBytes), nil
+	}
+
+	n, err := t.save(dest, resp.Body)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	out := fmt.Sprintf("Saved %d bytes to %s", n, dest)
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		out += fmt.Sprintf(" (%s)", ct)
+	}
+	return out, nil
+}
+
+// save streams body to a temporary file beside dest and renames it into
+// place, so an aborted or oversized download never leaves a partial file.
+func (t *DownloadTool) save(dest string, body io.Reader) (int64, error) {
+	dir := filepath.Dir(dest)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	// Copy one byte past the cap to detect an oversized body.
+	n, err := io.Copy(tmp, io.LimitReader(body, t.maxBytes+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("download failed: %w", err)
+	}
+	if n > t.maxBytes {
+		return 0, fmt.Errorf("download exceeds the %d byte limit; aborted", t.maxBytes)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
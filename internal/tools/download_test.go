@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+func TestDownload_SavesFile(t *testing.T) {
+	payload := bytes.Repeat([]byte{0x89, 'P', 'N', 'G', 0x00}, 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/logo.png", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	tool := NewDownloadTool(dir, dir, toolcfg.DownloadToolConfig{AllowPrivateNetworks: true})
+	out, err := tool.Execute(context.Background(), map[string]any{"url": srv.URL + "/old", "path": "assets/logo.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "assets", "logo.png")
+	if !strings.Contains(out, "Saved 500 bytes to") || !strings.Contains(out, "image/png") {
+		t.Errorf("unexpected result %q", out)
+	}
+	if data, err := os.ReadFile(dest); err != nil || !bytes.Equal(data, payload) {
+		t.Errorf("saved file mismatch: %d bytes, err %v", len(data), err)
+	}
+}
+
+func TestDownload_SizeCapAborts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Stream without Content-Length so the cap is enforced while copying.
+		w.(http.Flusher).Flush()
+		w.Write(bytes.Repeat([]byte("x"), 4096))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	tool := NewDownloadTool(dir, dir, toolcfg.DownloadToolConfig{AllowPrivateNetworks: true, MaxBytes: 1024})
+	out, _ := tool.Execute(context.Background(), map[string]any{"url": srv.URL, "path": "big.bin"})
+	if !strings.HasPrefix(out, "Error:") || !strings.Contains(out, "1024 byte limit") {
+		t.Errorf("expected size-cap error, got %q", out)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no partial files left, found %v", entries)
+	}
+}
+
+func TestDownload_Guards(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	guarded := NewDownloadTool(dir, dir, toolcfg.DownloadToolConfig{})
+	if out, _ := guarded.Execute(context.Background(), map[string]any{"url": srv.URL, "path": "x"}); !strings.Contains(out, "blocked address") {
+		t.Errorf("expected loopback download to be blocked, got %q", out)
+	}
+
+	open := NewDownloadTool(dir, dir, toolcfg.DownloadToolConfig{AllowPrivateNetworks: true})
+	if out, _ := open.Execute(context.Background(), map[string]any{"url": srv.URL, "path": "../escape"}); !strings.Contains(out, "outside allowed directory") {
+		t.Errorf("expected path outside allowedDir to be rejected, got %q", out)
+	}
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// EditMemoryTool lets the agent correct long-term memory (MEMORY.md) in
+// place. It never overwrites the whole file: edits must name the exact
+// text they replace, and new facts are appended.
+type EditMemoryTool struct {
+	store schema.MemoryStore
+}
+
+// NewEditMemoryTool creates an EditMemoryTool backed by the given MemoryStore.
+func NewEditMemoryTool(store schema.MemoryStore) *EditMemoryTool {
+	return &EditMemoryTool{store: store}
+}
+
+func (t *EditMemoryTool) Name() string { return string(ToolEditMemory) }
+func (t *EditMemoryTool) Description() string {
+	return "Read or correct long-term memory (MEMORY.md). action \"read\" returns it; " +
+		"\"replace\" swaps old_text (which must exist exactly and be unique, unless replace_all) for new_text; " +
+		"\"append\" adds fact at the end. Use this when the user corrects or adds a remembered fact."
+}
+
+func (t *EditMemoryTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {"type": "string", "enum": ["read", "replace", "append"], "description": "What to do"},
+			"old_text": {"type": "string", "description": "For replace: the exact text to change"},
+			"new_text": {"type": "string", "description": "For replace: the text to put in its place (empty deletes it)"},
+			"replace_all": {"type": "boolean", "description": "For replace: change every occurrence of old_text (default false)"},
+			"fact": {"type": "string", "description": "For append: the fact to add"}
+		},
+		"required": ["action"]
+	}`)
+}
+
+func (t *EditMemoryTool) Execute(_ context.Context, params map[string]any) (string, error) {
+	action, _ := params["action"].(string)
+	current := t.store.ReadLongTerm()
+
+	switch action {
+	case "read":
+		if strings.TrimSpace(current) == "" {
+			return "MEMORY.md is empty.", nil
+		}
+		return current, nil
+
+	case "replace":
+		oldText, _ := params["old_text"].(string)
+		newText, _ := params["new_text"].(string)
+		replaceAll, _ := params["replace_all"].(bool)
+		if oldText == "" {
+			return "Error: old_text is required for replace; use action \"read\" to see the current memory", nil
+		}
+		updated, count, problem := replaceText(current, oldText, newText, "MEMORY.md", replaceAll)
+		if problem != "" {
+			return problem, nil
+		}
+		if err := t.store.WriteLongTerm(updated); err != nil {
+			return fmt.Sprintf("Error writing memory: %s", err), nil
+		}
+		if count > 1 {
+			return fmt.Sprintf("Updated MEMORY.md (%d replacements)", count), nil
+		}
+		return "Updated MEMORY.md", nil
+
+	case "append":
+		fact, _ := params["fact"].(string)
+		fact = strings.TrimSpace(fact)
+		if fact == "" {
+			return "Error: fact is required for append", nil
+		}
+		updated := strings.TrimRight(current, "\n")
+		if updated != "" {
+			updated += "\n"
+		}
+		if err := t.store.WriteLongTerm(updated + fact + "\n"); err != nil {
+			return fmt.Sprintf("Error writing memory: %s", err), nil
+		}
+		return "Added to MEMORY.md", nil
+	}
+	return fmt.Sprintf("Error: unknown action %q (want read, replace or append)", action), nil
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// longTermMemory keeps MEMORY.md in a string.
+type longTermMemory struct {
+	schema.MemoryStore
+	content string
+	writes  int
+}
+
+func (m *longTermMemory) ReadLongTerm() string { return m.content }
+func (m *longTermMemory) WriteLongTerm(content string) error {
+	m.content = content
+	m.writes++
+	return nil
+}
+
+func TestEditMemoryTool_Replace(t *testing.T) {
+	mem := &longTermMemory{content: "# Facts\n- Lives in Berlin\n- Likes tea\n"}
+	tool := NewEditMemoryTool(mem)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"action": "replace", "old_text": "Lives in Berlin", "new_text": "Lives in Munich"})
+	if out != "Updated MEMORY.md" || mem.content != "# Facts\n- Lives in Munich\n- Likes tea\n" {
+		t.Errorf("unexpected edit: %q → %q", out, mem.content)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"action": "append", "fact": "- Has a cat"})
+	if out != "Added to MEMORY.md" || !strings.HasSuffix(mem.content, "- Likes tea\n- Has a cat\n") {
+		t.Errorf("unexpected append: %q → %q", out, mem.content)
+	}
+}
+
+func TestEditMemoryTool_NotFound(t *testing.T) {
+	mem := &longTermMemory{content: "# Facts\n- Lives in Berlin\n"}
+	tool := NewEditMemoryTool(mem)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"action": "replace", "old_text": "Lives in Paris", "new_text": "x"})
+	if !strings.HasPrefix(out, "Error: old_text not found in MEMORY.md") {
+		t.Errorf("expected not-found error, got %q", out)
+	}
+	out, _ = tool.Execute(context.Background(), map[string]any{"action": "replace", "new_text": "everything"})
+	if !strings.HasPrefix(out, "Error: old_text is required") {
+		t.Errorf("expected a full overwrite to be refused, got %q", out)
+	}
+	if mem.writes != 0 {
+		t.Errorf("expected memory untouched, got %d writes", mem.writes)
+	}
+}
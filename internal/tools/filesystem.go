@@ -1,13 +1,18 @@
 package tools
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"syscall"
 )
 
 // resolvePath resolves a file path against workspace (if relative) and enforces
@@ -18,20 +23,94 @@ func resolvePath(path, workspace, allowedDir string) (string, error) {
 	if !filepath.IsAbs(p) && workspace != "" {
 		p = filepath.Join(workspace, p)
 	}
-	resolved, err := filepath.EvalSymlinks(p)
+	resolved, err := evalPath(filepath.Clean(p), 0)
 	if err != nil {
-		// Path may not exist yet (for writes) — use Clean instead
-		resolved = filepath.Clean(p)
+		return "", fmt.Errorf("cannot resolve path %s: %w", path, err)
 	}
 	if allowedDir != "" {
-		allowedResolved := filepath.Clean(allowedDir)
-		if !strings.HasPrefix(resolved, allowedResolved) {
+		allowed, err := evalPath(filepath.Clean(allowedDir), 0)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve allowed directory %s: %w", allowedDir, err)
+		}
+		if !withinDir(resolved, allowed) {
 			return "", fmt.Errorf("path %s is outside allowed directory %s", path, allowedDir)
 		}
 	}
 	return resolved, nil
 }
 
+// resolveEntry is resolvePath for tools that act on a directory entry itself
+// (delete, move): symlinks in the parent are resolved, but a final symlink is
+// not followed, so the link is removed or moved rather than its target. It
+// also returns the resolved allowedDir ("" when unrestricted) so callers can
+// refuse to act on the allowed directory itself.
+func resolveEntry(path, workspace, allowedDir string) (entry, allowed string, err error) {
+	p := path
+	if !filepath.IsAbs(p) && workspace != "" {
+		p = filepath.Join(workspace, p)
+	}
+	p = filepath.Clean(p)
+	parent, err := evalPath(filepath.Dir(p), 0)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot resolve path %s: %w", path, err)
+	}
+	entry = filepath.Join(parent, filepath.Base(p))
+	if allowedDir != "" {
+		allowed, err = evalPath(filepath.Clean(allowedDir), 0)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot resolve allowed directory %s: %w", allowedDir, err)
+		}
+		if !withinDir(entry, allowed) {
+			return "", "", fmt.Errorf("path %s is outside allowed directory %s", path, allowedDir)
+		}
+	}
+	return entry, allowed, nil
+}
+
+// maxSymlinkHops bounds symlink chains followed by evalPath.
+const maxSymlinkHops = 40
+
+// evalPath resolves every symlink in p, including paths that do not exist yet
+// (for writes): the deepest existing ancestor is resolved and the missing
+// components appended. A dangling symlink resolves to its target, so writing
+// through it cannot escape the allowed directory unnoticed.
+func evalPath(p string, hops int) (string, error) {
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		return resolved, nil
+	}
+	if info, err := os.Lstat(p); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if hops >= maxSymlinkHops {
+			return "", errors.New("too many levels of symbolic links")
+		}
+		target, err := os.Readlink(p)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(p), target)
+		}
+		return evalPath(filepath.Clean(target), hops+1)
+	}
+	parent := filepath.Dir(p)
+	if parent == p {
+		return p, nil
+	}
+	resolvedParent, err := evalPath(parent, hops)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(p)), nil
+}
+
+// withinDir reports whether path is dir itself or lies beneath it. Both must
+// be clean; a sibling such as "/ws-other" is not within "/ws".
+func withinDir(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
 // ---------------------------------------------------------------------------
 // ReadFileTool
 // ---------------------------------------------------------------------------
@@ -40,14 +119,21 @@ func resolvePath(path, workspace, allowedDir string) (string, error) {
 type ReadFileTool struct {
 	workspace  string
 	allowedDir string
+	maxBytes   int
 }
 
-func NewReadFileTool(workspace, allowedDir string) *ReadFileTool {
-	return &ReadFileTool{workspace: workspace, allowedDir: allowedDir}
+func NewReadFileTool(workspace, allowedDir string, maxBytes int) *ReadFileTool {
+	if maxBytes <= 0 {
+		maxBytes = 2 << 20
+	}
+	return &ReadFileTool{workspace: workspace, allowedDir: allowedDir, maxBytes: maxBytes}
 }
 
-func (t *ReadFileTool) Name() string        { return "read_file" }
-func (t *ReadFileTool) Description() string { return "Read the contents of a file at the given path." }
+func (t *ReadFileTool) Name() string { return "read_file" }
+func (t *ReadFileTool) Description() string {
+	return "Read the contents of a file at the given path. Use start_line/end_line to read only part of a large file. " +
+		"Binary files are not shown and very large files are truncated."
+}
 func (t *ReadFileTool) Parameters() json.RawMessage {
 	return json.RawMessage(`{
 		"type": "object",
@@ -55,18 +141,27 @@ func (t *ReadFileTool) Parameters() json.RawMessage {
 			"path": {
 				"type": "string",
 				"description": "The file path to read"
+			},
+			"start_line": {
+				"type": "integer",
+				"description": "First line to return (1-based, inclusive; default 1)"
+			},
+			"end_line": {
+				"type": "integer",
+				"description": "Last line to return (1-based, inclusive; default last line)"
 			}
 		},
 		"required": ["path"]
 	}`)
 }
 
-func (t *ReadFileTool) Execute(_ context.Context, params map[string]any) (string, error) {
+func (t *ReadFileTool) Execute(ctx context.Context, params map[string]any) (string, error) {
 	path, _ := params["path"].(string)
 	if path == "" {
 		return "Error: path is required", nil
 	}
-	fp, err := resolvePath(path, t.workspace, t.allowedDir)
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	fp, err := resolvePath(path, workspace, allowedDir)
 	if err != nil {
 		return "Error: " + err.Error(), nil
 	}
@@ -77,11 +172,126 @@ func (t *ReadFileTool) Execute(_ context.Context, params map[string]any) (string
 	if !info.Mode().IsRegular() {
 		return fmt.Sprintf("Error: Not a file: %s", path), nil
 	}
-	data, err := os.ReadFile(fp)
+	f, err := os.Open(fp)
 	if err != nil {
 		return fmt.Sprintf("Error reading file: %s", err), nil
 	}
-	return string(data), nil
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	if head, _ := br.Peek(grepSniffBytes); bytes.IndexByte(head, 0) >= 0 {
+		return fmt.Sprintf("Binary file not shown: %s (%d bytes)", path, info.Size()), nil
+	}
+	start, hasStart := intParam(params, "start_line")
+	end, hasEnd := intParam(params, "end_line")
+	hasRange := hasStart || hasEnd
+
+	if info.Size() > int64(t.maxBytes) {
+		if hasRange {
+			out, err := readLineRange(br, start, end, hasEnd, t.maxBytes)
+			if err != nil {
+				return fmt.Sprintf("Error reading file: %s", err), nil
+			}
+			return out, nil
+		}
+		return readHead(br, path, info.Size(), t.maxBytes), nil
+	}
+
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return fmt.Sprintf("Error reading file: %s", err), nil
+	}
+	if !hasRange {
+		return string(data), nil
+	}
+	return sliceLines(string(data), start, end, hasEnd), nil
+}
+
+// readHead returns the first maxBytes of an oversized file, cut at a line
+// boundary where possible, followed by a note on how to read the rest.
+func readHead(r io.Reader, path string, size int64, maxBytes int) string {
+	buf := make([]byte, maxBytes)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Sprintf("Error reading file: %s", err)
+	}
+	buf = buf[:n]
+	if i := bytes.LastIndexByte(buf, '\n'); i > 0 {
+		buf = buf[:i+1]
+	}
+	return fmt.Sprintf("%s\n[Truncated: %s is %d bytes; showing the first %d. "+
+		"Use start_line/end_line or grep to read other parts.]", buf, path, size, len(buf))
+}
+
+// readLineRange streams r and returns lines start..end under the same header
+// as sliceLines, without loading the whole file. Output stops at maxBytes.
+func readLineRange(r io.Reader, start, end int, hasEnd bool, maxBytes int) (string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxBytes)
+	start = max(1, start)
+
+	var out strings.Builder
+	total, first, last := 0, 0, 0
+	truncated := false
+	for sc.Scan() {
+		total++
+		if total < start || (hasEnd && total > end) || truncated {
+			continue
+		}
+		line := sc.Text()
+		if out.Len()+len(line)+1 > maxBytes {
+			truncated = true
+			continue
+		}
+		if first == 0 {
+			first = total
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+		last = total
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	if first == 0 {
+		return fmt.Sprintf("[No lines in range; file has %d lines]\n", total), nil
+	}
+	res := fmt.Sprintf("[Lines %d-%d of %d]\n", first, last, total) + out.String()
+	if truncated {
+		res += fmt.Sprintf("[Truncated at %d bytes; request a smaller range to see more]", maxBytes)
+	}
+	return res, nil
+}
+
+// sliceLines returns lines start..end (1-based, inclusive) of content under a
+// header giving the range and total line count. The range is clamped to the
+// file; without an end, it runs to the last line.
+func sliceLines(content string, start, end int, hasEnd bool) string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	total := len(lines)
+	if total == 0 {
+		return "[Lines 0-0 of 0]\n"
+	}
+	if !hasEnd || end > total {
+		end = total
+	}
+	start = max(1, min(start, total))
+	end = max(start, end)
+	return fmt.Sprintf("[Lines %d-%d of %d]\n", start, end, total) + strings.Join(lines[start-1:end], "")
+}
+
+// intParam reads an integer tool argument, which arrives as float64 from JSON.
+func intParam(params map[string]any, key string) (int, bool) {
+	switch v := params[key].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	}
+	return 0, false
 }
 
 // ---------------------------------------------------------------------------
@@ -119,13 +329,14 @@ func (t *WriteFileTool) Parameters() json.RawMessage {
 	}`)
 }
 
-func (t *WriteFileTool) Execute(_ context.Context, params map[string]any) (string, error) {
+func (t *WriteFileTool) Execute(ctx context.Context, params map[string]any) (string, error) {
 	path, _ := params["path"].(string)
 	content, _ := params["content"].(string)
 	if path == "" {
 		return "Error: path is required", nil
 	}
-	fp, err := resolvePath(path, t.workspace, t.allowedDir)
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	fp, err := resolvePath(path, workspace, allowedDir)
 	if err != nil {
 		return "Error: " + err.Error(), nil
 	}
@@ -138,11 +349,76 @@ func (t *WriteFileTool) Execute(_ context.Context, params map[string]any) (strin
 	return fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), fp), nil
 }
 
+// ---------------------------------------------------------------------------
+// AppendFileTool
+// ---------------------------------------------------------------------------
+
+// AppendFileTool appends content to a file, creating it and its parent
+// directories as needed.
+type AppendFileTool struct {
+	workspace  string
+	allowedDir string
+}
+
+func NewAppendFileTool(workspace, allowedDir string) *AppendFileTool {
+	return &AppendFileTool{workspace: workspace, allowedDir: allowedDir}
+}
+
+func (t *AppendFileTool) Name() string { return "append_file" }
+func (t *AppendFileTool) Description() string {
+	return "Append content to the end of a file at the given path. Creates the file and parent directories if needed."
+}
+func (t *AppendFileTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"path": {
+				"type": "string",
+				"description": "The file path to append to"
+			},
+			"content": {
+				"type": "string",
+				"description": "The content to append"
+			}
+		},
+		"required": ["path", "content"]
+	}`)
+}
+
+func (t *AppendFileTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	path, _ := params["path"].(string)
+	content, _ := params["content"].(string)
+	if path == "" {
+		return "Error: path is required", nil
+	}
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	fp, err := resolvePath(path, workspace, allowedDir)
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+		return fmt.Sprintf("Error creating directories: %s", err), nil
+	}
+	f, err := os.OpenFile(fp, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Sprintf("Error opening file: %s", err), nil
+	}
+	n, err := f.WriteString(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Sprintf("Error appending to file: %s", err), nil
+	}
+	return fmt.Sprintf("Successfully appended %d bytes to %s", n, fp), nil
+}
+
 // ---------------------------------------------------------------------------
 // EditFileTool
 // ---------------------------------------------------------------------------
 
-// EditFileTool replaces old_text with new_text in a file (first occurrence).
+// EditFileTool replaces old_text with new_text in a file. old_text must be
+// unique unless replace_all is set.
 type EditFileTool struct {
 	workspace  string
 	allowedDir string
@@ -154,7 +430,8 @@ func NewEditFileTool(workspace, allowedDir string) *EditFileTool {
 
 func (t *EditFileTool) Name() string { return "edit_file" }
 func (t *EditFileTool) Description() string {
-	return "Edit a file by replacing old_text with new_text. The old_text must exist exactly in the file."
+	return "Edit a file by replacing old_text with new_text. The old_text must exist exactly in the file " +
+		"and be unique, unless replace_all is true."
 }
 func (t *EditFileTool) Parameters() json.RawMessage {
 	return json.RawMessage(`{
@@ -171,21 +448,30 @@ func (t *EditFileTool) Parameters() json.RawMessage {
 			"new_text": {
 				"type": "string",
 				"description": "The text to replace with"
+			},
+			"replace_all": {
+				"type": "boolean",
+				"description": "Replace every occurrence of old_text instead of requiring it to be unique (default false)"
 			}
 		},
 		"required": ["path", "old_text", "new_text"]
 	}`)
 }
 
-func (t *EditFileTool) Execute(_ context.Context, params map[string]any) (string, error) {
+func (t *EditFileTool) Execute(ctx context.Context, params map[string]any) (string, error) {
 	path, _ := params["path"].(string)
 	oldText, _ := params["old_text"].(string)
 	newText, _ := params["new_text"].(string)
+	replaceAll, _ := params["replace_all"].(bool)
 	if path == "" {
 		return "Error: path is required", nil
 	}
+	if oldText == "" {
+		return "Error: old_text is required", nil
+	}
 
-	fp, err := resolvePath(path, t.workspace, t.allowedDir)
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	fp, err := resolvePath(path, workspace, allowedDir)
 	if err != nil {
 		return "Error: " + err.Error(), nil
 	}
@@ -193,23 +479,34 @@ func (t *EditFileTool) Execute(_ context.Context, params map[string]any) (string
 	if err != nil {
 		return fmt.Sprintf("Error: File not found: %s", path), nil
 	}
-	content := string(data)
-
-	if !strings.Contains(content, oldText) {
-		return editNotFoundMessage(oldText, content, path), nil
-	}
-	count := strings.Count(content, oldText)
-	if count > 1 {
-		return fmt.Sprintf("Warning: old_text appears %d times. Please provide more context to make it unique.", count), nil
+	newContent, count, problem := replaceText(string(data), oldText, newText, path, replaceAll)
+	if problem != "" {
+		return problem, nil
 	}
-
-	newContent := strings.Replace(content, oldText, newText, 1)
 	if err := os.WriteFile(fp, []byte(newContent), 0o644); err != nil {
 		return fmt.Sprintf("Error writing file: %s", err), nil
 	}
+	if replaceAll {
+		return fmt.Sprintf("Successfully edited %s (%d replacements)", fp, count), nil
+	}
 	return fmt.Sprintf("Successfully edited %s", fp), nil
 }
 
+// replaceText replaces oldText with newText in content. oldText must occur
+// exactly once unless replaceAll is set; otherwise problem is the message to
+// return to the model, naming path.
+func replaceText(content, oldText, newText, path string, replaceAll bool) (result string, count int, problem string) {
+	if !strings.Contains(content, oldText) {
+		return "", 0, editNotFoundMessage(oldText, content, path)
+	}
+	count = strings.Count(content, oldText)
+	if count > 1 && !replaceAll {
+		return "", count, fmt.Sprintf("Warning: old_text appears %d times. Please provide more context to make it unique, "+
+			"or set replace_all to replace every occurrence.", count)
+	}
+	return strings.ReplaceAll(content, oldText, newText), count, ""
+}
+
 // editNotFoundMessage builds a helpful diff hint when old_text is not found.
 // Mirrors Python's EditFileTool._not_found_message() using a sliding window.
 func editNotFoundMessage(oldText, content, path string) string {
@@ -311,12 +608,13 @@ func (t *ListDirTool) Parameters() json.RawMessage {
 	}`)
 }
 
-func (t *ListDirTool) Execute(_ context.Context, params map[string]any) (string, error) {
+func (t *ListDirTool) Execute(ctx context.Context, params map[string]any) (string, error) {
 	path, _ := params["path"].(string)
 	if path == "" {
 		return "Error: path is required", nil
 	}
-	dp, err := resolvePath(path, t.workspace, t.allowedDir)
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	dp, err := resolvePath(path, workspace, allowedDir)
 	if err != nil {
 		return "Error: " + err.Error(), nil
 	}
@@ -349,3 +647,359 @@ func (t *ListDirTool) Execute(_ context.Context, params map[string]any) (string,
 	}
 	return strings.Join(lines, "\n"), nil
 }
+
+// ---------------------------------------------------------------------------
+// TreeTool
+// ---------------------------------------------------------------------------
+
+const (
+	defaultTreeDepth   = 3
+	defaultTreeEntries = 200
+)
+
+// TreeTool renders a directory as an indented tree, a multi-level list_dir.
+type TreeTool struct {
+	workspace  string
+	allowedDir string
+}
+
+func NewTreeTool(workspace, allowedDir string) *TreeTool {
+	return &TreeTool{workspace: workspace, allowedDir: allowedDir}
+}
+
+func (t *TreeTool) Name() string { return "tree" }
+func (t *TreeTool) Description() string {
+	return "Show a directory tree with [D]/[F] markers, recursing up to max_depth levels. " +
+		"Hidden entries and .git are skipped unless show_hidden is true (.git is always skipped)."
+}
+func (t *TreeTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"path": {
+				"type": "string",
+				"description": "The directory to show (default: the workspace)"
+			},
+			"max_depth": {
+				"type": "integer",
+				"description": "How many levels to descend (default 3)"
+			},
+			"max_entries": {
+				"type": "integer",
+				"description": "Stop after this many entries (default 200)"
+			},
+			"show_hidden": {
+				"type": "boolean",
+				"description": "Include entries whose names start with a dot"
+			}
+		}
+	}`)
+}
+
+func (t *TreeTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	path, _ := params["path"].(string)
+	showHidden, _ := params["show_hidden"].(bool)
+	if path == "" {
+		path = "."
+	}
+	maxDepth, ok := intParam(params, "max_depth")
+	if !ok || maxDepth <= 0 {
+		maxDepth = defaultTreeDepth
+	}
+	maxEntries, ok := intParam(params, "max_entries")
+	if !ok || maxEntries <= 0 {
+		maxEntries = defaultTreeEntries
+	}
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	dp, err := resolvePath(path, workspace, allowedDir)
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+	info, err := os.Stat(dp)
+	if err != nil {
+		return fmt.Sprintf("Error: Directory not found: %s", path), nil
+	}
+	if !info.IsDir() {
+		return fmt.Sprintf("Error: Not a directory: %s", path), nil
+	}
+
+	w := treeWriter{maxDepth: maxDepth, maxEntries: maxEntries, showHidden: showHidden}
+	w.lines = append(w.lines, strings.TrimSuffix(path, "/")+"/")
+	w.walk(dp, 1)
+	if w.truncated {
+		w.lines = append(w.lines, fmt.Sprintf("[truncated: stopped after %d entries]", maxEntries))
+	}
+	return strings.Join(w.lines, "\n"), nil
+}
+
+// treeWriter accumulates TreeTool output lines.
+type treeWriter struct {
+	maxDepth, maxEntries int
+	showHidden           bool
+
+	lines     []string
+	entries   int
+	truncated bool
+}
+
+func (w *treeWriter) walk(dir string, depth int) {
+	entries, err := os.ReadDir(dir) // sorted by name
+	if err != nil {
+		w.lines = append(w.lines, strings.Repeat("  ", depth)+"[error: "+err.Error()+"]")
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if name == ".git" || (!w.showHidden && strings.HasPrefix(name, ".")) {
+			continue
+		}
+		if w.entries >= w.maxEntries {
+			w.truncated = true
+			return
+		}
+		w.entries++
+		indent := strings.Repeat("  ", depth)
+		if !e.IsDir() {
+			w.lines = append(w.lines, indent+"[F] "+name)
+			continue
+		}
+		w.lines = append(w.lines, indent+"[D] "+name+"/")
+		if depth < w.maxDepth {
+			w.walk(filepath.Join(dir, name), depth+1)
+			if w.truncated {
+				return
+			}
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// MoveFileTool
+// ---------------------------------------------------------------------------
+
+// MoveFileTool moves or renames a file or directory within the allowed area.
+type MoveFileTool struct {
+	workspace  string
+	allowedDir string
+}
+
+func NewMoveFileTool(workspace, allowedDir string) *MoveFileTool {
+	return &MoveFileTool{workspace: workspace, allowedDir: allowedDir}
+}
+
+func (t *MoveFileTool) Name() string { return "move_file" }
+func (t *MoveFileTool) Description() string {
+	return "Move or rename a file or directory. Creates parent directories of the destination if needed. " +
+		"Refuses to replace an existing destination unless overwrite is true."
+}
+func (t *MoveFileTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"from": {
+				"type": "string",
+				"description": "The current path"
+			},
+			"to": {
+				"type": "string",
+				"description": "The new path"
+			},
+			"overwrite": {
+				"type": "boolean",
+				"description": "Replace the destination if it already exists (default false)"
+			}
+		},
+		"required": ["from", "to"]
+	}`)
+}
+
+func (t *MoveFileTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	from, _ := params["from"].(string)
+	to, _ := params["to"].(string)
+	overwrite, _ := params["overwrite"].(bool)
+	if from == "" || to == "" {
+		return "Error: from and to are required", nil
+	}
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	src, allowed, err := resolveEntry(from, workspace, allowedDir)
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+	dst, err := resolvePath(to, workspace, allowedDir)
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+	if src == allowed {
+		return fmt.Sprintf("Error: Refusing to move the allowed directory itself: %s", from), nil
+	}
+	if src == dst {
+		return fmt.Sprintf("Error: Source and destination are the same: %s", from), nil
+	}
+	info, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Sprintf("Error: File not found: %s", from), nil
+	}
+	if _, err := os.Lstat(dst); err == nil && !overwrite {
+		return fmt.Sprintf("Error: Destination already exists: %s (pass overwrite=true to replace it)", to), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Sprintf("Error creating directories: %s", err), nil
+	}
+	if err := renameEntry(src, dst); err != nil {
+		if errors.Is(err, syscall.EXDEV) && info.IsDir() {
+			return fmt.Sprintf("Error: cross-device directory moves are not supported: %s", from), nil
+		}
+		if !errors.Is(err, syscall.EXDEV) || !info.Mode().IsRegular() {
+			return fmt.Sprintf("Error moving file: %s", err), nil
+		}
+		// Rename cannot cross filesystems; copy the file, then remove the source.
+		if err := copyFile(src, dst, info.Mode().Perm()); err != nil {
+			return fmt.Sprintf("Error moving file: %s", err), nil
+		}
+		if err := os.Remove(src); err != nil {
+			return fmt.Sprintf("Error removing source after copy: %s", err), nil
+		}
+	}
+	return fmt.Sprintf("Successfully moved %s to %s", src, dst), nil
+}
+
+// protectedEntry reports whether removing p would take out a filesystem root,
+// the user's home directory or the workspace, or one of their ancestors.
+// delete_file refuses these even when it is not restricted to the workspace.
+func protectedEntry(p, workspace string) bool {
+	if filepath.Dir(p) == p {
+		return true
+	}
+	home, _ := os.UserHomeDir()
+	for _, dir := range []string{workspace, home} {
+		for _, form := range entryForms(dir) {
+			if withinDir(form, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withinAny reports whether p lies within dir under any of its entryForms.
+func withinAny(p, dir string) bool {
+	for _, form := range entryForms(dir) {
+		if withinDir(p, form) {
+			return true
+		}
+	}
+	return false
+}
+
+// entryForms returns dir fully resolved and as resolveEntry would name it
+// (final symlink kept), so comparisons hold whether or not dir is a link.
+func entryForms(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	dir = filepath.Clean(dir)
+	var forms []string
+	if resolved, err := evalPath(dir, 0); err == nil {
+		forms = append(forms, resolved)
+	}
+	if parent, err := evalPath(filepath.Dir(dir), 0); err == nil {
+		forms = append(forms, filepath.Join(parent, filepath.Base(dir)))
+	}
+	return forms
+}
+
+// renameEntry is os.Rename; tests replace it to simulate a cross-device move.
+var renameEntry = os.Rename
+
+// copyFile copies the regular file src to dst, replacing dst if it exists.
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// ---------------------------------------------------------------------------
+// DeleteFileTool
+// ---------------------------------------------------------------------------
+
+// DeleteFileTool removes a file, or a directory tree when recursive is set.
+type DeleteFileTool struct {
+	workspace  string
+	allowedDir string
+}
+
+func NewDeleteFileTool(workspace, allowedDir string) *DeleteFileTool {
+	return &DeleteFileTool{workspace: workspace, allowedDir: allowedDir}
+}
+
+func (t *DeleteFileTool) Name() string { return "delete_file" }
+func (t *DeleteFileTool) Description() string {
+	return "Delete a file at the given path. Directories are only removed when recursive is true."
+}
+func (t *DeleteFileTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"path": {
+				"type": "string",
+				"description": "The file or directory path to delete"
+			},
+			"recursive": {
+				"type": "boolean",
+				"description": "Delete a directory and everything in it (default false)"
+			}
+		},
+		"required": ["path"]
+	}`)
+}
+
+func (t *DeleteFileTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	path, _ := params["path"].(string)
+	recursive, _ := params["recursive"].(bool)
+	if path == "" {
+		return "Error: path is required", nil
+	}
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	fp, allowed, err := resolveEntry(path, workspace, allowedDir)
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+	if fp == allowed {
+		return fmt.Sprintf("Error: Refusing to delete the allowed directory itself: %s", path), nil
+	}
+	if protectedEntry(fp, workspace) {
+		return fmt.Sprintf("Error: Refusing to delete a filesystem root, the home directory or the workspace: %s", path), nil
+	}
+	info, err := os.Lstat(fp)
+	if err != nil {
+		return fmt.Sprintf("Error: File not found: %s", path), nil
+	}
+	if info.IsDir() {
+		if !recursive {
+			return fmt.Sprintf("Error: %s is a directory; pass recursive=true to delete it and its contents", path), nil
+		}
+		if allowed == "" && !withinAny(fp, workspace) {
+			return fmt.Sprintf("Error: Refusing to recursively delete %s outside the workspace", path), nil
+		}
+		if err := os.RemoveAll(fp); err != nil {
+			return fmt.Sprintf("Error deleting directory: %s", err), nil
+		}
+		return fmt.Sprintf("Successfully deleted directory %s", fp), nil
+	}
+	if err := os.Remove(fp); err != nil {
+		return fmt.Sprintf("Error deleting file: %s", err), nil
+	}
+	return fmt.Sprintf("Successfully deleted %s", fp), nil
+}
@@ -0,0 +1,540 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestAppendFile_CreatesNewFileAndParents(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewAppendFileTool(dir, dir)
+
+	out, err := tool.Execute(context.Background(), map[string]any{"path": "logs/today.md", "content": "first\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "appended 6 bytes") {
+		t.Errorf("expected byte count in result, got %q", out)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "logs", "today.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\n" {
+		t.Errorf("expected %q, got %q", "first\n", data)
+	}
+}
+
+func TestAppendFile_AppendsToExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(fp, []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewAppendFileTool(dir, dir)
+
+	for _, line := range []string{"b\n", "c\n"} {
+		if out, _ := tool.Execute(context.Background(), map[string]any{"path": "notes.txt", "content": line}); strings.HasPrefix(out, "Error") {
+			t.Fatalf("unexpected error: %s", out)
+		}
+	}
+	data, _ := os.ReadFile(fp)
+	if string(data) != "a\nb\nc\n" {
+		t.Errorf("expected appended content, got %q", data)
+	}
+}
+
+func TestAppendFile_RejectsPathOutsideAllowedDir(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewAppendFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "/etc/passwd", "content": "x"})
+	if !strings.HasPrefix(out, "Error:") || !strings.Contains(out, "outside allowed directory") {
+		t.Errorf("expected allowedDir error, got %q", out)
+	}
+}
+
+func TestDeleteFile_RemovesFile(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, "tmp.txt")
+	os.WriteFile(fp, []byte("x"), 0o644)
+	tool := NewDeleteFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "tmp.txt"})
+	if !strings.HasPrefix(out, "Successfully deleted") {
+		t.Fatalf("unexpected result: %q", out)
+	}
+	if _, err := os.Stat(fp); !os.IsNotExist(err) {
+		t.Errorf("expected file to be gone, stat err = %v", err)
+	}
+}
+
+func TestDeleteFile_DirectoryRequiresRecursive(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "build")
+	os.MkdirAll(filepath.Join(sub, "out"), 0o755)
+	os.WriteFile(filepath.Join(sub, "out", "a.o"), []byte("x"), 0o644)
+	tool := NewDeleteFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "build"})
+	if !strings.Contains(out, "recursive=true") {
+		t.Errorf("expected refusal mentioning recursive, got %q", out)
+	}
+	if _, err := os.Stat(sub); err != nil {
+		t.Fatalf("directory should still exist: %v", err)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"path": "build", "recursive": true})
+	if !strings.HasPrefix(out, "Successfully deleted directory") {
+		t.Fatalf("unexpected result: %q", out)
+	}
+	if _, err := os.Stat(sub); !os.IsNotExist(err) {
+		t.Errorf("expected directory to be gone, stat err = %v", err)
+	}
+}
+
+func TestDeleteFile_RejectsOutsideWorkspace(t *testing.T) {
+	root := t.TempDir()
+	ws := filepath.Join(root, "ws")
+	sibling := filepath.Join(root, "ws-other")
+	os.MkdirAll(ws, 0o755)
+	os.MkdirAll(sibling, 0o755)
+	victim := filepath.Join(sibling, "keep.txt")
+	os.WriteFile(victim, []byte("x"), 0o644)
+	tool := NewDeleteFileTool(ws, ws)
+
+	for _, p := range []string{victim, "../ws-other/keep.txt"} {
+		out, _ := tool.Execute(context.Background(), map[string]any{"path": p})
+		if !strings.Contains(out, "outside allowed directory") {
+			t.Errorf("%s: expected rejection, got %q", p, out)
+		}
+	}
+	if out, _ := tool.Execute(context.Background(), map[string]any{"path": ".", "recursive": true}); !strings.HasPrefix(out, "Error") {
+		t.Errorf("expected refusal to delete the workspace root, got %q", out)
+	}
+	if _, err := os.Stat(victim); err != nil {
+		t.Errorf("file outside workspace was touched: %v", err)
+	}
+	if _, err := os.Stat(ws); err != nil {
+		t.Errorf("workspace root was removed: %v", err)
+	}
+}
+
+func TestDeleteFile_UnrestrictedRefusesProtectedPaths(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	ws := filepath.Join(home, "ws")
+	writeTree(t, root, map[string]string{"home/ws/a.txt": "x", "other/b.txt": "x"})
+	t.Setenv("HOME", home)
+	tool := NewDeleteFileTool(ws, "")
+
+	for _, p := range []string{".", "..", home, root, filepath.Join(root, "other")} {
+		out, _ := tool.Execute(context.Background(), map[string]any{"path": p, "recursive": true})
+		if !strings.HasPrefix(out, "Error: Refusing") {
+			t.Errorf("%s: expected refusal, got %q", p, out)
+		}
+	}
+	for _, p := range []string{"home/ws/a.txt", "other/b.txt"} {
+		if _, err := os.Stat(filepath.Join(root, p)); err != nil {
+			t.Errorf("%s was removed: %v", p, err)
+		}
+	}
+	if !protectedEntry(string(filepath.Separator), ws) {
+		t.Error("expected the filesystem root to be protected")
+	}
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": filepath.Join(root, "other", "b.txt")})
+	if !strings.HasPrefix(out, "Successfully deleted") {
+		t.Errorf("expected single-file delete outside the workspace to succeed, got %q", out)
+	}
+}
+
+func TestDeleteFile_RemovesSymlinkNotTarget(t *testing.T) {
+	root := t.TempDir()
+	ws := filepath.Join(root, "ws")
+	writeTree(t, root, map[string]string{"ws/real.txt": "x", "ws/dir/a.txt": "x"})
+	for link, target := range map[string]string{"file-link": "real.txt", "dir-link": "dir"} {
+		if err := os.Symlink(target, filepath.Join(ws, link)); err != nil {
+			t.Skipf("symlinks unsupported: %v", err)
+		}
+	}
+	tool := NewDeleteFileTool(ws, ws)
+
+	for _, p := range []string{"file-link", "dir-link"} {
+		out, _ := tool.Execute(context.Background(), map[string]any{"path": p})
+		if !strings.HasPrefix(out, "Successfully deleted") {
+			t.Fatalf("%s: unexpected result: %q", p, out)
+		}
+		if _, err := os.Lstat(filepath.Join(ws, p)); !os.IsNotExist(err) {
+			t.Errorf("%s: expected link to be gone, lstat err = %v", p, err)
+		}
+	}
+	for _, p := range []string{"real.txt", "dir/a.txt"} {
+		if _, err := os.Stat(filepath.Join(ws, p)); err != nil {
+			t.Errorf("link target %s was touched: %v", p, err)
+		}
+	}
+}
+
+func TestDeleteFile_RefusesSymlinkedWorkspaceRoot(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"real/keep.txt": "x"})
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(filepath.Join(root, "real"), link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	del := NewDeleteFileTool(link, link)
+	mv := NewMoveFileTool(link, link)
+
+	for _, p := range []string{".", "../real", filepath.Join(root, "real")} {
+		if out, _ := del.Execute(context.Background(), map[string]any{"path": p, "recursive": true}); !strings.HasPrefix(out, "Error") {
+			t.Errorf("delete %s: expected refusal, got %q", p, out)
+		}
+		if out, _ := mv.Execute(context.Background(), map[string]any{"from": p, "to": "moved"}); !strings.HasPrefix(out, "Error") {
+			t.Errorf("move %s: expected refusal, got %q", p, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "real", "keep.txt")); err != nil {
+		t.Errorf("workspace root was removed or moved: %v", err)
+	}
+}
+
+func TestMoveFile_Rename(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "draft.md"), []byte("text"), 0o644)
+	tool := NewMoveFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"from": "draft.md", "to": "final.md"})
+	if !strings.HasPrefix(out, "Successfully moved") {
+		t.Fatalf("unexpected result: %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "draft.md")); !os.IsNotExist(err) {
+		t.Errorf("expected source to be gone, stat err = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "final.md")); string(data) != "text" {
+		t.Errorf("expected moved content, got %q", data)
+	}
+}
+
+func TestMoveFile_AcrossDirectoriesCreatesParents(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "inbox"), 0o755)
+	os.WriteFile(filepath.Join(dir, "inbox", "a.txt"), []byte("a"), 0o644)
+	tool := NewMoveFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"from": "inbox/a.txt", "to": "archive/2026/a.txt"})
+	if !strings.HasPrefix(out, "Successfully moved") {
+		t.Fatalf("unexpected result: %q", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "archive", "2026", "a.txt")); string(data) != "a" {
+		t.Errorf("expected moved content, got %q", data)
+	}
+}
+
+func TestMoveFile_OverwriteGuard(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0o644)
+	os.WriteFile(filepath.Join(dir, "old.txt"), []byte("old"), 0o644)
+	tool := NewMoveFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"from": "new.txt", "to": "old.txt"})
+	if !strings.Contains(out, "overwrite=true") {
+		t.Fatalf("expected overwrite refusal, got %q", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "old.txt")); string(data) != "old" {
+		t.Fatalf("destination changed without overwrite: %q", data)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"from": "new.txt", "to": "old.txt", "overwrite": true})
+	if !strings.HasPrefix(out, "Successfully moved") {
+		t.Fatalf("unexpected result: %q", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "old.txt")); string(data) != "new" {
+		t.Errorf("expected destination replaced, got %q", data)
+	}
+}
+
+func TestMoveFile_RejectsDestinationOutsideWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644)
+	tool := NewMoveFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"from": "a.txt", "to": "../escaped.txt"})
+	if !strings.Contains(out, "outside allowed directory") {
+		t.Errorf("expected rejection, got %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); err != nil {
+		t.Errorf("source should be untouched: %v", err)
+	}
+}
+
+func TestMoveFile_CrossDevice(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "a", "tree/b.txt": "b"})
+	renameEntry = func(string, string) error { return &os.LinkError{Op: "rename", Err: syscall.EXDEV} }
+	defer func() { renameEntry = os.Rename }()
+	tool := NewMoveFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"from": "a.txt", "to": "other/a.txt"})
+	if !strings.HasPrefix(out, "Successfully moved") {
+		t.Fatalf("file: unexpected result: %q", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "other", "a.txt")); string(data) != "a" {
+		t.Errorf("expected copied content, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected source file removed after copy, stat err = %v", err)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"from": "tree", "to": "other/tree"})
+	if !strings.Contains(out, "cross-device directory moves are not supported") {
+		t.Errorf("directory: expected cross-device refusal, got %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tree", "b.txt")); err != nil {
+		t.Errorf("source directory should be untouched: %v", err)
+	}
+}
+
+func TestReadFile_LineRange(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("one\ntwo\nthree\nfour\nfive\n"), 0o644)
+	tool := NewReadFileTool(dir, dir, 0)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "f.txt", "start_line": float64(2), "end_line": float64(3)})
+	if want := "[Lines 2-3 of 5]\ntwo\nthree\n"; out != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"path": "f.txt", "start_line": float64(4)})
+	if want := "[Lines 4-5 of 5]\nfour\nfive\n"; out != want {
+		t.Errorf("expected open-ended range %q, got %q", want, out)
+	}
+}
+
+func TestReadFile_OutOfBoundsRangeIsClamped(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("a\nb\nc"), 0o644)
+	tool := NewReadFileTool(dir, dir, 0)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "f.txt", "start_line": float64(-4), "end_line": float64(99)})
+	if want := "[Lines 1-3 of 3]\na\nb\nc"; out != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"path": "f.txt", "start_line": float64(10), "end_line": float64(2)})
+	if want := "[Lines 3-3 of 3]\nc"; out != want {
+		t.Errorf("expected %q, got %q", want, out)
+	}
+}
+
+func TestReadFile_WholeFileByDefault(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("a\nb\n"), 0o644)
+	tool := NewReadFileTool(dir, dir, 0)
+
+	if out, _ := tool.Execute(context.Background(), map[string]any{"path": "f.txt"}); out != "a\nb\n" {
+		t.Errorf("expected unchanged content, got %q", out)
+	}
+}
+
+func TestTree_DepthLimit(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a/b/c/deep.txt": "",
+		"a/top.txt":      "",
+		"root.txt":       "",
+		".hidden/x":      "",
+		".git/HEAD":      "",
+	})
+	tool := NewTreeTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"max_depth": float64(2)})
+	want := strings.Join([]string{
+		"./",
+		"  [D] a/",
+		"    [D] b/",
+		"    [F] top.txt",
+		"  [F] root.txt",
+	}, "\n")
+	if out != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, out)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"show_hidden": true, "max_depth": float64(1)})
+	if !strings.Contains(out, "[D] .hidden/") || strings.Contains(out, ".git") {
+		t.Errorf("expected .hidden shown and .git skipped, got:\n%s", out)
+	}
+}
+
+func TestTree_EntryCap(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{}
+	for _, n := range []string{"a", "b", "c", "d", "e"} {
+		files["sub/"+n+".txt"] = ""
+	}
+	writeTree(t, dir, files)
+	tool := NewTreeTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"max_entries": float64(3)})
+	if n := strings.Count(out, "[F]") + strings.Count(out, "[D]"); n != 3 {
+		t.Errorf("expected 3 entries, got %d:\n%s", n, out)
+	}
+	if !strings.HasSuffix(out, "[truncated: stopped after 3 entries]") {
+		t.Errorf("expected truncation note, got:\n%s", out)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"path": "/etc"})
+	if !strings.Contains(out, "outside allowed directory") {
+		t.Errorf("expected allowedDir rejection, got %q", out)
+	}
+}
+
+func TestResolvePath_RejectsSiblingPrefix(t *testing.T) {
+	root := t.TempDir()
+	ws := filepath.Join(root, "workspace")
+	writeTree(t, root, map[string]string{"workspace/ok.txt": "", "workspace-evil/secret.txt": ""})
+
+	if _, err := resolvePath("../workspace-evil/secret.txt", ws, ws); err == nil {
+		t.Error("expected sibling directory sharing the prefix to be rejected")
+	}
+	if _, err := resolvePath(filepath.Join(root, "workspace-evil"), ws, ws); err == nil {
+		t.Error("expected absolute sibling path to be rejected")
+	}
+	if _, err := resolvePath("ok.txt", ws, ws); err != nil {
+		t.Errorf("expected path inside workspace to pass: %v", err)
+	}
+}
+
+func TestResolvePath_RejectsSymlinkEscape(t *testing.T) {
+	root := t.TempDir()
+	ws := filepath.Join(root, "ws")
+	outside := filepath.Join(root, "outside")
+	writeTree(t, root, map[string]string{"ws/keep.txt": "", "outside/secret.txt": ""})
+	for link, target := range map[string]string{
+		"file-link": filepath.Join(outside, "secret.txt"),
+		"dir-link":  outside,
+		"dangling":  filepath.Join(outside, "new.txt"),
+	} {
+		if err := os.Symlink(target, filepath.Join(ws, link)); err != nil {
+			t.Skipf("symlinks unsupported: %v", err)
+		}
+	}
+
+	for _, p := range []string{"file-link", "dir-link/secret.txt", "dir-link/new/file.txt", "dangling"} {
+		if _, err := resolvePath(p, ws, ws); err == nil {
+			t.Errorf("%s: expected symlink escape to be rejected", p)
+		}
+	}
+	if _, err := resolvePath("sub/new.txt", ws, ws); err != nil {
+		t.Errorf("expected new path inside workspace to pass: %v", err)
+	}
+}
+
+func TestResolvePath_SymlinkedAllowedDir(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"real/a.txt": ""})
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(filepath.Join(root, "real"), link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	got, err := resolvePath("a.txt", link, link)
+	if err != nil {
+		t.Fatalf("expected file under symlinked allowed dir to pass: %v", err)
+	}
+	if want := filepath.Join(root, "real", "a.txt"); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestEditFile_SingleOccurrence(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.go": "x := oldName()\n"})
+	tool := NewEditFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "a.go", "old_text": "oldName", "new_text": "newName"})
+	if !strings.HasPrefix(out, "Successfully edited") || strings.Contains(out, "replacements") {
+		t.Errorf("unexpected result: %q", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.go")); string(data) != "x := newName()\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+}
+
+func TestEditFile_MultipleRequiresReplaceAll(t *testing.T) {
+	dir := t.TempDir()
+	orig := "oldName()\noldName()\noldName()\n"
+	writeTree(t, dir, map[string]string{"a.go": orig})
+	tool := NewEditFileTool(dir, dir)
+	p := filepath.Join(dir, "a.go")
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "a.go", "old_text": "oldName", "new_text": "newName"})
+	if !strings.Contains(out, "appears 3 times") {
+		t.Errorf("expected uniqueness warning, got %q", out)
+	}
+	if data, _ := os.ReadFile(p); string(data) != orig {
+		t.Errorf("file should be unchanged without replace_all, got %q", data)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{
+		"path": "a.go", "old_text": "oldName", "new_text": "newName", "replace_all": true,
+	})
+	if !strings.Contains(out, "(3 replacements)") {
+		t.Errorf("expected replacement count, got %q", out)
+	}
+	if data, _ := os.ReadFile(p); string(data) != "newName()\nnewName()\nnewName()\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+}
+
+func TestEditFile_NotFoundKeepsHint(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.go": "func main() {}\n"})
+	tool := NewEditFileTool(dir, dir)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{
+		"path": "a.go", "old_text": "func mian() {}", "new_text": "x", "replace_all": true,
+	})
+	if want := editNotFoundMessage("func mian() {}", "func main() {}\n", "a.go"); out != want {
+		t.Errorf("expected not-found hint %q, got %q", want, out)
+	}
+}
+
+func TestReadFile_BinaryNotShown(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"blob.bin": "\x7fELF\x02\x01\x01\x00\x00garbage"})
+	tool := NewReadFileTool(dir, dir, 0)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "blob.bin"})
+	if !strings.HasPrefix(out, "Binary file not shown: blob.bin") {
+		t.Errorf("expected binary notice, got %q", out)
+	}
+}
+
+func TestReadFile_OversizedIsTruncated(t *testing.T) {
+	dir := t.TempDir()
+	var sb strings.Builder
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&sb, "line %04d\n", i) // 10 bytes per line
+	}
+	writeTree(t, dir, map[string]string{"big.log": sb.String()})
+	tool := NewReadFileTool(dir, dir, 95)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"path": "big.log"})
+	if !strings.HasPrefix(out, "line 0001\n") || strings.Contains(out, "line 0010") {
+		t.Errorf("expected only the first whole lines, got %q", out)
+	}
+	if !strings.Contains(out, "[Truncated: big.log is 10000 bytes; showing the first 90.") {
+		t.Errorf("expected truncation note, got %q", out)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"path": "big.log", "start_line": 500, "end_line": 502})
+	if out != "[Lines 500-502 of 1000]\nline 0500\nline 0501\nline 0502\n" {
+		t.Errorf("expected streamed line range, got %q", out)
+	}
+}
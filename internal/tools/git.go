@@ -0,0 +1,395 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	gitMaxOutputChars = 50000 // diff output beyond this is cut
+	gitDefaultLogN    = 20
+	gitMaxLogN        = 200
+)
+
+// gitSafetyArgs stop a repository from running programs it controls through
+// this tool: hooks, the fsmonitor daemon, the pager and commit signing. Diff
+// drivers are disabled per command with --no-ext-diff / --no-textconv, and
+// repositories configuring other programs are refused (gitUnsafeConfig).
+var gitSafetyArgs = []string{
+	"--no-pager",
+	"-c", "core.hooksPath=" + os.DevNull,
+	"-c", "core.fsmonitor=false",
+	"-c", "commit.gpgSign=false",
+	"-c", "color.ui=false",
+}
+
+// gitUnsafeConfig matches config keys that make git run a program with no
+// command-line override: clean/smudge filters (selected by .gitattributes on
+// add), signing programs and the ssh command.
+const gitUnsafeConfig = `^(filter\..*|gpg\.(.*\.)?program|core\.sshcommand)$`
+
+// GitTool runs a fixed set of git operations (status, diff, log, add, commit,
+// branch) in a repository inside the workspace and returns readable output,
+// without giving the model a shell.
+type GitTool struct {
+	workspace  string
+	allowedDir string
+}
+
+func NewGitTool(workspace, allowedDir string) *GitTool {
+	return &GitTool{workspace: workspace, allowedDir: allowedDir}
+}
+
+func (t *GitTool) Name() string { return "git" }
+func (t *GitTool) Description() string {
+	return "Run git in a workspace repository. action=status: branch and changed files. " +
+		"action=diff: patch of unstaged changes (staged=true for the index). action=log: recent commits. " +
+		"action=add: stage files. action=commit: commit staged changes with message. " +
+		"action=branch: list branches, or create one with name."
+}
+func (t *GitTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["status", "diff", "log", "add", "commit", "branch"]
+			},
+			"repo": {
+				"type": "string",
+				"description": "Repository directory (default: the workspace)"
+			},
+			"files": {
+				"type": "array",
+				"items": {"type": "string"},
+				"description": "diff/log: limit to these paths; add: paths to stage (required)"
+			},
+			"staged": {
+				"type": "boolean",
+				"description": "diff: show staged changes instead of unstaged"
+			},
+			"limit": {
+				"type": "integer",
+				"description": "log: number of commits (default 20, max 200)"
+			},
+			"message": {
+				"type": "string",
+				"description": "commit: commit message (required)"
+			},
+			"name": {
+				"type": "string",
+				"description": "branch: name of a branch to create"
+			}
+		},
+		"required": ["action"]
+	}`)
+}
+
+func (t *GitTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "Error: git is not installed", nil
+	}
+	action, _ := params["action"].(string)
+	repoParam, _ := params["repo"].(string)
+	if repoParam == "" {
+		repoParam = "."
+	}
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	repo, err := resolvePath(repoParam, workspace, allowedDir)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	files, err := pathspecs(repo, allowedDir, params["files"])
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	args, err := gitArgs(action, params, files)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+
+	bound := allowedDir
+	if bound == "" {
+		bound = workspace
+	}
+	g, err := openRepo(ctx, repo, bound, allowedDir != "")
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	out, err := g.run(ctx, args)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	switch action {
+	case "status":
+		return formatGitStatus(out), nil
+	case "log":
+		return formatGitLog(out), nil
+	case "diff":
+		if strings.TrimSpace(out) == "" {
+			return "No changes.", nil
+		}
+		if len(out) > gitMaxOutputChars {
+			return out[:gitMaxOutputChars] + fmt.Sprintf("\n... diff truncated (%d more chars); pass files to narrow it", len(out)-gitMaxOutputChars), nil
+		}
+		return out, nil
+	case "add":
+		status, err := g.run(ctx, []string{"status", "--porcelain=v1", "--branch"})
+		if err != nil {
+			return "Staged.", nil
+		}
+		return "Staged.\n\n" + formatGitStatus(status), nil
+	case "branch":
+		if name, _ := params["name"].(string); name != "" {
+			return fmt.Sprintf("Created branch %s.", name), nil
+		}
+		return strings.TrimRight(out, "\n"), nil
+	default:
+		return strings.TrimSpace(out), nil
+	}
+}
+
+// pathspecs resolves each file under allowedDir and returns it relative to
+// repo, so no pathspec can reach outside the permitted tree.
+func pathspecs(repo, allowedDir string, v any) ([]string, error) {
+	raw, _ := v.([]any)
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		f, ok := item.(string)
+		if !ok || f == "" {
+			return nil, fmt.Errorf("files must be non-empty strings")
+		}
+		abs, err := resolvePath(f, repo, allowedDir)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(repo, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("path %s is outside the repository", f)
+		}
+		out = append(out, rel)
+	}
+	return out, nil
+}
+
+// gitArgs builds the git argument list for action. files are already
+// validated pathspecs; they always follow "--" so none is read as an option.
+func gitArgs(action string, params map[string]any, files []string) ([]string, error) {
+	var args []string
+	switch action {
+	case "status":
+		args = []string{"status", "--porcelain=v1", "--branch"}
+	case "diff":
+		args = []string{"diff", "--no-ext-diff", "--no-textconv"}
+		if staged, _ := params["staged"].(bool); staged {
+			args = append(args, "--cached")
+		}
+	case "log":
+		n := gitDefaultLogN
+		if v, ok := params["limit"].(float64); ok && v > 0 {
+			n = min(int(v), gitMaxLogN)
+		}
+		args = []string{"log", "--no-ext-diff", "--no-textconv", fmt.Sprintf("-n%d", n),
+			"--date=iso-strict", "--pretty=format:%h%x1f%an%x1f%ad%x1f%s%x1e"}
+	case "add":
+		if len(files) == 0 {
+			return nil, fmt.Errorf("files is required for add")
+		}
+		args = []string{"add"}
+	case "commit":
+		msg, _ := params["message"].(string)
+		if strings.TrimSpace(msg) == "" {
+			return nil, fmt.Errorf("message is required for commit")
+		}
+		if len(files) > 0 {
+			return nil, fmt.Errorf("commit takes staged changes; stage files with add first")
+		}
+		return []string{"commit", "-m", msg}, nil
+	case "branch":
+		name, _ := params["name"].(string)
+		if name == "" {
+			return []string{"branch", "--list", "--format=%(HEAD) %(refname:short)"}, nil
+		}
+		if strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n~^:?*[\\") {
+			return nil, fmt.Errorf("invalid branch name %q", name)
+		}
+		return []string{"branch", "--", name}, nil
+	default:
+		return nil, fmt.Errorf("unknown action %q (want status, diff, log, add, commit or branch)", action)
+	}
+	if len(files) > 0 {
+		args = append(append(args, "--"), files...)
+	}
+	return args, nil
+}
+
+// gitRepo runs git in one repository directory.
+type gitRepo struct {
+	dir     string
+	ceiling string // GIT_CEILING_DIRECTORIES: repository discovery stops here
+}
+
+// openRepo checks the repository containing dir before any action runs in
+// it. Discovery cannot climb above bound, so a repository enclosing the
+// workspace is never picked up; when confined, the work tree must also lie
+// within bound. Repositories whose own config names a program for git to run
+// (gitUnsafeConfig) are refused: that would be arbitrary command execution
+// outside the exec tool's policy and approvals. Config from the user's global
+// and system files is trusted.
+func openRepo(ctx context.Context, dir, bound string, confined bool) (*gitRepo, error) {
+	root, err := evalPath(filepath.Clean(bound), 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve workspace %s: %w", bound, err)
+	}
+	g := &gitRepo{dir: dir, ceiling: filepath.Dir(root)}
+
+	top, err := g.run(ctx, []string{"rev-parse", "--show-toplevel"})
+	if err != nil {
+		return nil, err
+	}
+	top = strings.TrimSpace(top)
+	if confined {
+		resolved, err := evalPath(filepath.Clean(top), 0)
+		if err != nil || !withinDir(resolved, root) {
+			return nil, fmt.Errorf("repository %s is outside allowed directory %s", top, bound)
+		}
+	}
+
+	// Exits 1 when no key matches.
+	cfg, _ := g.run(ctx, []string{"config", "--show-scope", "--get-regexp", gitUnsafeConfig})
+	for _, line := range strings.Split(strings.TrimSpace(cfg), "\n") {
+		scope, entry, ok := strings.Cut(line, "\t")
+		if !ok || scope == "system" || scope == "global" {
+			continue
+		}
+		key, _, _ := strings.Cut(entry, " ")
+		return nil, fmt.Errorf("refusing to use this repository: its config sets %s, which makes git run a program", key)
+	}
+	return g, nil
+}
+
+// run runs git with args and returns stdout. On failure the error carries
+// git's own message.
+func (g *gitRepo) run(ctx context.Context, args []string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append(append([]string{}, gitSafetyArgs...), args...)...)
+	cmd.Dir = g.dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_OPTIONAL_LOCKS=0", "GIT_CEILING_DIRECTORIES="+g.ceiling)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String()) // e.g. "nothing to commit"
+		}
+		var exitErr *exec.ExitError
+		if msg == "" || !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %v", args[0], err)
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// formatGitStatus renders `git status --porcelain=v1 --branch` output as
+// the branch line followed by staged, unstaged, untracked and conflicted files.
+func formatGitStatus(porcelain string) string {
+	var branch string
+	var staged, unstaged, untracked, conflicted []string
+	for _, line := range strings.Split(strings.TrimRight(porcelain, "\n"), "\n") {
+		if rest, ok := strings.CutPrefix(line, "## "); ok {
+			branch = rest
+			continue
+		}
+		if len(line) < 4 {
+			continue
+		}
+		x, y, path := line[0], line[1], line[3:]
+		switch {
+		case x == '?' && y == '?':
+			untracked = append(untracked, path)
+		case x == 'U' || y == 'U' || (x == 'A' && y == 'A') || (x == 'D' && y == 'D'):
+			conflicted = append(conflicted, path)
+		default:
+			if x != ' ' {
+				staged = append(staged, gitStatusWord(x)+" "+path)
+			}
+			if y != ' ' {
+				unstaged = append(unstaged, gitStatusWord(y)+" "+path)
+			}
+		}
+	}
+
+	var b strings.Builder
+	if branch != "" {
+		fmt.Fprintf(&b, "Branch: %s\n", strings.Replace(branch, "...", " tracking ", 1))
+	}
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Staged", staged}, {"Unstaged", unstaged}, {"Untracked", untracked}, {"Conflicted", conflicted},
+	}
+	empty := true
+	for _, s := range sections {
+		if len(s.items) == 0 {
+			continue
+		}
+		empty = false
+		fmt.Fprintf(&b, "%s:\n", s.title)
+		for _, item := range s.items {
+			fmt.Fprintf(&b, "  %s\n", item)
+		}
+	}
+	if empty {
+		b.WriteString("Working tree clean.\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func gitStatusWord(c byte) string {
+	switch c {
+	case 'M':
+		return "modified:"
+	case 'A':
+		return "added:   "
+	case 'D':
+		return "deleted: "
+	case 'R':
+		return "renamed: "
+	case 'C':
+		return "copied:  "
+	case 'T':
+		return "typechange:"
+	default:
+		return string(c) + ":"
+	}
+}
+
+// formatGitLog renders the unit/record-separated log format from gitArgs as
+// one "hash date author: subject" line per commit.
+func formatGitLog(out string) string {
+	var lines []string
+	for _, rec := range strings.Split(out, "\x1e") {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+		f := strings.SplitN(rec, "\x1f", 4)
+		if len(f) != 4 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s: %s", f[0], f[2], f[1], f[3]))
+	}
+	if len(lines) == 0 {
+		return "No commits."
+	}
+	return strings.Join(lines, "\n")
+}
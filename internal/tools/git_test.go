@@ -0,0 +1,201 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGitArgs(t *testing.T) {
+	cases := []struct {
+		action string
+		params map[string]any
+		files  []string
+		want   []string
+	}{
+		{"status", nil, nil, []string{"status", "--porcelain=v1", "--branch"}},
+		{"diff", map[string]any{"staged": true}, []string{"a.go"},
+			[]string{"diff", "--no-ext-diff", "--no-textconv", "--cached", "--", "a.go"}},
+		{"log", map[string]any{"limit": float64(500)}, nil,
+			[]string{"log", "--no-ext-diff", "--no-textconv", "-n200", "--date=iso-strict", "--pretty=format:%h%x1f%an%x1f%ad%x1f%s%x1e"}},
+		{"add", nil, []string{"-rf", "b.go"}, []string{"add", "--", "-rf", "b.go"}},
+		{"commit", map[string]any{"message": "--amend"}, nil, []string{"commit", "-m", "--amend"}},
+		{"branch", nil, nil, []string{"branch", "--list", "--format=%(HEAD) %(refname:short)"}},
+		{"branch", map[string]any{"name": "feature/x"}, nil, []string{"branch", "--", "feature/x"}},
+	}
+	for _, c := range cases {
+		got, err := gitArgs(c.action, c.params, c.files)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("gitArgs(%s, %v) = %q, %v; want %q", c.action, c.params, got, err, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		action string
+		params map[string]any
+		files  []string
+	}{
+		{"push", nil, nil},
+		{"add", nil, nil},
+		{"commit", nil, nil},
+		{"commit", map[string]any{"message": "x"}, []string{"a.go"}},
+		{"branch", map[string]any{"name": "-D"}, nil},
+		{"branch", map[string]any{"name": "a b"}, nil},
+	} {
+		if _, err := gitArgs(c.action, c.params, c.files); err == nil {
+			t.Errorf("gitArgs(%s, %v, %v): expected an error", c.action, c.params, c.files)
+		}
+	}
+}
+
+func TestFormatGitStatus(t *testing.T) {
+	out := formatGitStatus("## main...origin/main [ahead 1]\nM  staged.go\n M edited.go\nMM both.go\n?? new.txt\nUU conflict.go\n")
+	want := `Branch: main tracking origin/main [ahead 1]
+Staged:
+  modified: staged.go
+  modified: both.go
+Unstaged:
+  modified: edited.go
+  modified: both.go
+Untracked:
+  new.txt
+Conflicted:
+  conflict.go`
+	if out != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+	if out := formatGitStatus("## main\n"); out != "Branch: main\nWorking tree clean." {
+		t.Errorf("unexpected clean status %q", out)
+	}
+}
+
+func TestFormatGitLog(t *testing.T) {
+	raw := "abc1234\x1fAda\x1f2025-01-02T10:00:00+00:00\x1fSecond\x1e\ndef5678\x1fBob\x1f2025-01-01T09:00:00+00:00\x1fFirst: with colon\x1e"
+	want := "abc1234 2025-01-02T10:00:00+00:00 Ada: Second\ndef5678 2025-01-01T09:00:00+00:00 Bob: First: with colon"
+	if got := formatGitLog(raw); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// initTestRepo creates a repository in dir with a committer identity and
+// the extra config entries given as key/value pairs.
+func initTestRepo(t *testing.T, dir string, config ...string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	cmds := [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+	}
+	for i := 0; i+1 < len(config); i += 2 {
+		cmds = append(cmds, []string{"config", config[i], config[i+1]})
+	}
+	for _, args := range cmds {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestGit_Workflow(t *testing.T) {
+	dir := t.TempDir()
+	initTestRepo(t, dir)
+	// A hook that must not run through the tool.
+	hook := filepath.Join(dir, ".git", "hooks", "pre-commit")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\ntouch "+filepath.Join(dir, "hook-ran")+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewGitTool(dir, dir)
+	run := func(params map[string]any) string {
+		t.Helper()
+		out, err := tool.Execute(context.Background(), params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if out := run(map[string]any{"action": "status"}); !strings.Contains(out, "Untracked:\n  a.txt") {
+		t.Errorf("unexpected status:\n%s", out)
+	}
+	if out := run(map[string]any{"action": "add", "files": []any{"a.txt"}}); !strings.Contains(out, "added:    a.txt") {
+		t.Errorf("unexpected add result:\n%s", out)
+	}
+	if out := run(map[string]any{"action": "commit", "message": "Add a.txt"}); !strings.Contains(out, "Add a.txt") {
+		t.Errorf("unexpected commit result:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hook-ran")); err == nil {
+		t.Error("expected repository hooks to be disabled")
+	}
+	if out := run(map[string]any{"action": "log"}); !strings.Contains(out, "Test: Add a.txt") {
+		t.Errorf("unexpected log:\n%s", out)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out := run(map[string]any{"action": "diff"}); !strings.Contains(out, "-one\n+two") {
+		t.Errorf("unexpected diff:\n%s", out)
+	}
+	if out := run(map[string]any{"action": "diff", "staged": true}); out != "No changes." {
+		t.Errorf("expected no staged changes, got %q", out)
+	}
+
+	run(map[string]any{"action": "branch", "name": "feature"})
+	if out := run(map[string]any{"action": "branch"}); out != "  feature\n* main" {
+		t.Errorf("unexpected branches %q", out)
+	}
+
+	if out := run(map[string]any{"action": "add", "files": []any{"../outside"}}); !strings.Contains(out, "outside allowed directory") {
+		t.Errorf("expected outside path rejected, got %q", out)
+	}
+	if out := run(map[string]any{"action": "commit", "message": "empty"}); !strings.Contains(out, "no changes added to commit") {
+		t.Errorf("expected a no-changes error, got %q", out)
+	}
+}
+
+func TestGit_RefusesRepoConfiguredPrograms(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "filter-ran")
+	initTestRepo(t, dir, "filter.evil.clean", "touch "+marker+"; cat")
+	writeTree(t, dir, map[string]string{".gitattributes": "* filter=evil\n", "a.txt": "one\n"})
+	tool := NewGitTool(dir, dir)
+
+	for _, action := range []string{"status", "add"} {
+		out, _ := tool.Execute(context.Background(), map[string]any{"action": action, "files": []any{"a.txt"}})
+		if !strings.Contains(out, "filter.evil.clean") {
+			t.Errorf("%s: expected the repository to be refused, got %q", action, out)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the repository's clean filter ran")
+	}
+
+	other := t.TempDir()
+	initTestRepo(t, other, "gpg.program", "touch "+marker)
+	if out, _ := NewGitTool(other, other).Execute(context.Background(), map[string]any{"action": "status"}); !strings.Contains(out, "gpg.program") {
+		t.Errorf("expected gpg.program to be refused, got %q", out)
+	}
+}
+
+func TestGit_DoesNotUseEnclosingRepo(t *testing.T) {
+	parent := t.TempDir()
+	initTestRepo(t, parent)
+	ws := filepath.Join(parent, "ws")
+	writeTree(t, ws, map[string]string{"a.txt": "one\n"})
+
+	out, _ := NewGitTool(ws, ws).Execute(context.Background(), map[string]any{"action": "status"})
+	if !strings.HasPrefix(out, "Error:") || !strings.Contains(out, "not a git repository") {
+		t.Errorf("expected the enclosing repository to be ignored, got %q", out)
+	}
+}
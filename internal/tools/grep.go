@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	grepMaxLineChars = 300     // longer matching lines are cut in the output
+	grepSniffBytes   = 8000    // prefix inspected for NUL bytes to detect binaries
+	grepMaxFileBytes = 8 << 20 // larger files are skipped
+)
+
+// grepSkipDirs are directory names never descended into.
+var grepSkipDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, "node_modules": true}
+
+// errGrepLimit stops the directory walk once enough matches are collected.
+var errGrepLimit = errors.New("match limit reached")
+
+// GrepTool searches file contents for a regular expression.
+type GrepTool struct {
+	workspace  string
+	allowedDir string
+	maxMatches int
+}
+
+func NewGrepTool(workspace, allowedDir string, maxMatches int) *GrepTool {
+	if maxMatches <= 0 {
+		maxMatches = 200
+	}
+	return &GrepTool{workspace: workspace, allowedDir: allowedDir, maxMatches: maxMatches}
+}
+
+func (t *GrepTool) Name() string { return "grep" }
+func (t *GrepTool) Description() string {
+	return "Search file contents for a regular expression. Returns matching lines as path:line: text. " +
+		"Binary files and .git / node_modules directories are skipped."
+}
+func (t *GrepTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"pattern": {
+				"type": "string",
+				"description": "Regular expression (Go RE2 syntax); prefix with (?i) for case-insensitive"
+			},
+			"path": {
+				"type": "string",
+				"description": "File or directory to search (default: the workspace)"
+			},
+			"glob": {
+				"type": "string",
+				"description": "Only search files whose name matches this glob, e.g. *.go"
+			}
+		},
+		"required": ["pattern"]
+	}`)
+}
+
+func (t *GrepTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	pattern, _ := params["pattern"].(string)
+	path, _ := params["path"].(string)
+	glob, _ := params["glob"].(string)
+	if pattern == "" {
+		return "Error: pattern is required", nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Sprintf("Error: invalid pattern: %s", err), nil
+	}
+	if glob != "" {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return fmt.Sprintf("Error: invalid glob: %s", err), nil
+		}
+	}
+	if path == "" {
+		path = "."
+	}
+	workspace, allowedDir := scopedDirs(ctx, t.workspace, t.allowedDir)
+	root, err := resolvePath(path, workspace, allowedDir)
+	if err != nil {
+		return "Error: " + err.Error(), nil
+	}
+	if _, err := os.Stat(root); err != nil {
+		return fmt.Sprintf("Error: Path not found: %s", path), nil
+	}
+
+	var matches []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // unreadable entry; keep walking
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if d.IsDir() {
+			if p != root && grepSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Symlinks may point outside allowedDir; regular files only.
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if glob != "" {
+			if ok, _ := filepath.Match(glob, d.Name()); !ok {
+				return nil
+			}
+		}
+		matches = grepFile(p, displayPath(workspace, p), re, matches, t.maxMatches)
+		if len(matches) >= t.maxMatches {
+			return errGrepLimit
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errGrepLimit) {
+		return fmt.Sprintf("Error searching files: %s", err), nil
+	}
+
+	if len(matches) == 0 {
+		return fmt.Sprintf("No matches for %q in %s", pattern, path), nil
+	}
+	out := strings.Join(matches, "\n")
+	if errors.Is(err, errGrepLimit) {
+		out += fmt.Sprintf("\n[stopped after %d matches; narrow the pattern, path, or glob]", t.maxMatches)
+	}
+	return out, nil
+}
+
+// displayPath shows p relative to the workspace when it lies inside it.
+func displayPath(workspace, p string) string {
+	if workspace != "" {
+		if rel, err := filepath.Rel(workspace, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return p
+}
+
+// grepFile appends "name:line: text" for each line of the file at p matching
+// re, stopping at limit total matches. Binary and oversized files are skipped.
+func grepFile(p, name string, re *regexp.Regexp, matches []string, limit int) []string {
+	f, err := os.Open(p)
+	if err != nil {
+		return matches
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.Size() > grepMaxFileBytes {
+		return matches
+	}
+
+	br := bufio.NewReader(f)
+	if head, _ := br.Peek(grepSniffBytes); bytes.IndexByte(head, 0) >= 0 {
+		return matches
+	}
+
+	for lineNo := 1; len(matches) < limit; lineNo++ {
+		line, err := br.ReadString('\n')
+		if line == "" && err != nil {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if re.MatchString(line) {
+			if r := []rune(line); len(r) > grepMaxLineChars {
+				line = string(r[:grepMaxLineChars]) + "…"
+			}
+			matches = append(matches, fmt.Sprintf("%s:%d: %s", name, lineNo, line))
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return matches
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files (relative path → content) under dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGrep_MatchesWithFileLinePrefixes(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"main.go":          "package main\n\nfunc main() {\n\tTODO()\n}\n",
+		"pkg/util.go":      "package pkg\n// TODO: tidy\n",
+		"README.md":        "TODO list\n",
+		"image.bin":        "TODO\x00\x01\x02",
+		".git/config":      "TODO inside git\n",
+		"pkg/notes/old.go": "nothing here\n",
+	})
+	tool := NewGrepTool(dir, dir, 100)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"pattern": "TODO", "glob": "*.go"})
+	lines := strings.Split(out, "\n")
+	want := []string{"main.go:4: \tTODO()", "pkg/util.go:2: // TODO: tidy"}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d matches, got %q", len(want), out)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d: expected %q, got %q", i, want[i], lines[i])
+		}
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"pattern": "TODO"})
+	if strings.Contains(out, "image.bin") {
+		t.Errorf("binary file should be skipped: %q", out)
+	}
+	if strings.Contains(out, ".git") {
+		t.Errorf(".git should be skipped: %q", out)
+	}
+	if !strings.Contains(out, "README.md:1: TODO list") {
+		t.Errorf("expected README match without glob: %q", out)
+	}
+}
+
+func TestGrep_SingleFileAndNoMatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "alpha\nbeta\n", "b.txt": "beta\n"})
+	tool := NewGrepTool(dir, dir, 100)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"pattern": "(?i)BETA", "path": "a.txt"})
+	if out != "a.txt:2: beta" {
+		t.Errorf("unexpected result: %q", out)
+	}
+	out, _ = tool.Execute(context.Background(), map[string]any{"pattern": "gamma"})
+	if !strings.HasPrefix(out, "No matches") {
+		t.Errorf("expected no-match message, got %q", out)
+	}
+}
+
+func TestGrep_CapsMatches(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"log.txt": strings.Repeat("hit\n", 50)})
+	tool := NewGrepTool(dir, dir, 5)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"pattern": "hit"})
+	if n := strings.Count(out, "log.txt:"); n != 5 {
+		t.Errorf("expected 5 matches, got %d", n)
+	}
+	if !strings.Contains(out, "stopped after 5 matches") {
+		t.Errorf("expected truncation note, got %q", out)
+	}
+}
+
+func TestGrep_RejectsOutsideAllowedDir(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewGrepTool(dir, dir, 10)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"pattern": "root", "path": "/etc"})
+	if !strings.Contains(out, "outside allowed directory") {
+		t.Errorf("expected rejection, got %q", out)
+	}
+	out, _ = tool.Execute(context.Background(), map[string]any{"pattern": "("})
+	if !strings.HasPrefix(out, "Error: invalid pattern") {
+		t.Errorf("expected invalid pattern error, got %q", out)
+	}
+}
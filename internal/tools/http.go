@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+// httpMethods are the methods accepted by http_request.
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+
+// HTTPRequestTool sends an arbitrary HTTP request and returns the raw
+// response, for calling JSON APIs that need headers or request bodies.
+// It shares web_fetch's URL validation and private-address guard.
+type HTTPRequestTool struct {
+	maxChars   int
+	guard      *hostGuard
+	httpClient *http.Client
+}
+
+// NewHTTPRequestTool creates an HTTPRequestTool. Timeout defaults to 30s and
+// maxResponseChars to 20000.
+func NewHTTPRequestTool(cfg toolcfg.HTTPToolConfig) *HTTPRequestTool {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	maxChars := cfg.MaxResponseChars
+	if maxChars <= 0 {
+		maxChars = 20000
+	}
+	guard := newHostGuard(cfg.AllowPrivateNetworks, cfg.AllowedHosts)
+	return &HTTPRequestTool{
+		maxChars:   maxChars,
+		guard:      guard,
+		httpClient: newGuardedClient(timeout, guard),
+	}
+}
+
+func (t *HTTPRequestTool) Name() string { return "http_request" }
+func (t *HTTPRequestTool) Description() string {
+	return "Send an HTTP request (e.g. to a JSON API) with custom method, headers, and body. Returns status, response headers, and the raw body."
+}
+func (t *HTTPRequestTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"method": {
+				"type": "string",
+				"enum": ["GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"],
+				"default": "GET"
+			},
+			"url": {
+				"type": "string",
+				"description": "Request URL (http/https)"
+			},
+			"headers": {
+				"type": "object",
+				"description": "Request headers",
+				"additionalProperties": {"type": "string"}
+			},
+			"body": {
+				"type": "string",
+				"description": "Request body, sent as-is"
+			}
+		},
+		"required": ["url"]
+	}`)
+}
+
+func (t *HTTPRequestTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	rawURL, _ := params["url"].(string)
+	if rawURL == "" {
+		return "Error: url is required", nil
+	}
+
+	method := "GET"
+	if m, ok := params["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+	if !slices.Contains(httpMethods, method) {
+		return fmt.Sprintf("Error: unsupported method %q", method), nil
+	}
+
+	if err := validateURL(rawURL); err != nil {
+		return fmt.Sprintf("Error: URL validation failed: %v", err), nil
+	}
+	parsed, _ := url.Parse(rawURL)
+	if err := t.guard.check(ctx, parsed); err != nil {
+		return fmt.Sprintf("Error: URL validation failed: %v", err), nil
+	}
+
+	var body io.Reader
+	if b, ok := params["body"].(string); ok && b != "" {
+		body = strings.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	req.Header.Set("User-Agent", webUserAgent)
+	if headers, ok := params["headers"].(map[string]any); ok {
+		for k, v := range headers {
+			req.Header.Set(k, fmt.Sprint(v))
+		}
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
+	}
+	defer resp.Body.Close()
+
+	// Read one byte past the cap to detect truncation without buffering
+	// arbitrarily large responses.
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxChars)+1))
+	if err != nil {
+		return fmt.Sprintf("Error reading response: %v", err), nil
+	}
+	truncated := len(raw) > t.maxChars
+	if truncated {
+		raw = raw[:t.maxChars]
+	}
+
+	respHeaders := make(map[string]string, len(resp.Header))
+	for k := range resp.Header {
+		respHeaders[k] = resp.Header.Get(k)
+	}
+
+	out, _ := json.Marshal(map[string]any{
+		"status":    resp.StatusCode,
+		"headers":   respHeaders,
+		"body":      string(raw),
+		"truncated": truncated,
+	})
+	return string(out), nil
+}
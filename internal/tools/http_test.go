@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+type httpToolResult struct {
+	Status    int               `json:"status"`
+	Headers   map[string]string `json:"headers"`
+	Body      string            `json:"body"`
+	Truncated bool              `json:"truncated"`
+}
+
+func runHTTPTool(t *testing.T, tool *HTTPRequestTool, params map[string]any) httpToolResult {
+	t.Helper()
+	out, _ := tool.Execute(context.Background(), params)
+	var res httpToolResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("bad output %q: %v", out, err)
+	}
+	return res
+}
+
+func TestHTTPRequest_GET(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Rate-Limit", "99")
+		w.Write([]byte(`{"items":[1,2,3]}`))
+	}))
+	defer srv.Close()
+
+	tool := NewHTTPRequestTool(toolcfg.HTTPToolConfig{AllowPrivateNetworks: true})
+	res := runHTTPTool(t, tool, map[string]any{
+		"url":     srv.URL + "/items",
+		"headers": map[string]any{"Authorization": "Bearer abc"},
+	})
+
+	if res.Status != 200 || res.Body != `{"items":[1,2,3]}` || res.Headers["X-Rate-Limit"] != "99" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+func TestHTTPRequest_POSTTruncated(t *testing.T) {
+	var gotMethod, gotBody, gotType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotType = r.Method, r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(strings.Repeat("x", 50)))
+	}))
+	defer srv.Close()
+
+	tool := NewHTTPRequestTool(toolcfg.HTTPToolConfig{AllowPrivateNetworks: true, MaxResponseChars: 10})
+	res := runHTTPTool(t, tool, map[string]any{
+		"method":  "post",
+		"url":     srv.URL,
+		"headers": map[string]any{"Content-Type": "application/json"},
+		"body":    `{"name":"x"}`,
+	})
+
+	if gotMethod != http.MethodPost || gotBody != `{"name":"x"}` || gotType != "application/json" {
+		t.Errorf("unexpected request: method=%s type=%s body=%s", gotMethod, gotType, gotBody)
+	}
+	if res.Status != 201 || !res.Truncated || len(res.Body) != 10 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+func TestHTTPRequest_BlocksPrivateByDefault(t *testing.T) {
+	tool := NewHTTPRequestTool(toolcfg.DefaultHTTPToolConfig())
+	out, _ := tool.Execute(context.Background(), map[string]any{"url": "http://127.0.0.1:8080/admin"})
+	if !strings.Contains(out, "blocked address") {
+		t.Errorf("expected SSRF guard to reject loopback, got %q", out)
+	}
+}
@@ -45,6 +45,22 @@ func (t *MessageTool) Parameters() json.RawMessage {
 				"type": "array",
 				"items": {"type": "string"},
 				"description": "Optional: list of file paths to attach (images, audio, documents)"
+			},
+			"buttons": {
+				"type": "array",
+				"description": "Optional: inline buttons shown under the message (Telegram only), as rows of buttons. Pressing one sends its data back to you as a user message.",
+				"items": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"properties": {
+							"text": {"type": "string", "description": "Button label"},
+							"data": {"type": "string", "description": "Text sent back when pressed (default: the label; max 64 bytes)"},
+							"url": {"type": "string", "description": "Open this link instead of sending data"}
+						},
+						"required": ["text"]
+					}
+				}
 			}
 		},
 		"required": ["content"]
@@ -89,6 +105,15 @@ func (t *MessageTool) Execute(ctx context.Context, params map[string]any) (strin
 	if msgID != "" {
 		metadata["message_id"] = msgID
 	}
+	if raw, ok := params["buttons"]; ok {
+		rows, err := parseButtons(raw)
+		if err != nil {
+			return "Error: " + err.Error(), nil
+		}
+		if len(rows) > 0 {
+			metadata[bus.MetadataButtons] = rows
+		}
+	}
 
 	message := bus.NewChannelMessageBuilder(channel, chatID, content).
 		Media(media).
@@ -107,3 +132,37 @@ func (t *MessageTool) Execute(ctx context.Context, params map[string]any) (strin
 	}
 	return fmt.Sprintf("Message sent to %s:%s%s", channel, chatID, info), nil
 }
+
+// parseButtons converts the buttons parameter into rows of bus.Button.
+func parseButtons(raw any) ([][]bus.Button, error) {
+	rawRows, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("buttons must be an array of button rows")
+	}
+	var rows [][]bus.Button
+	for _, rr := range rawRows {
+		items, ok := rr.([]any)
+		if !ok {
+			return nil, fmt.Errorf("each buttons row must be an array")
+		}
+		var row []bus.Button
+		for _, item := range items {
+			obj, _ := item.(map[string]any)
+			text, _ := obj["text"].(string)
+			if text == "" {
+				return nil, fmt.Errorf("every button needs a text label")
+			}
+			b := bus.Button{Text: text}
+			b.Data, _ = obj["data"].(string)
+			b.URL, _ = obj["url"].(string)
+			if b.Data == "" && b.URL == "" {
+				b.Data = text
+			}
+			row = append(row, b)
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// blockedNets are ranges not covered by the net.IP classification helpers
+// that web_fetch must never reach.
+var blockedNets = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	out := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// isBlockedIP reports whether ip is loopback, private, link-local (which
+// includes the 169.254.169.254 cloud metadata endpoint), unspecified,
+// multicast, or in one of blockedNets.
+func isBlockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostGuard rejects URLs whose host resolves to an internal address, so the
+// model cannot use web_fetch to reach intranet services (SSRF).
+type hostGuard struct {
+	allowPrivate bool            // disables the check entirely
+	allowedHosts map[string]bool // hostnames exempt from the check
+	lookupIP     func(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+func newHostGuard(allowPrivate bool, allowedHosts []string) *hostGuard {
+	allowed := make(map[string]bool, len(allowedHosts))
+	for _, h := range allowedHosts {
+		allowed[strings.ToLower(h)] = true
+	}
+	return &hostGuard{
+		allowPrivate: allowPrivate,
+		allowedHosts: allowed,
+		lookupIP:     net.DefaultResolver.LookupIP,
+	}
+}
+
+// check resolves u's host and fails if any resolved address is blocked.
+// It gives an early, readable error; dialContext enforces the same rule on
+// the addresses actually connected to.
+func (g *hostGuard) check(ctx context.Context, u *url.URL) error {
+	_, err := g.resolve(ctx, u.Hostname())
+	return err
+}
+
+// resolve returns host's addresses, failing if any of them is blocked. It
+// returns nil addresses when the guard is off or host is exempt.
+func (g *hostGuard) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if g.allowPrivate {
+		return nil, nil
+	}
+	host = strings.ToLower(host)
+	if g.allowedHosts[host] {
+		return nil, nil
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		var err error
+		ips, err = g.lookupIP(ctx, "ip", host)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", host, err)
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("resolve %s: no addresses", host)
+		}
+	}
+	for _, ip := range ips {
+		if isBlockedIP(ip) {
+			return nil, fmt.Errorf("host %s resolves to blocked address %s", host, ip)
+		}
+	}
+	return ips, nil
+}
+
+// dialContext resolves addr's host itself and connects only to the addresses
+// it has just checked, so a DNS answer that changes after check (rebinding)
+// cannot steer the connection to an internal host.
+func (g *hostGuard) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	ips, err := g.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if ips == nil {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// newGuardedClient returns an HTTP client that follows at most maxRedirects
+// redirects, re-validates every redirect target against guard, and dials
+// through guard. Environment proxies are ignored while the guard is on:
+// the proxy, not the client, would pick the address connected to.
+func newGuardedClient(timeout time.Duration, guard *hostGuard) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = guard.dialContext
+	if !guard.allowPrivate {
+		transport.Proxy = nil
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if err := validateURL(req.URL.String()); err != nil {
+				return fmt.Errorf("redirect blocked: %w", err)
+			}
+			if err := guard.check(req.Context(), req.URL); err != nil {
+				return fmt.Errorf("redirect blocked: %w", err)
+			}
+			return nil
+		},
+	}
+}
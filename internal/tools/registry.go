@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 )
@@ -11,17 +12,25 @@ import (
 type ToolName string
 
 const (
-	ToolExec       ToolName = "exec"
-	ToolReadFile   ToolName = "read_file"
-	ToolWriteFile  ToolName = "write_file"
-	ToolEditFile   ToolName = "edit_file"
-	ToolListDir    ToolName = "list_dir"
-	ToolWebSearch  ToolName = "web_search"
-	ToolWebFetch   ToolName = "web_fetch"
-	ToolMessage    ToolName = "message"
-	ToolSpawn      ToolName = "spawn"
-	ToolCron       ToolName = "cron"
-	ToolSaveMemory ToolName = "save_memory"
+	ToolExec           ToolName = "exec"
+	ToolReadFile       ToolName = "read_file"
+	ToolWriteFile      ToolName = "write_file"
+	ToolAppendFile     ToolName = "append_file"
+	ToolEditFile       ToolName = "edit_file"
+	ToolDeleteFile     ToolName = "delete_file"
+	ToolMoveFile       ToolName = "move_file"
+	ToolListDir        ToolName = "list_dir"
+	ToolTree           ToolName = "tree"
+	ToolGrep           ToolName = "grep"
+	ToolWebSearch      ToolName = "web_search"
+	ToolWebFetch       ToolName = "web_fetch"
+	ToolMessage        ToolName = "message"
+	ToolSpawn          ToolName = "spawn"
+	ToolCron           ToolName = "cron"
+	ToolSaveMemory     ToolName = "save_memory"
+	ToolSearchSessions ToolName = "search_sessions"
+	ToolSearchMemory   ToolName = "search_memory"
+	ToolEditMemory     ToolName = "edit_memory"
 )
 
 // Registry holds a set of named tools and exposes them for execution.
@@ -35,7 +44,7 @@ func (r *Registry) Get(name ToolName) schema.Tool {
 }
 
 func (r *Registry) GetAll() ToolList {
-	list := ToolList{tools: make(map[string]schema.Tool, len(r.tools))}
+	list := ToolList{mu: new(sync.RWMutex), tools: make(map[string]schema.Tool, len(r.tools))}
 	for k, t := range r.tools {
 		list.tools[k] = t
 	}
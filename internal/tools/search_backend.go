@@ -0,0 +1,259 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+const (
+	braveSearchURL  = "https://api.search.brave.com/res/v1/web/search"
+	googleCSEURL    = "https://www.googleapis.com/customsearch/v1"
+	searchMaxOffset = 9 // page offset 0-9, in units of count (Brave's range)
+)
+
+// freshnessRe matches Brave-style freshness values: pd/pw/pm/py or a
+// YYYY-MM-DDtoYYYY-MM-DD date range.
+var freshnessRe = regexp.MustCompile(`^(pd|pw|pm|py|\d{4}-\d{2}-\d{2}to\d{4}-\d{2}-\d{2})$`)
+
+// searchRequest is the backend-neutral form of a web_search call.
+type searchRequest struct {
+	Query     string
+	Count     int
+	Offset    int    // page offset in units of Count
+	Freshness string // Brave-style: pd|pw|pm|py or a date range
+}
+
+// searchResult is one hit, as rendered by WebSearchTool.
+type searchResult struct {
+	Title       string
+	URL         string
+	Description string
+}
+
+// searchBackend is implemented by each supported search provider.
+type searchBackend interface {
+	search(ctx context.Context, req searchRequest) ([]searchResult, error)
+}
+
+// newSearchBackend selects the backend named by cfg.Provider.
+func newSearchBackend(cfg toolcfg.WebSearchConfig) searchBackend {
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch cfg.Provider {
+	case toolcfg.SearchProviderSearXNG:
+		return &searxngBackend{instanceURL: strings.TrimRight(cfg.InstanceURL, "/"), httpClient: client}
+	case toolcfg.SearchProviderGoogleCSE:
+		return &googleCSEBackend{apiKey: cfg.APIKey, cx: cfg.CX, endpoint: googleCSEURL, httpClient: client}
+	case "", toolcfg.SearchProviderBrave:
+		return &braveBackend{apiKey: cfg.APIKey, endpoint: braveSearchURL, httpClient: client}
+	default:
+		return unknownBackend(cfg.Provider)
+	}
+}
+
+// unknownBackend reports a misconfigured provider name on every call.
+type unknownBackend string
+
+func (b unknownBackend) search(context.Context, searchRequest) ([]searchResult, error) {
+	return nil, fmt.Errorf("unknown search provider %q (use brave, searxng or google_cse)", string(b))
+}
+
+// getJSON sends req and decodes a JSON response body into out.
+func getJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Brave
+// ---------------------------------------------------------------------------
+
+type braveBackend struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+func (b *braveBackend) search(ctx context.Context, sr searchRequest) ([]searchResult, error) {
+	if b.apiKey == "" {
+		return nil, errors.New("BRAVE_API_KEY not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Set("q", sr.Query)
+	q.Set("count", fmt.Sprintf("%d", sr.Count))
+	if sr.Offset > 0 {
+		q.Set("offset", fmt.Sprintf("%d", sr.Offset))
+	}
+	if sr.Freshness != "" {
+		q.Set("freshness", sr.Freshness)
+	}
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", b.apiKey)
+
+	var data struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := getJSON(b.httpClient, req, &data); err != nil {
+		return nil, err
+	}
+
+	out := make([]searchResult, 0, len(data.Web.Results))
+	for _, r := range data.Web.Results {
+		out = append(out, searchResult{Title: r.Title, URL: r.URL, Description: r.Description})
+	}
+	return out, nil
+}
+
+// ---------------------------------------------------------------------------
+// SearXNG
+// ---------------------------------------------------------------------------
+
+// searxngTimeRanges maps Brave-style freshness to SearXNG's time_range.
+// Custom date ranges have no SearXNG equivalent and are ignored.
+var searxngTimeRanges = map[string]string{
+	"pd": "day",
+	"pw": "week",
+	"pm": "month",
+	"py": "year",
+}
+
+type searxngBackend struct {
+	instanceURL string
+	httpClient  *http.Client
+}
+
+func (b *searxngBackend) search(ctx context.Context, sr searchRequest) ([]searchResult, error) {
+	if b.instanceURL == "" {
+		return nil, errors.New("SearXNG instanceUrl not configured")
+	}
+	form := url.Values{}
+	form.Set("q", sr.Query)
+	form.Set("format", "json")
+	form.Set("pageno", fmt.Sprintf("%d", sr.Offset+1))
+	if tr, ok := searxngTimeRanges[sr.Freshness]; ok {
+		form.Set("time_range", tr)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		b.instanceURL+"/search", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var data searxngResponse
+	if err := getJSON(b.httpClient, req, &data); err != nil {
+		return nil, err
+	}
+	return data.results(), nil
+}
+
+// searxngResponse is the subset of SearXNG's format=json body we use.
+type searxngResponse struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+func (r searxngResponse) results() []searchResult {
+	out := make([]searchResult, 0, len(r.Results))
+	for _, item := range r.Results {
+		out = append(out, searchResult{
+			Title:       item.Title,
+			URL:         item.URL,
+			Description: strings.TrimSpace(item.Content),
+		})
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Google Custom Search
+// ---------------------------------------------------------------------------
+
+// googleDateRestricts maps Brave-style freshness to CSE's dateRestrict.
+var googleDateRestricts = map[string]string{
+	"pd": "d1",
+	"pw": "w1",
+	"pm": "m1",
+	"py": "y1",
+}
+
+type googleCSEBackend struct {
+	apiKey     string
+	cx         string
+	endpoint   string
+	httpClient *http.Client
+}
+
+func (b *googleCSEBackend) search(ctx context.Context, sr searchRequest) ([]searchResult, error) {
+	if b.apiKey == "" || b.cx == "" {
+		return nil, errors.New("Google CSE apiKey and cx must both be configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Set("key", b.apiKey)
+	q.Set("cx", b.cx)
+	q.Set("q", sr.Query)
+	q.Set("num", fmt.Sprintf("%d", sr.Count))
+	if sr.Offset > 0 {
+		q.Set("start", fmt.Sprintf("%d", sr.Offset*sr.Count+1))
+	}
+	if dr, ok := googleDateRestricts[sr.Freshness]; ok {
+		q.Set("dateRestrict", dr)
+	}
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Accept", "application/json")
+
+	var data struct {
+		Items []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+		} `json:"items"`
+	}
+	if err := getJSON(b.httpClient, req, &data); err != nil {
+		return nil, err
+	}
+
+	out := make([]searchResult, 0, len(data.Items))
+	for _, r := range data.Items {
+		out = append(out, searchResult{Title: r.Title, URL: r.Link, Description: r.Snippet})
+	}
+	return out, nil
+}
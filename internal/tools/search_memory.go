@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+const (
+	memorySearchDefaultLimit = 10
+	memorySearchMaxLimit     = 50
+)
+
+// SearchMemoryTool searches the consolidated history (and optionally the
+// long-term memory) written by memory consolidation.
+type SearchMemoryTool struct {
+	store schema.MemoryStore
+}
+
+// NewSearchMemoryTool creates a SearchMemoryTool backed by the given MemoryStore.
+func NewSearchMemoryTool(store schema.MemoryStore) *SearchMemoryTool {
+	return &SearchMemoryTool{store: store}
+}
+
+func (t *SearchMemoryTool) Name() string { return string(ToolSearchMemory) }
+func (t *SearchMemoryTool) Description() string {
+	return "Search the long-running history log (memory/HISTORY.md) for entries containing all the given words, case-insensitively. " +
+		"Returns matching entries with their [YYYY-MM-DD HH:MM] timestamps, newest first. " +
+		"Set include_long_term to also search MEMORY.md. " +
+		"Set semantic to instead rank history entries by similarity of meaning, for when the exact wording is unknown."
+}
+
+func (t *SearchMemoryTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"query": {"type": "string", "description": "Words to look for; an entry must contain all of them"},
+			"limit": {"type": "integer", "description": "Maximum entries to return (default 10, max 50)"},
+			"include_long_term": {"type": "boolean", "description": "Also search MEMORY.md (default false)"},
+			"semantic": {"type": "boolean", "description": "Rank HISTORY.md entries by meaning instead of requiring every word; falls back to word search when embeddings are not configured (default false)"}
+		},
+		"required": ["query"]
+	}`)
+}
+
+func (t *SearchMemoryTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	query, _ := params["query"].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "Error: query is required", nil
+	}
+	limit := memorySearchDefaultLimit
+	if n, ok := intParam(params, "limit"); ok && n > 0 {
+		limit = min(n, memorySearchMaxLimit)
+	}
+	longTerm, _ := params["include_long_term"].(bool)
+	semantic, _ := params["semantic"].(bool)
+
+	var matches []schema.MemoryMatch
+	if semantic {
+		matches = t.store.RecallMemory(ctx, query, limit)
+	} else {
+		matches = t.store.Search(query, limit, longTerm)
+	}
+	if len(matches) == 0 {
+		return fmt.Sprintf("No memory entries match %q.", query), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d match(es) for %q:\n", len(matches), query)
+	for _, m := range matches {
+		if m.Timestamp != "" {
+			fmt.Fprintf(&sb, "\n[%s] %s\n", m.Timestamp, m.Text)
+		} else {
+			fmt.Fprintf(&sb, "\n(%s) %s\n", m.Source, m.Text)
+		}
+	}
+	return sb.String(), nil
+}
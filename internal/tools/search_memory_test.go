@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/schema"
+)
+
+// fakeMemory returns fixed matches and records the last search.
+type fakeMemory struct {
+	schema.MemoryStore
+	matches  []schema.MemoryMatch
+	limit    int
+	longTerm bool
+	recalled bool
+}
+
+func (m *fakeMemory) Search(_ string, limit int, longTerm bool) []schema.MemoryMatch {
+	m.limit, m.longTerm = limit, longTerm
+	return m.matches
+}
+
+func (m *fakeMemory) RecallMemory(_ context.Context, _ string, k int) []schema.MemoryMatch {
+	m.limit, m.recalled = k, true
+	return m.matches
+}
+
+func TestSearchMemoryTool(t *testing.T) {
+	mem := &fakeMemory{matches: []schema.MemoryMatch{
+		{Source: "HISTORY.md", Timestamp: "2026-01-05 18:40", Text: "Moved the backup to S3."},
+		{Source: "MEMORY.md", Text: "Backups live in eu-west-1."},
+	}}
+	tool := NewSearchMemoryTool(mem)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"query": "backup", "limit": 500.0, "include_long_term": true})
+	want := "2 match(es) for \"backup\":\n\n[2026-01-05 18:40] Moved the backup to S3.\n\n(MEMORY.md) Backups live in eu-west-1.\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+	if mem.limit != memorySearchMaxLimit || !mem.longTerm {
+		t.Errorf("expected capped limit and long-term search, got limit %d longTerm %v", mem.limit, mem.longTerm)
+	}
+
+	if mem.recalled {
+		t.Error("expected word search without semantic")
+	}
+	if _, _ = tool.Execute(context.Background(), map[string]any{"query": "backup", "semantic": true}); !mem.recalled || mem.limit != memorySearchDefaultLimit {
+		t.Errorf("expected semantic recall with default limit, got recalled %v limit %d", mem.recalled, mem.limit)
+	}
+
+	mem.matches = nil
+	if out, _ := tool.Execute(context.Background(), map[string]any{"query": "x"}); out != `No memory entries match "x".` {
+		t.Errorf("unexpected empty result: %q", out)
+	}
+	if out, _ := tool.Execute(context.Background(), map[string]any{}); out != "Error: query is required" {
+		t.Errorf("expected missing query error, got %q", out)
+	}
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	agentcfg "github.com/crystaldolphin/crystaldolphin/internal/config/agent"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+)
+
+const (
+	sessionSearchDefaultLimit = 10
+	sessionSearchMaxLimit     = 50
+	sessionSnippetChars       = 240 // context kept around each match
+)
+
+// SessionSearcher finds past messages; session.SessionStore implements it.
+type SessionSearcher interface {
+	Search(query string, limit int) []session.SearchHit
+}
+
+// SearchSessionsTool searches past conversations for a phrase. Under a
+// channel or session workspace scope it only sees the caller's channel or
+// session, matching the isolation of the file tools.
+type SearchSessionsTool struct {
+	sessions SessionSearcher
+	scope    string // agents.defaults.workspaceScope
+}
+
+// NewSearchSessionsTool creates a SearchSessionsTool over the given store.
+func NewSearchSessionsTool(sessions SessionSearcher, scope string) *SearchSessionsTool {
+	return &SearchSessionsTool{sessions: sessions, scope: scope}
+}
+
+func (t *SearchSessionsTool) Name() string { return string(ToolSearchSessions) }
+func (t *SearchSessionsTool) Description() string {
+	which := "all channels and chats"
+	switch t.scope {
+	case agentcfg.WorkspaceScopeChannel:
+		which = "chats on the current channel"
+	case agentcfg.WorkspaceScopeSession:
+		which = "the current conversation"
+	}
+	return "Search past conversations (" + which + ") for a phrase, case-insensitively. " +
+		"Returns matching message snippets with their session and last-updated time, newest session first. " +
+		"Use it to recall earlier decisions or details that are no longer in the current context."
+}
+
+func (t *SearchSessionsTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"query": {"type": "string", "description": "Text to look for (plain substring, not a regex)"},
+			"limit": {"type": "integer", "description": "Maximum matches to return (default 10, max 50)"}
+		},
+		"required": ["query"]
+	}`)
+}
+
+func (t *SearchSessionsTool) Execute(ctx context.Context, params map[string]any) (string, error) {
+	query, _ := params["query"].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "Error: query is required", nil
+	}
+	limit := sessionSearchDefaultLimit
+	if n, ok := intParam(params, "limit"); ok && n > 0 {
+		limit = min(n, sessionSearchMaxLimit)
+	}
+
+	hits := t.search(ctx, query, limit)
+	if len(hits) == 0 {
+		return fmt.Sprintf("No past messages match %q.", query), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d match(es) for %q:\n", len(hits), query)
+	for _, h := range hits {
+		when := "unknown time"
+		if !h.UpdatedAt.IsZero() {
+			when = h.UpdatedAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(&sb, "\n[%s, updated %s] %s: %s\n", h.Key, when, h.Role, matchSnippet(h.Content, query))
+	}
+	return sb.String(), nil
+}
+
+// search returns up to limit hits visible to the current turn.
+func (t *SearchSessionsTool) search(ctx context.Context, query string, limit int) []session.SearchHit {
+	turn := TurnCtx(ctx)
+	var visible func(key string) bool
+	switch t.scope {
+	case agentcfg.WorkspaceScopeChannel:
+		prefix := string(turn.Channel) + ":"
+		visible = func(key string) bool { return turn.Channel != "" && strings.HasPrefix(key, prefix) }
+	case agentcfg.WorkspaceScopeSession:
+		visible = func(key string) bool { return turn.SessionKey != "" && key == turn.SessionKey }
+	default:
+		return t.sessions.Search(query, limit)
+	}
+
+	var hits []session.SearchHit
+	for _, h := range t.sessions.Search(query, 0) {
+		if visible(h.Key) {
+			hits = append(hits, h)
+			if len(hits) == limit {
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// matchSnippet returns up to sessionSnippetChars of text centred on the first
+// case-insensitive occurrence of query, on a single line.
+func matchSnippet(text, query string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= sessionSnippetChars {
+		return text
+	}
+	// Locate the match in runes; lower-casing can change byte lengths.
+	lower := []rune(strings.ToLower(text))
+	needle := []rune(strings.ToLower(query))
+	at := 0
+	for i := 0; i+len(needle) <= len(lower); i++ {
+		if string(lower[i:i+len(needle)]) == string(needle) {
+			at = i
+			break
+		}
+	}
+	start := max(at-(sessionSnippetChars-len(needle))/2, 0)
+	end := min(start+sessionSnippetChars, len(runes))
+	start = max(end-sessionSnippetChars, 0)
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
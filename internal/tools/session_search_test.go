@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crystaldolphin/crystaldolphin/internal/bus"
+	agentcfg "github.com/crystaldolphin/crystaldolphin/internal/config/agent"
+	"github.com/crystaldolphin/crystaldolphin/internal/session"
+)
+
+func TestSearchSessions_FixtureFiles(t *testing.T) {
+	ws := t.TempDir()
+	writeTree(t, filepath.Join(ws, "sessions"), map[string]string{
+		"telegram_42.jsonl": `{"_type":"metadata","key":"telegram:42","created_at":"2026-10-01T09:00:00Z","updated_at":"2026-10-08T14:03:00Z","metadata":{"topic":"Postgres"}}
+{"role":"user","content":"Should we use Postgres or SQLite?","timestamp":"2026-10-08T14:00:00Z"}
+{"role":"assistant","content":"We decided on POSTGRES for the ledger service.","timestamp":"2026-10-08T14:01:00Z"}
+`,
+		"cli_direct.jsonl": `{"_type":"metadata","key":"cli:direct","created_at":"2026-10-10T09:00:00Z","updated_at":"2026-10-10T09:30:00Z","metadata":{}}
+{"role":"user","content":"` + strings.Repeat("filler ", 100) + `postgres tuning notes ` + strings.Repeat("tail ", 100) + `","timestamp":"2026-10-10T09:00:00Z"}
+`,
+	})
+	mgr, err := session.NewManager(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewSearchSessionsTool(mgr, agentcfg.WorkspaceScopeShared)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"query": "postgres"})
+	if !strings.HasPrefix(out, `3 match(es) for "postgres"`) {
+		t.Fatalf("expected 3 matches, got:\n%s", out)
+	}
+	if strings.Index(out, "cli:direct") > strings.Index(out, "telegram:42") {
+		t.Errorf("expected newest session first:\n%s", out)
+	}
+	if !strings.Contains(out, "[telegram:42, updated 2026-10-08") || !strings.Contains(out, "assistant: We decided on POSTGRES") {
+		t.Errorf("expected session key, time and role in output:\n%s", out)
+	}
+	if strings.Contains(out, "Postgres\"}") || strings.Contains(out, "topic") {
+		t.Errorf("metadata lines must not be searched:\n%s", out)
+	}
+	if !strings.Contains(out, "user: …") || !strings.Contains(out, "postgres tuning notes") || strings.Count(out, "tail") > 40 {
+		t.Errorf("expected long message cut to a snippet around the match:\n%s", out)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"query": "postgres", "limit": float64(1)})
+	if !strings.HasPrefix(out, "1 match(es)") {
+		t.Errorf("expected limit to cap results, got:\n%s", out)
+	}
+	if out, _ := tool.Execute(context.Background(), map[string]any{"query": "mongodb"}); !strings.HasPrefix(out, "No past messages") {
+		t.Errorf("unexpected result for no matches: %s", out)
+	}
+	if out, _ := tool.Execute(context.Background(), map[string]any{"query": "  "}); out != "Error: query is required" {
+		t.Errorf("unexpected result for empty query: %s", out)
+	}
+}
+
+func TestSearchSessions_ScopedToCaller(t *testing.T) {
+	store := session.NewInMemoryStore()
+	for key, text := range map[string]string{
+		"telegram:1": "the launch code is alpha",
+		"telegram:2": "the launch code is bravo",
+		"discord:3":  "the launch code is charlie",
+	} {
+		s := store.GetOrCreate(key)
+		s.AddUser(text)
+		store.Save(s)
+	}
+	ctx := WithTurn(context.Background(), TurnContext{Channel: bus.ChannelTelegram, ChatID: "1", SessionKey: "telegram:1"})
+
+	out, _ := NewSearchSessionsTool(store, agentcfg.WorkspaceScopeSession).Execute(ctx, map[string]any{"query": "launch code"})
+	if !strings.Contains(out, "alpha") || strings.Contains(out, "bravo") || strings.Contains(out, "charlie") {
+		t.Errorf("session scope should only see the caller's session:\n%s", out)
+	}
+
+	out, _ = NewSearchSessionsTool(store, agentcfg.WorkspaceScopeChannel).Execute(ctx, map[string]any{"query": "launch code"})
+	if !strings.Contains(out, "alpha") || !strings.Contains(out, "bravo") || strings.Contains(out, "charlie") {
+		t.Errorf("channel scope should only see the caller's channel:\n%s", out)
+	}
+
+	out, _ = NewSearchSessionsTool(store, agentcfg.WorkspaceScopeSession).Execute(context.Background(), map[string]any{"query": "launch code"})
+	if !strings.HasPrefix(out, "No past messages") {
+		t.Errorf("a scoped search without a turn should see nothing:\n%s", out)
+	}
+}
@@ -1,7 +1,6 @@
 package tools
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,6 +10,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
 )
 
 // denyPatterns mirrors Python ExecTool's deny_patterns exactly.
@@ -31,20 +32,41 @@ type ExecTool struct {
 	timeout             time.Duration
 	workingDir          string
 	restrictToWorkspace bool
+	sandbox             string // toolcfg.ExecSandbox*
+	image               string
+	network             bool
+	policy              execPolicy
+	maxOutput           int           // bytes of stdout+stderr kept
+	progressEvery       time.Duration // streaming flush interval
 }
 
 // NewExecTool creates an ExecTool.
 // workingDir is the default CWD (empty = os.Getwd()).
 // restrictToWorkspace enables workspace path restriction.
-func NewExecTool(workingDir string, timeoutSeconds int, restrictToWorkspace bool) *ExecTool {
+func NewExecTool(workingDir string, cfg toolcfg.ExecToolConfig, restrictToWorkspace bool) *ExecTool {
 	t := 60
-	if timeoutSeconds > 0 {
-		t = timeoutSeconds
+	if cfg.Timeout > 0 {
+		t = cfg.Timeout
+	}
+	def := toolcfg.DefaultExecToolConfig()
+	image := cfg.Image
+	if image == "" {
+		image = def.Image
+	}
+	maxOutput := cfg.MaxOutputBytes
+	if maxOutput <= 0 {
+		maxOutput = def.MaxOutputBytes
 	}
 	return &ExecTool{
 		timeout:             time.Duration(t) * time.Second,
 		workingDir:          workingDir,
 		restrictToWorkspace: restrictToWorkspace,
+		sandbox:             cfg.Sandbox,
+		image:               image,
+		network:             cfg.Network,
+		policy:              newExecPolicy(cfg.Allow, cfg.Deny),
+		maxOutput:           maxOutput,
+		progressEvery:       500 * time.Millisecond,
 	}
 }
 
@@ -75,35 +97,76 @@ func (e *ExecTool) Execute(ctx context.Context, params map[string]any) (string,
 		return "Error: command is required", nil
 	}
 
-	cwd := e.workingDir
+	cwd, mount, restrict := e.workingDir, e.workingDir, e.restrictToWorkspace
+	ws := TurnCtx(ctx).Workspace
+	if ws != "" {
+		// guardCommand is a heuristic ("cd .. && cat x" gets past it), so an
+		// isolated conversation only runs commands in the docker sandbox,
+		// where the bind mount of its own workspace is the real boundary.
+		if e.sandbox != toolcfg.ExecSandboxDocker {
+			return "Error: exec requires tools.exec.sandbox=docker when agents.defaults.workspaceScope is channel or session", nil
+		}
+		cwd, mount, restrict = ws, ws, true
+	}
 	if wd, ok := params["working_dir"].(string); ok && wd != "" {
 		cwd = wd
+		if ws != "" {
+			resolved, err := resolvePath(wd, ws, ws)
+			if err != nil {
+				return "Error: " + err.Error(), nil
+			}
+			cwd = resolved
+		}
 	}
 	if cwd == "" {
 		cwd, _ = os.Getwd()
 	}
 
-	if guard := e.guardCommand(command, cwd); guard != "" {
+	if guard := e.guardCommand(command, cwd, restrict); guard != "" {
 		return guard, nil
 	}
 
 	cmdCtx, cancel := context.WithTimeout(ctx, e.timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "sh", "-c", command)
-	cmd.Dir = cwd
+	var cmd *exec.Cmd
+	var container string
+	switch e.sandbox {
+	case toolcfg.ExecSandboxNone:
+		cmd = exec.CommandContext(cmdCtx, "sh", "-c", command)
+		cmd.Dir = cwd
+	case toolcfg.ExecSandboxDocker:
+		docker, err := exec.LookPath("docker")
+		if err != nil {
+			return "Error: exec sandbox is docker but docker was not found in PATH", nil
+		}
+		container = sandboxContainerName()
+		args, err := e.dockerArgs(command, mount, cwd, container)
+		if err != nil {
+			return "Error: " + err.Error(), nil
+		}
+		cmd = exec.CommandContext(cmdCtx, docker, args...)
+	default:
+		return fmt.Sprintf("Error: unknown exec sandbox %q", e.sandbox), nil
+	}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	out := newExecOutput(e.maxOutput)
+	cmd.Stdout = out.writer(false)
+	cmd.Stderr = out.writer(true)
 
+	stopStreaming := out.streamTo(TurnCtx(ctx).Progress, e.progressEvery)
 	runErr := cmd.Run()
+	stopStreaming()
+	if container != "" && cmdCtx.Err() != nil {
+		// Killing the docker CLI leaves the container running.
+		killContainer(container)
+	}
 
 	var parts []string
-	if out := stdout.String(); out != "" {
-		parts = append(parts, out)
+	if stdout := out.stdout.String(); stdout != "" {
+		parts = append(parts, stdout)
 	}
-	if errOut := stderr.String(); strings.TrimSpace(errOut) != "" {
+	if errOut := out.stderr.String(); strings.TrimSpace(errOut) != "" {
 		parts = append(parts, "STDERR:\n"+errOut)
 	}
 	if runErr != nil && cmd.ProcessState != nil && cmd.ProcessState.ExitCode() != 0 {
@@ -117,15 +180,17 @@ func (e *ExecTool) Execute(ctx context.Context, params map[string]any) (string,
 	if result == "" {
 		result = "(no output)"
 	}
-	const maxLen = 10000
-	if len(result) > maxLen {
-		result = result[:maxLen] + fmt.Sprintf("\n... (truncated, %d more chars)", len(result)-maxLen)
+	if e.sandbox == toolcfg.ExecSandboxDocker {
+		result = e.sandboxLabel() + "\n" + result
+	}
+	if out.dropped > 0 {
+		result += fmt.Sprintf("\n... (truncated, %d more bytes)", out.dropped)
 	}
 	return result, nil
 }
 
 // guardCommand implements Python's _guard_command safety check.
-func (e *ExecTool) guardCommand(command, cwd string) string {
+func (e *ExecTool) guardCommand(command, cwd string, restrictToWorkspace bool) string {
 	lower := strings.ToLower(strings.TrimSpace(command))
 
 	for _, p := range denyPatterns {
@@ -133,8 +198,11 @@ func (e *ExecTool) guardCommand(command, cwd string) string {
 			return "Error: Command blocked by safety guard (dangerous pattern detected)"
 		}
 	}
+	if msg := e.policy.check(command); msg != "" {
+		return msg
+	}
 
-	if e.restrictToWorkspace {
+	if restrictToWorkspace {
 		if strings.Contains(command, `..\\`) || strings.Contains(command, "../") {
 			return "Error: Command blocked by safety guard (path traversal detected)"
 		}
@@ -151,7 +219,7 @@ func (e *ExecTool) guardCommand(command, cwd string) string {
 			if err != nil {
 				p = filepath.Clean(raw)
 			}
-			if filepath.IsAbs(p) && !strings.HasPrefix(p, cwdResolved) && p != cwdResolved {
+			if filepath.IsAbs(p) && !withinDir(p, cwdResolved) {
 				return "Error: Command blocked by safety guard (path outside working dir)"
 			}
 		}
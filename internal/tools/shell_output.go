@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// execOutput collects a command's stdout and stderr up to a byte limit,
+// discarding (but counting) the rest so huge output cannot exhaust memory.
+// Everything kept is also queued in arrival order for progress streaming.
+type execOutput struct {
+	mu      sync.Mutex
+	limit   int
+	stdout  bytes.Buffer
+	stderr  bytes.Buffer
+	pending bytes.Buffer // kept output not yet streamed, stdout and stderr interleaved
+	dropped int
+}
+
+func newExecOutput(limit int) *execOutput {
+	return &execOutput{limit: limit}
+}
+
+// writer returns the io.Writer for stdout or stderr.
+func (o *execOutput) writer(stderr bool) io.Writer {
+	return execStream{o: o, stderr: stderr}
+}
+
+type execStream struct {
+	o      *execOutput
+	stderr bool
+}
+
+func (s execStream) Write(p []byte) (int, error) {
+	o := s.o
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	keep := p
+	if room := max(o.limit-o.stdout.Len()-o.stderr.Len(), 0); len(keep) > room {
+		keep = keep[:room]
+		o.dropped += len(p) - room
+	}
+	if s.stderr {
+		o.stderr.Write(keep)
+	} else {
+		o.stdout.Write(keep)
+	}
+	o.pending.Write(keep)
+	return len(p), nil
+}
+
+// takePending returns and clears output not yet streamed.
+func (o *execOutput) takePending() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := o.pending.String()
+	o.pending.Reset()
+	return s
+}
+
+// streamTo sends new output to progress every interval until the returned
+// stop function is called. Commands that finish before the first flush
+// stream nothing; once streaming has started, stop flushes the remainder so
+// the streamed output is complete. A nil progress disables streaming.
+func (o *execOutput) streamTo(progress func(string), every time.Duration) (stop func()) {
+	if progress == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		streamed := false
+		t := time.NewTicker(every)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				if s := o.takePending(); s != "" {
+					progress(s)
+					streamed = true
+				}
+			case <-done:
+				if s := o.takePending(); streamed && s != "" {
+					progress(s)
+				}
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+func TestExec_OutputCap(t *testing.T) {
+	cfg := toolcfg.DefaultExecToolConfig()
+	cfg.MaxOutputBytes = 100
+	tool := NewExecTool(t.TempDir(), cfg, false)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{
+		"command": `head -c 5000 /dev/zero | tr '\0' x`,
+	})
+	if !strings.HasPrefix(out, strings.Repeat("x", 100)+"\n... (truncated, 4900 more bytes)") {
+		t.Errorf("expected 100 bytes and a truncation marker, got %q", out)
+	}
+}
+
+func TestExec_StreamsInterleavedOutput(t *testing.T) {
+	tool := NewExecTool(t.TempDir(), toolcfg.DefaultExecToolConfig(), false)
+	tool.progressEvery = 20 * time.Millisecond
+
+	var (
+		mu     sync.Mutex
+		chunks []string
+	)
+	ctx := WithTurn(context.Background(), TurnContext{Progress: func(s string) {
+		mu.Lock()
+		chunks = append(chunks, s)
+		mu.Unlock()
+	}})
+
+	out, _ := tool.Execute(ctx, map[string]any{
+		"command": "echo out1; sleep 0.2; echo err1 >&2; sleep 0.2; echo out2",
+	})
+	if out != "out1\nout2\n\nSTDERR:\nerr1\n" {
+		t.Errorf("unexpected final result %q", out)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(chunks) < 2 {
+		t.Errorf("expected output streamed in several chunks, got %q", chunks)
+	}
+	if got := strings.Join(chunks, ""); got != "out1\nerr1\nout2\n" {
+		t.Errorf("expected streamed output in arrival order, got %q", got)
+	}
+}
+
+func TestExec_FastCommandDoesNotStream(t *testing.T) {
+	tool := NewExecTool(t.TempDir(), toolcfg.DefaultExecToolConfig(), false)
+	called := false
+	ctx := WithTurn(context.Background(), TurnContext{Progress: func(string) { called = true }})
+
+	if out, _ := tool.Execute(ctx, map[string]any{"command": "echo quick"}); out != "quick\n" {
+		t.Errorf("unexpected result %q", out)
+	}
+	if called {
+		t.Error("a command finishing before the first flush should not stream")
+	}
+}
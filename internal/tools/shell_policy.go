@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// execWrappers are commands that run their arguments as another command;
+// they are stripped so policies see the real program.
+var execWrappers = map[string]bool{
+	"sudo": true, "env": true, "command": true, "builtin": true,
+	"exec": true, "nohup": true, "time": true, "nice": true,
+}
+
+var envAssignRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// execPolicy applies the tools.exec.allow / tools.exec.deny regex lists.
+// Patterns are matched against the raw command and against each simple
+// command it contains, after unquoting and resolving the program name, so
+// quoted or escaped names, /bin/rm and "sudo rm" are all seen as "rm ...".
+type execPolicy struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+	err   error // invalid pattern; every command is refused
+}
+
+func newExecPolicy(allow, deny []string) execPolicy {
+	var p execPolicy
+	p.allow, p.err = compilePatterns("allow", allow)
+	if p.err == nil {
+		p.deny, p.err = compilePatterns("deny", deny)
+	}
+	return p
+}
+
+func compilePatterns(list string, patterns []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, s := range patterns {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tools.exec.%s pattern %q: %v", list, s, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// check returns a rejection message, or "" when command may run.
+func (p execPolicy) check(command string) string {
+	if p.err != nil {
+		return "Error: " + p.err.Error()
+	}
+	if len(p.allow) == 0 && len(p.deny) == 0 {
+		return ""
+	}
+
+	var resolved []string
+	for _, argv := range splitShellCommands(command) {
+		if cmd := normalizeArgv(argv); cmd != "" {
+			resolved = append(resolved, cmd)
+		}
+	}
+
+	for _, re := range p.deny {
+		if re.MatchString(command) {
+			return fmt.Sprintf("Error: Command blocked by exec policy (matches deny pattern %q)", re)
+		}
+		for _, cmd := range resolved {
+			if re.MatchString(cmd) {
+				return fmt.Sprintf("Error: Command blocked by exec policy (%q matches deny pattern %q)", cmd, re)
+			}
+		}
+	}
+
+	if len(p.allow) > 0 {
+		for _, cmd := range resolved {
+			if !matchesAny(p.allow, cmd) {
+				return fmt.Sprintf("Error: Command blocked by exec policy (%q is not in the allowlist)", cmd)
+			}
+		}
+	}
+	return ""
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeArgv drops leading VAR=value assignments and wrapper commands
+// (with their flags), reduces the program to its base name, and joins argv
+// with single spaces.
+func normalizeArgv(argv []string) string {
+	for len(argv) > 0 {
+		switch {
+		case envAssignRE.MatchString(argv[0]):
+			argv = argv[1:]
+		case execWrappers[filepath.Base(argv[0])]:
+			argv = argv[1:]
+			for len(argv) > 0 && strings.HasPrefix(argv[0], "-") {
+				argv = argv[1:]
+			}
+		default:
+			out := append([]string{filepath.Base(argv[0])}, argv[1:]...)
+			return strings.Join(out, " ")
+		}
+	}
+	return ""
+}
+
+// splitShellCommands splits a shell command line into the argv of each simple
+// command it runs, including those inside $(...) and backticks. Quotes and
+// backslash escapes are removed as the shell would. It is a best-effort lexer
+// for policy checks, not a full shell parser.
+func splitShellCommands(s string) [][]string {
+	var (
+		cmds   [][]string
+		argv   []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+	)
+	flushWord := func() {
+		if inWord {
+			argv = append(argv, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	flushCmd := func() {
+		flushWord()
+		if len(argv) > 0 {
+			cmds = append(cmds, argv)
+			argv = nil
+		}
+	}
+
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		if quote == '\'' {
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+			continue
+		}
+
+		// Command substitution is checked as its own command; its text stays
+		// in the enclosing word.
+		if r == '`' || (r == '$' && i+1 < len(rs) && rs[i+1] == '(') {
+			end := substitutionEnd(rs, i)
+			cmds = append(cmds, splitShellCommands(string(rs[substitutionStart(rs, i):end]))...)
+			next := min(end+1, len(rs))
+			word.WriteString(string(rs[i:next]))
+			inWord = true
+			i = next - 1
+			continue
+		}
+
+		if quote == '"' {
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(rs) && strings.ContainsRune("\"\\$`", rs[i+1]):
+				i++
+				word.WriteRune(rs[i])
+			default:
+				word.WriteRune(r)
+			}
+			continue
+		}
+
+		switch {
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			if i+1 < len(rs) {
+				i++
+				if rs[i] != '\n' {
+					word.WriteRune(rs[i])
+					inWord = true
+				}
+			}
+		case r == ' ' || r == '\t':
+			flushWord()
+		case strings.ContainsRune(";&|\n()", r):
+			flushCmd()
+		case r == '<' || r == '>':
+			flushWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	flushCmd()
+	return cmds
+}
+
+// substitutionStart returns the index of the first rune inside the command
+// substitution beginning at i ("$(" or "`").
+func substitutionStart(rs []rune, i int) int {
+	if rs[i] == '`' {
+		return i + 1
+	}
+	return i + 2
+}
+
+// substitutionEnd returns the index of the rune closing the substitution that
+// begins at i, or len(rs) if it is unterminated.
+func substitutionEnd(rs []rune, i int) int {
+	if rs[i] == '`' {
+		for j := i + 1; j < len(rs); j++ {
+			if rs[j] == '`' {
+				return j
+			}
+		}
+		return len(rs)
+	}
+	depth := 0
+	for j := i + 2; j < len(rs); j++ {
+		switch rs[j] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return j
+			}
+			depth--
+		}
+	}
+	return len(rs)
+}
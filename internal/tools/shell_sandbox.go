@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// dockerArgs builds the `docker run` arguments for command. The workspace,
+// mount, is bind-mounted read-write at its host path so paths in commands keep
+// working, networking is off unless enabled, and the command runs as the host
+// user so files it creates are not root-owned.
+func (e *ExecTool) dockerArgs(command, mount, cwd, container string) ([]string, error) {
+	if mount == "" {
+		mount = cwd
+	}
+	mount, err := filepath.Abs(mount)
+	if err != nil {
+		return nil, err
+	}
+	cwd, err = filepath.Abs(cwd)
+	if err != nil {
+		return nil, err
+	}
+	if !withinDir(cwd, mount) {
+		return nil, fmt.Errorf("working_dir %s is outside the sandboxed workspace %s", cwd, mount)
+	}
+
+	args := []string{"run", "--rm", "--name", container}
+	if !e.network {
+		args = append(args, "--network", "none")
+	}
+	if uid := os.Getuid(); uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+	}
+	args = append(args,
+		"-v", mount+":"+mount,
+		"-w", cwd,
+		e.image, "sh", "-c", command,
+	)
+	return args, nil
+}
+
+// sandboxLabel describes the sandbox a command ran in, for the tool result.
+func (e *ExecTool) sandboxLabel() string {
+	network := "off"
+	if e.network {
+		network = "on"
+	}
+	return fmt.Sprintf("[sandbox: docker %s, network %s]", e.image, network)
+}
+
+// sandboxContainerName returns a unique name so a timed-out container can be
+// removed.
+func sandboxContainerName() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "crystaldolphin-exec-" + hex.EncodeToString(b)
+}
+
+// killContainer force-removes a sandbox container.
+func killContainer(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := exec.CommandContext(ctx, "docker", "rm", "-f", name).Run(); err != nil {
+		slog.Warn("exec sandbox: failed to remove container", "container", name, "err", err)
+	}
+}
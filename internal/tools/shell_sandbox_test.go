@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+func TestExecSandbox_DockerArgs(t *testing.T) {
+	ws := t.TempDir()
+	tool := NewExecTool(ws, toolcfg.ExecToolConfig{Sandbox: toolcfg.ExecSandboxDocker}, false)
+	sub := filepath.Join(ws, "src")
+
+	args, err := tool.dockerArgs("make test", ws, sub, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(args, " ")
+	for _, want := range []string{
+		"run --rm --name c1 --network none",
+		"-v " + ws + ":" + ws,
+		"-w " + sub,
+		"alpine:3 sh -c make test",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+	if args[len(args)-1] != "make test" {
+		t.Errorf("command must be a single argument, got %q", args[len(args)-1])
+	}
+
+	if _, err := tool.dockerArgs("ls", ws, t.TempDir(), "c2"); err == nil {
+		t.Error("expected working_dir outside the workspace to be rejected")
+	}
+}
+
+func TestExecSandbox_NetworkAndImage(t *testing.T) {
+	ws := t.TempDir()
+	tool := NewExecTool(ws, toolcfg.ExecToolConfig{
+		Sandbox: toolcfg.ExecSandboxDocker, Image: "golang:1.23", Network: true,
+	}, false)
+
+	args, err := tool.dockerArgs("go version", ws, ws, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(args, "none") {
+		t.Errorf("network should be enabled: %q", args)
+	}
+	if !slices.Contains(args, "golang:1.23") {
+		t.Errorf("expected configured image: %q", args)
+	}
+	if label := tool.sandboxLabel(); label != "[sandbox: docker golang:1.23, network on]" {
+		t.Errorf("unexpected label %q", label)
+	}
+}
+
+func TestExecSandbox_UnknownModeAndHostUnchanged(t *testing.T) {
+	ws := t.TempDir()
+	out, _ := NewExecTool(ws, toolcfg.ExecToolConfig{Sandbox: "vm"}, false).
+		Execute(context.Background(), map[string]any{"command": "echo hi"})
+	if !strings.Contains(out, `unknown exec sandbox "vm"`) {
+		t.Errorf("expected unknown sandbox error, got %q", out)
+	}
+
+	out, _ = NewExecTool(ws, toolcfg.DefaultExecToolConfig(), false).
+		Execute(context.Background(), map[string]any{"command": "echo hi"})
+	if out != "hi\n" {
+		t.Errorf("host mode output should be unchanged, got %q", out)
+	}
+}
+
+func TestExecSandbox_DockerRun(t *testing.T) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker not available")
+	}
+	if err := exec.Command("docker", "image", "inspect", "alpine:3").Run(); err != nil {
+		t.Skip("alpine:3 image not present locally")
+	}
+	ws := t.TempDir()
+	tool := NewExecTool(ws, toolcfg.ExecToolConfig{Sandbox: toolcfg.ExecSandboxDocker}, false)
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"command": "echo sandboxed > out.txt && cat out.txt"})
+	if !strings.HasPrefix(out, "[sandbox: docker alpine:3, network off]\nsandboxed") {
+		t.Errorf("unexpected output %q", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(ws, "out.txt")); string(data) != "sandboxed\n" {
+		t.Errorf("expected file written through the bind mount, got %q", data)
+	}
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+func TestExec_TurnWorkspaceRequiresDocker(t *testing.T) {
+	root := t.TempDir()
+	ws := filepath.Join(root, "scopes", "a")
+	if err := os.MkdirAll(ws, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewExecTool(root, toolcfg.DefaultExecToolConfig(), false)
+	ctx := WithTurn(context.Background(), TurnContext{Workspace: ws})
+
+	for _, cmd := range []string{"pwd", "cd .. && ls"} {
+		if out, _ := tool.Execute(ctx, map[string]any{"command": cmd}); !strings.Contains(out, "requires tools.exec.sandbox=docker") {
+			t.Errorf("%s: expected exec to be refused outside the sandbox, got %q", cmd, out)
+		}
+	}
+	if out, _ := tool.Execute(context.Background(), map[string]any{"command": "pwd"}); strings.TrimSpace(out) != root {
+		t.Errorf("shared workspace should still run on the host, got %q", out)
+	}
+}
+
+func TestExec_TurnWorkspaceConfinesSandbox(t *testing.T) {
+	root := t.TempDir()
+	ws := filepath.Join(root, "scopes", "a")
+	if err := os.MkdirAll(ws, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewExecTool(root, toolcfg.ExecToolConfig{Sandbox: toolcfg.ExecSandboxDocker}, false)
+	ctx := WithTurn(context.Background(), TurnContext{Workspace: ws})
+
+	if out, _ := tool.Execute(ctx, map[string]any{"command": "ls", "working_dir": root}); !strings.Contains(out, "outside allowed directory") {
+		t.Errorf("expected working_dir outside the turn workspace to be rejected, got %q", out)
+	}
+	if out, _ := tool.Execute(ctx, map[string]any{"command": "cat " + ws + "-other/x"}); !strings.Contains(out, "path outside working dir") {
+		t.Errorf("expected sibling path sharing the workspace prefix to be blocked, got %q", out)
+	}
+	args, err := tool.dockerArgs("ls", ws, ws, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(args, " "); !strings.Contains(got, "-v "+ws+":"+ws) {
+		t.Errorf("expected only the turn workspace to be mounted, got %q", got)
+	}
+}
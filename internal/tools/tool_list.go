@@ -2,18 +2,24 @@ package tools
 
 import (
 	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
 
 	"github.com/crystaldolphin/crystaldolphin/internal/schema"
 )
 
 // ToolList holds a named set of tools and exposes them for LLM calls and
-// runtime extension (e.g. MCP servers).
+// runtime extension (e.g. MCP servers). It is safe for concurrent use: MCP
+// servers may add and remove tools while a turn reads the list. Copies share
+// the same tools and lock; build lists with NewToolList or Registry.GetAll.
 type ToolList struct {
+	mu    *sync.RWMutex
 	tools map[string]schema.Tool
 }
 
 func NewToolList(ts ...schema.Tool) *ToolList {
-	list := ToolList{tools: make(map[string]schema.Tool, len(ts))}
+	list := ToolList{mu: new(sync.RWMutex), tools: make(map[string]schema.Tool, len(ts))}
 	for _, t := range ts {
 		list.tools[t.Name()] = t
 	}
@@ -23,20 +29,35 @@ func NewToolList(ts ...schema.Tool) *ToolList {
 
 // Get returns the tool with the given name, or nil if not found.
 func (r *ToolList) Get(name string) schema.Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.tools[name]
 }
 
 // Add registers a new tool, replacing any existing tool with the same name.
 func (r *ToolList) Add(t schema.Tool) schema.Tool {
+	r.mu.Lock()
 	r.tools[t.Name()] = t
+	r.mu.Unlock()
 
 	return t
 }
 
-// Definitions returns all tool definitions in OpenAI function-calling format.
+// Remove unregisters the tool with the given name, if present.
+func (r *ToolList) Remove(name string) {
+	r.mu.Lock()
+	delete(r.tools, name)
+	r.mu.Unlock()
+}
+
+// Definitions returns all tool definitions in OpenAI function-calling format,
+// sorted by name so the request prefix is stable across turns.
 func (r *ToolList) Definitions() []map[string]any {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	list := make([]map[string]any, 0, len(r.tools))
-	for _, t := range r.tools {
+	for _, name := range slices.Sorted(maps.Keys(r.tools)) {
+		t := r.tools[name]
 		var params any
 		if err := json.Unmarshal(t.Parameters(), &params); err != nil {
 			params = map[string]any{"type": "object", "properties": map[string]any{}}
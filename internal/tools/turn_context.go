@@ -14,10 +14,30 @@ type TurnContext struct {
 	ChatID  string
 	MsgID   string
 
+	// SenderID is the user who sent the message that started the turn.
+	SenderID string
+
+	// Unattended is set when nobody can answer a prompt during the turn
+	// (one-shot CLI runs), so tool approvals are denied rather than awaited.
+	Unattended bool
+
+	// SessionKey identifies the conversation (its routing key), or the
+	// subagent task, for logging.
+	SessionKey string
+
+	// Workspace, when set, is this conversation's own workspace directory
+	// (agents.defaults.workspaceScope). File and exec tools use it in place
+	// of the configured workspace and cannot reach outside it.
+	Workspace string
+
 	// MessageSent is closed by MessageTool.Execute when it delivers a message.
 	// The agent loop checks it after runLoop via a non-blocking receive to
 	// decide whether to suppress the automatic reply.
 	MessageSent chan struct{}
+
+	// Progress, when set, delivers intermediate output (such as streamed exec
+	// output) to the user while a tool is still running.
+	Progress func(string)
 }
 
 type turnKey struct{}
@@ -33,3 +53,13 @@ func TurnCtx(ctx context.Context) TurnContext {
 	tc, _ := ctx.Value(turnKey{}).(TurnContext)
 	return tc
 }
+
+// scopedDirs returns the workspace and allowed directory a file tool should
+// use for this call: the turn's isolated workspace for both when one is set,
+// otherwise the tool's configured pair.
+func scopedDirs(ctx context.Context, workspace, allowedDir string) (string, string) {
+	if ws := TurnCtx(ctx).Workspace; ws != "" {
+		return ws, ws
+	}
+	return workspace, allowedDir
+}
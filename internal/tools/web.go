@@ -6,13 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-shiori/go-readability"
+	"github.com/ledongthuc/pdf"
+	"golang.org/x/net/html/charset"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
 )
 
 const (
@@ -39,23 +45,23 @@ func validateURL(rawURL string) error {
 // WebSearchTool
 // ---------------------------------------------------------------------------
 
-// WebSearchTool searches the web using the Brave Search API.
+// WebSearchTool searches the web through the configured search backend
+// (Brave by default, or SearXNG / Google CSE).
 type WebSearchTool struct {
-	apiKey     string
+	backend    searchBackend
 	maxResults int
-	httpClient *http.Client
 }
 
-// NewWebSearchTool creates a WebSearchTool.
-// apiKey is BRAVE_API_KEY; maxResults defaults to 5.
-func NewWebSearchTool(apiKey string, maxResults int) *WebSearchTool {
+// NewWebSearchTool creates a WebSearchTool from the search config.
+// maxResults defaults to 5; an empty provider selects Brave.
+func NewWebSearchTool(cfg toolcfg.WebSearchConfig) *WebSearchTool {
+	maxResults := cfg.MaxResults
 	if maxResults <= 0 {
 		maxResults = 5
 	}
 	return &WebSearchTool{
-		apiKey:     apiKey,
+		backend:    newSearchBackend(cfg),
 		maxResults: maxResults,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
@@ -76,6 +82,16 @@ func (t *WebSearchTool) Parameters() json.RawMessage {
 				"description": "Results (1-10)",
 				"minimum": 1,
 				"maximum": 10
+			},
+			"offset": {
+				"type": "integer",
+				"description": "Page offset in units of count (0-9) for deeper results",
+				"minimum": 0,
+				"maximum": 9
+			},
+			"freshness": {
+				"type": "string",
+				"description": "Restrict to recent results: pd (day), pw (week), pm (month), py (year), or YYYY-MM-DDtoYYYY-MM-DD"
 			}
 		},
 		"required": ["query"]
@@ -83,9 +99,6 @@ func (t *WebSearchTool) Parameters() json.RawMessage {
 }
 
 func (t *WebSearchTool) Execute(ctx context.Context, params map[string]any) (string, error) {
-	if t.apiKey == "" {
-		return "Error: BRAVE_API_KEY not configured", nil
-	}
 	query, _ := params["query"].(string)
 	if query == "" {
 		return "Error: query is required", nil
@@ -107,38 +120,29 @@ func (t *WebSearchTool) Execute(ctx context.Context, params map[string]any) (str
 		n = 10
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
-		"https://api.search.brave.com/res/v1/web/search", nil)
-	if err != nil {
-		return fmt.Sprintf("Error: %v", err), nil
+	offset := 0
+	switch v := params["offset"].(type) {
+	case float64:
+		offset = int(v)
+	case int:
+		offset = v
 	}
-	q := req.URL.Query()
-	q.Set("q", query)
-	q.Set("count", fmt.Sprintf("%d", n))
-	req.URL.RawQuery = q.Encode()
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-Subscription-Token", t.apiKey)
+	offset = max(0, min(offset, searchMaxOffset))
 
-	resp, err := t.httpClient.Do(req)
-	if err != nil {
-		return fmt.Sprintf("Error: %v", err), nil
+	freshness, _ := params["freshness"].(string)
+	if freshness != "" && !freshnessRe.MatchString(freshness) {
+		return fmt.Sprintf("Error: invalid freshness %q (use pd, pw, pm, py or YYYY-MM-DDtoYYYY-MM-DD)", freshness), nil
 	}
-	defer resp.Body.Close()
 
-	var data struct {
-		Web struct {
-			Results []struct {
-				Title       string `json:"title"`
-				URL         string `json:"url"`
-				Description string `json:"description"`
-			} `json:"results"`
-		} `json:"web"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return fmt.Sprintf("Error parsing response: %v", err), nil
+	results, err := t.backend.search(ctx, searchRequest{
+		Query:     query,
+		Count:     n,
+		Offset:    offset,
+		Freshness: freshness,
+	})
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err), nil
 	}
-
-	results := data.Web.Results
 	if len(results) == 0 {
 		return fmt.Sprintf("No results for: %s", query), nil
 	}
@@ -165,25 +169,28 @@ func (t *WebSearchTool) Execute(ctx context.Context, params map[string]any) (str
 // WebFetchTool fetches a URL and extracts readable content.
 type WebFetchTool struct {
 	maxChars   int
+	guard      *hostGuard
+	cache      *fetchCache // nil when caching is disabled
 	httpClient *http.Client
 }
 
 // NewWebFetchTool creates a WebFetchTool. maxChars defaults to 50000.
-func NewWebFetchTool(maxChars int) *WebFetchTool {
+// URLs resolving to private, loopback, or link-local addresses are rejected
+// (including after redirects) unless cfg allows them. Results are cached for
+// cfg.CacheTTLSeconds; zero disables the cache.
+func NewWebFetchTool(cfg toolcfg.WebFetchConfig) *WebFetchTool {
+	maxChars := cfg.MaxChars
 	if maxChars <= 0 {
 		maxChars = 50000
 	}
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= maxRedirects {
-				return fmt.Errorf("stopped after %d redirects", maxRedirects)
-			}
-			return nil
-		},
+	guard := newHostGuard(cfg.AllowPrivateNetworks, cfg.AllowedHosts)
+	client := newGuardedClient(30*time.Second, guard)
+	var cache *fetchCache
+	if cfg.CacheTTLSeconds > 0 {
+		cache = newFetchCache(time.Duration(cfg.CacheTTLSeconds)*time.Second, fetchCacheSize)
 	}
-	return &WebFetchTool{maxChars: maxChars, httpClient: client}
+	return &WebFetchTool{maxChars: maxChars, guard: guard, cache: cache, httpClient: client}
 }
 
 func (t *WebFetchTool) Name() string { return "web_fetch" }
@@ -225,6 +232,14 @@ func (t *WebFetchTool) Execute(ctx context.Context, params map[string]any) (stri
 		})
 		return string(result), nil
 	}
+	parsed, _ := url.Parse(rawURL)
+	if err := t.guard.check(ctx, parsed); err != nil {
+		result, _ := json.Marshal(map[string]any{
+			"error": fmt.Sprintf("URL validation failed: %v", err),
+			"url":   rawURL,
+		})
+		return string(result), nil
+	}
 
 	extractMode := "markdown"
 	if m, ok := params["extractMode"].(string); ok && m != "" {
@@ -240,6 +255,18 @@ func (t *WebFetchTool) Execute(ctx context.Context, params map[string]any) (stri
 		}
 	}
 
+	key := fetchCacheKey(rawURL, extractMode, maxChars)
+	if cached, ok := t.cache.get(key); ok {
+		hit := make(map[string]any, len(cached)+1)
+		for k, v := range cached {
+			hit[k] = v
+		}
+		hit["url"] = rawURL
+		hit["cached"] = true
+		out, _ := json.Marshal(hit)
+		return string(out), nil
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
 		out, _ := json.Marshal(map[string]any{"error": err.Error(), "url": rawURL})
@@ -266,6 +293,16 @@ func (t *WebFetchTool) Execute(ctx context.Context, params map[string]any) (stri
 	var text, extractor string
 
 	switch {
+	case strings.Contains(ctype, "application/pdf") || bytes.HasPrefix(bodyBytes, []byte("%PDF-")):
+		if pdfText, err := extractPDFText(bodyBytes); err == nil {
+			text = pdfText
+			extractor = "pdf"
+		} else {
+			slog.Debug("web_fetch: PDF extraction failed, returning raw body", "url", rawURL, "err", err)
+			text = string(bodyBytes)
+			extractor = "raw"
+		}
+
 	case strings.Contains(ctype, "application/json"):
 		var jsonData any
 		if err := json.Unmarshal(bodyBytes, &jsonData); err == nil {
@@ -277,6 +314,7 @@ func (t *WebFetchTool) Execute(ctx context.Context, params map[string]any) (stri
 		extractor = "json"
 
 	case strings.Contains(ctype, "text/html") || isHTMLPrefix(bodyBytes):
+		bodyBytes = decodeToUTF8(bodyBytes, ctype)
 		parsedURL, _ := url.Parse(rawURL)
 		article, err := readability.FromReader(bytes.NewReader(bodyBytes), parsedURL)
 		if err == nil {
@@ -295,6 +333,9 @@ func (t *WebFetchTool) Execute(ctx context.Context, params map[string]any) (stri
 		extractor = "readability"
 
 	default:
+		if strings.HasPrefix(ctype, "text/") {
+			bodyBytes = decodeToUTF8(bodyBytes, ctype)
+		}
 		text = string(bodyBytes)
 		extractor = "raw"
 	}
@@ -304,7 +345,7 @@ func (t *WebFetchTool) Execute(ctx context.Context, params map[string]any) (stri
 		text = text[:maxChars]
 	}
 
-	out, _ := json.Marshal(map[string]any{
+	result := map[string]any{
 		"url":       rawURL,
 		"finalUrl":  finalURL,
 		"status":    resp.StatusCode,
@@ -312,10 +353,35 @@ func (t *WebFetchTool) Execute(ctx context.Context, params map[string]any) (stri
 		"truncated": truncated,
 		"length":    len(text),
 		"text":      text,
-	})
+	}
+	if resp.StatusCode == http.StatusOK {
+		t.cache.put(key, result)
+		if finalURL != rawURL {
+			t.cache.put(fetchCacheKey(finalURL, extractMode, maxChars), result)
+		}
+	}
+
+	out, _ := json.Marshal(result)
 	return string(out), nil
 }
 
+// decodeToUTF8 transcodes body to UTF-8 using the charset from the
+// Content-Type header, falling back to a BOM or <meta charset> in the first
+// 1 KB. Bodies that are already valid UTF-8 without an explicit header
+// charset are returned as-is, as are bodies that fail to decode.
+func decodeToUTF8(body []byte, contentType string) []byte {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" || (!certain && utf8.Valid(body)) {
+		return body
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		slog.Debug("web_fetch: charset decode failed", "charset", name, "err", err)
+		return body
+	}
+	return decoded
+}
+
 // isHTMLPrefix returns true if the body starts with an HTML declaration.
 func isHTMLPrefix(b []byte) bool {
 	prefix := strings.ToLower(strings.TrimSpace(string(b[:min(256, len(b))])))
@@ -329,6 +395,36 @@ func min(a, b int) int {
 	return b
 }
 
+// extractPDFText returns the plain text of every page, separated by blank
+// lines. The PDF parser panics on some malformed inputs, so panics are
+// converted to errors.
+func extractPDFText(data []byte) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("parse PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	pages := make([]string, 0, r.NumPage())
+	for i := 1; i <= r.NumPage(); i++ {
+		p := r.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		pageText, err := p.GetPlainText(nil)
+		if err != nil {
+			return "", fmt.Errorf("page %d: %w", i, err)
+		}
+		pages = append(pages, strings.TrimSpace(pageText))
+	}
+	return strings.Join(pages, "\n\n"), nil
+}
+
 // ---------------------------------------------------------------------------
 // HTML → text/markdown helpers
 // ---------------------------------------------------------------------------
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// fetchCacheSize bounds the number of web_fetch results kept in memory.
+const fetchCacheSize = 128
+
+// fetchCache is a small LRU of web_fetch results with a per-entry TTL.
+// Keys combine URL, extract mode, and maxChars so differing requests for
+// the same page never share an entry.
+type fetchCache struct {
+	ttl      time.Duration
+	capacity int
+	now      func() time.Time // injectable for tests
+
+	mu      sync.Mutex
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+type fetchCacheEntry struct {
+	key     string
+	result  map[string]any
+	expires time.Time
+}
+
+func newFetchCache(ttl time.Duration, capacity int) *fetchCache {
+	return &fetchCache{
+		ttl:      ttl,
+		capacity: capacity,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func fetchCacheKey(url, extractMode string, maxChars int) string {
+	return fmt.Sprintf("%s|%s|%d", url, extractMode, maxChars)
+}
+
+// get returns the cached result for key, or false on a miss or expiry.
+func (c *fetchCache) get(key string) (map[string]any, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*fetchCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.result, true
+}
+
+// put stores result under key, evicting the least recently used entry when full.
+func (c *fetchCache) put(key string, result map[string]any) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*fetchCacheEntry)
+		entry.result, entry.expires = result, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&fetchCacheEntry{key: key, result: result, expires: expires})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*fetchCacheEntry).key)
+	}
+}
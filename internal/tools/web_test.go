@@ -0,0 +1,404 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	toolcfg "github.com/crystaldolphin/crystaldolphin/internal/config/tool"
+)
+
+func newTestSearchTool(t *testing.T, got *url.Values) *WebSearchTool {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*got = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"web":{"results":[{"title":"T","url":"https://example.com","description":"D"}]}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	tool := NewWebSearchTool(toolcfg.WebSearchConfig{APIKey: "key", MaxResults: 5})
+	tool.backend.(*braveBackend).endpoint = srv.URL
+	return tool
+}
+
+func TestWebSearch_OffsetAndFreshnessInQuery(t *testing.T) {
+	var got url.Values
+	tool := newTestSearchTool(t, &got)
+
+	_, err := tool.Execute(context.Background(), map[string]any{
+		"query":     "golang",
+		"offset":    float64(2),
+		"freshness": "pw",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("offset") != "2" {
+		t.Errorf("expected offset=2, got %q", got.Get("offset"))
+	}
+	if got.Get("freshness") != "pw" {
+		t.Errorf("expected freshness=pw, got %q", got.Get("freshness"))
+	}
+	if got.Get("count") != "5" {
+		t.Errorf("expected count=5, got %q", got.Get("count"))
+	}
+}
+
+func TestWebSearch_OffsetClamped(t *testing.T) {
+	var got url.Values
+	tool := newTestSearchTool(t, &got)
+
+	tool.Execute(context.Background(), map[string]any{"query": "q", "offset": float64(50)})
+	if got.Get("offset") != "9" {
+		t.Errorf("expected offset clamped to 9, got %q", got.Get("offset"))
+	}
+
+	tool.Execute(context.Background(), map[string]any{"query": "q"})
+	if got.Has("offset") || got.Has("freshness") {
+		t.Errorf("expected no offset/freshness by default, got %v", got)
+	}
+}
+
+// searxngRecorded is a trimmed SearXNG format=json response.
+const searxngRecorded = `{
+	"query": "golang generics",
+	"number_of_results": 0,
+	"results": [
+		{
+			"url": "https://go.dev/doc/tutorial/generics",
+			"title": "Tutorial: Getting started with generics",
+			"content": "  This tutorial introduces the basics of generics in Go.  ",
+			"engine": "duckduckgo",
+			"engines": ["duckduckgo", "bing"],
+			"score": 4.0
+		},
+		{
+			"url": "https://go.dev/blog/intro-generics",
+			"title": "An Introduction To Generics",
+			"content": "",
+			"engine": "google",
+			"score": 1.5
+		}
+	],
+	"answers": [],
+	"suggestions": ["golang generics constraints"]
+}`
+
+func TestSearXNG_ResultMapping(t *testing.T) {
+	var form url.Values
+	var method string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		r.ParseForm()
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(searxngRecorded))
+	}))
+	defer srv.Close()
+
+	tool := NewWebSearchTool(toolcfg.WebSearchConfig{
+		Provider:    toolcfg.SearchProviderSearXNG,
+		InstanceURL: srv.URL + "/",
+	})
+
+	out, _ := tool.Execute(context.Background(), map[string]any{
+		"query":     "golang generics",
+		"offset":    float64(1),
+		"freshness": "pm",
+	})
+
+	if method != http.MethodPost {
+		t.Errorf("expected POST, got %s", method)
+	}
+	if form.Get("format") != "json" || form.Get("pageno") != "2" || form.Get("time_range") != "month" {
+		t.Errorf("unexpected form: %v", form)
+	}
+
+	want := "Results for: golang generics\n\n" +
+		"1. Tutorial: Getting started with generics\n   https://go.dev/doc/tutorial/generics\n" +
+		"   This tutorial introduces the basics of generics in Go.\n" +
+		"2. An Introduction To Generics\n   https://go.dev/blog/intro-generics\n"
+	if out != want {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestWebSearch_UnknownProvider(t *testing.T) {
+	tool := NewWebSearchTool(toolcfg.WebSearchConfig{Provider: "bing"})
+	out, _ := tool.Execute(context.Background(), map[string]any{"query": "q"})
+	if !strings.Contains(out, "unknown search provider") {
+		t.Errorf("expected unknown provider error, got %q", out)
+	}
+}
+
+// fakeLookup resolves hostnames from a fixed table.
+func fakeLookup(table map[string]string) func(context.Context, string, string) ([]net.IP, error) {
+	return func(_ context.Context, _, host string) ([]net.IP, error) {
+		if ip, ok := table[host]; ok {
+			return []net.IP{net.ParseIP(ip)}, nil
+		}
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+}
+
+func TestWebFetch_BlocksPrivateAddresses(t *testing.T) {
+	tool := NewWebFetchTool(toolcfg.DefaultWebFetchConfig())
+	tool.guard.lookupIP = fakeLookup(map[string]string{
+		"loopback.test": "127.0.0.1",
+		"intranet.test": "10.1.2.3",
+	})
+
+	for _, u := range []string{
+		"http://loopback.test/",
+		"http://intranet.test/admin",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]:8080/",
+	} {
+		out, _ := tool.Execute(context.Background(), map[string]any{"url": u})
+		if !strings.Contains(out, "blocked address") {
+			t.Errorf("%s: expected blocked, got %s", u, out)
+		}
+	}
+}
+
+func TestWebFetch_AllowPrivateNetworks(t *testing.T) {
+	cfg := toolcfg.DefaultWebFetchConfig()
+	cfg.AllowedHosts = []string{"intranet.test"}
+	tool := NewWebFetchTool(cfg)
+	tool.guard.lookupIP = fakeLookup(map[string]string{"intranet.test": "10.1.2.3"})
+
+	if err := tool.guard.check(context.Background(), &url.URL{Host: "intranet.test"}); err != nil {
+		t.Errorf("expected allowed host to pass, got %v", err)
+	}
+
+	cfg = toolcfg.WebFetchConfig{AllowPrivateNetworks: true}
+	if err := NewWebFetchTool(cfg).guard.check(context.Background(), &url.URL{Host: "127.0.0.1"}); err != nil {
+		t.Errorf("expected private networks allowed, got %v", err)
+	}
+}
+
+func TestWebFetch_BlocksRedirectToPrivate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://intranet.test/secret", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	tool := NewWebFetchTool(toolcfg.DefaultWebFetchConfig())
+	tool.guard.lookupIP = fakeLookup(map[string]string{
+		"public.test":   "93.184.216.34",
+		"intranet.test": "10.0.0.5",
+	})
+	// Route every connection to the test server regardless of hostname.
+	tool.httpClient.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"url": "http://public.test/"})
+	if !strings.Contains(out, "redirect blocked") {
+		t.Errorf("expected redirect to be blocked, got %s", out)
+	}
+}
+
+func TestWebFetch_BlocksDNSRebinding(t *testing.T) {
+	tool := NewWebFetchTool(toolcfg.DefaultWebFetchConfig())
+	// The first lookup (the pre-check) sees a public address, every later one
+	// (the dial) an internal one.
+	var lookups int
+	tool.guard.lookupIP = func(_ context.Context, _, _ string) ([]net.IP, error) {
+		lookups++
+		if lookups == 1 {
+			return []net.IP{net.ParseIP("93.184.216.34")}, nil
+		}
+		return []net.IP{net.ParseIP("127.0.0.1")}, nil
+	}
+
+	out, _ := tool.Execute(context.Background(), map[string]any{"url": "http://rebind.test/"})
+	if !strings.Contains(out, "blocked address 127.0.0.1") {
+		t.Errorf("expected the dialed address to be blocked, got %s", out)
+	}
+	if lookups < 2 {
+		t.Errorf("expected the dial to resolve the host again, got %d lookups", lookups)
+	}
+}
+
+// buildTestPDF returns a minimal PDF with one Helvetica text line per page.
+func buildTestPDF(pages ...string) []byte {
+	n := len(pages)
+	var objs []string
+	kids := make([]string, n)
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objs = append(objs,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), n),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+	for i, text := range pages {
+		stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		objs = append(objs,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		)
+	}
+
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objs))
+	for i, o := range objs {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	return []byte(b.String())
+}
+
+func TestWebFetch_ExtractsPDFText(t *testing.T) {
+	body := buildTestPDF("Hello from page one", "Second page text")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream") // detected by magic bytes
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	tool := NewWebFetchTool(toolcfg.WebFetchConfig{AllowPrivateNetworks: true})
+	out, _ := tool.Execute(context.Background(), map[string]any{"url": srv.URL + "/doc.pdf"})
+
+	var res struct {
+		Extractor string `json:"extractor"`
+		Text      string `json:"text"`
+		Truncated bool   `json:"truncated"`
+	}
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("bad output %s: %v", out, err)
+	}
+	if res.Extractor != "pdf" {
+		t.Fatalf("expected pdf extractor, got %q (%s)", res.Extractor, res.Text)
+	}
+	if res.Text != "Hello from page one\n\nSecond page text" {
+		t.Errorf("unexpected text: %q", res.Text)
+	}
+
+	out, _ = tool.Execute(context.Background(), map[string]any{"url": srv.URL + "/doc.pdf", "maxChars": float64(10)})
+	json.Unmarshal([]byte(out), &res)
+	if !res.Truncated || res.Text != "Hello from" {
+		t.Errorf("expected text truncated to maxChars, got %+v", res)
+	}
+}
+
+func TestExtractPDFText_Malformed(t *testing.T) {
+	if _, err := extractPDFText([]byte("%PDF-1.4\ngarbage")); err == nil {
+		t.Error("expected error for malformed PDF")
+	}
+}
+
+func TestWebFetch_CacheHitMissAndExpiry(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("plain body"))
+	}))
+	defer srv.Close()
+
+	tool := NewWebFetchTool(toolcfg.WebFetchConfig{AllowPrivateNetworks: true, CacheTTLSeconds: 60})
+	now := time.Unix(1_700_000_000, 0)
+	tool.cache.now = func() time.Time { return now }
+
+	fetch := func(params map[string]any) map[string]any {
+		t.Helper()
+		out, _ := tool.Execute(context.Background(), params)
+		var res map[string]any
+		if err := json.Unmarshal([]byte(out), &res); err != nil {
+			t.Fatalf("bad output %s: %v", out, err)
+		}
+		return res
+	}
+	params := map[string]any{"url": srv.URL + "/page"}
+
+	if res := fetch(params); res["cached"] != nil || hits != 1 {
+		t.Fatalf("expected miss on first fetch, hits=%d res=%v", hits, res)
+	}
+	if res := fetch(params); res["cached"] != true || res["text"] != "plain body" || hits != 1 {
+		t.Fatalf("expected cache hit, hits=%d res=%v", hits, res)
+	}
+
+	// A different maxChars is a different entry.
+	fetch(map[string]any{"url": srv.URL + "/page", "maxChars": float64(100)})
+	if hits != 2 {
+		t.Errorf("expected maxChars change to miss, hits=%d", hits)
+	}
+
+	now = now.Add(61 * time.Second)
+	if res := fetch(params); res["cached"] != nil || hits != 3 {
+		t.Errorf("expected miss after TTL expiry, hits=%d res=%v", hits, res)
+	}
+}
+
+func TestFetchCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newFetchCache(time.Minute, 2)
+	c.put("a", map[string]any{})
+	c.put("b", map[string]any{})
+	c.get("a")
+	c.put("c", map[string]any{})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("expected a retained")
+	}
+}
+
+func TestWebFetch_DecodesGBK(t *testing.T) {
+	const chinese = "你好，世界。这是一个用于测试字符集转换的中文网页。"
+	html := `<html><head><meta charset="gb2312"><title>测试页面</title></head>` +
+		`<body><article><p>` + chinese + `</p></article></body></html>`
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := simplifiedchinese.GBK.NewEncoder().String(chinese)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			w.Header().Set("Content-Type", "text/plain; charset=GBK")
+			w.Write([]byte(plain))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html") // charset only in <meta>
+		w.Write([]byte(gbk))
+	}))
+	defer srv.Close()
+
+	tool := NewWebFetchTool(toolcfg.WebFetchConfig{AllowPrivateNetworks: true})
+	for _, path := range []string{"/page", "/plain"} {
+		out, _ := tool.Execute(context.Background(), map[string]any{"url": srv.URL + path, "extractMode": "text"})
+		var res struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal([]byte(out), &res); err != nil {
+			t.Fatalf("%s: bad output %s", path, out)
+		}
+		if !strings.Contains(res.Text, chinese) {
+			t.Errorf("%s: expected decoded Chinese text, got %q", path, res.Text)
+		}
+	}
+}